@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/olivier-w/climp/internal/player"
+)
+
+// benchJSON is the --bench report. Timings and allocations are averaged over
+// the iterations; each iteration opens and decodes the whole file.
+type benchJSON struct {
+	File           string  `json:"file"`
+	Iterations     int     `json:"iterations"`
+	SampleRate     int     `json:"sample_rate"`
+	Channels       int     `json:"channels"`
+	Frames         int64   `json:"frames"`
+	AudioSeconds   float64 `json:"audio_seconds"`
+	DecodeSeconds  float64 `json:"decode_seconds"`
+	FramesPerSec   float64 `json:"frames_per_sec"`
+	RealtimeFactor float64 `json:"realtime_factor"`
+	Allocs         uint64  `json:"allocs_per_run"`
+	AllocBytes     uint64  `json:"alloc_bytes_per_run"`
+}
+
+// benchDecode decodes the --bench file opts.iterations times with climp's
+// own decoders, draining the PCM into io.Discard, and writes the averaged
+// throughput to w as JSON. Like --decode it never opens an audio device or
+// runs ffmpeg, so a file the native decoders reject is an error.
+func benchDecode(w io.Writer, opts cliOptions) error {
+	if err := checkMediaFile("--bench", opts.bench); err != nil {
+		return err
+	}
+	iterations := max(opts.iterations, 1)
+	report := benchJSON{File: opts.bench, Iterations: iterations}
+	buf := make([]byte, 64*1024)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var elapsed time.Duration
+	for range iterations {
+		start := time.Now()
+		s, err := player.OpenPCM(opts.bench, 0, 0)
+		if err != nil {
+			return fmt.Errorf("%s: %w", opts.bench, err)
+		}
+		n, err := io.CopyBuffer(io.Discard, s, buf)
+		s.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", opts.bench, err)
+		}
+		elapsed += time.Since(start)
+		report.SampleRate = s.SampleRate()
+		report.Channels = s.ChannelCount()
+		report.Frames = n / int64(2*s.ChannelCount())
+	}
+	runtime.ReadMemStats(&after)
+
+	runs := uint64(iterations)
+	report.Allocs = (after.Mallocs - before.Mallocs) / runs
+	report.AllocBytes = (after.TotalAlloc - before.TotalAlloc) / runs
+	report.DecodeSeconds = elapsed.Seconds() / float64(iterations)
+	if report.SampleRate > 0 {
+		report.AudioSeconds = float64(report.Frames) / float64(report.SampleRate)
+	}
+	if report.DecodeSeconds > 0 {
+		report.FramesPerSec = float64(report.Frames) / report.DecodeSeconds
+		report.RealtimeFactor = report.AudioSeconds / report.DecodeSeconds
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
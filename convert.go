@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olivier-w/climp/internal/player"
+)
+
+// convertFormats are the --convert-to output formats.
+var convertFormats = []string{"wav", "flac"}
+
+// convertDir converts every supported audio file under dir (and its
+// subdirectories) to format with climp's own decoders, writing each result
+// next to its source. Files already in format, or whose output file exists,
+// are skipped. Progress goes to w, one line per file, and a failed file does
+// not stop the batch; the returned error reports how many failed.
+func convertDir(w io.Writer, dir, format string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	files, _ := walkAudioTree(root, 0)
+
+	var converted, skipped, failed int
+	for i, src := range files {
+		name, err := filepath.Rel(root, src)
+		if err != nil {
+			name = src
+		}
+		prefix := fmt.Sprintf("[%d/%d] %s", i+1, len(files), name)
+
+		ext := filepath.Ext(src)
+		if strings.EqualFold(ext, "."+format) {
+			fmt.Fprintf(w, "%s: skipped, already %s\n", prefix, format)
+			skipped++
+			continue
+		}
+		dst := strings.TrimSuffix(src, ext) + "." + format
+		if _, err := os.Stat(dst); err == nil {
+			fmt.Fprintf(w, "%s: skipped, %s exists\n", prefix, filepath.Base(dst))
+			skipped++
+			continue
+		}
+		if err := convertFile(src, dst, format); err != nil {
+			fmt.Fprintf(w, "%s: failed: %v\n", prefix, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "%s -> %s\n", prefix, filepath.Base(dst))
+		converted++
+	}
+
+	fmt.Fprintf(w, "Converted %d, skipped %d, failed %d\n", converted, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed to convert", failed, len(files))
+	}
+	return nil
+}
+
+// convertFile decodes src and writes it to dst as format. dst must not
+// exist; a partial dst is removed on failure.
+func convertFile(src, dst, format string) error {
+	s, err := player.OpenPCM(src, 0, 0)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "flac":
+		_, err = s.WriteFLAC(out)
+	default:
+		_, err = s.WriteWAV(out)
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+	}
+	return err
+}
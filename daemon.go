@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/downloader"
+	"github.com/olivier-w/climp/internal/ui"
+)
+
+// controlTimeout bounds how long a control command waits for the player to
+// answer, and how long --send waits for the daemon.
+const controlTimeout = 5 * time.Second
+
+// defaultSocketPath is where --daemon listens and --send connects without
+// --socket: climp.sock in $XDG_RUNTIME_DIR, which only its user can enter,
+// else in a per-user directory under the shared temp directory, which
+// makePrivateDir creates.
+func defaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "climp.sock")
+	}
+	name := "climp"
+	if uid := os.Getuid(); uid >= 0 {
+		name = fmt.Sprintf("climp-%d", uid)
+	}
+	return filepath.Join(os.TempDir(), name, "climp.sock")
+}
+
+// makePrivateDir creates dir readable only by this user if it is missing, and
+// rejects an existing one that other users can enter: until the socket in it
+// is chmodded, they could connect. Windows has no such permission bits; its
+// temp directory is per-user already.
+func makePrivateDir(dir string) error {
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("%s is open to other users", dir)
+	}
+	return nil
+}
+
+// listenControl listens on the Unix socket at path, which only this user
+// may connect to. A socket file left by a daemon that died is replaced; one
+// that still answers means a daemon is already running.
+func listenControl(path string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// runDaemon plays model with no terminal UI, taking commands from the Unix
+// socket at socket (defaultSocketPath if empty) until playback ends or a
+// quit command arrives. The model runs under a Bubble Tea program with no
+// renderer and no input, so queue advance, downloads, and repeat behave as
+// they do in the TUI.
+func runDaemon(model ui.Model, socket string) error {
+	var err error
+	if socket == "" {
+		socket = defaultSocketPath()
+		err = makePrivateDir(filepath.Dir(socket))
+	}
+	var ln net.Listener
+	if err == nil {
+		ln, err = listenControl(socket)
+	}
+	if err != nil {
+		model.Close()
+		return err
+	}
+	defer os.Remove(socket)
+	defer ln.Close()
+
+	program := tea.NewProgram(model.WithHeadless(), tea.WithoutRenderer(), tea.WithInput(nil), tea.WithoutSignalHandler())
+	send := func(command string) []byte {
+		reply := make(chan []byte, 1)
+		program.Send(ui.ControlMsg{Command: command, Reply: reply})
+		select {
+		case data := <-reply:
+			return data
+		case <-time.After(controlTimeout):
+			data, _ := json.Marshal(ui.ControlReply{Error: "player did not answer"})
+			return data
+		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		for range signals {
+			send("quit")
+		}
+	}()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveControl(conn, send)
+		}
+	}()
+
+	_, err = program.Run()
+	return err
+}
+
+// serveControl answers conn one command per line, one JSON reply per line,
+// until the client closes it.
+func serveControl(conn net.Conn, send func(string) []byte) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		command := strings.TrimSpace(scanner.Text())
+		if command == "" {
+			continue
+		}
+		if _, err := conn.Write(append(send(command), '\n')); err != nil {
+			return
+		}
+	}
+}
+
+// sendControl sends one command to the daemon on socket (defaultSocketPath
+// if empty) and copies its JSON reply to w. A reply that is not ok is an
+// error, so scripts can check the exit status.
+func sendControl(w io.Writer, socket, command string) error {
+	if socket == "" {
+		socket = defaultSocketPath()
+	}
+	conn, err := net.DialTimeout("unix", socket, time.Second)
+	if err != nil {
+		return fmt.Errorf("no daemon listening on %s", socket)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(controlTimeout))
+	if _, err := fmt.Fprintln(conn, command); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("daemon closed the connection")
+		}
+		return err
+	}
+	if _, err := w.Write(line); err != nil {
+		return err
+	}
+	var reply ui.ControlReply
+	if err := json.Unmarshal(line, &reply); err != nil {
+		return err
+	}
+	if !reply.OK {
+		return errors.New(reply.Error)
+	}
+	return nil
+}
+
+// downloadURLHeadless downloads like downloadURL without its progress
+// screen, for the daemon.
+func downloadURLHeadless(rawURL string) (ui.DownloadResult, error) {
+	path, title, cleanup, err := downloader.Download(rawURL, func(downloader.DownloadStatus) {})
+	return ui.DownloadResult{
+		Path:    path,
+		Title:   title,
+		Cleanup: cleanup,
+		Err:     err,
+	}, nil
+}
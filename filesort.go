@@ -0,0 +1,114 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/olivier-w/climp/internal/player"
+)
+
+// File orders for --sort.
+const (
+	sortName    = "name"    // case-insensitive path order
+	sortNatural = "natural" // like name, but digit runs compare as numbers
+	sortMeta    = "meta"    // disc and track tags within each folder
+)
+
+var sortModes = []string{sortName, sortNatural, sortMeta}
+
+// fileSort is the order used for directory scans, set from --sort.
+var fileSort = sortName
+
+// sortAudioFiles orders scanned files by mode. With sortMeta, files are
+// grouped by folder and tagged files come first by disc and track; files
+// without a track number follow in natural order. Reading tags costs a file
+// open per track, so it is opt-in.
+func sortAudioFiles(files []string, mode string) {
+	switch mode {
+	case sortNatural:
+		sort.SliceStable(files, func(i, j int) bool {
+			return pathLess(strings.ToLower(files[i]), strings.ToLower(files[j]), naturalLess)
+		})
+	case sortMeta:
+		type key struct {
+			dir         string
+			untagged    bool
+			disc, track int
+		}
+		keys := make(map[string]key, len(files))
+		for _, f := range files {
+			disc, track, ok := player.ReadTrackNumber(f)
+			keys[f] = key{dir: strings.ToLower(filepath.Dir(f)), untagged: !ok, disc: disc, track: track}
+		}
+		sort.SliceStable(files, func(i, j int) bool {
+			a, b := keys[files[i]], keys[files[j]]
+			switch {
+			case a.dir != b.dir:
+				return pathLess(a.dir, b.dir, naturalLess)
+			case a.untagged != b.untagged:
+				return !a.untagged
+			case a.disc != b.disc:
+				return a.disc < b.disc
+			case a.track != b.track:
+				return a.track < b.track
+			}
+			return pathLess(strings.ToLower(files[i]), strings.ToLower(files[j]), naturalLess)
+		})
+	default:
+		sort.SliceStable(files, func(i, j int) bool {
+			return pathLess(strings.ToLower(files[i]), strings.ToLower(files[j]), func(a, b string) bool { return a < b })
+		})
+	}
+}
+
+// pathLess compares paths one element at a time with less, so everything
+// under a directory sorts together: comparing whole strings would put
+// "artist two/a" between "artist/album/x" and "artist/y", since ' ' sorts
+// before the separator.
+func pathLess(a, b string, less func(a, b string) bool) bool {
+	as := strings.Split(a, string(filepath.Separator))
+	bs := strings.Split(b, string(filepath.Separator))
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return less(as[i], bs[i])
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// naturalLess compares strings with runs of digits taken as numbers, so
+// "Track 2" sorts before "Track 10". Equal numbers with different zero
+// padding compare by what follows them.
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			na, nb := digitRun(a), digitRun(b)
+			ta, tb := strings.TrimLeft(a[:na], "0"), strings.TrimLeft(b[:nb], "0")
+			if len(ta) != len(tb) {
+				return len(ta) < len(tb)
+			}
+			if ta != tb {
+				return ta < tb
+			}
+			a, b = a[na:], b[nb:]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// digitRun returns the length of the run of digits at the start of s.
+func digitRun(s string) int {
+	n := 0
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	return n
+}
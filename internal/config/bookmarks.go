@@ -0,0 +1,113 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	bookmarksFileName = "bookmarks.json"
+
+	// MaxBookmarks caps the bookmark store; the least recently saved
+	// bookmarks are evicted first.
+	MaxBookmarks = 200
+)
+
+// bookmark is one saved position. The store keeps them most recent first.
+type bookmark struct {
+	Key      string `json:"key"`
+	Position int64  `json:"position_ms"`
+}
+
+// BookmarkKey identifies a local file by a hash of its absolute path and
+// size, so a replaced file with the same name does not resume stale positions.
+func BookmarkKey(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", abs, info.Size())))
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// BookmarksPath returns the location of the bookmark store under the user
+// config dir.
+func BookmarksPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName, bookmarksFileName), nil
+}
+
+// SaveBookmark records pos for key in the user config dir.
+func SaveBookmark(key string, pos time.Duration) error {
+	path, err := BookmarksPath()
+	if err != nil {
+		return err
+	}
+	return SaveBookmarkFile(path, key, pos)
+}
+
+// LoadBookmark returns the saved position for key, if any.
+func LoadBookmark(key string) (time.Duration, bool) {
+	path, err := BookmarksPath()
+	if err != nil {
+		return 0, false
+	}
+	return LoadBookmarkFile(path, key)
+}
+
+// SaveBookmarkFile records pos for key in the store at path, moving it to the
+// front and evicting the oldest entries beyond MaxBookmarks.
+func SaveBookmarkFile(path, key string, pos time.Duration) error {
+	marks := readBookmarks(path)
+	out := make([]bookmark, 0, len(marks)+1)
+	out = append(out, bookmark{Key: key, Position: pos.Milliseconds()})
+	for _, b := range marks {
+		if b.Key != key && len(out) < MaxBookmarks {
+			out = append(out, b)
+		}
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// LoadBookmarkFile returns the position saved for key in the store at path.
+// A missing or corrupt store has no bookmarks.
+func LoadBookmarkFile(path, key string) (time.Duration, bool) {
+	for _, b := range readBookmarks(path) {
+		if b.Key == key {
+			if b.Position <= 0 {
+				return 0, false
+			}
+			return time.Duration(b.Position) * time.Millisecond, true
+		}
+	}
+	return 0, false
+}
+
+func readBookmarks(path string) []bookmark {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var marks []bookmark
+	if err := json.Unmarshal(data, &marks); err != nil {
+		return nil
+	}
+	return marks
+}
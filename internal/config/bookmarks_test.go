@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBookmarkFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "climp", "bookmarks.json")
+	if _, ok := LoadBookmarkFile(path, "book"); ok {
+		t.Fatal("expected no bookmark in a missing store")
+	}
+
+	if err := SaveBookmarkFile(path, "book", 90*time.Minute+1500*time.Millisecond); err != nil {
+		t.Fatalf("SaveBookmarkFile() error = %v", err)
+	}
+	if err := SaveBookmarkFile(path, "book", 2*time.Hour); err != nil {
+		t.Fatalf("SaveBookmarkFile() error = %v", err)
+	}
+	got, ok := LoadBookmarkFile(path, "book")
+	if !ok || got != 2*time.Hour {
+		t.Fatalf("LoadBookmarkFile() = %v, %v; want 2h, true", got, ok)
+	}
+	if marks := readBookmarks(path); len(marks) != 1 {
+		t.Fatalf("expected resaving to replace the entry, got %d entries", len(marks))
+	}
+}
+
+func TestSaveBookmarkFileEvictsLeastRecent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.json")
+	for i := 0; i <= MaxBookmarks; i++ {
+		if err := SaveBookmarkFile(path, fmt.Sprintf("k%d", i), time.Second); err != nil {
+			t.Fatalf("SaveBookmarkFile() error = %v", err)
+		}
+	}
+
+	if marks := readBookmarks(path); len(marks) != MaxBookmarks {
+		t.Fatalf("expected %d bookmarks, got %d", MaxBookmarks, len(marks))
+	}
+	if _, ok := LoadBookmarkFile(path, "k0"); ok {
+		t.Fatal("expected the oldest bookmark to be evicted")
+	}
+	if _, ok := LoadBookmarkFile(path, fmt.Sprintf("k%d", MaxBookmarks)); !ok {
+		t.Fatal("expected the newest bookmark to be kept")
+	}
+}
+
+func TestBookmarkKeyChangesWithFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.m4b")
+	if err := os.WriteFile(path, []byte("a"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	first, err := BookmarkKey(path)
+	if err != nil {
+		t.Fatalf("BookmarkKey() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte("ab"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	second, err := BookmarkKey(path)
+	if err != nil {
+		t.Fatalf("BookmarkKey() error = %v", err)
+	}
+	if first == second {
+		t.Fatal("expected key to change when the file size changes")
+	}
+}
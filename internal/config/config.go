@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const (
+	appDirName    = "climp"
+	stateFileName = "state.json"
+
+	// DefaultVolume matches the player's initial volume.
+	DefaultVolume = 0.8
+)
+
+// State holds playback settings that persist between runs.
+// Mode fields store the integer values of player.SpeedMode, ui.RepeatMode,
+// and ui.ShuffleMode; callers validate them when applying. Theme names a
+// built-in color theme and Dither turns on dithering of hi-res FLAC; both
+// are only ever edited by hand. Remaining shows the time left instead of the
+// duration.
+type State struct {
+	Volume    float64 `json:"volume"`
+	Speed     int     `json:"speed"`
+	Repeat    int     `json:"repeat"`
+	Shuffle   int     `json:"shuffle"`
+	Theme     string  `json:"theme,omitempty"`
+	Dither    bool    `json:"dither,omitempty"`
+	Remaining bool    `json:"remaining,omitempty"`
+}
+
+// Default returns the settings used when no state file exists.
+func Default() State {
+	return State{Volume: DefaultVolume}
+}
+
+// StatePath returns the location of the state file under the user config dir.
+func StatePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName, stateFileName), nil
+}
+
+// Load reads the persisted state, falling back to defaults if the file is
+// missing, unreadable, or corrupt.
+func Load() State {
+	path, err := StatePath()
+	if err != nil {
+		return Default()
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads state from path, falling back to defaults on any error.
+// Volume is clamped to 0.0-1.0 so a hand-edited file can't break playback.
+func LoadFile(path string) State {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Default()
+	}
+
+	s := Default()
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Default()
+	}
+	if s.Volume < 0 {
+		s.Volume = 0
+	}
+	if s.Volume > 1 {
+		s.Volume = 1
+	}
+	return s
+}
+
+// Save writes the state to the user config dir.
+func Save(s State) error {
+	path, err := StatePath()
+	if err != nil {
+		return err
+	}
+	return SaveFile(path, s)
+}
+
+// SaveFile writes state to path, creating parent directories as needed.
+// The file is written to a temp sibling first and renamed into place.
+func SaveFile(path string, s State) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// writeFileAtomic writes data to a temp sibling of path and renames it into
+// place, creating parent directories as needed.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFileMissingReturnsDefaults(t *testing.T) {
+	got := LoadFile(filepath.Join(t.TempDir(), "missing.json"))
+	if got != Default() {
+		t.Fatalf("LoadFile() = %+v, want %+v", got, Default())
+	}
+}
+
+func TestLoadFileCorruptReturnsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("write state: %v", err)
+	}
+
+	got := LoadFile(path)
+	if got != Default() {
+		t.Fatalf("LoadFile() = %+v, want %+v", got, Default())
+	}
+}
+
+func TestLoadFileClampsVolume(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		body string
+		want float64
+	}{
+		{body: `{"volume": 3.5}`, want: 1},
+		{body: `{"volume": -2}`, want: 0},
+	} {
+		path := filepath.Join(dir, "state.json")
+		if err := os.WriteFile(path, []byte(tc.body), 0o644); err != nil {
+			t.Fatalf("write state: %v", err)
+		}
+		if got := LoadFile(path).Volume; got != tc.want {
+			t.Fatalf("LoadFile(%s).Volume = %v, want %v", tc.body, got, tc.want)
+		}
+	}
+}
+
+func TestSaveFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	want := State{Volume: 0.35, Speed: 1, Repeat: 2, Shuffle: 1, Theme: "sunset", Dither: true, Remaining: true}
+
+	if err := SaveFile(path, want); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	if got := LoadFile(path); got != want {
+		t.Fatalf("LoadFile() = %+v, want %+v", got, want)
+	}
+}
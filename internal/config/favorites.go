@@ -0,0 +1,90 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const favoritesFileName = "favorites.json"
+
+// Favorite is a starred track or stream. The store keeps them most recently
+// starred first.
+type Favorite struct {
+	Source string `json:"source"` // absolute file path or URL
+	Title  string `json:"title,omitempty"`
+}
+
+// FavoritesPath returns the location of the favorites store under the user
+// config dir.
+func FavoritesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName, favoritesFileName), nil
+}
+
+// LoadFavorites returns the favorites in the user config dir.
+func LoadFavorites() []Favorite {
+	path, err := FavoritesPath()
+	if err != nil {
+		return nil
+	}
+	return LoadFavoritesFile(path)
+}
+
+// ToggleFavorite stars f in the user config dir, or unstars it when its
+// source is already a favorite. It reports whether f is now starred.
+func ToggleFavorite(f Favorite) (bool, error) {
+	path, err := FavoritesPath()
+	if err != nil {
+		return false, err
+	}
+	return ToggleFavoriteFile(path, f)
+}
+
+// ToggleFavoriteFile stars or unstars f.Source in the store at path. A new
+// favorite goes to the front.
+func ToggleFavoriteFile(path string, f Favorite) (bool, error) {
+	favs := LoadFavoritesFile(path)
+	out := make([]Favorite, 0, len(favs)+1)
+	for _, old := range favs {
+		if old.Source != f.Source {
+			out = append(out, old)
+		}
+	}
+	starred := len(out) == len(favs)
+	if starred {
+		out = append([]Favorite{f}, out...)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return false, err
+	}
+	return starred, nil
+}
+
+// LoadFavoritesFile returns the favorites stored at path. A missing or
+// corrupt store has no favorites.
+func LoadFavoritesFile(path string) []Favorite {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var favs []Favorite
+	if err := json.Unmarshal(data, &favs); err != nil {
+		return nil
+	}
+	out := favs[:0]
+	for _, f := range favs {
+		if f.Source != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestToggleFavoriteFileStarsAndUnstars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "climp", "favorites.json")
+	if favs := LoadFavoritesFile(path); len(favs) != 0 {
+		t.Fatalf("expected no favorites in a missing store, got %+v", favs)
+	}
+
+	for _, f := range []Favorite{
+		{Source: "/music/a.flac", Title: "A"},
+		{Source: "https://example.com/live", Title: "Radio"},
+	} {
+		starred, err := ToggleFavoriteFile(path, f)
+		if err != nil || !starred {
+			t.Fatalf("ToggleFavoriteFile(%q) = %v, %v; want true, nil", f.Source, starred, err)
+		}
+	}
+	favs := LoadFavoritesFile(path)
+	if len(favs) != 2 || favs[0].Source != "https://example.com/live" || favs[1].Title != "A" {
+		t.Fatalf("expected newest first, got %+v", favs)
+	}
+
+	starred, err := ToggleFavoriteFile(path, Favorite{Source: "/music/a.flac"})
+	if err != nil || starred {
+		t.Fatalf("ToggleFavoriteFile() = %v, %v; want false, nil", starred, err)
+	}
+	if favs := LoadFavoritesFile(path); len(favs) != 1 || favs[0].Source != "https://example.com/live" {
+		t.Fatalf("expected the second toggle to unstar, got %+v", favs)
+	}
+}
+
+func TestLoadFavoritesFileIgnoresCorruptStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if favs := LoadFavoritesFile(path); favs != nil {
+		t.Fatalf("expected no favorites from a corrupt store, got %+v", favs)
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	foldersFileName = "folders.json"
+
+	// MaxFolders caps the folder position store; the least recently saved
+	// folders are evicted first.
+	MaxFolders = 200
+)
+
+// folderPosition is where a directory session stopped. The store keeps them
+// most recent first.
+type folderPosition struct {
+	Dir      string `json:"dir"`
+	Files    string `json:"files"` // folderFingerprint of the queued files
+	Index    int    `json:"index"`
+	Position int64  `json:"position_ms"`
+}
+
+// folderFingerprint identifies the file list a position was saved against,
+// so adding, removing, or reordering files drops the saved position.
+func folderFingerprint(files []string) string {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = filepath.Base(f)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(names, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// FoldersPath returns the location of the folder position store under the
+// user config dir.
+func FoldersPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName, foldersFileName), nil
+}
+
+// SaveFolderPosition records that the session over files in dir stopped at
+// files[index], pos into the track.
+func SaveFolderPosition(dir string, files []string, index int, pos time.Duration) error {
+	path, err := FoldersPath()
+	if err != nil {
+		return err
+	}
+	return SaveFolderPositionFile(path, dir, files, index, pos)
+}
+
+// LoadFolderPosition returns the saved track index and position for files
+// in dir, if any.
+func LoadFolderPosition(dir string, files []string) (int, time.Duration, bool) {
+	path, err := FoldersPath()
+	if err != nil {
+		return 0, 0, false
+	}
+	return LoadFolderPositionFile(path, dir, files)
+}
+
+// SaveFolderPositionFile records a folder position in the store at path,
+// moving it to the front and evicting the oldest entries beyond MaxFolders.
+func SaveFolderPositionFile(path, dir string, files []string, index int, pos time.Duration) error {
+	saved := readFolderPositions(path)
+	out := make([]folderPosition, 0, len(saved)+1)
+	out = append(out, folderPosition{Dir: dir, Files: folderFingerprint(files), Index: index, Position: pos.Milliseconds()})
+	for _, f := range saved {
+		if f.Dir != dir && len(out) < MaxFolders {
+			out = append(out, f)
+		}
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// LoadFolderPositionFile returns the position saved for dir in the store at
+// path. A position saved against a different file list, or pointing past
+// its end, is ignored.
+func LoadFolderPositionFile(path, dir string, files []string) (int, time.Duration, bool) {
+	for _, f := range readFolderPositions(path) {
+		if f.Dir != dir {
+			continue
+		}
+		if f.Files != folderFingerprint(files) || f.Index < 0 || f.Index >= len(files) {
+			return 0, 0, false
+		}
+		return f.Index, time.Duration(max(f.Position, 0)) * time.Millisecond, true
+	}
+	return 0, 0, false
+}
+
+func readFolderPositions(path string) []folderPosition {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var saved []folderPosition
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil
+	}
+	return saved
+}
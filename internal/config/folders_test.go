@@ -0,0 +1,43 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFolderPositionFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "climp", "folders.json")
+	files := []string{"/music/album/01.flac", "/music/album/02.flac", "/music/album/03.flac"}
+	if _, _, ok := LoadFolderPositionFile(path, "/music/album", files); ok {
+		t.Fatal("expected no position in a missing store")
+	}
+
+	if err := SaveFolderPositionFile(path, "/music/album", files, 2, 95*time.Second); err != nil {
+		t.Fatalf("SaveFolderPositionFile() error = %v", err)
+	}
+	if err := SaveFolderPositionFile(path, "/music/other", files[:2], 1, time.Second); err != nil {
+		t.Fatalf("SaveFolderPositionFile() error = %v", err)
+	}
+	idx, pos, ok := LoadFolderPositionFile(path, "/music/album", files)
+	if !ok || idx != 2 || pos != 95*time.Second {
+		t.Fatalf("LoadFolderPositionFile() = %d, %v, %v; want 2, 95s, true", idx, pos, ok)
+	}
+}
+
+func TestFolderPositionIgnoredWhenFilesChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "folders.json")
+	files := []string{"/music/album/01.flac", "/music/album/02.flac"}
+	if err := SaveFolderPositionFile(path, "/music/album", files, 1, time.Minute); err != nil {
+		t.Fatalf("SaveFolderPositionFile() error = %v", err)
+	}
+
+	added := append(append([]string{}, files...), "/music/album/03.flac")
+	if _, _, ok := LoadFolderPositionFile(path, "/music/album", added); ok {
+		t.Fatal("expected a changed file set to drop the saved position")
+	}
+	reordered := []string{files[1], files[0]}
+	if _, _, ok := LoadFolderPositionFile(path, "/music/album", reordered); ok {
+		t.Fatal("expected a reordered file set to drop the saved position")
+	}
+}
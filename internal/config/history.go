@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	historyFileName = "history.jsonl"
+
+	// MaxHistory caps the play history; the oldest plays are dropped first.
+	MaxHistory = 1000
+
+	// historySlack is how far the log may grow past MaxHistory before it is
+	// rewritten, so most plays are a single append.
+	historySlack = 100
+)
+
+// Play is one entry in the play history.
+type Play struct {
+	Source string        // file path or URL
+	Title  string        // title when playback started
+	At     time.Time     // when the play ended
+	Played time.Duration // furthest position reached
+}
+
+// historyEntry is a Play as stored, one JSON object per line.
+type historyEntry struct {
+	Source string    `json:"source"`
+	Title  string    `json:"title,omitempty"`
+	At     time.Time `json:"at"`
+	Played int64     `json:"played_ms"`
+}
+
+// HistoryPath returns the location of the play history under the user config
+// dir.
+func HistoryPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName, historyFileName), nil
+}
+
+// AppendPlay adds p to the play history in the user config dir.
+func AppendPlay(p Play) error {
+	path, err := HistoryPath()
+	if err != nil {
+		return err
+	}
+	return AppendPlayFile(path, p)
+}
+
+// LoadHistory returns the plays in the user config dir, oldest first.
+func LoadHistory() []Play {
+	path, err := HistoryPath()
+	if err != nil {
+		return nil
+	}
+	return LoadHistoryFile(path)
+}
+
+// AppendPlayFile appends p to the log at path. Once the log holds more than
+// MaxHistory plays by a margin, it is rewritten with the newest MaxHistory.
+func AppendPlayFile(path string, p Play) error {
+	line, err := json.Marshal(historyEntry{Source: p.Source, Title: p.Title, At: p.At, Played: p.Played.Milliseconds()})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return trimHistory(path)
+}
+
+// trimHistory rewrites the log at path with its newest MaxHistory lines once
+// it has grown past MaxHistory+historySlack.
+func trimHistory(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := bytes.SplitAfter(bytes.TrimRight(data, "\n"), []byte("\n"))
+	if len(lines) <= MaxHistory+historySlack {
+		return nil
+	}
+	kept := bytes.Join(lines[len(lines)-MaxHistory:], nil)
+	return writeFileAtomic(path, append(bytes.TrimRight(kept, "\n"), '\n'))
+}
+
+// LoadHistoryFile returns the plays logged at path, oldest first. Lines that
+// do not parse are skipped, so one bad write does not lose the rest.
+func LoadHistoryFile(path string) []Play {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var plays []Play
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var e historyEntry
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &e) != nil || e.Source == "" {
+			continue
+		}
+		plays = append(plays, Play{Source: e.Source, Title: e.Title, At: e.At, Played: time.Duration(e.Played) * time.Millisecond})
+	}
+	return plays
+}
+
+// RecentPlay is the latest play of one source and how often it was played.
+type RecentPlay struct {
+	Play
+	Count int
+}
+
+// RecentPlays returns up to n distinct sources from plays (oldest first),
+// most recently played first.
+func RecentPlays(plays []Play, n int) []RecentPlay {
+	counts := make(map[string]int, len(plays))
+	for _, p := range plays {
+		counts[p.Source]++
+	}
+	var recent []RecentPlay
+	seen := make(map[string]bool, len(counts))
+	for i := len(plays) - 1; i >= 0 && len(recent) < n; i-- {
+		p := plays[i]
+		if seen[p.Source] {
+			continue
+		}
+		seen[p.Source] = true
+		recent = append(recent, RecentPlay{Play: p, Count: counts[p.Source]})
+	}
+	return recent
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlayHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "climp", "history.jsonl")
+	if plays := LoadHistoryFile(path); plays != nil {
+		t.Fatalf("LoadHistoryFile(missing) = %v, want nil", plays)
+	}
+
+	at := time.Date(2026, time.October, 16, 14, 2, 0, 0, time.UTC)
+	want := []Play{
+		{Source: "/music/a.flac", Title: "A", At: at, Played: 3*time.Minute + 500*time.Millisecond},
+		{Source: "https://example.com/live", At: at.Add(time.Hour), Played: 40 * time.Minute},
+	}
+	for _, p := range want {
+		if err := AppendPlayFile(path, p); err != nil {
+			t.Fatalf("AppendPlayFile() error = %v", err)
+		}
+	}
+	// A torn or hand-edited line does not hide the others.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{not json\n")
+	f.Close()
+
+	got := LoadHistoryFile(path)
+	if len(got) != len(want) {
+		t.Fatalf("LoadHistoryFile() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Source != want[i].Source || got[i].Title != want[i].Title || !got[i].At.Equal(want[i].At) || got[i].Played != want[i].Played {
+			t.Fatalf("play %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAppendPlayFileTrimsOldest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	total := MaxHistory + historySlack + 1
+	for i := range total {
+		if err := AppendPlayFile(path, Play{Source: fmt.Sprintf("t%d", i)}); err != nil {
+			t.Fatalf("AppendPlayFile() error = %v", err)
+		}
+	}
+
+	plays := LoadHistoryFile(path)
+	if len(plays) != MaxHistory {
+		t.Fatalf("kept %d plays, want %d", len(plays), MaxHistory)
+	}
+	if first, last := plays[0].Source, plays[len(plays)-1].Source; first != fmt.Sprintf("t%d", total-MaxHistory) || last != fmt.Sprintf("t%d", total-1) {
+		t.Fatalf("kept %s..%s, want the newest plays", first, last)
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.HasSuffix(data, []byte("}\n")) || bytes.Contains(data, []byte("\n\n")) {
+		t.Fatal("trimmed log is not one play per line")
+	}
+}
+
+func TestRecentPlaysCountsAndOrdersBySource(t *testing.T) {
+	plays := []Play{
+		{Source: "a", Title: "A"},
+		{Source: "b", Title: "B"},
+		{Source: "a", Title: "A again"},
+		{Source: "c", Title: "C"},
+	}
+	got := RecentPlays(plays, 2)
+	if len(got) != 2 {
+		t.Fatalf("RecentPlays() = %+v, want 2 entries", got)
+	}
+	if got[0].Source != "c" || got[0].Count != 1 {
+		t.Fatalf("first = %+v, want c played once", got[0])
+	}
+	if got[1].Source != "a" || got[1].Count != 2 || got[1].Title != "A again" {
+		t.Fatalf("second = %+v, want the latest play of a, counted twice", got[1])
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const keysFileName = "keys.json"
+
+// KeyList is the keys bound to one action. In keys.json it may be written as
+// a single key string or as an array of key strings.
+type KeyList []string
+
+// UnmarshalJSON accepts either "k" or ["k", "l"].
+func (k *KeyList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*k = KeyList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*k = many
+	return nil
+}
+
+// KeysPath returns the location of the keybinding remap file under the user
+// config dir.
+func KeysPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName, keysFileName), nil
+}
+
+// LoadKeys reads the keybinding remaps, returning nil if the file is missing,
+// unreadable, or corrupt.
+func LoadKeys() map[string]KeyList {
+	path, err := KeysPath()
+	if err != nil {
+		return nil
+	}
+	return LoadKeysFile(path)
+}
+
+// LoadKeysFile reads action-to-keys remaps from path. Actions with no keys are
+// dropped so a stray empty entry can't leave an action unreachable.
+func LoadKeysFile(path string) map[string]KeyList {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var remap map[string]KeyList
+	if err := json.Unmarshal(data, &remap); err != nil {
+		return nil
+	}
+	for action, keys := range remap {
+		if len(keys) == 0 {
+			delete(remap, action)
+		}
+	}
+	return remap
+}
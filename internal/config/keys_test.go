@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadKeysFileAcceptsStringsAndArrays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data := `{"pause": "p", "seek-back": ["a", "left"], "next": []}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+
+	got := LoadKeysFile(path)
+	if !slices.Equal(got["pause"], KeyList{"p"}) {
+		t.Fatalf("pause = %q, want [p]", got["pause"])
+	}
+	if !slices.Equal(got["seek-back"], KeyList{"a", "left"}) {
+		t.Fatalf("seek-back = %q, want [a left]", got["seek-back"])
+	}
+	if _, ok := got["next"]; ok {
+		t.Fatal("expected empty next entry to be dropped")
+	}
+}
+
+func TestLoadKeysFileMissingOrCorruptReturnsNil(t *testing.T) {
+	dir := t.TempDir()
+	if got := LoadKeysFile(filepath.Join(dir, "missing.json")); got != nil {
+		t.Fatalf("missing file = %v, want nil", got)
+	}
+
+	path := filepath.Join(dir, "keys.json")
+	if err := os.WriteFile(path, []byte(`{"pause": 1}`), 0o644); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+	if got := LoadKeysFile(path); got != nil {
+		t.Fatalf("corrupt file = %v, want nil", got)
+	}
+}
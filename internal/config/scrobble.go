@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const scrobbleFileName = "scrobble.json"
+
+// Scrobble holds the credentials for the optional scrobbling services. A
+// service with empty credentials is disabled.
+type Scrobble struct {
+	ListenBrainz ListenBrainz `json:"listenbrainz"`
+	LastFM       LastFM       `json:"lastfm"`
+}
+
+// ListenBrainz configures submissions to a ListenBrainz server. URL defaults
+// to the public server when empty.
+type ListenBrainz struct {
+	Token string `json:"token"`
+	URL   string `json:"url,omitempty"`
+}
+
+// LastFM configures submissions to Last.fm. SessionKey comes from the
+// Last.fm desktop auth flow for the API account.
+type LastFM struct {
+	APIKey     string `json:"api_key"`
+	Secret     string `json:"secret"`
+	SessionKey string `json:"session_key"`
+}
+
+// ScrobblePath returns the location of the scrobbling credentials under the
+// user config dir.
+func ScrobblePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName, scrobbleFileName), nil
+}
+
+// LoadScrobble reads the scrobbling credentials. A missing, unreadable, or
+// corrupt file disables scrobbling.
+func LoadScrobble() Scrobble {
+	path, err := ScrobblePath()
+	if err != nil {
+		return Scrobble{}
+	}
+	return LoadScrobbleFile(path)
+}
+
+// LoadScrobbleFile reads scrobbling credentials from path, returning an empty
+// configuration on any error.
+func LoadScrobbleFile(path string) Scrobble {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scrobble{}
+	}
+	var s Scrobble
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Scrobble{}
+	}
+	return s
+}
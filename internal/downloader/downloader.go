@@ -19,7 +19,7 @@ import (
 
 // DownloadStatus represents the current state of a download.
 type DownloadStatus struct {
-	Phase     string  // "fetching", "downloading", "converting"
+	Phase     string  // "fetching", "downloading", "resuming", "converting"
 	Percent   float64 // 0.0-1.0, or -1 if indeterminate
 	TotalSize string  // e.g. "4.53MiB"
 	Speed     string  // e.g. "1.23MiB/s"
@@ -35,14 +35,21 @@ var (
 	ErrLiveStreamNotSupported = errors.New("live radio stream not supported yet")
 	// ErrUnsupportedScheme indicates a non-http(s) URL was provided.
 	ErrUnsupportedScheme = errors.New("unsupported URL scheme (only http/https)")
+	// ErrNetwork indicates yt-dlp failed on a connection problem or a
+	// temporary server error; trying again later may succeed.
+	ErrNetwork = errors.New("network error")
+	// ErrUnavailable indicates the media does not exist or cannot be
+	// downloaded (HTTP 4xx, removed or private video, unsupported site).
+	ErrUnavailable = errors.New("media unavailable")
 )
 
 const (
-	maxDownloadDuration  = 5 * time.Minute
-	fetchIdleTimeout     = 15 * time.Second
-	downloadIdleTimeout  = 15 * time.Second
-	convertIdleTimeout   = 30 * time.Second
-	noActivityRetryCount = 0
+	maxDownloadDuration    = 5 * time.Minute
+	playlistExtractTimeout = 30 * time.Second
+	fetchIdleTimeout       = 15 * time.Second
+	downloadIdleTimeout    = 15 * time.Second
+	convertIdleTimeout     = 30 * time.Second
+	noActivityRetryCount   = 1 // stalled downloads restart once, resuming the .part file
 )
 
 type downloadPhase uint8
@@ -78,24 +85,48 @@ func IsLiveBySuffix(rawURL string) bool {
 // Download uses yt-dlp to download audio from a URL as WAV.
 // onStatus is called with structured progress data as it becomes available.
 // Returns the path to the temp file, the video title, and a cleanup function.
+//
+// A download that stalls is restarted once in the same temp dir, so yt-dlp
+// resumes its .part file instead of starting over. The temp dir is removed
+// on failure, or by the returned cleanup.
 func Download(url string, onStatus func(DownloadStatus)) (string, string, func(), error) {
 	normalizedURL, err := normalizeAndValidateURL(url)
 	if err != nil {
 		return "", "", nil, err
 	}
+	ytdlp, err := exec.LookPath("yt-dlp")
+	if err != nil {
+		return "", "", nil, errYtdlpNotFound
+	}
 
+	tmpDir, err := os.MkdirTemp("", "climp-*")
+	if err != nil {
+		return "", "", nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	cleanup := func() {
+		os.RemoveAll(tmpDir)
+	}
+
+	// A likely live stream never finishes, so a stall is not worth retrying.
+	retries := noActivityRetryCount
+	if IsLiveURL(normalizedURL) {
+		retries = 0
+	}
 	var lastErr error
-	for attempt := 0; attempt <= noActivityRetryCount; attempt++ {
-		path, title, cleanup, err := downloadOnce(normalizedURL, onStatus)
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 && onStatus != nil {
+			onStatus(DownloadStatus{Phase: "resuming", Percent: -1})
+		}
+		path, title, err := downloadOnce(ytdlp, normalizedURL, tmpDir, onStatus)
 		if err == nil {
 			return path, title, cleanup, nil
 		}
 		lastErr = err
-		if errors.Is(err, ErrNoActivityTimeout) && attempt < noActivityRetryCount {
-			continue
+		if !errors.Is(err, ErrNoActivityTimeout) {
+			break
 		}
-		break
 	}
+	cleanup()
 
 	if errors.Is(lastErr, ErrNoActivityTimeout) && IsLiveURL(normalizedURL) {
 		return "", "", nil, ErrLiveStreamNotSupported
@@ -103,57 +134,50 @@ func Download(url string, onStatus func(DownloadStatus)) (string, string, func()
 	return "", "", nil, lastErr
 }
 
-func downloadOnce(url string, onStatus func(DownloadStatus)) (string, string, func(), error) {
-	ytdlp, err := exec.LookPath("yt-dlp")
-	if err != nil {
-		return "", "", nil, errYtdlpNotFound
-	}
-
-	tmpDir, err := os.MkdirTemp("", "climp-*")
-	if err != nil {
-		return "", "", nil, fmt.Errorf("creating temp dir: %w", err)
-	}
-
-	cleanup := func() {
-		os.RemoveAll(tmpDir)
-	}
-
-	// Use a fixed output template inside our temp dir.
-	// --print outputs title then final filepath to stdout (one per line).
-	outTemplate := filepath.Join(tmpDir, "audio.%(ext)s")
-	ctx, cancel := context.WithTimeout(context.Background(), maxDownloadDuration)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, ytdlp,
+// ytdlpArgs returns the yt-dlp arguments that download url as WAV into the
+// output template. --continue and --part keep an interrupted download on
+// disk so the next run in the same dir resumes it.
+func ytdlpArgs(url, outTemplate string) []string {
+	return []string{
 		"-x", "--audio-format", "wav",
 		"--no-playlist", // only download the single video, even if URL is a playlist
 		"--newline",     // print progress on new lines instead of \r (needed when piped)
 		"--progress",    // force progress output even when not connected to a TTY
+		"--continue", "--part",
 		"--print", "title",
 		"--print", "after_move:filepath",
 		"-o", outTemplate,
 		url,
-	)
+	}
+}
+
+// downloadOnce runs yt-dlp once, writing into tmpDir. The caller owns tmpDir
+// and removes it.
+func downloadOnce(ytdlp, url, tmpDir string, onStatus func(DownloadStatus)) (string, string, error) {
+	// Use a fixed output template inside our temp dir.
+	// --print outputs title then final filepath to stdout (one per line).
+	outTemplate := filepath.Join(tmpDir, "audio.%(ext)s")
+	ctx, cancel := context.WithTimeout(context.Background(), maxDownloadDuration)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, ytdlp, ytdlpArgs(url, outTemplate)...)
 	cmd.Stdin = nil
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		cleanup()
-		return "", "", nil, fmt.Errorf("setting up yt-dlp: %w", err)
+		return "", "", fmt.Errorf("setting up yt-dlp: %w", err)
 	}
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		cleanup()
-		return "", "", nil, fmt.Errorf("setting up yt-dlp: %w", err)
+		return "", "", fmt.Errorf("setting up yt-dlp: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
-		cleanup()
-		return "", "", nil, fmt.Errorf("starting yt-dlp: %w", err)
+		return "", "", fmt.Errorf("starting yt-dlp: %w", err)
 	}
 
 	// Read title and final filepath from stdout, and parse progress lines.
 	// With --print and --newline, yt-dlp sends title, [download] progress lines,
 	// final filepath, and possibly more [download] lines all to stdout.
-	var title, finalPath string
+	var title, finalPath, errLine string
 	titleRead := false
 	var stateMu sync.Mutex
 	lastActivity := time.Now()
@@ -242,6 +266,8 @@ func downloadOnce(url string, onStatus func(DownloadStatus)) (string, string, fu
 			line := scanner.Text()
 			touch()
 			switch {
+			case strings.HasPrefix(line, "ERROR:"):
+				errLine = line
 			case strings.Contains(line, "Extracting") || strings.Contains(line, "Downloading webpage"):
 				setPhase(phaseFetching)
 				if onStatus != nil {
@@ -293,19 +319,57 @@ func downloadOnce(url string, onStatus func(DownloadStatus)) (string, string, fu
 	stderrWg.Wait()
 
 	if err := cmd.Wait(); err != nil {
-		cleanup()
 		if timedOut.Load() {
-			return "", "", nil, ErrNoActivityTimeout
+			return "", "", ErrNoActivityTimeout
+		}
+		if cause := classifyYtdlpError(errLine); cause != nil {
+			return "", "", fmt.Errorf("yt-dlp failed: %w: %w", cause, err)
 		}
-		return "", "", nil, fmt.Errorf("yt-dlp failed: %w", err)
+		return "", "", fmt.Errorf("yt-dlp failed: %w", err)
 	}
 
 	if finalPath == "" {
-		cleanup()
-		return "", "", nil, fmt.Errorf("yt-dlp did not produce an output file")
+		return "", "", fmt.Errorf("yt-dlp did not produce an output file")
 	}
 
-	return finalPath, title, cleanup, nil
+	return finalPath, title, nil
+}
+
+// IsRetryable reports whether a Download error is likely transient, so the
+// same URL may succeed on a later attempt.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrNoActivityTimeout) || errors.Is(err, ErrNetwork)
+}
+
+var ytdlpHTTPErrorRe = regexp.MustCompile(`HTTP Error (\d{3})`)
+
+// classifyYtdlpError maps the last "ERROR:" line yt-dlp printed to
+// ErrNetwork or ErrUnavailable, or nil when the cause is unknown.
+func classifyYtdlpError(line string) error {
+	if line == "" {
+		return nil
+	}
+	if m := ytdlpHTTPErrorRe.FindStringSubmatch(line); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		switch {
+		case code == 408 || code == 429 || code >= 500:
+			return ErrNetwork
+		case code >= 400:
+			return ErrUnavailable
+		}
+	}
+	lower := strings.ToLower(line)
+	for _, s := range []string{"timed out", "urlopen error", "connection", "temporary failure", "name resolution", "network is unreachable", "incompleteread"} {
+		if strings.Contains(lower, s) {
+			return ErrNetwork
+		}
+	}
+	for _, s := range []string{"unsupported url", "video unavailable", "private video", "not available", "does not exist"} {
+		if strings.Contains(lower, s) {
+			return ErrUnavailable
+		}
+	}
+	return nil
 }
 
 func normalizeAndValidateURL(raw string) (string, error) {
@@ -340,12 +404,18 @@ type PlaylistEntry struct {
 // Returns nil, nil if the URL is a single video (0 or 1 entries).
 // Caps at 50 entries.
 func ExtractPlaylist(url string) ([]PlaylistEntry, error) {
+	return ExtractPlaylistContext(context.Background(), url)
+}
+
+// ExtractPlaylistContext is ExtractPlaylist bound to ctx: cancelling ctx
+// kills the yt-dlp process and returns ctx's error.
+func ExtractPlaylistContext(ctx context.Context, url string) ([]PlaylistEntry, error) {
 	ytdlp, err := exec.LookPath("yt-dlp")
 	if err != nil {
 		return nil, errYtdlpNotFound
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, playlistExtractTimeout)
 	defer cancel()
 	cmd := exec.CommandContext(ctx, ytdlp,
 		"--flat-playlist",
@@ -359,6 +429,9 @@ func ExtractPlaylist(url string) ([]PlaylistEntry, error) {
 
 	output, err := cmd.Output()
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, fmt.Errorf("yt-dlp playlist extraction failed: %w", err)
 	}
 
@@ -2,6 +2,7 @@ package downloader
 
 import (
 	"errors"
+	"slices"
 	"testing"
 )
 
@@ -42,3 +43,37 @@ func TestIsLiveBySuffix(t *testing.T) {
 		}
 	}
 }
+
+func TestClassifyYtdlpError(t *testing.T) {
+	cases := []struct {
+		line string
+		want error
+	}{
+		{line: "ERROR: [youtube] abc: Unable to download webpage: HTTP Error 503: Service Unavailable", want: ErrNetwork},
+		{line: "ERROR: [generic] Unable to download webpage: <urlopen error [Errno -3] Temporary failure in name resolution>", want: ErrNetwork},
+		{line: "ERROR: [youtube] abc: Read timed out.", want: ErrNetwork},
+		{line: "ERROR: [generic] Unable to download webpage: HTTP Error 404: Not Found", want: ErrUnavailable},
+		{line: "ERROR: [youtube] abc: Video unavailable", want: ErrUnavailable},
+		{line: "ERROR: something unexpected", want: nil},
+		{line: "", want: nil},
+	}
+
+	for _, tc := range cases {
+		if got := classifyYtdlpError(tc.line); got != tc.want {
+			t.Errorf("classifyYtdlpError(%q) = %v, want %v", tc.line, got, tc.want)
+		}
+	}
+	if IsRetryable(ErrUnsupportedScheme) || !IsRetryable(ErrNoActivityTimeout) {
+		t.Fatal("expected only transient errors to be retryable")
+	}
+}
+
+func TestYtdlpArgsResumePartialDownloads(t *testing.T) {
+	args := ytdlpArgs("https://example.com/v", "/tmp/climp-1/audio.%(ext)s")
+	if !slices.Contains(args, "--continue") || !slices.Contains(args, "--part") {
+		t.Fatalf("ytdlpArgs() = %v, want --continue and --part", args)
+	}
+	if args[len(args)-1] != "https://example.com/v" {
+		t.Fatalf("ytdlpArgs() = %v, want the URL last", args)
+	}
+}
@@ -0,0 +1,142 @@
+package downloader
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+// hlsVideoCodecs are CODECS prefixes that mark a variant as carrying video.
+var hlsVideoCodecs = []string{"avc1", "avc3", "hvc1", "hev1", "dvh1", "dvhe", "vp08", "vp09", "av01", "mp4v"}
+
+type hlsVariant struct {
+	url       string
+	bandwidth int
+	audioOnly bool
+}
+
+// selectHLSRendition picks the media playlist to play from an HLS master
+// playlist. In order of preference it returns the default (or first)
+// EXT-X-MEDIA audio rendition, the highest-bandwidth audio-only variant, or
+// the lowest-bandwidth variant with video. It returns false when body is a
+// media playlist or lists nothing usable.
+func selectHLSRendition(body, baseURL string) (string, bool) {
+	var (
+		audioURL     string
+		audioDefault bool
+		variants     []hlsVariant
+		pending      map[string]string
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\uFEFF"))
+		if line == "" {
+			continue
+		}
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "#EXT-X-MEDIA:"):
+			attrs := parseHLSAttributes(line[len("#EXT-X-MEDIA:"):])
+			if !strings.EqualFold(attrs["TYPE"], "AUDIO") || attrs["URI"] == "" {
+				continue
+			}
+			resolved, ok := resolveRemoteURL(attrs["URI"], baseURL)
+			if !ok {
+				continue
+			}
+			isDefault := strings.EqualFold(attrs["DEFAULT"], "YES")
+			if audioURL == "" || (isDefault && !audioDefault) {
+				audioURL, audioDefault = resolved, isDefault
+			}
+		case strings.HasPrefix(upper, "#EXT-X-STREAM-INF:"):
+			pending = parseHLSAttributes(line[len("#EXT-X-STREAM-INF:"):])
+		case strings.HasPrefix(line, "#"):
+			continue
+		case pending != nil:
+			if resolved, ok := resolveRemoteURL(line, baseURL); ok {
+				bandwidth, _ := strconv.Atoi(pending["BANDWIDTH"])
+				variants = append(variants, hlsVariant{
+					url:       resolved,
+					bandwidth: bandwidth,
+					audioOnly: isAudioOnlyVariant(pending),
+				})
+			}
+			pending = nil
+		}
+	}
+
+	if audioURL != "" {
+		return audioURL, true
+	}
+	var bestAudio, lowestVideo *hlsVariant
+	for i := range variants {
+		v := &variants[i]
+		if v.audioOnly {
+			if bestAudio == nil || v.bandwidth > bestAudio.bandwidth {
+				bestAudio = v
+			}
+		} else if lowestVideo == nil || v.bandwidth < lowestVideo.bandwidth {
+			lowestVideo = v
+		}
+	}
+	switch {
+	case bestAudio != nil:
+		return bestAudio.url, true
+	case lowestVideo != nil:
+		return lowestVideo.url, true
+	}
+	return "", false
+}
+
+// isAudioOnlyVariant reports whether an EXT-X-STREAM-INF entry carries no
+// video: it has no RESOLUTION and, when CODECS is present, no video codec.
+func isAudioOnlyVariant(attrs map[string]string) bool {
+	if attrs["RESOLUTION"] != "" {
+		return false
+	}
+	codecs := attrs["CODECS"]
+	if codecs == "" {
+		return false
+	}
+	for _, codec := range strings.Split(codecs, ",") {
+		codec = strings.ToLower(strings.TrimSpace(codec))
+		for _, prefix := range hlsVideoCodecs {
+			if strings.HasPrefix(codec, prefix) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseHLSAttributes parses an HLS attribute list (KEY=VALUE,KEY="a,b").
+// Keys are upper-cased; quoted values are unquoted.
+func parseHLSAttributes(s string) map[string]string {
+	attrs := make(map[string]string)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.ToUpper(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+
+		var val string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				val, s = s[1:], ""
+			} else {
+				val, s = s[1:end+1], s[end+2:]
+			}
+		} else if comma := strings.IndexByte(s, ','); comma >= 0 {
+			val, s = s[:comma], s[comma:]
+		} else {
+			val, s = s, ""
+		}
+		attrs[key] = strings.TrimSpace(val)
+		s = strings.TrimPrefix(strings.TrimSpace(s), ",")
+	}
+	return attrs
+}
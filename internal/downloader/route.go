@@ -3,6 +3,7 @@ package downloader
 import (
 	"bufio"
 	"context"
+	"errors"
 	"io"
 	"mime"
 	"net/http"
@@ -30,6 +31,38 @@ type URLRouteResult struct {
 	Kind     URLRouteKind
 	FinalURL string
 	Playlist []media.PlaylistEntry
+	Station  Station // live streams only; zero when the server sent no ICY headers
+}
+
+// Station describes an internet radio station from its ICY response headers.
+type Station struct {
+	Name    string
+	Genre   string
+	Bitrate int // kbps, 0 if unknown
+}
+
+// Title returns the station name, or the host of rawURL when the server
+// did not send one.
+func (s Station) Title(rawURL string) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	return rawURL
+}
+
+// Details returns the genre and bitrate for display, e.g. "Jazz · 128 kbps".
+func (s Station) Details() string {
+	var parts []string
+	if s.Genre != "" {
+		parts = append(parts, s.Genre)
+	}
+	if s.Bitrate > 0 {
+		parts = append(parts, strconv.Itoa(s.Bitrate)+" kbps")
+	}
+	return strings.Join(parts, " · ")
 }
 
 const (
@@ -37,6 +70,33 @@ const (
 	routeProbeBodyLimit = 128 * 1024
 )
 
+// ErrNotPlayableMedia indicates a URL answered with a web page rather than
+// audio or a playlist, typically a login wall or an article the link was
+// redirected to. Known sites are left to yt-dlp instead.
+var ErrNotPlayableMedia = errors.New("not playable media (the URL returned a web page)")
+
+// siteHosts are sites whose pages yt-dlp extracts audio from, so an HTML
+// response from them is expected. Subdomains match too.
+var siteHosts = []string{
+	"youtube.com",
+	"youtu.be",
+	"soundcloud.com",
+	"bandcamp.com",
+	"mixcloud.com",
+	"vimeo.com",
+	"twitch.tv",
+	"dailymotion.com",
+	"archive.org",
+	"bilibili.com",
+	"nicovideo.jp",
+	"tiktok.com",
+	"twitter.com",
+	"x.com",
+	"reddit.com",
+	"facebook.com",
+	"instagram.com",
+}
+
 var (
 	routeHTTPClient = &http.Client{
 		Timeout: routeProbeTimeout,
@@ -54,6 +114,7 @@ type probeResult struct {
 	headers       http.Header
 	body          string
 	chunked       bool
+	station       Station
 }
 
 // IsLiveURL reports whether a URL should use the live playback path.
@@ -74,7 +135,8 @@ func IsLiveURL(rawURL string) bool {
 }
 
 // ResolveURLRoute probes a URL and classifies it as finite media download,
-// live stream, or remote playlist wrapper.
+// live stream, or remote playlist wrapper. A web page from a site yt-dlp is
+// not known to handle returns ErrNotPlayableMedia.
 func ResolveURLRoute(rawURL string) (URLRouteResult, error) {
 	normalizedURL, err := normalizeAndValidateURL(rawURL)
 	if err != nil {
@@ -96,6 +158,9 @@ func ResolveURLRoute(rawURL string) (URLRouteResult, error) {
 
 	if hasHLSBodyMarker(probe.body) {
 		result.Kind = RouteLiveStream
+		if rendition, ok := selectHLSRendition(probe.body, result.FinalURL); ok {
+			result.FinalURL = rendition
+		}
 		cacheLiveURL(normalizedURL)
 		cacheLiveURL(result.FinalURL)
 		return result, nil
@@ -112,14 +177,35 @@ func ResolveURLRoute(rawURL string) (URLRouteResult, error) {
 
 	if isLiveProbe(probe) {
 		result.Kind = RouteLiveStream
+		result.Station = probe.station
 		cacheLiveURL(normalizedURL)
 		cacheLiveURL(result.FinalURL)
 		return result, nil
 	}
 
+	if isHTMLContentType(probe.contentType) && !isSiteURL(normalizedURL) && !isSiteURL(result.FinalURL) {
+		return result, ErrNotPlayableMedia
+	}
+
 	return result, nil
 }
 
+// isSiteURL reports whether rawURL is on a site in siteHosts, whose pages
+// go through yt-dlp rather than being played directly.
+func isSiteURL(rawURL string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, site := range siteHosts {
+		if host == site || strings.HasSuffix(host, "."+site) {
+			return true
+		}
+	}
+	return false
+}
+
 func probeURL(rawURL string) (probeResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), routeProbeTimeout)
 	defer cancel()
@@ -160,6 +246,7 @@ func probeURL(rawURL string) (probeResult, error) {
 		contentLength: resp.ContentLength,
 		headers:       resp.Header,
 		body:          string(bodyBytes),
+		station:       stationFromHeaders(resp.Header),
 	}
 	for _, enc := range resp.TransferEncoding {
 		if strings.EqualFold(strings.TrimSpace(enc), "chunked") {
@@ -235,6 +322,15 @@ func isHLSContentType(contentType string) bool {
 	}
 }
 
+func isHTMLContentType(contentType string) bool {
+	switch strings.ToLower(strings.TrimSpace(contentType)) {
+	case "text/html", "application/xhtml+xml":
+		return true
+	default:
+		return false
+	}
+}
+
 func isAudioLikeContentType(contentType string) bool {
 	contentType = strings.ToLower(strings.TrimSpace(contentType))
 	return strings.HasPrefix(contentType, "audio/") ||
@@ -251,6 +347,20 @@ func hasICYHeaders(headers http.Header) bool {
 	return false
 }
 
+// stationFromHeaders reads icy-name, icy-genre, and icy-br. Some servers
+// send the bitrate as a comma-separated list; the first value is used.
+func stationFromHeaders(headers http.Header) Station {
+	s := Station{
+		Name:  strings.TrimSpace(headers.Get("Icy-Name")),
+		Genre: strings.TrimSpace(headers.Get("Icy-Genre")),
+	}
+	br, _, _ := strings.Cut(headers.Get("Icy-Br"), ",")
+	if n, err := strconv.Atoi(strings.TrimSpace(br)); err == nil && n > 0 {
+		s.Bitrate = n
+	}
+	return s
+}
+
 func hasPlaylistBodyMarker(body string) bool {
 	for _, line := range strings.Split(body, "\n") {
 		trimmed := strings.TrimSpace(strings.TrimPrefix(line, "\uFEFF"))
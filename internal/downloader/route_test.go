@@ -1,6 +1,8 @@
 package downloader
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -71,6 +73,8 @@ func TestResolveURLRouteLiveICYNoExtension(t *testing.T) {
 		}
 		w.Header().Set("Content-Type", "audio/mpeg")
 		w.Header().Set("Icy-Name", "Demo")
+		w.Header().Set("Icy-Genre", "Jazz")
+		w.Header().Set("Icy-Br", "128,128")
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("fake-audio-bytes"))
 	}))
@@ -87,6 +91,22 @@ func TestResolveURLRouteLiveICYNoExtension(t *testing.T) {
 	if !IsLiveURL(url) {
 		t.Fatalf("IsLiveURL(%q) = false, want true", url)
 	}
+	want := Station{Name: "Demo", Genre: "Jazz", Bitrate: 128}
+	if got.Station != want {
+		t.Fatalf("ResolveURLRoute() station = %+v, want %+v", got.Station, want)
+	}
+	if d := got.Station.Details(); d != "Jazz · 128 kbps" {
+		t.Fatalf("Station.Details() = %q, want %q", d, "Jazz · 128 kbps")
+	}
+}
+
+func TestStationTitleFallsBackToHost(t *testing.T) {
+	if got := (Station{Name: "Demo"}).Title("http://radio.example.com/live"); got != "Demo" {
+		t.Fatalf("Title() = %q, want %q", got, "Demo")
+	}
+	if got := (Station{}).Title("http://radio.example.com:8000/live"); got != "radio.example.com:8000" {
+		t.Fatalf("Title() = %q, want host", got)
+	}
 }
 
 func TestResolveURLRouteLiveMP3AndOGG(t *testing.T) {
@@ -167,3 +187,107 @@ func TestResolveURLRouteHLSBodyIsLive(t *testing.T) {
 		t.Fatalf("ResolveURLRoute() kind = %v, want %v", got.Kind, RouteLiveStream)
 	}
 }
+
+func TestResolveURLRouteHLSMasterPicksAudioRendition(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("#EXTM3U\n" +
+			"#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"aud\",NAME=\"English\",DEFAULT=NO,URI=\"audio/en.m3u8\"\n" +
+			"#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"aud\",NAME=\"Main\",DEFAULT=YES,URI=\"audio/main.m3u8\"\n" +
+			"#EXT-X-STREAM-INF:BANDWIDTH=800000,CODECS=\"avc1.4d401f,mp4a.40.2\",RESOLUTION=640x360,AUDIO=\"aud\"\n" +
+			"video/360.m3u8\n"))
+	}))
+	defer srv.Close()
+
+	got, err := ResolveURLRoute(srv.URL + "/master.m3u8")
+	if err != nil {
+		t.Fatalf("ResolveURLRoute() error = %v", err)
+	}
+	if got.Kind != RouteLiveStream {
+		t.Fatalf("ResolveURLRoute() kind = %v, want %v", got.Kind, RouteLiveStream)
+	}
+	if want := srv.URL + "/audio/main.m3u8"; got.FinalURL != want {
+		t.Fatalf("ResolveURLRoute() FinalURL = %q, want %q", got.FinalURL, want)
+	}
+	if !IsLiveURL(got.FinalURL) {
+		t.Fatalf("IsLiveURL(%q) = false, want true", got.FinalURL)
+	}
+}
+
+func TestSelectHLSRenditionVariants(t *testing.T) {
+	base := "https://example.com/hls/master.m3u8"
+	tests := []struct {
+		name string
+		body string
+		want string
+		ok   bool
+	}{
+		{
+			name: "audio-only variant wins over video",
+			body: "#EXTM3U\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=500000,CODECS=\"avc1.42e01e,mp4a.40.2\",RESOLUTION=416x234\nlow.m3u8\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=64000,CODECS=\"mp4a.40.5\"\naudio64.m3u8\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=128000,CODECS=\"mp4a.40.2\"\naudio128.m3u8\n",
+			want: "https://example.com/hls/audio128.m3u8",
+			ok:   true,
+		},
+		{
+			name: "lowest video variant without audio-only",
+			body: "#EXTM3U\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=2000000,RESOLUTION=1280x720\nhigh.m3u8\n" +
+				"#EXT-X-STREAM-INF:BANDWIDTH=500000,RESOLUTION=416x234\nlow.m3u8\n",
+			want: "https://example.com/hls/low.m3u8",
+			ok:   true,
+		},
+		{
+			name: "media playlist is left alone",
+			body: "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6,\nsegment1.ts\n",
+			ok:   false,
+		},
+	}
+	for _, tt := range tests {
+		got, ok := selectHLSRendition(tt.body, base)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s: selectHLSRendition() = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestResolveURLRouteHTMLPageIsNotPlayable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/episode.mp3":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		case "/login":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = io.WriteString(w, "<!doctype html><title>Sign in</title>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	got, err := ResolveURLRoute(srv.URL + "/episode.mp3")
+	if !errors.Is(err, ErrNotPlayableMedia) {
+		t.Fatalf("ResolveURLRoute() error = %v, want ErrNotPlayableMedia", err)
+	}
+	if got.FinalURL != srv.URL+"/login" {
+		t.Fatalf("ResolveURLRoute() final URL = %q", got.FinalURL)
+	}
+}
+
+func TestIsSiteURLMatchesSubdomains(t *testing.T) {
+	tests := map[string]bool{
+		"https://www.youtube.com/watch?v=abc": true,
+		"https://artist.bandcamp.com/track/x": true,
+		"https://youtu.be/abc":                true,
+		"https://notyoutube.com/watch":        false,
+		"https://example.com/page":            false,
+	}
+	for raw, want := range tests {
+		if got := isSiteURL(raw); got != want {
+			t.Errorf("isSiteURL(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package downloader
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -20,22 +25,52 @@ func SanitizeFilename(name string) string {
 	return name
 }
 
+// SaveFormats lists the formats accepted by SaveFileAs.
+var SaveFormats = []string{"mp3", "flac", "wav"}
+
+// ffmpegSaveArgs holds the encoder arguments for each save format.
+var ffmpegSaveArgs = map[string][]string{
+	"mp3":  {"-q:a", "2"},
+	"flac": {"-c:a", "flac"},
+	"wav":  {"-c:a", "pcm_s16le"},
+}
+
 // SaveFile converts the WAV source file to MP3 via ffmpeg and writes it to the
 // current directory using the sanitized title. Returns the destination filename.
 func SaveFile(srcPath, title string) (string, error) {
-	ffmpeg, err := exec.LookPath("ffmpeg")
-	if err != nil {
-		return "", fmt.Errorf("ffmpeg not found (required for saving)")
+	return SaveFileAs(srcPath, title, "mp3")
+}
+
+// SaveFileAs writes the source file to the current directory as format (one of
+// SaveFormats) using the sanitized title, transcoding with ffmpeg. A WAV source
+// saved as WAV is copied as-is, and when ffmpeg is missing the source is copied
+// unconverted under its own extension. Existing files are never overwritten.
+// Returns the destination filename.
+func SaveFileAs(srcPath, title, format string) (string, error) {
+	format = strings.ToLower(format)
+	args, ok := ffmpegSaveArgs[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported save format %q", format)
 	}
+	base := SanitizeFilename(title)
+	srcExt := strings.ToLower(filepath.Ext(srcPath))
 
-	destName := SanitizeFilename(title) + ".mp3"
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil || "."+format == srcExt {
+		destName := base + srcExt
+		if err := copyNewFile(srcPath, destName); err != nil {
+			return "", err
+		}
+		return destName, nil
+	}
 
-	cmd := exec.Command(ffmpeg,
+	destName := base + "." + format
+	cmdArgs := []string{
 		"-n", // never overwrite — fails if file exists (avoids TOCTOU race)
 		"-i", srcPath,
-		"-q:a", "2",
-		destName,
-	)
+	}
+	cmdArgs = append(cmdArgs, args...)
+	cmd := exec.Command(ffmpeg, append(cmdArgs, destName)...)
 	cmd.Stdin = nil
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -44,3 +79,64 @@ func SaveFile(srcPath, title string) (string, error) {
 
 	return destName, nil
 }
+
+// KeepDir returns the directory kept downloads go to: climp under the user's
+// Music folder.
+func KeepDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Music", "climp"), nil
+}
+
+// KeepFile puts a copy of the downloaded file srcPath in dir, named after the
+// sanitized title with the source extension. The copy is a hard link when
+// possible, so the caller's temp cleanup afterwards turns it into a move.
+// Existing files are never overwritten; "Title (2).wav" and so on are used
+// instead. Returns the destination path.
+func KeepFile(srcPath, title, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	base := SanitizeFilename(title)
+	ext := strings.ToLower(filepath.Ext(srcPath))
+	for n := 1; n <= 99; n++ {
+		name := base + ext
+		if n > 1 {
+			name = fmt.Sprintf("%s (%d)%s", base, n, ext)
+		}
+		dest := filepath.Join(dir, name)
+		err := os.Link(srcPath, dest)
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			err = copyNewFile(srcPath, dest)
+		}
+		if err == nil {
+			return dest, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("too many files named %q in %s", base, dir)
+}
+
+// copyNewFile copies src to dest, failing if dest already exists.
+func copyNewFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return err
+	}
+	return out.Close()
+}
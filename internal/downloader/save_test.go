@@ -0,0 +1,58 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveFileAsCopiesWAVWithoutTranscoding(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "climp-123.wav")
+	if err := os.WriteFile(src, []byte("RIFF-data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	dest, err := SaveFileAs(src, "My: Song?", "WAV")
+	if err != nil {
+		t.Fatalf("SaveFileAs() error = %v", err)
+	}
+	if dest != "My Song.wav" {
+		t.Fatalf("SaveFileAs() dest = %q, want %q", dest, "My Song.wav")
+	}
+	if got, err := os.ReadFile(dest); err != nil || string(got) != "RIFF-data" {
+		t.Fatalf("saved file = %q, %v; want copied source", got, err)
+	}
+
+	if _, err := SaveFileAs(src, "My: Song?", "wav"); err == nil {
+		t.Fatal("expected an error instead of overwriting an existing file")
+	}
+	if _, err := SaveFileAs(src, "x", "ogg"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
+
+func TestKeepFileNeverOverwrites(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "audio.WAV")
+	if err := os.WriteFile(src, []byte("RIFF-data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	keep := filepath.Join(dir, "Music", "climp")
+
+	first, err := KeepFile(src, "Artist / Song", keep)
+	if err != nil {
+		t.Fatalf("KeepFile() error = %v", err)
+	}
+	second, err := KeepFile(src, "Artist / Song", keep)
+	if err != nil {
+		t.Fatalf("KeepFile() second error = %v", err)
+	}
+	if filepath.Base(first) != "Artist  Song.wav" || filepath.Base(second) != "Artist  Song (2).wav" {
+		t.Fatalf("KeepFile() = %q, %q; want numbered names", first, second)
+	}
+	if got, err := os.ReadFile(second); err != nil || string(got) != "RIFF-data" {
+		t.Fatalf("kept file = %q, %v; want source contents", got, err)
+	}
+}
@@ -0,0 +1,169 @@
+package media
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cueFramesPerSecond is the CD frame rate used by cue sheet INDEX times.
+const cueFramesPerSecond = 75
+
+// parseCue turns a cue sheet into one entry per audio TRACK. Each entry points
+// at the track's FILE with Start at its INDEX 01 and End at the next track's
+// start in the same file (0 for the last track of a file). Tracks without an
+// INDEX 01 are skipped.
+func parseCue(scanner *bufio.Scanner, baseDir string) []PlaylistEntry {
+	type cueTrack struct {
+		number    string
+		title     string
+		performer string
+		path      string
+		start     time.Duration
+		hasStart  bool
+	}
+	var (
+		tracks  []cueTrack
+		file    string
+		current *cueTrack
+	)
+	firstLine := true
+	for scanner.Scan() {
+		line := normalizeEntryText(scanner.Text(), firstLine)
+		firstLine = false
+		cmd, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		switch strings.ToUpper(cmd) {
+		case "FILE":
+			if name := cueFileName(rest); name != "" {
+				file = resolvePlaylistEntryPath(name, baseDir)
+			}
+			current = nil
+		case "TRACK":
+			number, kind, _ := strings.Cut(rest, " ")
+			current = nil
+			if file == "" || !strings.EqualFold(strings.TrimSpace(kind), "AUDIO") {
+				continue
+			}
+			tracks = append(tracks, cueTrack{number: number, path: file})
+			current = &tracks[len(tracks)-1]
+		case "TITLE":
+			if current != nil {
+				current.title = normalizeEntryText(rest, false)
+			}
+		case "PERFORMER":
+			if current != nil {
+				current.performer = normalizeEntryText(rest, false)
+			}
+		case "INDEX":
+			num, at, _ := strings.Cut(rest, " ")
+			if current == nil || num != "01" {
+				continue
+			}
+			if d, ok := parseCueTime(strings.TrimSpace(at)); ok {
+				current.start, current.hasStart = d, true
+			}
+		}
+	}
+
+	entries := make([]PlaylistEntry, 0, len(tracks))
+	for i, t := range tracks {
+		if !t.hasStart {
+			continue
+		}
+		title := t.title
+		if title == "" {
+			title = "Track " + t.number
+		}
+		if t.performer != "" {
+			title = t.performer + " - " + title
+		}
+		entry := PlaylistEntry{Title: title, Path: t.path, Start: t.start}
+		for _, next := range tracks[i+1:] {
+			if next.hasStart {
+				if next.path == t.path && next.start > t.start {
+					entry.End = next.start
+				}
+				break
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// cueFileName extracts the file name from the arguments of a FILE command,
+// dropping the trailing file type (WAVE, MP3, ...).
+func cueFileName(rest string) string {
+	if strings.HasPrefix(rest, `"`) {
+		if end := strings.Index(rest[1:], `"`); end >= 0 {
+			return rest[1 : end+1]
+		}
+		return strings.Trim(rest, `"`)
+	}
+	if i := strings.LastIndex(rest, " "); i > 0 {
+		return strings.TrimSpace(rest[:i])
+	}
+	return rest
+}
+
+// parseCueTime parses an MM:SS:FF cue time, where FF counts 1/75 s frames.
+func parseCueTime(s string) (time.Duration, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	var v [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		v[i] = n
+	}
+	if v[1] >= 60 || v[2] >= cueFramesPerSecond {
+		return 0, false
+	}
+	return time.Duration(v[0]*60+v[1])*time.Second +
+		time.Duration(v[2])*time.Second/cueFramesPerSecond, true
+}
+
+// SiblingCueSheet looks for a cue sheet next to a media file (song.cue or
+// song.flac.cue) that references it, and returns the sheet's playable tracks.
+func SiblingCueSheet(path string) ([]PlaylistEntry, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, false
+	}
+	base := strings.TrimSuffix(abs, filepath.Ext(abs))
+	for _, cue := range []string{base + ".cue", abs + ".cue"} {
+		if info, err := os.Stat(cue); err != nil || info.IsDir() {
+			continue
+		}
+		entries, err := ParseLocalPlaylist(cue)
+		if err != nil {
+			continue
+		}
+		entries, _ = FilterPlayablePlaylistEntries(entries)
+		for _, e := range entries {
+			if e.Path == abs {
+				return entries, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// latin1ToUTF8 decodes ISO-8859-1 text, the usual encoding of cue sheets
+// written by older rippers.
+func latin1ToUTF8(data []byte) []byte {
+	var sb strings.Builder
+	sb.Grow(len(data) * 2)
+	for _, b := range data {
+		sb.WriteRune(rune(b))
+	}
+	return []byte(sb.String())
+}
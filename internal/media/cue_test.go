@@ -0,0 +1,68 @@
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLocalPlaylistCue(t *testing.T) {
+	dir := t.TempDir()
+	sheet := filepath.Join(dir, "live.cue")
+	content := "\uFEFFPERFORMER \"Band\"\r\nTITLE \"Live\"\r\nFILE \"live set.flac\" WAVE\r\n" +
+		"  TRACK 01 AUDIO\r\n    TITLE \"Intro\"\r\n    INDEX 01 00:00:00\r\n" +
+		"  TRACK 02 AUDIO\r\n    TITLE \"Song\"\r\n    PERFORMER \"Guest\"\r\n    INDEX 00 03:58:00\r\n    INDEX 01 04:00:37\r\n" +
+		"  TRACK 03 AUDIO\r\n    INDEX 01 09:30:00\r\n" +
+		"FILE encore.flac WAVE\r\n  TRACK 04 AUDIO\r\n    TITLE \"Encore\"\r\n    INDEX 01 00:00:00\r\n"
+	if err := os.WriteFile(sheet, []byte(content), 0o644); err != nil {
+		t.Fatalf("write cue sheet: %v", err)
+	}
+
+	got, err := ParseLocalPlaylist(sheet)
+	if err != nil {
+		t.Fatalf("ParseLocalPlaylist() error = %v", err)
+	}
+
+	set := filepath.Join(dir, "live set.flac")
+	songStart := 4*time.Minute + 37*time.Second/75
+	want := []PlaylistEntry{
+		{Title: "Intro", Path: set, End: songStart},
+		{Title: "Guest - Song", Path: set, Start: songStart, End: 9*time.Minute + 30*time.Second},
+		{Title: "Track 03", Path: set, Start: 9*time.Minute + 30*time.Second},
+		{Title: "Encore", Path: filepath.Join(dir, "encore.flac")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLocalPlaylist() = %#v, want %#v", got, want)
+	}
+}
+
+func TestSiblingCueSheetFindsReferencingSheet(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "set.flac")
+	if err := os.WriteFile(audio, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := SiblingCueSheet(audio); ok {
+		t.Fatal("expected no cue sheet before one exists")
+	}
+
+	content := "FILE \"set.flac\" WAVE\nTRACK 01 AUDIO\nINDEX 01 00:00:00\nTRACK 02 AUDIO\nINDEX 01 01:00:00\n"
+	if err := os.WriteFile(filepath.Join(dir, "set.cue"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries, ok := SiblingCueSheet(audio)
+	if !ok || len(entries) != 2 || entries[1].Start != time.Minute {
+		t.Fatalf("expected two cue tracks for set.flac, got %#v", entries)
+	}
+}
+
+func TestParseCueTimeRejectsInvalidFrames(t *testing.T) {
+	if _, ok := parseCueTime("01:02:75"); ok {
+		t.Fatal("expected frame 75 to be rejected")
+	}
+	if d, ok := parseCueTime("61:00:00"); !ok || d != 61*time.Minute {
+		t.Fatalf("expected minutes past 60 to parse, got %v %v", d, ok)
+	}
+}
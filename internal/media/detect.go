@@ -1,21 +1,29 @@
 package media
 
-import "strings"
+import (
+	"bytes"
+	"strings"
+)
 
 var audioExts = map[string]bool{
 	".mp3":  true,
 	".wav":  true,
+	".aiff": true,
+	".aif":  true,
 	".flac": true,
 	".ogg":  true,
+	".oga":  true,
 	".aac":  true,
 	".m4a":  true,
 	".m4b":  true,
+	".opus": true,
 }
 
 var playlistExts = map[string]bool{
 	".m3u":  true,
 	".m3u8": true,
 	".pls":  true,
+	".cue":  true,
 }
 
 // IsSupportedExt returns true if the extension is a supported playable media format.
@@ -30,5 +38,33 @@ func IsPlaylistExt(ext string) bool {
 
 // SupportedExtsList returns a human-readable list of supported playable media formats.
 func SupportedExtsList() string {
-	return ".mp3, .wav, .flac, .ogg, .aac, .m4a, .m4b"
+	return ".mp3, .wav, .aiff, .aif, .flac, .ogg, .oga, .aac, .m4a, .m4b, .opus"
+}
+
+// SniffExt guesses the extension of audio data from its first bytes, for
+// input that has no file name. It needs about 36 bytes and returns "" when
+// the format is not recognized.
+func SniffExt(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return ".flac"
+	case bytes.HasPrefix(header, []byte("OggS")):
+		if len(header) >= 36 && bytes.Equal(header[28:36], []byte("OpusHead")) {
+			return ".opus"
+		}
+		return ".ogg"
+	case len(header) >= 12 && bytes.Equal(header[:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return ".wav"
+	case len(header) >= 12 && bytes.Equal(header[:4], []byte("FORM")) && (bytes.Equal(header[8:12], []byte("AIFF")) || bytes.Equal(header[8:12], []byte("AIFC"))):
+		return ".aiff"
+	case len(header) >= 8 && bytes.Equal(header[4:8], []byte("ftyp")):
+		return ".m4a"
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return ".mp3"
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xF6 == 0xF0:
+		return ".aac" // ADTS: frame sync with layer bits 00
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0 && header[1]&0x06 != 0:
+		return ".mp3"
+	}
+	return ""
 }
@@ -21,3 +21,40 @@ func TestSupportedExtsListIncludesAACFamily(t *testing.T) {
 		}
 	}
 }
+
+func TestIsSupportedExtIncludesOpus(t *testing.T) {
+	if !IsSupportedExt(".opus") || !IsSupportedExt(".OPUS") {
+		t.Fatal("expected .opus to be supported")
+	}
+	if !strings.Contains(SupportedExtsList(), ".opus") {
+		t.Fatalf("expected supported ext list to include .opus, got %q", SupportedExtsList())
+	}
+}
+
+func TestSniffExt(t *testing.T) {
+	ogg := append([]byte("OggS"), make([]byte, 24)...)
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), ".flac"},
+		{"vorbis", append(append([]byte{}, ogg...), "\x01vorbis\x00"...), ".ogg"},
+		{"opus", append(append([]byte{}, ogg...), "OpusHead"...), ".opus"},
+		{"ogg flac", append(append([]byte{}, ogg...), "\x7fFLAC\x01\x00"...), ".ogg"},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), ".wav"},
+		{"aiff", []byte("FORM\x00\x00\x00\x2eAIFFCOMM"), ".aiff"},
+		{"aifc", []byte("FORM\x00\x00\x00\x2eAIFCFVER"), ".aiff"},
+		{"mp4", []byte("\x00\x00\x00\x20ftypM4A "), ".m4a"},
+		{"id3", []byte("ID3\x04\x00"), ".mp3"},
+		{"mp3 frame", []byte{0xFF, 0xFB, 0x90, 0x64}, ".mp3"},
+		{"adts", []byte{0xFF, 0xF1, 0x50, 0x80}, ".aac"},
+		{"text", []byte("hello world"), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := SniffExt(tt.header); got != tt.want {
+			t.Errorf("%s: SniffExt() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
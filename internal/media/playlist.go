@@ -3,23 +3,44 @@ package media
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
 // PlaylistEntry represents one playable candidate from a local playlist file.
-// Exactly one of URL or Path is expected to be set.
+// Exactly one of URL or Path is expected to be set. Cue sheet entries also
+// carry the section of Path to play; End 0 plays to the end of the file.
 type PlaylistEntry struct {
 	Title string
 	URL   string
 	Path  string
+	Start time.Duration
+	End   time.Duration
 }
 
-// ParseLocalPlaylist parses a local .m3u/.m3u8/.pls file into playlist entries.
-// Relative path entries are resolved against the playlist file directory.
+// maxNestedPlaylistDepth limits how many levels of local playlists that
+// reference other local playlists are expanded, as maxRemotePlaylistDepth
+// does for remote ones.
+const maxNestedPlaylistDepth = 2
+
+// ParseLocalPlaylist parses a local .m3u/.m3u8/.pls/.cue file into playlist
+// entries. Relative path entries are resolved against the playlist file
+// directory. Entries naming another local playlist are replaced by its
+// entries, up to maxNestedPlaylistDepth levels; a playlist that refers back
+// to one it is nested in, or that cannot be read, is dropped.
 func ParseLocalPlaylist(path string) ([]PlaylistEntry, error) {
+	return parseLocalPlaylist(path, maxNestedPlaylistDepth, nil)
+}
+
+// parseLocalPlaylist parses path and expands nested playlists while depth
+// allows. parents holds the absolute paths of the playlists path is nested
+// in.
+func parseLocalPlaylist(path string, depth int, parents []string) ([]PlaylistEntry, error) {
 	ext := strings.ToLower(filepath.Ext(path))
 	if !IsPlaylistExt(ext) {
 		return nil, fmt.Errorf("unsupported playlist format %s", ext)
@@ -34,6 +55,9 @@ func ParseLocalPlaylist(path string) ([]PlaylistEntry, error) {
 	if err != nil {
 		return nil, fmt.Errorf("reading playlist: %w", err)
 	}
+	if ext == ".cue" && !utf8.Valid(data) {
+		data = latin1ToUTF8(data)
+	}
 	if !utf8.Valid(data) {
 		return nil, fmt.Errorf("playlist is not valid UTF-8")
 	}
@@ -41,12 +65,33 @@ func ParseLocalPlaylist(path string) ([]PlaylistEntry, error) {
 	baseDir := filepath.Dir(absPlaylistPath)
 	scanner := bufio.NewScanner(strings.NewReader(string(data)))
 
+	var entries []PlaylistEntry
 	switch ext {
 	case ".pls":
-		return parsePLS(scanner, baseDir), nil
+		entries = parsePLS(scanner, baseDir)
+	case ".cue":
+		entries = parseCue(scanner, baseDir)
 	default:
-		return parseM3U(scanner, baseDir), nil
+		entries = parseM3U(scanner, baseDir)
+	}
+
+	parents = append(parents, absPlaylistPath)
+	out := entries[:0:0]
+	for _, e := range entries {
+		if e.Path == "" || !IsPlaylistExt(filepath.Ext(e.Path)) {
+			out = append(out, e)
+			continue
+		}
+		if depth <= 0 || slices.Contains(parents, e.Path) {
+			continue
+		}
+		nested, err := parseLocalPlaylist(e.Path, depth-1, parents)
+		if err != nil {
+			continue
+		}
+		out = append(out, nested...)
 	}
+	return out, nil
 }
 
 // FilterPlayablePlaylistEntries keeps only entries that can be attempted:
@@ -89,18 +134,35 @@ func FilterPlayablePlaylistEntries(entries []PlaylistEntry) ([]PlaylistEntry, in
 	return out, skipped
 }
 
+// parseM3U reads M3U entries, titling each from a preceding #EXTINF line when
+// one is present. Lines may end in \n or \r\n; the trailing \r is trimmed
+// with the surrounding whitespace.
 func parseM3U(scanner *bufio.Scanner, baseDir string) []PlaylistEntry {
 	entries := make([]PlaylistEntry, 0)
 	firstLine := true
+	pendingTitle := ""
 	for scanner.Scan() {
 		line := normalizeEntryText(scanner.Text(), firstLine)
 		firstLine = false
-		if line == "" || strings.HasPrefix(line, "#") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(line), "#extinf:") {
+			if comma := strings.Index(line, ","); comma >= 0 && comma+1 < len(line) {
+				pendingTitle = strings.TrimSpace(line[comma+1:])
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
 			continue
 		}
 		if entry, ok := parseEntry(line, baseDir); ok {
+			if pendingTitle != "" {
+				entry.Title = pendingTitle
+			}
 			entries = append(entries, entry)
 		}
+		pendingTitle = ""
 	}
 	return entries
 }
@@ -187,3 +249,30 @@ func resolvePlaylistEntryPath(raw, baseDir string) string {
 	}
 	return filepath.Clean(filepath.Join(baseDir, p))
 }
+
+// WritePlaylist writes entries as an extended M3U playlist. Each entry is
+// written by its URL when set, otherwise by its local path; entries with
+// neither are skipped.
+func WritePlaylist(w io.Writer, entries []PlaylistEntry) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("#EXTM3U\n"); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		location := e.URL
+		if location == "" {
+			location = e.Path
+		}
+		if location == "" {
+			continue
+		}
+		title := strings.Join(strings.Fields(e.Title), " ")
+		if title == "" {
+			title = location
+		}
+		if _, err := fmt.Fprintf(bw, "#EXTINF:-1,%s\n%s\n", title, location); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
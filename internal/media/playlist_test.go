@@ -30,6 +30,89 @@ func TestParseLocalPlaylistM3U(t *testing.T) {
 	}
 }
 
+func TestParseLocalPlaylistM3UResolvesParentPathsWithTitles(t *testing.T) {
+	dir := t.TempDir()
+	lists := filepath.Join(dir, "lists")
+	if err := os.Mkdir(lists, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	playlist := filepath.Join(lists, "mix.m3u8")
+	content := "\uFEFF#EXTM3U\n#EXTINF:215,Artist - Song\n../sibling/song.flac\n../other.mp3\n"
+	if err := os.WriteFile(playlist, []byte(content), 0o644); err != nil {
+		t.Fatalf("write playlist: %v", err)
+	}
+
+	got, err := ParseLocalPlaylist(playlist)
+	if err != nil {
+		t.Fatalf("ParseLocalPlaylist() error = %v", err)
+	}
+
+	want := []PlaylistEntry{
+		{Path: filepath.Join(dir, "sibling", "song.flac"), Title: "Artist - Song"},
+		{Path: filepath.Join(dir, "other.mp3")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLocalPlaylist() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseLocalPlaylistExpandsNestedPlaylists(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// master -> albums/a.m3u (relative to albums/) -> deep.m3u, which is one
+	// level too deep; loop.m3u refers to itself and to master.
+	write("master.m3u", "intro.mp3\nalbums/a.m3u\nloop.m3u\nmissing.m3u\n")
+	write("albums/a.m3u", "#EXTINF:1,Track A\ntrack-a.flac\n../deep/one.m3u\n")
+	write("deep/one.m3u", "two.m3u\nd1.mp3\n")
+	write("deep/two.m3u", "d2.mp3\n")
+	write("loop.m3u", "loop.m3u\nmaster.m3u\nloop.ogg\n")
+
+	got, err := ParseLocalPlaylist(filepath.Join(dir, "master.m3u"))
+	if err != nil {
+		t.Fatalf("ParseLocalPlaylist() error = %v", err)
+	}
+	want := []PlaylistEntry{
+		{Path: filepath.Join(dir, "intro.mp3")},
+		{Path: filepath.Join(dir, "albums", "track-a.flac"), Title: "Track A"},
+		{Path: filepath.Join(dir, "deep", "d1.mp3")},
+		{Path: filepath.Join(dir, "loop.ogg")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLocalPlaylist() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseLocalPlaylistM3UWithCRLFLineEndings(t *testing.T) {
+	dir := t.TempDir()
+	playlist := filepath.Join(dir, "windows.m3u")
+	content := "\uFEFF#EXTM3U\r\n#EXTINF:-1,Radio\r\nhttps://example.com/live\r\n\r\nsong.mp3\r\n"
+	if err := os.WriteFile(playlist, []byte(content), 0o644); err != nil {
+		t.Fatalf("write playlist: %v", err)
+	}
+
+	got, err := ParseLocalPlaylist(playlist)
+	if err != nil {
+		t.Fatalf("ParseLocalPlaylist() error = %v", err)
+	}
+
+	want := []PlaylistEntry{
+		{URL: "https://example.com/live", Title: "Radio"},
+		{Path: filepath.Join(dir, "song.mp3")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLocalPlaylist() = %#v, want %#v", got, want)
+	}
+}
+
 func TestParseLocalPlaylistPLS(t *testing.T) {
 	dir := t.TempDir()
 	playlist := filepath.Join(dir, "list.pls")
@@ -99,3 +182,41 @@ func TestFilterPlayablePlaylistEntries(t *testing.T) {
 		t.Fatalf("FilterPlayablePlaylistEntries() skipped=%d, want %d", skipped, 3)
 	}
 }
+
+func TestWritePlaylistRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	entries := []PlaylistEntry{
+		{Title: "Local\nSong", Path: filepath.Join(dir, "one.mp3")},
+		{Title: "Remote", URL: "https://example.com/watch?v=1", Path: filepath.Join(dir, "tmp.wav")},
+		{Title: "Empty"},
+	}
+
+	playlist := filepath.Join(dir, "out.m3u8")
+	f, err := os.Create(playlist)
+	if err != nil {
+		t.Fatalf("create playlist: %v", err)
+	}
+	if err := WritePlaylist(f, entries); err != nil {
+		t.Fatalf("WritePlaylist() error = %v", err)
+	}
+	f.Close()
+
+	data, err := os.ReadFile(playlist)
+	if err != nil {
+		t.Fatalf("read playlist: %v", err)
+	}
+	want := "#EXTM3U\n" +
+		"#EXTINF:-1,Local Song\n" + filepath.Join(dir, "one.mp3") + "\n" +
+		"#EXTINF:-1,Remote\nhttps://example.com/watch?v=1\n"
+	if string(data) != want {
+		t.Fatalf("WritePlaylist() wrote %q, want %q", data, want)
+	}
+
+	got, err := ParseLocalPlaylist(playlist)
+	if err != nil {
+		t.Fatalf("ParseLocalPlaylist() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Path != filepath.Join(dir, "one.mp3") || got[1].URL != "https://example.com/watch?v=1" {
+		t.Fatalf("unexpected round-trip entries: %#v", got)
+	}
+}
@@ -1,11 +1,170 @@
 package player
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/bogem/id3v2/v2"
 	aacfile "github.com/olivier-w/climp-aac-decoder/aacfile"
 )
 
+// ErrDRMProtected is returned when a file's audio is encrypted (an iTunes
+// .m4p purchase, for example). Neither the native decoder nor ffmpeg can play
+// it, so there is no fallback.
+var ErrDRMProtected = errors.New("file is DRM-protected")
+
+// newAACDecoder opens AAC-family files with the native climp-aac-decoder.
+// Streams it does not support yet (HE-AAC/SBR, multichannel layouts, channel
+// layouts given by a program config element, MP4 tracks with more than one
+// sample description) fall back to the ffmpeg temp-WAV decoder when ffmpeg is
+// installed. Raw ADTS files are trimmed to
+// their gapless length when their ID3 tag carries iTunSMPB.
 func newAACDecoder(f *os.File) (audioDecoder, error) {
-	return aacfile.OpenFile(f)
+	dec, err := aacfile.OpenFile(f)
+	if err == nil {
+		d := &aacDecoder{Reader: dec}
+		if strings.EqualFold(filepath.Ext(f.Name()), ".aac") {
+			if delay, padding, samples, ok := readADTSGapless(f); ok {
+				d.trim(delay, padding, samples)
+			}
+		}
+		return d, nil
+	}
+	if isEncryptedMP4(err) {
+		return nil, fmt.Errorf("%w: %s", ErrDRMProtected, err)
+	}
+	if _, serr := f.Seek(0, io.SeekStart); serr != nil {
+		return nil, err
+	}
+	fallback, ferr := newFFmpegDecoder(f)
+	if ferr != nil {
+		return nil, fmt.Errorf("%w (ffmpeg fallback: %v)", err, ferr)
+	}
+	return fallback, nil
+}
+
+// isEncryptedMP4 reports whether aacfile rejected the file for carrying an
+// encrypted sample entry (enca, or a protection scheme sinf box).
+func isEncryptedMP4(err error) bool {
+	var feature *aacfile.UnsupportedFeatureError
+	return errors.As(err, &feature) && feature.Feature == "encrypted MP4"
+}
+
+// aacDecoder is the native AAC reader, wrapped so it can describe itself and
+// drop encoder delay and padding the reader does not know about. Positions
+// are relative to the trimmed stream.
+type aacDecoder struct {
+	*aacfile.Reader
+	skip  int64 // bytes of encoder delay before the first real sample
+	limit int64 // bytes kept after skip; 0 keeps the rest of the stream
+	pos   int64
+}
+
+// trim applies gapless info given in sample frames. samples (the original
+// length) wins over padding; values that do not fit the stream are ignored.
+func (d *aacDecoder) trim(delay, padding int, samples int64) {
+	frame := int64(d.ChannelCount()) * 2
+	total := d.Reader.Length()
+	skip := int64(delay) * frame
+	var limit int64
+	switch {
+	case samples > 0:
+		limit = samples * frame
+	case padding > 0:
+		limit = total - skip - int64(padding)*frame
+	}
+	if skip < 0 || limit < 0 || skip+limit > total || (skip == 0 && limit == 0) {
+		return
+	}
+	if _, err := d.Reader.Seek(skip, io.SeekStart); err != nil {
+		return
+	}
+	d.skip, d.limit, d.pos = skip, limit, 0
+}
+
+func (d *aacDecoder) Length() int64 {
+	if d.limit > 0 {
+		return d.limit
+	}
+	return d.Reader.Length() - d.skip
+}
+
+func (d *aacDecoder) Read(p []byte) (int, error) {
+	if d.limit > 0 {
+		if d.pos >= d.limit {
+			return 0, io.EOF
+		}
+		if rest := d.limit - d.pos; int64(len(p)) > rest {
+			p = p[:rest]
+		}
+	}
+	n, err := d.Reader.Read(p)
+	d.pos += int64(n)
+	return n, err
+}
+
+func (d *aacDecoder) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += d.pos
+	case io.SeekEnd:
+		offset += d.Length()
+	}
+	offset = max(min(offset, d.Length()), 0)
+	if _, err := d.Reader.Seek(d.skip+offset, io.SeekStart); err != nil {
+		return d.pos, err
+	}
+	d.pos = offset
+	return offset, nil
+}
+
+// readADTSGapless reads iTunSMPB from the ID3v2 tag in front of a raw AAC
+// stream, as a comment (iTunes) or TXXX frame.
+func readADTSGapless(f *os.File) (delay, padding int, samples int64, ok bool) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	tag, err := id3v2.ParseReader(io.NewSectionReader(f, 0, info.Size()), id3v2.Options{Parse: true})
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	for _, fr := range tag.GetFrames(tag.CommonID("Comments")) {
+		if c, isComment := fr.(id3v2.CommentFrame); isComment && strings.EqualFold(c.Description, "iTunSMPB") {
+			return parseITunSMPB(c.Text)
+		}
+	}
+	for _, fr := range tag.GetFrames("TXXX") {
+		if udtf, isText := fr.(id3v2.UserDefinedTextFrame); isText && strings.EqualFold(udtf.Description, "iTunSMPB") {
+			return parseITunSMPB(udtf.Value)
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// parseITunSMPB reads the encoder delay, padding, and original length (in
+// sample frames) from an iTunSMPB value: space-separated hex fields, the
+// second through fourth of which hold those numbers.
+func parseITunSMPB(v string) (delay, padding int, samples int64, ok bool) {
+	fields := strings.Fields(v)
+	if len(fields) < 4 {
+		return 0, 0, 0, false
+	}
+	d, err1 := strconv.ParseUint(fields[1], 16, 32)
+	p, err2 := strconv.ParseUint(fields[2], 16, 32)
+	n, err3 := strconv.ParseUint(fields[3], 16, 63)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, 0, 0, false
+	}
+	return int(d), int(p), int64(n), true
+}
+
+func (d *aacDecoder) sourceInfo() SourceInfo {
+	info := d.Info()
+	return SourceInfo{Decoder: "native-aac", SampleRate: info.SampleRate, Channels: info.ChannelCount}
 }
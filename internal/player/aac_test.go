@@ -1,10 +1,15 @@
 package player
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"testing"
+
+	aacfile "github.com/olivier-w/climp-aac-decoder/aacfile"
 )
 
 func TestNewNativeDecoderOpensAACFixtures(t *testing.T) {
@@ -90,6 +95,62 @@ func TestNewDecoderNormalizesAACFixtures(t *testing.T) {
 	}
 }
 
+func TestParseITunSMPB(t *testing.T) {
+	delay, padding, samples, ok := parseITunSMPB(" 00000000 00000840 000001C0 0000000000046E00 00000000 00000000")
+	if !ok || delay != 0x840 || padding != 0x1C0 || samples != 0x46E00 {
+		t.Fatalf("parseITunSMPB() = %d, %d, %d, %v", delay, padding, samples, ok)
+	}
+	if _, _, _, ok := parseITunSMPB("00000000 zz"); ok {
+		t.Fatal("parseITunSMPB() accepted a malformed value")
+	}
+}
+
+// TestAACDecoderTrimMatchesUntrimmedTail checks that a trimmed reader yields
+// the same bytes as the full one near the tail, and stops where the gapless
+// length says.
+func TestAACDecoderTrimMatchesUntrimmedTail(t *testing.T) {
+	path := fixturePath(t, "smoke-aac-12s.aac")
+	open := func() *aacDecoder {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		dec, err := aacfile.OpenFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &aacDecoder{Reader: dec}
+	}
+	full, trimmed := open(), open()
+	frame := int64(full.ChannelCount()) * 2
+	const delay, padding = 2112, 960
+	trimmed.trim(delay, padding, 0)
+	want := full.Length() - (delay+padding)*frame
+	if trimmed.Length() != want {
+		t.Fatalf("trimmed Length() = %d, want %d", trimmed.Length(), want)
+	}
+
+	tail := 8192 * frame
+	if _, err := trimmed.Seek(-tail, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(trimmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := full.Seek(delay*frame+want-tail, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	ref := make([]byte, tail)
+	if _, err := io.ReadFull(full, ref); err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(got)) != tail || !bytes.Equal(got, ref) {
+		t.Fatalf("trimmed tail: %d bytes, equal = %v; want the %d bytes before the padding", len(got), bytes.Equal(got, ref), tail)
+	}
+}
+
 func fixturePath(t testing.TB, name string) string {
 	t.Helper()
 
@@ -106,3 +167,19 @@ func fixturePath(t testing.TB, name string) string {
 	t.Skipf("fixture %q not available in songs/ or sibling climp-aac-decoder repo", name)
 	return ""
 }
+
+func TestIsEncryptedMP4MatchesOnlyEncryption(t *testing.T) {
+	encrypted := fmt.Errorf("open: %w", &aacfile.UnsupportedFeatureError{Feature: "encrypted MP4"})
+	if !isEncryptedMP4(encrypted) {
+		t.Fatal("expected the encrypted MP4 feature error to be recognized")
+	}
+	for _, err := range []error{
+		&aacfile.UnsupportedFeatureError{Feature: "MP4 audio track layout"},
+		aacfile.ErrMalformedBitstream,
+		errors.New("encrypted MP4"),
+	} {
+		if isEncryptedMP4(err) {
+			t.Fatalf("isEncryptedMP4(%v) = true, want false", err)
+		}
+	}
+}
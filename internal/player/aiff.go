@@ -0,0 +1,200 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// --- AIFF decoder ---
+
+// aiffDecoder reads uncompressed AIFF and AIFF-C files. AIFF samples are
+// big-endian two's complement at every depth (8-bit included, unlike WAV);
+// AIFF-C also allows "sowt", the little-endian 16-bit variant macOS writes.
+type aiffDecoder struct {
+	baseDecoder
+	src          *io.SectionReader // the SSND sample data
+	srcBitDepth  int
+	srcFrameSize int64 // bytes per sample frame in source format
+	littleEndian bool  // AIFF-C "sowt"
+}
+
+func newAIFFDecoder(f *os.File) (*aiffDecoder, error) {
+	var hdr [12]byte
+	if _, err := io.ReadFull(f, hdr[:]); err != nil || string(hdr[:4]) != "FORM" {
+		return nil, fmt.Errorf("invalid AIFF file")
+	}
+	aifc := string(hdr[8:12]) == "AIFC"
+	if !aifc && string(hdr[8:12]) != "AIFF" {
+		return nil, fmt.Errorf("invalid AIFF file")
+	}
+
+	var (
+		comm               []byte
+		pcmStart, pcmLen   int64
+		haveComm, haveSSND bool
+	)
+	for !haveComm || !haveSSND {
+		var ch [8]byte
+		if _, err := io.ReadFull(f, ch[:]); err != nil {
+			break
+		}
+		size := int64(binary.BigEndian.Uint32(ch[4:]))
+		start, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		switch string(ch[:4]) {
+		case "COMM":
+			if size < 18 || size > 1024 {
+				return nil, fmt.Errorf("invalid AIFF COMM chunk")
+			}
+			comm = make([]byte, size)
+			if _, err := io.ReadFull(f, comm); err != nil {
+				return nil, fmt.Errorf("reading AIFF COMM chunk: %w", err)
+			}
+			haveComm = true
+		case "SSND":
+			var off [8]byte
+			if size < 8 {
+				return nil, fmt.Errorf("invalid AIFF SSND chunk")
+			}
+			if _, err := io.ReadFull(f, off[:]); err != nil {
+				return nil, fmt.Errorf("reading AIFF SSND chunk: %w", err)
+			}
+			skip := int64(binary.BigEndian.Uint32(off[:4]))
+			pcmStart = start + 8 + skip
+			pcmLen = max(size-8-skip, 0)
+			haveSSND = true
+		}
+		// Chunks are padded to an even size.
+		if _, err := f.Seek(start+size+size&1, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	if !haveComm {
+		return nil, fmt.Errorf("AIFF file has no COMM chunk")
+	}
+	if !haveSSND {
+		return nil, fmt.Errorf("AIFF file has no SSND chunk")
+	}
+
+	channels := int(binary.BigEndian.Uint16(comm[0:]))
+	frames := int64(binary.BigEndian.Uint32(comm[2:]))
+	bitDepth := int(binary.BigEndian.Uint16(comm[6:]))
+	sampleRate := int(math.Round(extendedToFloat(comm[8:18])))
+	littleEndian := false
+	if aifc {
+		if len(comm) < 22 {
+			return nil, fmt.Errorf("invalid AIFF-C COMM chunk")
+		}
+		switch compression := string(comm[18:22]); compression {
+		case "NONE", "twos":
+		case "sowt":
+			littleEndian = true
+		default:
+			return nil, fmt.Errorf("unsupported AIFF-C compression: %q", compression)
+		}
+	}
+	if channels < 1 || sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid AIFF format: %d channels at %d Hz", channels, sampleRate)
+	}
+	// Sample sizes that are not whole bytes are stored left-justified in the
+	// next byte size, so rounding up reads them correctly.
+	bitDepth = (bitDepth + 7) / 8 * 8
+	if bitDepth < 8 || bitDepth > 32 || littleEndian && bitDepth != 16 {
+		return nil, fmt.Errorf("unsupported AIFF bit depth: %d", bitDepth)
+	}
+
+	srcFrameSize := int64(channels) * int64(bitDepth) / 8
+	frames = min(frames, pcmLen/srcFrameSize)
+	return &aiffDecoder{
+		baseDecoder: baseDecoder{
+			totalBytes: frames * int64(channels) * 2, // 16-bit output
+			sampleRate: sampleRate,
+			channels:   channels,
+		},
+		src:          io.NewSectionReader(f, pcmStart, frames*srcFrameSize),
+		srcBitDepth:  bitDepth,
+		srcFrameSize: srcFrameSize,
+		littleEndian: littleEndian,
+	}, nil
+}
+
+// extendedToFloat decodes the 80-bit IEEE 754 extended float AIFF uses for
+// the sample rate.
+func extendedToFloat(b []byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[0:]))
+	mant := binary.BigEndian.Uint64(b[2:])
+	sign := 1.0
+	if exp&0x8000 != 0 {
+		sign = -1
+	}
+	exp &= 0x7FFF
+	if exp == 0 && mant == 0 {
+		return 0
+	}
+	return sign * math.Ldexp(float64(mant), exp-16383-63)
+}
+
+func (d *aiffDecoder) sourceInfo() SourceInfo {
+	return SourceInfo{Decoder: "aiff", SampleRate: d.sampleRate, Channels: d.channels, BitDepth: d.srcBitDepth}
+}
+
+func (d *aiffDecoder) Read(p []byte) (int, error) {
+	if n, ok := d.drainBuf(p); ok {
+		return n, nil
+	}
+
+	srcBytesPerSample := d.srcBitDepth / 8
+	numOutputSamples := max(len(p)/2, 1)
+	srcBytes := make([]byte, numOutputSamples*srcBytesPerSample)
+	n, err := io.ReadFull(d.src, srcBytes)
+	samplesRead := n / srcBytesPerSample
+	if samplesRead == 0 {
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, err
+		}
+		return 0, io.EOF
+	}
+
+	// Convert to 16-bit LE PCM, keeping the most significant bytes.
+	raw := make([]byte, samplesRead*2)
+	for i := 0; i < samplesRead; i++ {
+		s := srcBytes[i*srcBytesPerSample:]
+		var hi, lo byte
+		switch {
+		case d.littleEndian:
+			hi, lo = s[1], s[0]
+		case srcBytesPerSample == 1:
+			hi = s[0]
+		default:
+			hi, lo = s[0], s[1]
+		}
+		raw[i*2] = lo
+		raw[i*2+1] = hi
+	}
+
+	written := d.bufferOutput(p, raw)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return written, err
+}
+
+func (d *aiffDecoder) Seek(offset int64, whence int) (int64, error) {
+	newPos := d.calcSeekPos(offset, whence)
+
+	// Convert output byte position to source byte position
+	outputFrameSize := int64(d.channels) * 2
+	sampleFrame := newPos / outputFrameSize
+	if _, err := d.src.Seek(sampleFrame*d.srcFrameSize, io.SeekStart); err != nil {
+		return d.pos, err
+	}
+
+	d.commitSeek(newPos)
+	return newPos, nil
+}
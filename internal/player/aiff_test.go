@@ -0,0 +1,139 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeAIFF writes a 44.1 kHz AIFF file (AIFF-C when compression is set)
+// with the given raw samples, followed by a trailing chunk that must not be
+// read as audio.
+func writeAIFF(t *testing.T, compression string, channels, bitDepth int, data []byte) *os.File {
+	t.Helper()
+	var comm bytes.Buffer
+	be := func(b *bytes.Buffer, v any) { _ = binary.Write(b, binary.BigEndian, v) }
+	be(&comm, uint16(channels))
+	be(&comm, uint32(len(data)/(channels*bitDepth/8)))
+	be(&comm, uint16(bitDepth))
+	comm.Write([]byte{0x40, 0x0E, 0xAC, 0x44, 0, 0, 0, 0, 0, 0}) // 44100 as 80-bit extended
+	form := "AIFF"
+	if compression != "" {
+		form = "AIFC"
+		comm.WriteString(compression)
+		comm.Write([]byte{0}) // empty pascal string name, padded
+		comm.Write([]byte{0})
+	}
+
+	var body bytes.Buffer
+	body.WriteString(form)
+	body.WriteString("COMM")
+	be(&body, uint32(comm.Len()))
+	body.Write(comm.Bytes())
+	body.WriteString("SSND")
+	be(&body, uint32(8+len(data)))
+	be(&body, uint32(0)) // offset
+	be(&body, uint32(0)) // block size
+	body.Write(data)
+	if len(data)%2 == 1 {
+		body.WriteByte(0)
+	}
+	body.WriteString("ANNO")
+	be(&body, uint32(4))
+	body.WriteString("note")
+
+	var b bytes.Buffer
+	b.WriteString("FORM")
+	be(&b, uint32(body.Len()))
+	b.Write(body.Bytes())
+
+	path := filepath.Join(t.TempDir(), "test.aiff")
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func readAllAIFF(t *testing.T, f *os.File) []byte {
+	t.Helper()
+	dec, err := newAIFFDecoder(f)
+	if err != nil {
+		t.Fatalf("newAIFFDecoder() error = %v", err)
+	}
+	if dec.SampleRate() != 44100 {
+		t.Fatalf("SampleRate() = %d, want 44100", dec.SampleRate())
+	}
+	got, err := io.ReadAll(dec)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if int64(len(got)) != dec.Length() {
+		t.Fatalf("read %d bytes, Length() = %d", len(got), dec.Length())
+	}
+	return got
+}
+
+func TestAIFFDecoderConvertsBigEndianSamples(t *testing.T) {
+	want := pcm16(0, 0x1234, -256, 32767, -32768)
+	tests := []struct {
+		name        string
+		compression string
+		bitDepth    int
+		data        []byte
+		want        []byte
+	}{
+		{"8-bit signed", "", 8, []byte{0x00, 0x12, 0xFF, 0x7F, 0x80}, pcm16(0, 0x1200, -256, 0x7F00, -32768)},
+		{"16-bit", "", 16, []byte{0, 0, 0x12, 0x34, 0xFF, 0x00, 0x7F, 0xFF, 0x80, 0x00}, want},
+		{"24-bit", "", 24, []byte{0, 0, 0x99, 0x12, 0x34, 0x56, 0xFF, 0x00, 0x01, 0x7F, 0xFF, 0xFF, 0x80, 0x00, 0x00}, want},
+		{"aifc none", "NONE", 16, []byte{0, 0, 0x12, 0x34, 0xFF, 0x00, 0x7F, 0xFF, 0x80, 0x00}, want},
+		{"aifc sowt", "sowt", 16, []byte{0, 0, 0x34, 0x12, 0x00, 0xFF, 0xFF, 0x7F, 0x00, 0x80}, want},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readAllAIFF(t, writeAIFF(t, tt.compression, 1, tt.bitDepth, tt.data)); !bytes.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAIFFDecoderSeeksBySourceFrame(t *testing.T) {
+	// Stereo 24-bit: frame i holds (i, -i) in the top 16 bits.
+	var data []byte
+	for i := range 100 {
+		for _, v := range []int16{int16(i), int16(-i)} {
+			data = append(data, byte(uint16(v)>>8), byte(v), 0x55)
+		}
+	}
+	dec, err := newNativeDecoder(writeAIFF(t, "", 2, 24, data))
+	if err != nil {
+		t.Fatalf("newNativeDecoder() error = %v", err)
+	}
+	if dec.Length() != 100*4 {
+		t.Fatalf("Length() = %d, want 400", dec.Length())
+	}
+	if pos, err := dec.Seek(42*4, io.SeekStart); err != nil || pos != 42*4 {
+		t.Fatalf("Seek() = %d, %v", pos, err)
+	}
+	got := make([]byte, 8)
+	if _, err := io.ReadFull(dec, got); err != nil {
+		t.Fatalf("ReadFull() error = %v", err)
+	}
+	if want := pcm16(42, -42, 43, -43); !bytes.Equal(got, want) {
+		t.Fatalf("after seek got %v, want %v", got, want)
+	}
+}
+
+func TestAIFFDecoderRejectsCompressedAIFC(t *testing.T) {
+	if _, err := newAIFFDecoder(writeAIFF(t, "ima4", 1, 16, make([]byte, 4))); err == nil {
+		t.Fatal("expected an error for compressed AIFF-C")
+	}
+}
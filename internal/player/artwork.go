@@ -0,0 +1,138 @@
+package player
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// pictureFrontCover is the front cover picture type shared by ID3 APIC
+	// frames and FLAC picture blocks.
+	pictureFrontCover = 3
+
+	// maxSidecarArtwork caps the size of a cover image read from disk; larger
+	// files are scans not worth decoding for a few terminal cells.
+	maxSidecarArtwork = 8 << 20
+)
+
+// sidecarArtworkNames are the cover files looked for next to a track without
+// embedded art, best first. "*" stands for the track's name without its extension.
+var sidecarArtworkNames = []string{
+	"*.jpg", "*.jpeg", "*.png",
+	"cover.jpg", "cover.jpeg", "cover.png",
+	"folder.jpg", "folder.jpeg", "folder.png",
+}
+
+// picture is one embedded image and its ID3/FLAC picture type.
+type picture struct {
+	kind uint32
+	data []byte
+}
+
+// pickArtwork returns the front cover, or the first picture when no picture is
+// marked as one.
+func pickArtwork(pics []picture) []byte {
+	for _, p := range pics {
+		if p.kind == pictureFrontCover && len(p.data) > 0 {
+			return p.data
+		}
+	}
+	for _, p := range pics {
+		if len(p.data) > 0 {
+			return p.data
+		}
+	}
+	return nil
+}
+
+// parsePictureBlock decodes the body of a FLAC METADATA_BLOCK_PICTURE: type,
+// MIME type, description, four dimension fields, then the image data. Pictures
+// given as a URL (MIME type "-->") are rejected.
+func parsePictureBlock(b []byte) (picture, bool) {
+	var p picture
+	field := func() (uint32, bool) {
+		if len(b) < 4 {
+			return 0, false
+		}
+		v := binary.BigEndian.Uint32(b)
+		b = b[4:]
+		return v, true
+	}
+	bytesField := func() ([]byte, bool) {
+		n, ok := field()
+		if !ok || uint64(n) > uint64(len(b)) {
+			return nil, false
+		}
+		v := b[:n]
+		b = b[n:]
+		return v, true
+	}
+
+	kind, ok := field()
+	if !ok {
+		return p, false
+	}
+	mime, ok := bytesField()
+	if !ok || string(mime) == "-->" {
+		return p, false
+	}
+	if _, ok := bytesField(); !ok {
+		return p, false
+	}
+	if len(b) < 16 {
+		return p, false
+	}
+	b = b[16:] // width, height, depth, palette size
+	data, ok := bytesField()
+	if !ok || len(data) == 0 {
+		return p, false
+	}
+	return picture{kind: kind, data: data}, true
+}
+
+// vorbisPicture decodes a METADATA_BLOCK_PICTURE Vorbis comment value, a
+// base64-encoded FLAC picture block.
+func vorbisPicture(value string) (picture, bool) {
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return picture{}, false
+	}
+	return parsePictureBlock(b)
+}
+
+// readSidecarArtwork returns the cover image next to path: <name>.jpg for the
+// track itself, else cover.jpg or folder.jpg for its directory, matched
+// case-insensitively. Files over maxSidecarArtwork are skipped.
+func readSidecarArtwork(path string) []byte {
+	dir := filepath.Dir(path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	files := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			files[strings.ToLower(e.Name())] = e.Name()
+		}
+	}
+	base := filepath.Base(path)
+	stem := strings.ToLower(strings.TrimSuffix(base, filepath.Ext(base)))
+	for _, name := range sidecarArtworkNames {
+		name = strings.Replace(name, "*", stem, 1)
+		actual, ok := files[name]
+		if !ok {
+			continue
+		}
+		full := filepath.Join(dir, actual)
+		if info, err := os.Stat(full); err != nil || info.Size() > maxSidecarArtwork {
+			continue
+		}
+		if data, err := os.ReadFile(full); err == nil && len(data) > 0 {
+			return data
+		}
+	}
+	return nil
+}
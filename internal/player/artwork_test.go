@@ -0,0 +1,91 @@
+package player
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func pictureBlock(kind uint32, mime string, data []byte) []byte {
+	var b bytes.Buffer
+	put := func(v uint32) { _ = binary.Write(&b, binary.BigEndian, v) }
+	put(kind)
+	put(uint32(len(mime)))
+	b.WriteString(mime)
+	put(5)
+	b.WriteString("cover")
+	put(300)
+	put(300)
+	put(24)
+	put(0)
+	put(uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func TestVorbisPictureDecodesBlock(t *testing.T) {
+	block := pictureBlock(pictureFrontCover, "image/png", []byte("PNGDATA"))
+	p, ok := vorbisPicture(base64.StdEncoding.EncodeToString(block))
+	if !ok || p.kind != pictureFrontCover || string(p.data) != "PNGDATA" {
+		t.Fatalf("vorbisPicture() = %+v, %v", p, ok)
+	}
+
+	if _, ok := parsePictureBlock(block[:len(block)-3]); ok {
+		t.Fatal("expected a truncated block to be rejected")
+	}
+	if _, ok := parsePictureBlock(pictureBlock(pictureFrontCover, "-->", []byte("https://x"))); ok {
+		t.Fatal("expected a URL picture to be rejected")
+	}
+	if _, ok := vorbisPicture("not base64!"); ok {
+		t.Fatal("expected invalid base64 to be rejected")
+	}
+}
+
+func TestPickArtworkPrefersFrontCover(t *testing.T) {
+	pics := []picture{{kind: 4, data: []byte("back")}, {kind: pictureFrontCover, data: []byte("front")}}
+	if got := string(pickArtwork(pics)); got != "front" {
+		t.Fatalf("pickArtwork() = %q, want front", got)
+	}
+	if got := string(pickArtwork(pics[:1])); got != "back" {
+		t.Fatalf("pickArtwork() = %q, want the only picture", got)
+	}
+	if pickArtwork(nil) != nil {
+		t.Fatal("expected no artwork without pictures")
+	}
+}
+
+func TestReadSidecarArtworkPrefersTrackImageAndIgnoresCase(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	track := filepath.Join(dir, "01 Song.flac")
+	if got := readSidecarArtwork(track); got != nil {
+		t.Fatalf("readSidecarArtwork() = %q, want nil without cover files", got)
+	}
+
+	write("Folder.JPG", "folder")
+	if got := string(readSidecarArtwork(track)); got != "folder" {
+		t.Fatalf("readSidecarArtwork() = %q, want Folder.JPG", got)
+	}
+	write("cover.png", "cover")
+	if got := string(readSidecarArtwork(track)); got != "cover" {
+		t.Fatalf("readSidecarArtwork() = %q, want cover.png over folder.jpg", got)
+	}
+	write("01 song.JPG", "track")
+	if got := string(readSidecarArtwork(track)); got != "track" {
+		t.Fatalf("readSidecarArtwork() = %q, want the track's own image", got)
+	}
+
+	write("01 song.JPG", strings.Repeat("x", maxSidecarArtwork+1))
+	if got := string(readSidecarArtwork(track)); got != "cover" {
+		t.Fatalf("readSidecarArtwork() = %.10q, want an oversized image skipped", got)
+	}
+}
@@ -0,0 +1,106 @@
+package player
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// MaxBoost is the highest gain SetBoost accepts (300%).
+const MaxBoost = 3.0
+
+// limiterKnee is the level, as a fraction of full scale, above which boosted
+// samples are compressed instead of passed through linearly.
+const limiterKnee = 0.6
+
+// softLimiter is the last stage before oto. It multiplies s16le samples by
+// the boost factor and bends anything above limiterKnee along a tanh curve
+// so loud peaks approach full scale instead of clipping. At a factor of 1
+// reads pass straight through.
+type softLimiter struct {
+	source    io.Reader
+	frameSize int
+	factor    atomic.Uint64 // math.Float64bits of the boost factor
+	dropPend  atomic.Bool   // discard pending before the next read (after a seek)
+	pending   []byte        // partial frame carried over from the previous read
+}
+
+func newSoftLimiter(source io.Reader, frameSize int) *softLimiter {
+	sl := &softLimiter{source: source, frameSize: frameSize}
+	sl.setFactor(1)
+	return sl
+}
+
+func (sl *softLimiter) setFactor(f float64) {
+	sl.factor.Store(math.Float64bits(f))
+}
+
+// reset drops any carried partial frame; called when the stream is repositioned.
+func (sl *softLimiter) reset() {
+	sl.dropPend.Store(true)
+}
+
+func (sl *softLimiter) Read(p []byte) (int, error) {
+	if sl.dropPend.Swap(false) {
+		sl.pending = sl.pending[:0]
+	}
+	factor := math.Float64frombits(sl.factor.Load())
+	if factor == 1 && len(sl.pending) == 0 {
+		return sl.source.Read(p)
+	}
+
+	fs := sl.frameSize
+	if len(p) < fs {
+		if n := copy(p, sl.pending); n > 0 {
+			sl.pending = sl.pending[n:]
+			return n, nil
+		}
+		return sl.source.Read(p)
+	}
+
+	for {
+		carried := copy(p, sl.pending)
+		sl.pending = sl.pending[:0]
+		n, err := sl.source.Read(p[carried:])
+		total := carried + n
+
+		aligned := total - total%fs
+		if aligned < total {
+			sl.pending = append(sl.pending, p[aligned:total]...)
+		}
+		if aligned > 0 {
+			limitSamples(p[:aligned], factor)
+			return aligned, nil
+		}
+		if err != nil {
+			if len(sl.pending) > 0 {
+				n := copy(p, sl.pending)
+				sl.pending = sl.pending[:0]
+				return n, err
+			}
+			return 0, err
+		}
+	}
+}
+
+// limitSamples applies gain and the soft limiter to s16le samples in place.
+func limitSamples(buf []byte, factor float64) {
+	for off := 0; off+2 <= len(buf); off += 2 {
+		s := int16(binary.LittleEndian.Uint16(buf[off:]))
+		v := softLimit(float64(s) / 32768 * factor)
+		v = min(max(math.Round(v*32768), -32768), 32767)
+		binary.LittleEndian.PutUint16(buf[off:], uint16(int16(v)))
+	}
+}
+
+// softLimit is linear up to limiterKnee and then follows a tanh curve that
+// meets it with the same slope and levels off at full scale.
+func softLimit(v float64) float64 {
+	a := math.Abs(v)
+	if a <= limiterKnee {
+		return v
+	}
+	a = limiterKnee + (1-limiterKnee)*math.Tanh((a-limiterKnee)/(1-limiterKnee))
+	return math.Copysign(a, v)
+}
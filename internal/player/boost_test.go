@@ -0,0 +1,71 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSoftLimiterBypassesAtUnity(t *testing.T) {
+	src := pcm16(100, -300, 32767, -32768)
+	got, err := io.ReadAll(newSoftLimiter(bytes.NewReader(src), 4))
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if !bytes.Equal(got, src) {
+		t.Fatalf("got %v, want %v", got, src)
+	}
+}
+
+func TestSoftLimiterBoostsQuietAndLimitsLoud(t *testing.T) {
+	sl := newSoftLimiter(bytes.NewReader(pcm16(1000, -1000, 12000, -12000)), 4)
+	sl.setFactor(3)
+	got, err := io.ReadAll(sl)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := pcm16(3000, -3000); !bytes.Equal(got[:4], want) {
+		t.Fatalf("quiet samples: got %v, want %v", got[:4], want)
+	}
+	loud := int16(uint16(got[4]) | uint16(got[5])<<8)
+	neg := int16(uint16(got[6]) | uint16(got[7])<<8)
+	if loud <= 12000 || loud >= 32000 || neg != -loud {
+		t.Fatalf("expected loud samples limited below full scale, got %d / %d", loud, neg)
+	}
+}
+
+func TestSoftLimiterCarriesPartialFrames(t *testing.T) {
+	sl := newSoftLimiter(iotest.OneByteReader(bytes.NewReader(pcm16(1, 2, 3, 4))), 4)
+	sl.setFactor(2)
+	got, err := io.ReadAll(sl)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := pcm16(2, 4, 6, 8); !bytes.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestAdjustVolumeBoostsPastFullVolume(t *testing.T) {
+	p := &Player{volume: 0.95, boost: 1}
+
+	p.AdjustVolume(0.1)
+	if p.Volume() != 1 {
+		t.Fatalf("expected volume capped at 1, got %v", p.Volume())
+	}
+	if got := p.Boost(); math.Abs(got-1.05) > 1e-9 {
+		t.Fatalf("expected boost 1.05, got %v", got)
+	}
+
+	p.AdjustVolume(5)
+	if p.Boost() != MaxBoost {
+		t.Fatalf("expected boost capped at %v, got %v", MaxBoost, p.Boost())
+	}
+
+	p.AdjustVolume(-2.5)
+	if p.Boost() != 1 || math.Abs(p.Volume()-0.5) > 1e-9 {
+		t.Fatalf("expected boost 1 and volume 0.5, got boost=%v volume=%v", p.Boost(), p.Volume())
+	}
+}
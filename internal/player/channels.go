@@ -0,0 +1,178 @@
+package player
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// ChannelMode selects how the two stereo channels reach the output.
+type ChannelMode int
+
+const (
+	ChannelStereo ChannelMode = iota
+	ChannelMono               // average of left and right in both channels
+	ChannelLeft               // left channel in both channels
+	ChannelRight              // right channel in both channels
+	ChannelSwap               // left and right exchanged
+)
+
+// Next cycles to the next mode: stereo → mono → left → right → swap → stereo.
+func (c ChannelMode) Next() ChannelMode {
+	if c >= ChannelSwap {
+		return ChannelStereo
+	}
+	return c + 1
+}
+
+// Label returns a display label for the channel mode.
+func (c ChannelMode) Label() string {
+	switch c {
+	case ChannelMono:
+		return "[mono]"
+	case ChannelLeft:
+		return "[left]"
+	case ChannelRight:
+		return "[right]"
+	case ChannelSwap:
+		return "[swap l/r]"
+	default:
+		return ""
+	}
+}
+
+// MaxStereoWidth is the widest stereo image SetStereoWidth accepts.
+const MaxStereoWidth = 2.0
+
+// StereoWidths are the width presets the UI steps through: normal first,
+// then wider, widest, mono, and narrow.
+var StereoWidths = []float64{1, 1.5, MaxStereoWidth, 0, 0.5}
+
+// StereoWidthLabel returns a display label for a stereo width, or "" at
+// normal width.
+func StereoWidthLabel(w float64) string {
+	if w == 1 {
+		return ""
+	}
+	return fmt.Sprintf("[width %d%%]", int(math.Round(w*100)))
+}
+
+// channelMixer sits between speedReader and the Equalizer and rewrites each
+// stereo frame according to the channel mode and stereo width. In stereo
+// mode at normal width, or for sources that are not stereo, reads pass
+// straight through.
+type channelMixer struct {
+	source   io.Reader
+	channels int
+	mode     atomic.Int32
+	width    atomic.Uint64 // math.Float64bits of the stereo width
+	dropPend atomic.Bool   // discard pending before the next read (after a seek)
+	pending  []byte        // partial frame carried over from the previous read
+}
+
+func newChannelMixer(source io.Reader, channels int) *channelMixer {
+	cm := &channelMixer{source: source, channels: channels}
+	cm.setWidth(1)
+	return cm
+}
+
+func (cm *channelMixer) setMode(mode ChannelMode) {
+	cm.mode.Store(int32(mode))
+}
+
+func (cm *channelMixer) setWidth(w float64) {
+	cm.width.Store(math.Float64bits(w))
+}
+
+// reset drops any carried partial frame; called when the stream is repositioned.
+func (cm *channelMixer) reset() {
+	cm.dropPend.Store(true)
+}
+
+func (cm *channelMixer) Read(p []byte) (int, error) {
+	if cm.dropPend.Swap(false) {
+		cm.pending = cm.pending[:0]
+	}
+	mode := ChannelMode(cm.mode.Load())
+	width := math.Float64frombits(cm.width.Load())
+	if (mode == ChannelStereo && width == 1 || cm.channels != 2) && len(cm.pending) == 0 {
+		return cm.source.Read(p)
+	}
+
+	const fs = 4 // 16-bit stereo
+	if len(p) < fs {
+		if n := copy(p, cm.pending); n > 0 {
+			cm.pending = cm.pending[n:]
+			return n, nil
+		}
+		return cm.source.Read(p)
+	}
+
+	for {
+		carried := copy(p, cm.pending)
+		cm.pending = cm.pending[:0]
+		n, err := cm.source.Read(p[carried:])
+		total := carried + n
+
+		aligned := total - total%fs
+		if aligned < total {
+			cm.pending = append(cm.pending, p[aligned:total]...)
+		}
+		if aligned > 0 {
+			mixChannels(p[:aligned], mode, width)
+			return aligned, nil
+		}
+		if err != nil {
+			if len(cm.pending) > 0 {
+				n := copy(p, cm.pending)
+				cm.pending = cm.pending[:0]
+				return n, err
+			}
+			return 0, err
+		}
+	}
+}
+
+// mixChannels rewrites whole s16le stereo frames in place. Outside the
+// single-channel modes, the side signal (L-R)/2 is scaled by width around
+// the mid (L+R)/2; a widened image is soft-limited so it cannot clip.
+func mixChannels(buf []byte, mode ChannelMode, width float64) {
+	spread := width != 1 && (mode == ChannelStereo || mode == ChannelSwap)
+	for off := 0; off+4 <= len(buf); off += 4 {
+		l := int16(binary.LittleEndian.Uint16(buf[off:]))
+		r := int16(binary.LittleEndian.Uint16(buf[off+2:]))
+		switch mode {
+		case ChannelMono:
+			m := int16((int32(l) + int32(r)) / 2)
+			l, r = m, m
+		case ChannelLeft:
+			r = l
+		case ChannelRight:
+			l = r
+		case ChannelSwap:
+			l, r = r, l
+		}
+		if spread {
+			l, r = widenFrame(l, r, width)
+		}
+		binary.LittleEndian.PutUint16(buf[off:], uint16(l))
+		binary.LittleEndian.PutUint16(buf[off+2:], uint16(r))
+	}
+}
+
+// widenFrame scales the side signal of one stereo frame by width.
+func widenFrame(l, r int16, width float64) (int16, int16) {
+	lf, rf := float64(l)/32768, float64(r)/32768
+	mid, side := (lf+rf)/2, (lf-rf)/2*width
+	lf, rf = mid+side, mid-side
+	if width > 1 {
+		lf, rf = softLimit(lf), softLimit(rf)
+	}
+	return toSample(lf), toSample(rf)
+}
+
+func toSample(v float64) int16 {
+	return int16(min(max(math.Round(v*32768), -32768), 32767))
+}
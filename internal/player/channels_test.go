@@ -0,0 +1,94 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestChannelMixerModes(t *testing.T) {
+	src := pcm16(100, -300, 2000, 0)
+	tests := []struct {
+		mode ChannelMode
+		want []byte
+	}{
+		{ChannelStereo, pcm16(100, -300, 2000, 0)},
+		{ChannelMono, pcm16(-100, -100, 1000, 1000)},
+		{ChannelLeft, pcm16(100, 100, 2000, 2000)},
+		{ChannelRight, pcm16(-300, -300, 0, 0)},
+		{ChannelSwap, pcm16(-300, 100, 0, 2000)},
+	}
+	for _, tt := range tests {
+		cm := newChannelMixer(bytes.NewReader(src), 2)
+		cm.setMode(tt.mode)
+		got, err := io.ReadAll(cm)
+		if err != nil {
+			t.Fatalf("%v: ReadAll() error = %v", tt.mode, err)
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("mode %d: got %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestChannelMixerCarriesPartialFrames(t *testing.T) {
+	cm := newChannelMixer(io.MultiReader(
+		bytes.NewReader(pcm16(1, 2)[:3]),
+		bytes.NewReader(pcm16(1, 2, 3, 4)[3:]),
+	), 2)
+	cm.setMode(ChannelSwap)
+	got, err := io.ReadAll(cm)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := pcm16(2, 1, 4, 3); !bytes.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestChannelModeCycle(t *testing.T) {
+	mode := ChannelStereo
+	for range 5 {
+		mode = mode.Next()
+	}
+	if mode != ChannelStereo {
+		t.Fatalf("expected five steps to return to stereo, got %d", mode)
+	}
+	if ChannelStereo.Label() != "" || ChannelMono.Label() != "[mono]" {
+		t.Fatal("unexpected channel mode labels")
+	}
+}
+
+func TestChannelMixerStereoWidth(t *testing.T) {
+	src := pcm16(1000, -1000, 4000, 2000)
+	tests := []struct {
+		width float64
+		want  []byte
+	}{
+		{1, pcm16(1000, -1000, 4000, 2000)},
+		{0, pcm16(0, 0, 3000, 3000)},
+		{0.5, pcm16(500, -500, 3500, 2500)},
+		{1.5, pcm16(1500, -1500, 4500, 1500)},
+	}
+	for _, tt := range tests {
+		cm := newChannelMixer(bytes.NewReader(src), 2)
+		cm.setWidth(tt.width)
+		got, err := io.ReadAll(cm)
+		if err != nil {
+			t.Fatalf("width %v: ReadAll() error = %v", tt.width, err)
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("width %v: got %v, want %v", tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestStereoWidthSoftLimitsWidenedPeaks(t *testing.T) {
+	l, r := widenFrame(30000, -30000, MaxStereoWidth)
+	if l <= 30000 || l == 32767 || r >= -30000 || r == -32768 {
+		t.Fatalf("widenFrame() = %d, %d, want louder peaks bent below full scale", l, r)
+	}
+	if StereoWidths[0] != 1 || StereoWidthLabel(1) != "" || StereoWidthLabel(1.5) != "[width 150%]" {
+		t.Fatal("unexpected stereo width presets or labels")
+	}
+}
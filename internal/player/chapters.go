@@ -0,0 +1,306 @@
+package player
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Chapter is a named start position within a track.
+type Chapter struct {
+	Title string
+	Start time.Duration
+}
+
+const (
+	// maxChapterBoxSize caps how much of one sample table is read into memory.
+	maxChapterBoxSize = 4 << 20
+	maxChapterTitle   = 1024
+)
+
+// readChapters reads chapter markers from MP4-family files. The Nero chpl box
+// (moov/udta/chpl) is preferred; otherwise the QuickTime text track that an
+// audio track references through tref/chap is used. Other formats and files
+// without chapters return nil.
+func readChapters(path, ext string) []Chapter {
+	switch ext {
+	case ".m4a", ".m4b", ".mp4":
+	default:
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	return readMP4Chapters(f)
+}
+
+func readMP4Chapters(r io.ReadSeeker) []Chapter {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil
+	}
+	moovStart, moovEnd, ok := findMP4Box(r, 0, end, "moov")
+	if !ok {
+		return nil
+	}
+	if udtaStart, udtaEnd, ok := findMP4Box(r, moovStart, moovEnd, "udta"); ok {
+		if body, ok := readMP4Body(r, udtaStart, udtaEnd, "chpl"); ok {
+			if chapters := parseChpl(body); len(chapters) > 0 {
+				return chapters
+			}
+		}
+	}
+	return readChapterTrack(r, moovStart, moovEnd)
+}
+
+// parseChpl decodes a Nero chapter list: version+flags, a reserved word in
+// version 1, a chapter count, then per chapter a start time in 100ns units
+// and a length-prefixed title.
+func parseChpl(b []byte) []Chapter {
+	if len(b) < 5 {
+		return nil
+	}
+	p := 4
+	if b[0] > 0 {
+		p += 4
+	}
+	if p >= len(b) {
+		return nil
+	}
+	n := int(b[p])
+	p++
+	var chapters []Chapter
+	for i := 0; i < n && p+9 <= len(b); i++ {
+		start := binary.BigEndian.Uint64(b[p:])
+		size := int(b[p+8])
+		p += 9
+		if p+size > len(b) {
+			break
+		}
+		chapters = append(chapters, Chapter{
+			Title: strings.TrimSpace(string(b[p : p+size])),
+			Start: time.Duration(start) * 100,
+		})
+		p += size
+	}
+	return chapters
+}
+
+// readChapterTrack finds the track named by a tref/chap reference and turns
+// each of its text samples into a chapter.
+func readChapterTrack(r io.ReadSeeker, start, end int64) []Chapter {
+	traks := map[uint32][2]int64{}
+	var chapID uint32
+	for pos := start; pos+8 <= end; {
+		typ, body, boxEnd, ok := readMP4BoxHeader(r, pos, end)
+		if !ok {
+			break
+		}
+		if typ == "trak" {
+			if tkhd, ok := readMP4Body(r, body, boxEnd, "tkhd"); ok {
+				if id, ok := beUint32At(tkhd, fullBoxOffset(tkhd, 12, 20)); ok {
+					traks[id] = [2]int64{body, boxEnd}
+				}
+			}
+			if trefStart, trefEnd, ok := findMP4Box(r, body, boxEnd, "tref"); ok && chapID == 0 {
+				if chap, ok := readMP4Body(r, trefStart, trefEnd, "chap"); ok {
+					chapID, _ = beUint32At(chap, 0)
+				}
+			}
+		}
+		pos = boxEnd
+	}
+	trak, ok := traks[chapID]
+	if chapID == 0 || !ok {
+		return nil
+	}
+
+	mdiaStart, mdiaEnd, ok := findMP4Box(r, trak[0], trak[1], "mdia")
+	if !ok {
+		return nil
+	}
+	mdhd, ok := readMP4Body(r, mdiaStart, mdiaEnd, "mdhd")
+	if !ok {
+		return nil
+	}
+	timescale, ok := beUint32At(mdhd, fullBoxOffset(mdhd, 12, 20))
+	if !ok || timescale == 0 {
+		return nil
+	}
+	minfStart, minfEnd, ok := findMP4Box(r, mdiaStart, mdiaEnd, "minf")
+	if !ok {
+		return nil
+	}
+	stblStart, stblEnd, ok := findMP4Box(r, minfStart, minfEnd, "stbl")
+	if !ok {
+		return nil
+	}
+
+	durations := readSampleDurations(r, stblStart, stblEnd)
+	offsets, sizes := readSampleLocations(r, stblStart, stblEnd)
+	n := min(len(durations), len(offsets))
+
+	chapters := make([]Chapter, 0, n)
+	var t uint64
+	for i := 0; i < n; i++ {
+		chapters = append(chapters, Chapter{
+			Title: readChapterText(r, offsets[i], sizes[i]),
+			Start: time.Duration(t * uint64(time.Second) / uint64(timescale)),
+		})
+		t += uint64(durations[i])
+	}
+	return chapters
+}
+
+// readSampleDurations expands the stts run-length table into per-sample
+// durations in track timescale units.
+func readSampleDurations(r io.ReadSeeker, start, end int64) []uint32 {
+	stts, ok := readMP4Body(r, start, end, "stts")
+	if !ok {
+		return nil
+	}
+	count, _ := beUint32At(stts, 4)
+	var durations []uint32
+	for i := 0; i < int(count); i++ {
+		n, ok1 := beUint32At(stts, 8+i*8)
+		delta, ok2 := beUint32At(stts, 12+i*8)
+		if !ok1 || !ok2 || len(durations)+int(n) > maxChapterBoxSize/4 {
+			break
+		}
+		for j := uint32(0); j < n; j++ {
+			durations = append(durations, delta)
+		}
+	}
+	return durations
+}
+
+// readSampleLocations resolves each sample's file offset and size from the
+// stsz, stsc, and stco/co64 tables.
+func readSampleLocations(r io.ReadSeeker, start, end int64) ([]int64, []uint32) {
+	stsz, ok := readMP4Body(r, start, end, "stsz")
+	if !ok {
+		return nil, nil
+	}
+	fixed, _ := beUint32At(stsz, 4)
+	count, _ := beUint32At(stsz, 8)
+	if int(count) > maxChapterBoxSize/4 {
+		return nil, nil
+	}
+	sizes := make([]uint32, count)
+	for i := range sizes {
+		sizes[i] = fixed
+		if fixed == 0 {
+			sizes[i], _ = beUint32At(stsz, 12+i*4)
+		}
+	}
+
+	var chunks []int64
+	if stco, ok := readMP4Body(r, start, end, "stco"); ok {
+		n, _ := beUint32At(stco, 4)
+		for i := 0; i < int(n) && 8+i*4+4 <= len(stco); i++ {
+			off, _ := beUint32At(stco, 8+i*4)
+			chunks = append(chunks, int64(off))
+		}
+	} else if co64, ok := readMP4Body(r, start, end, "co64"); ok {
+		n, _ := beUint32At(co64, 4)
+		for i := 0; i < int(n) && 8+i*8+8 <= len(co64); i++ {
+			chunks = append(chunks, int64(binary.BigEndian.Uint64(co64[8+i*8:])))
+		}
+	}
+
+	stsc, ok := readMP4Body(r, start, end, "stsc")
+	if !ok {
+		return nil, nil
+	}
+	entries, _ := beUint32At(stsc, 4)
+	offsets := make([]int64, 0, len(sizes))
+	for e := 0; e < int(entries); e++ {
+		first, ok1 := beUint32At(stsc, 8+e*12)
+		perChunk, ok2 := beUint32At(stsc, 12+e*12)
+		if !ok1 || !ok2 || first == 0 {
+			break
+		}
+		last := uint32(len(chunks))
+		if next, ok := beUint32At(stsc, 8+(e+1)*12); ok && e+1 < int(entries) {
+			last = next - 1
+		}
+		for c := first; c <= last && int(c) <= len(chunks); c++ {
+			off := chunks[c-1]
+			for s := uint32(0); s < perChunk && len(offsets) < len(sizes); s++ {
+				offsets = append(offsets, off)
+				off += int64(sizes[len(offsets)-1])
+			}
+		}
+	}
+	return offsets, sizes[:len(offsets)]
+}
+
+// readChapterText reads a QuickTime text sample: a 16-bit length followed by
+// the title.
+func readChapterText(r io.ReadSeeker, off int64, size uint32) string {
+	if size < 2 {
+		return ""
+	}
+	buf := make([]byte, min(size, maxChapterTitle+2))
+	if _, err := r.Seek(off, io.SeekStart); err != nil {
+		return ""
+	}
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return ""
+	}
+	n := min(int(binary.BigEndian.Uint16(buf)), len(buf)-2)
+	return strings.TrimSpace(string(buf[2 : 2+n]))
+}
+
+// findMP4Box returns the body range of the first box of type typ between
+// start and end.
+func findMP4Box(r io.ReadSeeker, start, end int64, typ string) (int64, int64, bool) {
+	for pos := start; pos+8 <= end; {
+		t, body, boxEnd, ok := readMP4BoxHeader(r, pos, end)
+		if !ok {
+			return 0, 0, false
+		}
+		if t == typ {
+			return body, boxEnd, true
+		}
+		pos = boxEnd
+	}
+	return 0, 0, false
+}
+
+// readMP4Body reads the body of the first box of type typ between start and
+// end.
+func readMP4Body(r io.ReadSeeker, start, end int64, typ string) ([]byte, bool) {
+	body, boxEnd, ok := findMP4Box(r, start, end, typ)
+	if !ok || boxEnd-body > maxChapterBoxSize {
+		return nil, false
+	}
+	buf := make([]byte, boxEnd-body)
+	if _, err := r.Seek(body, io.SeekStart); err != nil {
+		return nil, false
+	}
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, false
+	}
+	return buf, true
+}
+
+// fullBoxOffset picks a field offset by full box version (0 uses 32-bit
+// times, 1 uses 64-bit times).
+func fullBoxOffset(b []byte, v0, v1 int) int {
+	if len(b) > 0 && b[0] == 1 {
+		return v1
+	}
+	return v0
+}
+
+func beUint32At(b []byte, off int) (uint32, bool) {
+	if off < 0 || off+4 > len(b) {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(b[off:]), true
+}
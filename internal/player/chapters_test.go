@@ -0,0 +1,101 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func mp4TestBox(typ string, body ...[]byte) []byte {
+	payload := bytes.Join(body, nil)
+	out := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(out, uint32(8+len(payload)))
+	copy(out[4:], typ)
+	return append(out, payload...)
+}
+
+func be32(vs ...uint32) []byte {
+	out := make([]byte, 0, 4*len(vs))
+	for _, v := range vs {
+		out = binary.BigEndian.AppendUint32(out, v)
+	}
+	return out
+}
+
+func TestReadMP4ChaptersFromChpl(t *testing.T) {
+	chpl := []byte{1, 0, 0, 0, 0, 0, 0, 0, 2}
+	for _, c := range []struct {
+		start uint64
+		title string
+	}{{0, "Opening"}, {90 * 10_000_000, "Part Two"}} {
+		chpl = binary.BigEndian.AppendUint64(chpl, c.start)
+		chpl = append(chpl, byte(len(c.title)))
+		chpl = append(chpl, c.title...)
+	}
+	file := bytes.Join([][]byte{
+		mp4TestBox("ftyp", []byte("M4B ")),
+		mp4TestBox("moov", mp4TestBox("udta", mp4TestBox("chpl", chpl))),
+	}, nil)
+
+	got := readMP4Chapters(bytes.NewReader(file))
+	want := []Chapter{{"Opening", 0}, {"Part Two", 90 * time.Second}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d chapters, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("chapter %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadMP4ChaptersFromTextTrack(t *testing.T) {
+	text := func(s string) []byte {
+		return append(binary.BigEndian.AppendUint16(nil, uint16(len(s))), s...)
+	}
+	samples := [][]byte{text("Intro"), text("Chapter 1")}
+	mdat := mp4TestBox("mdat", samples...)
+	// ftyp (12 bytes) precedes mdat; samples start after mdat's header.
+	first := uint32(12 + 8)
+
+	audio := mp4TestBox("trak",
+		mp4TestBox("tkhd", be32(0, 0, 0, 1)),
+		mp4TestBox("tref", mp4TestBox("chap", be32(2))),
+	)
+	chapterTrak := mp4TestBox("trak",
+		mp4TestBox("tkhd", be32(0, 0, 0, 2)),
+		mp4TestBox("mdia",
+			mp4TestBox("mdhd", be32(0, 0, 0, 1000)),
+			mp4TestBox("minf", mp4TestBox("stbl",
+				mp4TestBox("stts", be32(0, 2, 1, 30000, 1, 60000)),
+				mp4TestBox("stsz", be32(0, 0, 2, uint32(len(samples[0])), uint32(len(samples[1])))),
+				mp4TestBox("stsc", be32(0, 1, 1, 2, 1)),
+				mp4TestBox("stco", be32(0, 1, first)),
+			)),
+		),
+	)
+	file := bytes.Join([][]byte{
+		mp4TestBox("ftyp", []byte("M4B ")),
+		mdat,
+		mp4TestBox("moov", audio, chapterTrak),
+	}, nil)
+
+	got := readMP4Chapters(bytes.NewReader(file))
+	want := []Chapter{{"Intro", 0}, {"Chapter 1", 30 * time.Second}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d chapters, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("chapter %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadMP4ChaptersWithoutChapters(t *testing.T) {
+	file := mp4TestBox("moov", mp4TestBox("trak", mp4TestBox("tkhd", be32(0, 0, 0, 1))))
+	if got := readMP4Chapters(bytes.NewReader(file)); got != nil {
+		t.Fatalf("expected no chapters, got %+v", got)
+	}
+}
@@ -0,0 +1,153 @@
+package player
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// crossfadeSteps are the lengths NextCrossfade cycles through after off.
+var crossfadeSteps = []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
+
+// NextCrossfade cycles the crossfade length: off → 2s → 4s → 8s → off.
+func NextCrossfade(d time.Duration) time.Duration {
+	for _, step := range crossfadeSteps {
+		if step > d {
+			return step
+		}
+	}
+	return 0
+}
+
+// CrossfadeLabel returns a display label for the crossfade length.
+func CrossfadeLabel(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("[xfade %ds]", int(d.Seconds()))
+}
+
+// lengther is implemented by decoders that know their total PCM length.
+type lengther interface {
+	Length() int64
+}
+
+// setFadeBytes sets the crossfade length in bytes; 0 keeps gapless cuts.
+func (cr *countingReader) setFadeBytes(n int64) {
+	cr.mu.Lock()
+	cr.fadeBytes = n
+	cr.mu.Unlock()
+}
+
+// rewindNextLocked restarts a partially mixed next reader, e.g. after seeking
+// back out of the crossfade region.
+func (cr *countingReader) rewindNextLocked() {
+	if cr.nextBusy {
+		cr.rewindDue = true
+		return
+	}
+	if cr.next == nil || cr.nextPos == 0 {
+		return
+	}
+	if _, err := cr.next.Seek(0, io.SeekStart); err == nil {
+		cr.nextPos = 0
+	}
+}
+
+// readCrossfade fills p with the current reader's tail mixed into the next
+// reader's head when playback is inside the crossfade region. The current
+// track fades out linearly while the next fades in. fading is false when no
+// crossfade applies and p was left untouched.
+//
+// The next reader is marked busy while it is read without mu held, so
+// clearNext cannot hand it back to be closed mid-read.
+func (cr *countingReader) readCrossfade(p []byte) (n int, err error, fading bool) {
+	cr.mu.Lock()
+	next := cr.next
+	fadeBytes := cr.fadeBytes
+	pos := cr.pos
+	l, ok := cr.reader.(lengther)
+	if next == nil || fadeBytes <= 0 || !ok {
+		cr.mu.Unlock()
+		return 0, nil, false
+	}
+	total := l.Length()
+	// Stay on whole frames so both streams remain aligned.
+	size := len(p) - len(p)%playbackFrameSize
+	if total <= 0 || total-pos > fadeBytes || size == 0 {
+		cr.mu.Unlock()
+		return 0, nil, false
+	}
+	cr.nextBusy = true
+	cr.mu.Unlock()
+
+	n, err = cr.reader.Read(p[:size])
+	n -= n % playbackFrameSize
+	if n == 0 {
+		cr.finishNextRead(next, 0)
+		return 0, err, true
+	}
+
+	if cap(cr.fadeBuf) < n {
+		cr.fadeBuf = make([]byte, n)
+	}
+	in := cr.fadeBuf[:n]
+	m, _ := io.ReadFull(next, in)
+	clear(in[m:])
+	cr.finishNextRead(next, m)
+
+	for i := 0; i+playbackFrameSize <= n; i += playbackFrameSize {
+		out := float64(total-pos-int64(i)) / float64(fadeBytes)
+		out = min(max(out, 0), 1)
+		for j := i; j < i+playbackFrameSize; j += 2 {
+			a := float64(int16(binary.LittleEndian.Uint16(p[j:])))
+			b := float64(int16(binary.LittleEndian.Uint16(in[j:])))
+			v := min(max(a*out+b*(1-out), -32768), 32767)
+			binary.LittleEndian.PutUint16(p[j:], uint16(int16(v)))
+		}
+	}
+	return n, err, true
+}
+
+// finishNextRead records the m bytes readCrossfade took from next and
+// releases it, applying a rewind requested by a seek in the meantime.
+func (cr *countingReader) finishNextRead(next io.ReadSeeker, m int) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.nextBusy = false
+	rewind := cr.rewindDue
+	cr.rewindDue = false
+	if cr.next != next {
+		return
+	}
+	cr.nextPos += int64(m)
+	if rewind {
+		if _, err := next.Seek(0, io.SeekStart); err == nil {
+			cr.nextPos = 0
+		}
+	}
+}
+
+// SetCrossfade sets how long the end of each track overlaps the start of the
+// preloaded next track. 0 disables crossfading (plain gapless playback).
+// Live streams never crossfade.
+func (p *Player) SetCrossfade(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.canSeek || d < 0 {
+		d = 0
+	}
+	p.crossfade = d
+	if p.counter != nil {
+		n := int64(d.Seconds() * float64(p.bytesPerSec))
+		p.counter.setFadeBytes(n - n%playbackFrameSize)
+	}
+}
+
+// Crossfade returns the current crossfade length.
+func (p *Player) Crossfade() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.crossfade
+}
@@ -0,0 +1,161 @@
+package player
+
+import (
+	"io"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCountingReaderCrossfadesIntoNextReader(t *testing.T) {
+	counter := &countingReader{
+		reader:    &stubPCMDecoder{data: pcm16(1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000), sampleRate: playbackSampleRate, channels: 2},
+		fadeBytes: 16,
+	}
+	next := &stubPCMDecoder{data: pcm16(0, 0, 0, 0, 0, 0, 0, 0, 7, 7, 7, 7), sampleRate: playbackSampleRate, channels: 2}
+	counter.setNext(next)
+
+	var out []byte
+	buf := make([]byte, 16)
+	for {
+		n, err := counter.Read(buf)
+		out = append(out, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+	}
+
+	want := pcm16(
+		1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000,
+		1000, 1000, 750, 750, 500, 500, 250, 250,
+		7, 7, 7, 7,
+	)
+	if string(out) != string(want) {
+		t.Fatalf("crossfaded PCM mismatch:\n got %v\nwant %v", out, want)
+	}
+	if !counter.takeSwitch() {
+		t.Fatal("expected switch to the next reader")
+	}
+	if got := counter.Pos(); got != int64(len(next.data)) {
+		t.Fatalf("expected position to include the faded-in head, got %d", got)
+	}
+}
+
+func TestCountingReaderSeekBackRewindsPartiallyMixedNext(t *testing.T) {
+	counter := &countingReader{
+		reader:    &stubPCMDecoder{data: make([]byte, 32), sampleRate: playbackSampleRate, channels: 2},
+		fadeBytes: 16,
+		pos:       16,
+	}
+	next := &stubPCMDecoder{data: make([]byte, 32), sampleRate: playbackSampleRate, channels: 2}
+	counter.reader.Seek(16, io.SeekStart)
+	counter.setNext(next)
+
+	if _, err := counter.Read(make([]byte, 8)); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if counter.clearNext() {
+		t.Fatal("expected next reader to stay queued once the crossfade started")
+	}
+
+	counter.SetPos(0)
+	if next.pos != 0 || counter.nextPos != 0 {
+		t.Fatalf("expected next reader rewound after seeking back, got pos=%d nextPos=%d", next.pos, counter.nextPos)
+	}
+}
+
+func TestSetCrossfadeIgnoredForLiveStreams(t *testing.T) {
+	live := &Player{counter: &countingReader{}, bytesPerSec: playbackSampleRate * playbackFrameSize}
+	live.SetCrossfade(4 * time.Second)
+	if live.Crossfade() != 0 || live.counter.fadeBytes != 0 {
+		t.Fatal("expected crossfade to stay off for live streams")
+	}
+
+	p := &Player{counter: &countingReader{}, bytesPerSec: playbackSampleRate * playbackFrameSize, canSeek: true}
+	p.SetCrossfade(2 * time.Second)
+	if got := p.counter.fadeBytes; got != 2*playbackSampleRate*playbackFrameSize {
+		t.Fatalf("expected 2s of fade bytes, got %d", got)
+	}
+}
+
+func TestNextCrossfadeCyclesSteps(t *testing.T) {
+	d := time.Duration(0)
+	var labels []string
+	for range 4 {
+		d = NextCrossfade(d)
+		labels = append(labels, CrossfadeLabel(d))
+	}
+	want := []string{"[xfade 2s]", "[xfade 4s]", "[xfade 8s]", ""}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Fatalf("step %d label = %q, want %q", i, labels[i], want[i])
+		}
+	}
+}
+
+// closeTrackingReader flags reads that find the reader closed. Each read
+// yields first, giving a concurrent clearNext the chance to run mid-read.
+type closeTrackingReader struct {
+	*stubPCMDecoder
+	closed         atomic.Bool
+	readAfterClose atomic.Bool
+}
+
+func (r *closeTrackingReader) Read(p []byte) (int, error) {
+	runtime.Gosched()
+	if r.closed.Load() {
+		r.readAfterClose.Store(true)
+	}
+	return r.stubPCMDecoder.Read(p)
+}
+
+// Run with -race: the audio goroutine mixes in the next reader while the UI
+// goroutine swaps the preload, as PreloadNext does.
+func TestCountingReaderCrossfadeDuringPreloadSwap(t *testing.T) {
+	const size = 64 << 10
+	counter := &countingReader{
+		reader:    &stubPCMDecoder{data: make([]byte, size), sampleRate: playbackSampleRate, channels: 2},
+		fadeBytes: size,
+	}
+	newNext := func() *closeTrackingReader {
+		return &closeTrackingReader{stubPCMDecoder: &stubPCMDecoder{sampleRate: playbackSampleRate, channels: 2}}
+	}
+	next := newNext()
+	counter.setNext(next)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 64)
+		for {
+			if _, err := counter.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+
+	var swapped []*closeTrackingReader
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			if counter.clearNext() {
+				next.closed.Store(true)
+				swapped = append(swapped, next)
+				next = newNext()
+				counter.setNext(next)
+			}
+			runtime.Gosched()
+		}
+	}
+	for _, r := range swapped {
+		if r.readAfterClose.Load() {
+			t.Fatal("crossfade read from a next reader after clearNext released it")
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -111,12 +112,16 @@ func newNativeDecoder(f *os.File) (audioDecoder, error) {
 		return newMP3Decoder(f)
 	case ".wav":
 		return newWAVDecoder(f)
+	case ".aiff", ".aif":
+		return newAIFFDecoder(f)
 	case ".flac":
 		return newFLACDecoder(f)
-	case ".ogg":
-		return newOGGDecoder(f)
+	case ".ogg", ".oga":
+		return newOggDecoder(f)
 	case ".aac", ".m4a", ".m4b":
 		return newAACDecoder(f)
+	case ".opus":
+		return newFFmpegDecoder(f)
 	default:
 		return nil, fmt.Errorf("unsupported format: %s", ext)
 	}
@@ -125,7 +130,8 @@ func newNativeDecoder(f *os.File) (audioDecoder, error) {
 // --- MP3 decoder ---
 
 type mp3Decoder struct {
-	dec *mp3.Decoder
+	dec    *mp3.Decoder
+	length int64 // see mp3Length; go-mp3's own count can run long
 }
 
 func newMP3Decoder(f *os.File) (*mp3Decoder, error) {
@@ -133,26 +139,39 @@ func newMP3Decoder(f *os.File) (*mp3Decoder, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &mp3Decoder{dec: dec}, nil
+	d := &mp3Decoder{dec: dec, length: dec.Length()}
+	if n, ok := mp3Length(f); ok && (d.length < 0 || n < d.length) {
+		d.length = n
+	}
+	return d, nil
 }
 
 func (d *mp3Decoder) Read(p []byte) (int, error) { return d.dec.Read(p) }
 func (d *mp3Decoder) Seek(offset int64, whence int) (int64, error) {
 	return d.dec.Seek(offset, whence)
 }
-func (d *mp3Decoder) Length() int64    { return d.dec.Length() }
+func (d *mp3Decoder) Length() int64    { return d.length }
 func (d *mp3Decoder) SampleRate() int  { return d.dec.SampleRate() }
 // ChannelCount returns 2 because go-mp3 always decodes to stereo output.
 func (d *mp3Decoder) ChannelCount() int { return 2 }
 
+// Channels stays 0: go-mp3 does not report the source layout.
+func (d *mp3Decoder) sourceInfo() SourceInfo {
+	return SourceInfo{Decoder: "mp3", SampleRate: d.dec.SampleRate()}
+}
+
 // --- WAV decoder ---
 
+// wavFormatFloat is the WAVE fmt tag for IEEE float samples.
+const wavFormatFloat = 3
+
 type wavDecoder struct {
 	baseDecoder
 	file         *os.File
 	pcmStart     int64 // byte offset in file where PCM data begins
 	srcBitDepth  int
 	srcFrameSize int64 // bytes per sample frame in source format
+	srcIsFloat   bool  // IEEE float samples (format tag 3) rather than integer PCM
 }
 
 func newWAVDecoder(f *os.File) (*wavDecoder, error) {
@@ -169,6 +188,10 @@ func newWAVDecoder(f *os.File) (*wavDecoder, error) {
 	sampleRate := int(dec.SampleRate)
 	channels := int(dec.NumChans)
 	bitDepth := int(dec.BitDepth)
+	isFloat := dec.WavAudioFormat == wavFormatFloat
+	if isFloat && bitDepth != 32 && bitDepth != 64 {
+		return nil, fmt.Errorf("unsupported float WAV bit depth: %d", bitDepth)
+	}
 	srcFrameSize := int64(channels) * int64(bitDepth) / 8
 
 	// Total output bytes (16-bit stereo)
@@ -191,10 +214,15 @@ func newWAVDecoder(f *os.File) (*wavDecoder, error) {
 		file:         f,
 		srcBitDepth:  bitDepth,
 		srcFrameSize: srcFrameSize,
+		srcIsFloat:   isFloat,
 		pcmStart:     pcmStart,
 	}, nil
 }
 
+func (d *wavDecoder) sourceInfo() SourceInfo {
+	return SourceInfo{Decoder: "wav", SampleRate: d.sampleRate, Channels: d.channels, BitDepth: d.srcBitDepth}
+}
+
 func (d *wavDecoder) Read(p []byte) (int, error) {
 	if n, ok := d.drainBuf(p); ok {
 		return n, nil
@@ -226,19 +254,35 @@ func (d *wavDecoder) Read(p []byte) (int, error) {
 	for i := 0; i < samplesRead; i++ {
 		var sample int
 		off := i * srcBytesPerSample
-		switch d.srcBitDepth {
-		case 8:
+		switch {
+		case d.srcIsFloat:
+			var v float64
+			if d.srcBitDepth == 64 {
+				v = math.Float64frombits(binary.LittleEndian.Uint64(srcBytes[off:]))
+			} else {
+				v = float64(math.Float32frombits(binary.LittleEndian.Uint32(srcBytes[off:])))
+			}
+			// Clamp before converting: float WAVs may exceed ±1.0, and NaN maps to 0.
+			if v > 1 {
+				v = 1
+			} else if v < -1 {
+				v = -1
+			} else if v != v {
+				v = 0
+			}
+			sample = int(math.Round(v * 32767))
+		case d.srcBitDepth == 8:
 			// 8-bit WAV is unsigned
 			sample = (int(srcBytes[off]) - 128) << 8
-		case 16:
+		case d.srcBitDepth == 16:
 			sample = int(int16(binary.LittleEndian.Uint16(srcBytes[off:])))
-		case 24:
+		case d.srcBitDepth == 24:
 			s := int32(srcBytes[off]) | int32(srcBytes[off+1])<<8 | int32(srcBytes[off+2])<<16
 			if s&0x800000 != 0 {
 				s |= ^0xFFFFFF // sign extend
 			}
 			sample = int(s >> 8)
-		case 32:
+		case d.srcBitDepth == 32:
 			sample = int(int32(binary.LittleEndian.Uint32(srcBytes[off:])) >> 16)
 		}
 		if sample > 32767 {
@@ -278,11 +322,12 @@ type flacDecoder struct {
 	baseDecoder
 	stream *flac.Stream
 	bps    int
-	tmpRaw []byte // reusable output buffer (grow-only)
+	dither *ditherer // TPDF dither for bps > 16 (SetDither); nil when off
+	tmpRaw []byte    // reusable output buffer (grow-only)
 }
 
-func newFLACDecoder(f *os.File) (*flacDecoder, error) {
-	stream, err := flac.NewSeek(f)
+func newFLACDecoder(r io.ReadSeeker) (*flacDecoder, error) {
+	stream, err := flac.NewSeek(r)
 	if err != nil {
 		return nil, fmt.Errorf("decoding FLAC: %w", err)
 	}
@@ -292,7 +337,7 @@ func newFLACDecoder(f *os.File) (*flacDecoder, error) {
 	channels := int(info.NChannels)
 	totalBytes := totalSamples * int64(channels) * 2 // 16-bit output
 
-	return &flacDecoder{
+	d := &flacDecoder{
 		baseDecoder: baseDecoder{
 			totalBytes: totalBytes,
 			sampleRate: int(info.SampleRate),
@@ -300,7 +345,15 @@ func newFLACDecoder(f *os.File) (*flacDecoder, error) {
 		},
 		stream: stream,
 		bps:    int(info.BitsPerSample),
-	}, nil
+	}
+	if d.bps > 16 && ditherEnabled.Load() {
+		d.dither = newDitherer(ditherSeed)
+	}
+	return d, nil
+}
+
+func (d *flacDecoder) sourceInfo() SourceInfo {
+	return SourceInfo{Decoder: "flac", SampleRate: d.sampleRate, Channels: d.channels, BitDepth: d.bps}
 }
 
 func (d *flacDecoder) Read(p []byte) (int, error) {
@@ -324,6 +377,8 @@ func (d *flacDecoder) Read(p []byte) (int, error) {
 		for ch := 0; ch < d.channels; ch++ {
 			sample := int(frame.Subframes[ch].Samples[i])
 			switch {
+			case d.bps > 16 && d.dither != nil:
+				sample = d.dither.reduce(sample, d.bps-16)
 			case d.bps > 16:
 				sample >>= (d.bps - 16)
 			case d.bps < 16:
@@ -357,6 +412,7 @@ func (d *flacDecoder) Seek(offset int64, whence int) (int64, error) {
 }
 
 // --- OGG Vorbis decoder ---
+// Ogg files are dispatched by codec in newOggDecoder (ogg.go).
 
 type oggDecoder struct {
 	baseDecoder
@@ -385,6 +441,10 @@ func newOGGDecoder(f *os.File) (*oggDecoder, error) {
 	}, nil
 }
 
+func (d *oggDecoder) sourceInfo() SourceInfo {
+	return SourceInfo{Decoder: "ogg", SampleRate: d.sampleRate, Channels: d.channels}
+}
+
 func (d *oggDecoder) Read(p []byte) (int, error) {
 	if n, ok := d.drainBuf(p); ok {
 		return n, nil
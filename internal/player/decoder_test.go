@@ -0,0 +1,128 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeWAV writes a mono WAV file with the given format tag and raw samples.
+func writeWAV(t *testing.T, format uint16, bitDepth int, data []byte) *os.File {
+	t.Helper()
+	var b bytes.Buffer
+	le := func(v any) { _ = binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("RIFF")
+	le(uint32(36 + len(data)))
+	b.WriteString("WAVEfmt ")
+	le(uint32(16))
+	le(format)
+	le(uint16(1))    // channels
+	le(uint32(8000)) // sample rate
+	le(uint32(8000 * bitDepth / 8))
+	le(uint16(bitDepth / 8))
+	le(uint16(bitDepth))
+	b.WriteString("data")
+	le(uint32(len(data)))
+	b.Write(data)
+
+	path := filepath.Join(t.TempDir(), "test.wav")
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func readAllWAV(t *testing.T, f *os.File) []byte {
+	t.Helper()
+	dec, err := newWAVDecoder(f)
+	if err != nil {
+		t.Fatalf("newWAVDecoder() error = %v", err)
+	}
+	got, err := io.ReadAll(dec)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return got
+}
+
+func TestWAVDecoderConvertsFloatSamples(t *testing.T) {
+	in := []float64{0, 0.5, -0.5, 1, -1, 2, float64(float32(math.NaN()))}
+	want := pcm16(0, 16384, -16384, 32767, -32767, 32767, 0)
+
+	var f32, f64 []byte
+	for _, v := range in {
+		f32 = binary.LittleEndian.AppendUint32(f32, math.Float32bits(float32(v)))
+		f64 = binary.LittleEndian.AppendUint64(f64, math.Float64bits(v))
+	}
+	if got := readAllWAV(t, writeWAV(t, wavFormatFloat, 32, f32)); !bytes.Equal(got, want) {
+		t.Fatalf("float32: got %v, want %v", got, want)
+	}
+	if got := readAllWAV(t, writeWAV(t, wavFormatFloat, 64, f64)); !bytes.Equal(got, want) {
+		t.Fatalf("float64: got %v, want %v", got, want)
+	}
+}
+
+func TestWAVDecoderKeepsIntegerSamples(t *testing.T) {
+	var s32 []byte
+	for _, v := range []int32{0, 1 << 30, -1 << 30} {
+		s32 = binary.LittleEndian.AppendUint32(s32, uint32(v))
+	}
+	if got, want := readAllWAV(t, writeWAV(t, 1, 32, s32)), pcm16(0, 16384, -16384); !bytes.Equal(got, want) {
+		t.Fatalf("int32: got %v, want %v", got, want)
+	}
+	if got, want := readAllWAV(t, writeWAV(t, 1, 16, pcm16(7, -7))), pcm16(7, -7); !bytes.Equal(got, want) {
+		t.Fatalf("int16: got %v, want %v", got, want)
+	}
+}
+
+func TestNormalizedDecoderReportsSourceInfo(t *testing.T) {
+	dec, err := newDecoder(writeWAV(t, 1, 24, make([]byte, 3*8000)))
+	if err != nil {
+		t.Fatalf("newDecoder() error = %v", err)
+	}
+	sp, ok := dec.(sourceInfoProvider)
+	if !ok {
+		t.Fatal("expected the normalized decoder to report source info")
+	}
+	want := SourceInfo{Decoder: "wav", SampleRate: 8000, Channels: 1, BitDepth: 24}
+	if got := sp.sourceInfo(); got != want {
+		t.Fatalf("sourceInfo() = %+v, want %+v", got, want)
+	}
+}
+
+func TestOpenPCMConvertsOnlyWhenAsked(t *testing.T) {
+	path := writeWAV(t, 1, 16, pcm16(100, 200, 300)).Name()
+
+	s, err := OpenPCM(path, 0, 0)
+	if err != nil {
+		t.Fatalf("OpenPCM() error = %v", err)
+	}
+	got, err := io.ReadAll(s)
+	s.Close()
+	if err != nil || !bytes.Equal(got, pcm16(100, 200, 300)) || s.SampleRate() != 8000 || s.ChannelCount() != 1 {
+		t.Fatalf("native decode = %v %v at %d Hz x%d", got, err, s.SampleRate(), s.ChannelCount())
+	}
+
+	s, err = OpenPCM(path, 8000, 2)
+	if err != nil {
+		t.Fatalf("OpenPCM(stereo) error = %v", err)
+	}
+	got, err = io.ReadAll(s)
+	s.Close()
+	if err != nil || !bytes.Equal(got, pcm16(100, 100, 200, 200, 300, 300)) || s.Length() != 12 {
+		t.Fatalf("upmixed decode = %v %v, length %d", got, err, s.Length())
+	}
+
+	if _, err := OpenPCM(path, 0, 3); err == nil {
+		t.Fatal("expected an error for 3 output channels")
+	}
+}
@@ -0,0 +1,41 @@
+package player
+
+import "sync/atomic"
+
+// ditherSeed starts the noise of every decoder, so a file decodes the same
+// way each time it is played.
+const ditherSeed = 0x2545F491
+
+// ditherEnabled adds TPDF dither to >16-bit FLAC decoded from now on.
+var ditherEnabled atomic.Bool
+
+// SetDither turns TPDF dither on or off for FLAC files with more than 16 bits
+// per sample opened after the call. Off keeps the output bit-exact.
+func SetDither(on bool) {
+	ditherEnabled.Store(on)
+}
+
+// ditherer reduces samples to 16 bits with triangular (TPDF) dither. The
+// noise comes from a 32-bit LCG: cheap, and repeatable from a seed.
+type ditherer struct {
+	state uint32
+}
+
+func newDitherer(seed uint32) *ditherer {
+	return &ditherer{state: seed}
+}
+
+// uniform returns the next noise value in [0, 1<<bits), bits <= 16.
+func (d *ditherer) uniform(bits int) int {
+	d.state = d.state*1664525 + 1013904223
+	return int(d.state >> (32 - bits))
+}
+
+// reduce scales sample down by shift bits, adding noise that spans ±1 output
+// step before rounding, so quiet detail below one step survives as noise
+// instead of being truncated away.
+func (d *ditherer) reduce(sample, shift int) int {
+	lsb := 1 << shift
+	noise := d.uniform(shift) + d.uniform(shift) - lsb
+	return (sample + noise + lsb/2) >> shift
+}
@@ -0,0 +1,30 @@
+package player
+
+import "testing"
+
+func TestDitherKeepsDetailBelowOneStep(t *testing.T) {
+	// A 24-bit level of a quarter 16-bit step truncates to silence; with
+	// dither it averages out to the same quarter step.
+	const n = 100000
+	d := newDitherer(ditherSeed)
+	sum := 0
+	for range n {
+		v := d.reduce(64, 8)
+		if v < -1 || v > 2 {
+			t.Fatalf("reduce() = %d, want noise within one step", v)
+		}
+		sum += v
+	}
+	if mean := float64(sum) / n; mean < 0.23 || mean > 0.27 {
+		t.Fatalf("mean = %.3f, want about 0.25", mean)
+	}
+}
+
+func TestDitherIsRepeatableFromSeed(t *testing.T) {
+	a, b := newDitherer(7), newDitherer(7)
+	for i := range 1000 {
+		if x, y := a.reduce(1<<20+i, 8), b.reduce(1<<20+i, 8); x != y {
+			t.Fatalf("sample %d: %d != %d", i, x, y)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package player
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+)
+
+// EQBandFrequencies lists the center frequencies (Hz) of the graphic equalizer bands.
+var EQBandFrequencies = [...]float64{31, 62, 125, 250, 500, 1000, 2000, 4000, 8000, 16000}
+
+// EQBandCount is the number of graphic equalizer bands.
+const EQBandCount = len(EQBandFrequencies)
+
+const (
+	eqBandQ      = 1.41 // roughly one octave per band
+	eqMaxGainDB  = 12.0
+	eqMaxChannel = 8
+)
+
+// EQPreset represents a named equalizer curve.
+type EQPreset int
+
+const (
+	EQFlat EQPreset = iota
+	EQBassBoost
+	EQVocal
+	EQTreble
+)
+
+// Next cycles to the next preset: flat → bass → vocal → treble → flat.
+func (e EQPreset) Next() EQPreset {
+	switch e {
+	case EQFlat:
+		return EQBassBoost
+	case EQBassBoost:
+		return EQVocal
+	case EQVocal:
+		return EQTreble
+	default:
+		return EQFlat
+	}
+}
+
+// Label returns a display label for the preset.
+func (e EQPreset) Label() string {
+	switch e {
+	case EQBassBoost:
+		return "[eq bass]"
+	case EQVocal:
+		return "[eq vocal]"
+	case EQTreble:
+		return "[eq treble]"
+	default:
+		return ""
+	}
+}
+
+// Gains returns the per-band gain in dB for the preset.
+func (e EQPreset) Gains() [EQBandCount]float64 {
+	switch e {
+	case EQBassBoost:
+		return [EQBandCount]float64{6, 5, 4, 2, 0, 0, 0, 0, 0, 0}
+	case EQVocal:
+		return [EQBandCount]float64{-2, -1, 0, 2, 4, 4, 3, 1, 0, -1}
+	case EQTreble:
+		return [EQBandCount]float64{0, 0, 0, 0, 0, 1, 2, 4, 5, 6}
+	default:
+		return [EQBandCount]float64{}
+	}
+}
+
+// biquad holds RBJ peaking filter coefficients (normalized by a0).
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+}
+
+// biquadState is the per-channel direct form I history for one band.
+type biquadState struct {
+	x1, x2 float64
+	y1, y2 float64
+}
+
+func newPeakingBiquad(sampleRate, freq, gainDB float64) biquad {
+	a := math.Pow(10, gainDB/40)
+	w0 := 2 * math.Pi * freq / sampleRate
+	alpha := math.Sin(w0) / (2 * eqBandQ)
+	cosW0 := math.Cos(w0)
+
+	a0 := 1 + alpha/a
+	return biquad{
+		b0: (1 + alpha*a) / a0,
+		b1: (-2 * cosW0) / a0,
+		b2: (1 - alpha*a) / a0,
+		a1: (-2 * cosW0) / a0,
+		a2: (1 - alpha/a) / a0,
+	}
+}
+
+// Equalizer sits between speedReader and Oto, applying a bank of biquad
+// peaking filters to the s16le stream. When every band is at 0 dB it passes
+// reads straight through to the source.
+//
+// Gains are set from the UI goroutine while Oto's audio goroutine calls Read,
+// so only gains and the dirty/reset flags are guarded by mu; filter state is
+// owned by the reading goroutine and rebuilt lazily at the start of a read.
+type Equalizer struct {
+	source     io.Reader
+	frameSize  int // channels * 2 (16-bit samples)
+	channels   int
+	sampleRate float64
+
+	mu         sync.Mutex
+	gains      [EQBandCount]float64
+	dirty      bool // gains changed since filters were last rebuilt
+	resetState bool // clear filter history before the next read
+
+	filters [EQBandCount]biquad
+	state   [EQBandCount][eqMaxChannel]biquadState
+	active  []int  // indices of bands with non-zero gain
+	pending []byte // partial frame carried over from the previous read
+}
+
+func newEqualizer(source io.Reader, sampleRate, channels int) *Equalizer {
+	if channels > eqMaxChannel {
+		channels = eqMaxChannel
+	}
+	return &Equalizer{
+		source:     source,
+		frameSize:  channels * 2,
+		channels:   channels,
+		sampleRate: float64(sampleRate),
+	}
+}
+
+// SetBand sets the gain of one band in dB (clamped to ±12 dB).
+// Out-of-range indices are ignored.
+func (eq *Equalizer) SetBand(index int, gainDB float64) {
+	if index < 0 || index >= EQBandCount {
+		return
+	}
+	if gainDB > eqMaxGainDB {
+		gainDB = eqMaxGainDB
+	} else if gainDB < -eqMaxGainDB {
+		gainDB = -eqMaxGainDB
+	}
+
+	eq.mu.Lock()
+	if eq.gains[index] != gainDB {
+		eq.gains[index] = gainDB
+		eq.dirty = true
+	}
+	eq.mu.Unlock()
+}
+
+// Bands returns a copy of the current per-band gains in dB.
+func (eq *Equalizer) Bands() []float64 {
+	eq.mu.Lock()
+	defer eq.mu.Unlock()
+	out := make([]float64, EQBandCount)
+	copy(out, eq.gains[:])
+	return out
+}
+
+func (eq *Equalizer) Read(p []byte) (int, error) {
+	eq.mu.Lock()
+	if eq.dirty {
+		eq.rebuildFilters(eq.gains)
+		eq.dirty = false
+	}
+	if eq.resetState {
+		eq.state = [EQBandCount][eqMaxChannel]biquadState{}
+		eq.pending = eq.pending[:0]
+		eq.resetState = false
+	}
+	eq.mu.Unlock()
+
+	if len(eq.active) == 0 && len(eq.pending) == 0 {
+		return eq.source.Read(p)
+	}
+
+	fs := eq.frameSize
+	if len(p) < fs {
+		if n := copy(p, eq.pending); n > 0 {
+			eq.pending = eq.pending[n:]
+			return n, nil
+		}
+		return eq.source.Read(p)
+	}
+
+	for {
+		carried := copy(p, eq.pending)
+		eq.pending = eq.pending[:0]
+		n, err := eq.source.Read(p[carried:])
+		total := carried + n
+
+		aligned := total - total%fs
+		if aligned < total {
+			eq.pending = append(eq.pending, p[aligned:total]...)
+		}
+		if aligned > 0 {
+			eq.process(p[:aligned])
+			return aligned, nil
+		}
+		if err != nil {
+			if len(eq.pending) > 0 {
+				// Return the trailing partial frame unfiltered so no data is lost.
+				n := copy(p, eq.pending)
+				eq.pending = eq.pending[:0]
+				return n, err
+			}
+			return 0, err
+		}
+	}
+}
+
+// rebuildFilters recomputes coefficients for changed bands and the active list.
+func (eq *Equalizer) rebuildFilters(gains [EQBandCount]float64) {
+	eq.active = eq.active[:0]
+	for i, g := range gains {
+		want := biquad{}
+		if g != 0 {
+			want = newPeakingBiquad(eq.sampleRate, EQBandFrequencies[i], g)
+		}
+		if eq.filters[i] != want {
+			eq.filters[i] = want
+			eq.state[i] = [eqMaxChannel]biquadState{}
+		}
+		if g != 0 && EQBandFrequencies[i] < eq.sampleRate/2 {
+			eq.active = append(eq.active, i)
+		}
+	}
+}
+
+// process filters whole frames of interleaved s16le samples in place.
+func (eq *Equalizer) process(buf []byte) {
+	if len(eq.active) == 0 {
+		return
+	}
+	for off := 0; off+eq.frameSize <= len(buf); off += eq.frameSize {
+		for ch := 0; ch < eq.channels; ch++ {
+			i := off + ch*2
+			x := float64(int16(binary.LittleEndian.Uint16(buf[i:])))
+			for _, b := range eq.active {
+				f := &eq.filters[b]
+				s := &eq.state[b][ch]
+				y := f.b0*x + f.b1*s.x1 + f.b2*s.x2 - f.a1*s.y1 - f.a2*s.y2
+				s.x2, s.x1 = s.x1, x
+				s.y2, s.y1 = s.y1, y
+				x = y
+			}
+			if x > 32767 {
+				x = 32767
+			} else if x < -32768 {
+				x = -32768
+			}
+			binary.LittleEndian.PutUint16(buf[i:], uint16(int16(math.Round(x))))
+		}
+	}
+}
+
+// reset clears filter history and any carried partial frame, e.g. after a seek.
+func (eq *Equalizer) reset() {
+	eq.mu.Lock()
+	eq.resetState = true
+	eq.mu.Unlock()
+}
@@ -0,0 +1,94 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEqualizerFlatPassesThrough(t *testing.T) {
+	in := pcm16(100, -100, 2000, -2000, 32767, -32768)
+	eq := newEqualizer(bytes.NewReader(in), playbackSampleRate, playbackChannels)
+
+	out, err := io.ReadAll(eq)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if !bytes.Equal(out, in) {
+		t.Fatalf("flat EQ changed PCM:\n got %v\nwant %v", out, in)
+	}
+}
+
+func TestEqualizerBoostRaisesBandEnergy(t *testing.T) {
+	const frames = 4800
+	samples := make([]int16, frames*playbackChannels)
+	for i := 0; i < frames; i++ {
+		v := int16(4000 * math.Sin(2*math.Pi*1000*float64(i)/playbackSampleRate))
+		samples[i*2] = v
+		samples[i*2+1] = v
+	}
+	in := pcm16(samples...)
+
+	eq := newEqualizer(bytes.NewReader(in), playbackSampleRate, playbackChannels)
+	eq.SetBand(5, 6) // 1 kHz
+	out, err := io.ReadAll(eq)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(out) != len(in) {
+		t.Fatalf("output length = %d, want %d", len(out), len(in))
+	}
+
+	if got, base := rms(out[len(out)/2:]), rms(in[len(in)/2:]); got < base*1.5 {
+		t.Fatalf("boosted RMS = %.1f, want at least 1.5x input RMS %.1f", got, base)
+	}
+}
+
+func TestEqualizerKeepsFrameAlignmentAcrossPartialReads(t *testing.T) {
+	in := pcm16(1, 2, 3, 4, 5, 6, 7, 8)
+	eq := newEqualizer(iotest.OneByteReader(bytes.NewReader(in)), playbackSampleRate, playbackChannels)
+	eq.SetBand(0, 3)
+
+	buf := make([]byte, 16)
+	for {
+		n, err := eq.Read(buf)
+		if n%playbackFrameSize != 0 && err == nil {
+			t.Fatalf("Read() returned %d bytes, want whole frames", n)
+		}
+		if err != nil {
+			break
+		}
+	}
+}
+
+func TestEqualizerClampsGainAndIgnoresBadIndex(t *testing.T) {
+	eq := newEqualizer(bytes.NewReader(nil), playbackSampleRate, playbackChannels)
+	eq.SetBand(0, 40)
+	eq.SetBand(EQBandCount, 3)
+	eq.SetBand(-1, 3)
+
+	bands := eq.Bands()
+	if bands[0] != eqMaxGainDB {
+		t.Fatalf("band 0 = %v, want %v", bands[0], eqMaxGainDB)
+	}
+	for i := 1; i < len(bands); i++ {
+		if bands[i] != 0 {
+			t.Fatalf("band %d = %v, want 0", i, bands[i])
+		}
+	}
+}
+
+func rms(pcm []byte) float64 {
+	var sum float64
+	n := len(pcm) / 2
+	for i := 0; i < n; i++ {
+		v := float64(int16(uint16(pcm[i*2]) | uint16(pcm[i*2+1])<<8))
+		sum += v * v
+	}
+	if n == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / float64(n))
+}
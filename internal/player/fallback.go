@@ -0,0 +1,141 @@
+package player
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// fallbackDecoder plays a file with climp's native decoders and, when one
+// fails partway through a slightly corrupt file, re-opens the file through
+// ffmpeg (which tolerates more damage) and carries on from the same byte.
+// Both sides produce the 48 kHz stereo playback format, so byte positions
+// line up and Position() does not jump. A normal EOF, and errors before any
+// audio was produced, pass through unchanged.
+type fallbackDecoder struct {
+	path string
+	open func(path string) (audioDecoder, error)
+
+	mu       sync.Mutex // dec and pos are read by the monitor and UI goroutines
+	dec      audioDecoder
+	pos      int64
+	tried    bool  // the fallback has been attempted; it is never retried
+	switched int64 // byte position of a switch not yet reported; -1 when none
+}
+
+// newFallbackDecoder wraps dec unless it already decodes through ffmpeg.
+func newFallbackDecoder(path string, dec audioDecoder) audioDecoder {
+	if decodesWithFFmpeg(dec) {
+		return dec
+	}
+	return &fallbackDecoder{path: path, open: openFFmpegFallback, dec: dec, switched: -1}
+}
+
+func (d *fallbackDecoder) current() audioDecoder {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dec
+}
+
+func (d *fallbackDecoder) Read(p []byte) (int, error) {
+	n, err := d.current().Read(p)
+	d.mu.Lock()
+	d.pos += int64(n)
+	pos, tried := d.pos, d.tried
+	d.tried = tried || (err != nil && err != io.EOF && pos > 0)
+	d.mu.Unlock()
+	if err == nil || err == io.EOF || tried || pos == 0 || !d.switchAt(pos) {
+		return n, err
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return d.Read(p)
+}
+
+// switchAt replaces the native decoder with the ffmpeg one, positioned at
+// pos. It reports false, keeping the native decoder's error, when ffmpeg
+// cannot open the file either.
+func (d *fallbackDecoder) switchAt(pos int64) bool {
+	fb, err := d.open(d.path)
+	if err != nil {
+		return false
+	}
+	// Land on the exact byte, even mid-frame, so channels stay in step.
+	rem := pos % playbackFrameSize
+	if _, err = fb.Seek(pos-rem, io.SeekStart); err == nil && rem > 0 {
+		_, err = io.ReadFull(fb, make([]byte, rem))
+	}
+	if err != nil {
+		closeDecoder(fb)
+		return false
+	}
+
+	d.mu.Lock()
+	old := d.dec
+	d.dec = fb
+	d.switched = pos
+	d.mu.Unlock()
+	closeDecoder(old)
+	return true
+}
+
+func (d *fallbackDecoder) Seek(offset int64, whence int) (int64, error) {
+	pos, err := d.current().Seek(offset, whence)
+	if err == nil {
+		d.mu.Lock()
+		d.pos = pos
+		d.mu.Unlock()
+	}
+	return pos, err
+}
+
+func (d *fallbackDecoder) Length() int64     { return d.current().Length() }
+func (d *fallbackDecoder) SampleRate() int   { return d.current().SampleRate() }
+func (d *fallbackDecoder) ChannelCount() int { return d.current().ChannelCount() }
+
+// Close releases whichever decoder is in use.
+func (d *fallbackDecoder) Close() error {
+	return closeDecoder(d.current())
+}
+
+func (d *fallbackDecoder) sourceInfo() SourceInfo {
+	if sp, ok := d.current().(sourceInfoProvider); ok {
+		return sp.sourceInfo()
+	}
+	return SourceInfo{}
+}
+
+// takeSwitch returns the byte position of an unreported switch to ffmpeg.
+func (d *fallbackDecoder) takeSwitch() (int64, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pos := d.switched
+	d.switched = -1
+	return pos, pos >= 0
+}
+
+func closeDecoder(dec audioDecoder) error {
+	if c, ok := dec.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// TakeDecoderFallback reports, once per switch, that the native decoder
+// failed partway through the current track and playback continued through
+// ffmpeg. at is the file position where it happened.
+func (p *Player) TakeDecoderFallback() (at time.Duration, ok bool) {
+	p.mu.Lock()
+	dec := p.decoder
+	p.mu.Unlock()
+	fd, isFallback := dec.(*fallbackDecoder)
+	if !isFallback {
+		return 0, false
+	}
+	pos, ok := fd.takeSwitch()
+	if !ok {
+		return 0, false
+	}
+	return durationFor(pos, p.bytesPerSec), true
+}
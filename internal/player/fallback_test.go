@@ -0,0 +1,83 @@
+package player
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// corruptDecoder decodes like stubPCMDecoder until failAt bytes, then fails.
+type corruptDecoder struct {
+	*stubPCMDecoder
+	failAt int64
+}
+
+var errBadFrame = errors.New("bad frame")
+
+func (d *corruptDecoder) Read(p []byte) (int, error) {
+	if d.pos >= d.failAt {
+		return 0, errBadFrame
+	}
+	if left := d.failAt - d.pos; int64(len(p)) > left {
+		p = p[:left]
+	}
+	return d.stubPCMDecoder.Read(p)
+}
+
+func newTestFallback(data []byte, failAt int64, opens *int) *fallbackDecoder {
+	native := &corruptDecoder{&stubPCMDecoder{data: data, sampleRate: playbackSampleRate, channels: 2}, failAt}
+	return &fallbackDecoder{
+		path: "song.mp3",
+		dec:  native,
+		open: func(string) (audioDecoder, error) {
+			*opens++
+			return &stubPCMDecoder{data: data, sampleRate: playbackSampleRate, channels: 2}, nil
+		},
+		switched: -1,
+	}
+}
+
+func TestFallbackDecoderContinuesThroughFFmpegAfterMidStreamError(t *testing.T) {
+	data := make([]byte, 4000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	var opens int
+	d := newTestFallback(data, 1002, &opens) // fails mid-frame
+
+	var got []byte
+	buf := make([]byte, 300)
+	for {
+		n, err := d.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+	}
+	if !bytes.Equal(got, data) || opens != 1 {
+		t.Fatalf("read %d bytes with %d fallback opens, want the whole file seamlessly with 1", len(got), opens)
+	}
+	if pos, ok := d.takeSwitch(); !ok || pos != 1002 {
+		t.Fatalf("takeSwitch() = %d, %v, want the failing byte 1002", pos, ok)
+	}
+	if _, ok := d.takeSwitch(); ok {
+		t.Fatal("the switch should be reported once")
+	}
+}
+
+func TestFallbackDecoderLeavesEOFAndEarlyErrorsAlone(t *testing.T) {
+	var opens int
+	d := newTestFallback(make([]byte, 400), 400, &opens)
+	if _, err := io.ReadAll(d); err != nil || opens != 0 {
+		t.Fatalf("clean EOF: err = %v, opens = %d", err, opens)
+	}
+
+	d = newTestFallback(make([]byte, 400), 0, &opens)
+	if _, err := d.Read(make([]byte, 64)); err != errBadFrame || opens != 0 {
+		t.Fatalf("error before any output: err = %v, opens = %d, want it passed through", err, opens)
+	}
+}
@@ -0,0 +1,126 @@
+package player
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ffmpegDecoder plays formats without a native Go decoder by transcoding the
+// file to a temporary 48 kHz stereo WAV with ffmpeg, then reading it through
+// the regular WAV decoder. This keeps seeking sample-accurate and Length exact:
+// seeks are served from the temp file and never restart ffmpeg. The temp
+// directory is removed by Close, which Player.Close calls for every decoder.
+// Surround sources (e.g. 5.1 AAC the native decoder rejects) are downmixed to
+// stereo by ffmpeg's "-ac 2" using the standard ITU center/surround weights.
+//
+// Encoder delay and padding need no handling here: when decoding to a file,
+// ffmpeg already applies MP4 edit lists and Opus pre-skip (skip_samples), so
+// the temp WAV starts at the first real sample and its Length matches the
+// trimmed duration. There is no streaming Read to discard samples from.
+type ffmpegDecoder struct {
+	*wavDecoder
+	tmpFile *os.File
+	tmpDir  string
+}
+
+func newFFmpegDecoder(f *os.File) (*ffmpegDecoder, error) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		ext := strings.ToLower(filepath.Ext(f.Name()))
+		return nil, fmt.Errorf("ffmpeg not found (required for %s playback)", ext)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "climp-ffmpeg-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	wavPath := filepath.Join(tmpDir, "audio.wav")
+
+	cmd := exec.Command(
+		ffmpeg,
+		"-nostdin",
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", f.Name(),
+		"-vn",
+		"-map", "0:a:0",
+		"-ac", "2",
+		"-ar", "48000",
+		"-c:a", "pcm_s16le",
+		"-f", "wav",
+		wavPath,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(tmpDir)
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			return nil, fmt.Errorf("ffmpeg decode failed: %w", err)
+		}
+		return nil, fmt.Errorf("ffmpeg decode failed: %s", msg)
+	}
+
+	tmpFile, err := os.Open(wavPath)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("opening decoded audio: %w", err)
+	}
+	wav, err := newWAVDecoder(tmpFile)
+	if err != nil {
+		tmpFile.Close()
+		os.RemoveAll(tmpDir)
+		return nil, err
+	}
+
+	return &ffmpegDecoder{
+		wavDecoder: wav,
+		tmpFile:    tmpFile,
+		tmpDir:     tmpDir,
+	}, nil
+}
+
+// ffmpeg has already converted the source to 16-bit 48 kHz stereo WAV, so
+// that is what is reported.
+func (d *ffmpegDecoder) sourceInfo() SourceInfo {
+	info := d.wavDecoder.sourceInfo()
+	info.Decoder = "ffmpeg"
+	return info
+}
+
+// Close releases the temporary WAV file.
+func (d *ffmpegDecoder) Close() error {
+	err := d.tmpFile.Close()
+	os.RemoveAll(d.tmpDir)
+	return err
+}
+
+// openFFmpegFallback decodes path through ffmpeg for fallbackDecoder, in the
+// same playback format as the native decoders.
+func openFFmpegFallback(path string) (audioDecoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // ffmpeg reads the file by name
+	dec, err := newFFmpegDecoder(f)
+	if err != nil {
+		return nil, err
+	}
+	norm, err := newNormalizedDecoder(dec)
+	if err != nil {
+		dec.Close()
+		return nil, err
+	}
+	return norm, nil
+}
+
+// decodesWithFFmpeg reports whether dec already decodes through ffmpeg.
+func decodesWithFFmpeg(dec audioDecoder) bool {
+	if n, ok := dec.(*normalizedDecoder); ok {
+		dec = n.src
+	}
+	_, ok := dec.(*ffmpegDecoder)
+	return ok
+}
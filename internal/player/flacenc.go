@@ -0,0 +1,90 @@
+package player
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+)
+
+// flacBlockSize is the number of samples per channel in each encoded frame.
+const flacBlockSize = 4096
+
+// WriteFLAC writes the whole stream to w as a 16-bit FLAC file. When w can
+// seek, the stream info (sample count and MD5) is filled in at the end.
+func (s *PCMStream) WriteFLAC(w io.Writer) (int64, error) {
+	return writeFLAC(w, s, s.rate, s.channels)
+}
+
+// writeFLAC encodes s16le PCM from r. Each frame starts out verbatim and the
+// encoder picks a cheaper prediction where one fits.
+func writeFLAC(w io.Writer, r io.Reader, rate, channels int) (int64, error) {
+	if channels < 1 || channels > 8 {
+		return 0, fmt.Errorf("unsupported channel count for FLAC: %d", channels)
+	}
+	info := &meta.StreamInfo{
+		BlockSizeMin:  flacBlockSize,
+		BlockSizeMax:  flacBlockSize,
+		SampleRate:    uint32(rate),
+		NChannels:     uint8(channels),
+		BitsPerSample: 16,
+	}
+	// The encoder closes its writer on Close; the caller owns w.
+	var out io.Writer = struct{ io.Writer }{w}
+	if ws, ok := w.(io.WriteSeeker); ok {
+		out = struct{ io.WriteSeeker }{ws}
+	}
+	enc, err := flac.NewEncoder(out, info)
+	if err != nil {
+		return 0, err
+	}
+
+	frameSize := channels * 2
+	buf := make([]byte, flacBlockSize*frameSize)
+	subframes := make([]*frame.Subframe, channels)
+	for ch := range subframes {
+		subframes[ch] = &frame.Subframe{
+			SubHeader: frame.SubHeader{Pred: frame.PredVerbatim},
+			Samples:   make([]int32, flacBlockSize),
+		}
+	}
+	var total int64
+	for {
+		n, rerr := io.ReadFull(r, buf)
+		frames := n / frameSize
+		if frames > 0 {
+			for ch, sf := range subframes {
+				sf.Pred = frame.PredVerbatim
+				sf.Samples = sf.Samples[:frames]
+				sf.NSamples = frames
+				for i := range frames {
+					sf.Samples[i] = int32(int16(binary.LittleEndian.Uint16(buf[i*frameSize+ch*2:])))
+				}
+			}
+			f := &frame.Frame{
+				Header: frame.Header{
+					HasFixedBlockSize: true,
+					BlockSize:         uint16(frames),
+					SampleRate:        uint32(rate),
+					Channels:          frame.Channels(channels - 1),
+					BitsPerSample:     16,
+				},
+				Subframes: subframes,
+			}
+			if err := enc.WriteFrame(f); err != nil {
+				return total, err
+			}
+			total += int64(frames * frameSize)
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			return total, rerr
+		}
+	}
+	return total, enc.Close()
+}
@@ -0,0 +1,53 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mewkiz/flac"
+)
+
+func TestWriteFLACRoundTrips(t *testing.T) {
+	const frames = flacBlockSize + 123 // a full block and a short last one
+	pcm := make([]byte, frames*4)
+	for i := range frames * 2 {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(i*37%20000-10000)))
+	}
+	path := filepath.Join(t.TempDir(), "out.flac")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := writeFLAC(f, bytes.NewReader(pcm), 44100, 2)
+	f.Close()
+	if err != nil || n != int64(len(pcm)) {
+		t.Fatalf("writeFLAC() = %d, %v; want %d bytes", n, err, len(pcm))
+	}
+
+	stream, err := flac.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	if stream.Info.NSamples != frames || stream.Info.SampleRate != 44100 {
+		t.Fatalf("stream info = %d samples at %d Hz, want %d at 44100", stream.Info.NSamples, stream.Info.SampleRate, frames)
+	}
+	var got []byte
+	for {
+		fr, err := stream.ParseNext()
+		if err != nil {
+			break
+		}
+		for i := range int(fr.BlockSize) {
+			for _, sf := range fr.Subframes {
+				got = binary.LittleEndian.AppendUint16(got, uint16(int16(sf.Samples[i])))
+			}
+		}
+	}
+	if !bytes.Equal(got, pcm) {
+		t.Fatalf("decoded %d bytes that differ from the %d written", len(got), len(pcm))
+	}
+}
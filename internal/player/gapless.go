@@ -0,0 +1,108 @@
+package player
+
+import (
+	"io"
+	"os"
+)
+
+// preloadWindowSecs is how close to the end of the current track the next
+// track's decoder is opened.
+const preloadWindowSecs = 1
+
+// preloadedTrack is a decoder opened ahead of time for gapless playback.
+type preloadedTrack struct {
+	path string
+	file *os.File
+	dec  audioDecoder
+}
+
+func (t *preloadedTrack) close() {
+	if c, ok := t.dec.(io.Closer); ok {
+		_ = c.Close()
+	}
+	_ = t.file.Close()
+}
+
+// PreloadNext tells the player which local file follows the current track.
+// Shortly before the current track ends its decoder is opened and stitched
+// into the same output stream, so playback continues without a gap. An empty
+// path cancels any pending preload. Non-seekable (live) players ignore this.
+func (p *Player) PreloadNext(path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || !p.canSeek {
+		return
+	}
+	p.finishAdvanceLocked()
+	if path == p.preloadPath {
+		return
+	}
+	if p.preload != nil && p.counter.clearNext() {
+		p.preload.close()
+		p.preload = nil
+	}
+	p.preloadPath = path
+}
+
+// Advanced returns a channel that receives the path of the next track each
+// time playback continues into a preloaded track. It is closed by Close.
+func (p *Player) Advanced() <-chan string {
+	if p == nil {
+		return nil
+	}
+	return p.advanced
+}
+
+// openPreload opens the decoder for path and queues it behind the current one.
+// Called from the monitor goroutine without p.mu held, since opening can be slow.
+func (p *Player) openPreload(path string) {
+	f, dec, err := openDecoder(path)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		// Leave the next track to the normal end-of-track path.
+		if p.preloadPath == path {
+			p.preloadPath = ""
+		}
+		return
+	}
+
+	track := &preloadedTrack{path: path, file: f, dec: dec}
+	if p.closed || p.preloadPath != path || p.preload != nil || !p.counter.setNext(dec) {
+		track.close()
+		return
+	}
+	p.preload = track
+}
+
+// finishAdvanceLocked adopts the preloaded track once the counter has switched
+// to it, releasing the previous decoder and notifying Advanced listeners.
+func (p *Player) finishAdvanceLocked() {
+	if p.preload == nil || !p.counter.takeSwitch() {
+		return
+	}
+
+	if c, ok := p.decoder.(io.Closer); ok {
+		_ = c.Close()
+	}
+	if p.file != nil {
+		p.file.Close()
+	}
+
+	next := p.preload
+	p.preload = nil
+	p.preloadPath = ""
+	p.file = next.file
+	p.decoder = next.dec
+	p.duration = durationFor(next.dec.Length(), p.bytesPerSec)
+	p.loopStart = 0
+	p.loopEnd = 0
+	p.boundStart = 0
+	p.boundEnd = 0
+
+	select {
+	case p.advanced <- next.path:
+	default:
+	}
+}
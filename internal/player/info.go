@@ -0,0 +1,44 @@
+package player
+
+// SourceInfo describes the audio a track decodes from, before it is
+// converted to the 48 kHz stereo output format.
+type SourceInfo struct {
+	Decoder    string // mp3, wav, flac, ogg, native-aac, or ffmpeg
+	SampleRate int
+	Channels   int    // 0 when the decoder does not report it
+	BitDepth   int    // 0 for lossy formats
+	Bitrate    int    // bits per second, averaged over the file
+	URL        string // what a live stream decodes from
+	Live       bool
+}
+
+// sourceInfoProvider is implemented by decoders that can describe their
+// source.
+type sourceInfoProvider interface {
+	sourceInfo() SourceInfo
+}
+
+// SourceInfo describes the current track's source. Bitrate is the file size
+// over the duration, so it is 0 for live streams.
+func (p *Player) SourceInfo() SourceInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var info SourceInfo
+	if sp, ok := p.decoder.(sourceInfoProvider); ok {
+		info = sp.sourceInfo()
+	}
+	if p.file != nil && p.duration > 0 {
+		if st, err := p.file.Stat(); err == nil {
+			info.Bitrate = int(float64(st.Size()) * 8 / p.duration.Seconds())
+		}
+	}
+	return info
+}
+
+func (d *normalizedDecoder) sourceInfo() SourceInfo {
+	if sp, ok := d.src.(sourceInfoProvider); ok {
+		return sp.sourceInfo()
+	}
+	return SourceInfo{SampleRate: d.srcRate, Channels: d.srcChannels}
+}
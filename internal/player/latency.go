@@ -0,0 +1,31 @@
+package player
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// MinLatency and MaxLatency bound the output buffer. Below about 10ms
+	// most devices underrun and crackle; past half a second pause and volume
+	// changes lag noticeably behind the key press.
+	MinLatency = 10 * time.Millisecond
+	MaxLatency = 500 * time.Millisecond
+)
+
+// outputLatency is the device buffer requested when the audio output opens;
+// 0 keeps the driver's default.
+var outputLatency atomic.Int64
+
+// SetLatency sets the audio device buffer, clamped to MinLatency-MaxLatency,
+// and returns the value used; 0 keeps the driver's default. A smaller buffer
+// makes pausing and volume changes take effect sooner but risks underruns
+// on a busy system. The output is opened once per process, with the first
+// player, so later calls have no effect.
+func SetLatency(d time.Duration) time.Duration {
+	if d != 0 {
+		d = min(max(d, MinLatency), MaxLatency)
+	}
+	outputLatency.Store(int64(d))
+	return d
+}
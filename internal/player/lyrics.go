@@ -0,0 +1,222 @@
+package player
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// LyricLine is one line of lyrics. At is when it is sung, for synced lyrics.
+type LyricLine struct {
+	At   time.Duration
+	Text string
+}
+
+// Lyrics holds a track's lyrics. Synced lyrics are sorted by time; unsynced
+// lyrics are the text as written.
+type Lyrics struct {
+	Lines  []LyricLine
+	Synced bool
+}
+
+// Empty reports whether there are no lyrics.
+func (l Lyrics) Empty() bool {
+	return len(l.Lines) == 0
+}
+
+// LineAt returns the index of the synced line being sung at pos, or -1
+// before the first line and for unsynced lyrics.
+func (l Lyrics) LineAt(pos time.Duration) int {
+	if !l.Synced {
+		return -1
+	}
+	i, _ := slices.BinarySearchFunc(l.Lines, pos, func(line LyricLine, t time.Duration) int {
+		if line.At <= t {
+			return -1
+		}
+		return 1
+	})
+	return i - 1
+}
+
+var (
+	// lrcTimestamp matches one [mm:ss], [mm:ss.xx], or [mm:ss.xxx] prefix.
+	lrcTimestamp = regexp.MustCompile(`^\[(\d{1,3}):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+	// lrcOffset matches the [offset:+/-ms] header tag.
+	lrcOffset = regexp.MustCompile(`^\[offset:\s*([+-]?\d+)\s*\]$`)
+	// lrcTag matches other header tags such as [ar:Artist].
+	lrcTag = regexp.MustCompile(`^\[[A-Za-z#]+:.*\]$`)
+)
+
+// ParseLRC parses LRC lyrics. Lines may carry several timestamps, and an
+// [offset:ms] tag shifts them all earlier (positive) or later. Text without
+// any timestamps is returned as unsynced lyrics, line for line.
+func ParseLRC(text string) Lyrics {
+	text = strings.ReplaceAll(strings.TrimPrefix(text, "\ufeff"), "\r\n", "\n")
+	var synced, plain []LyricLine
+	var offset time.Duration
+	for _, raw := range strings.Split(strings.ReplaceAll(text, "\r", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		if m := lrcOffset.FindStringSubmatch(line); m != nil {
+			ms, _ := strconv.Atoi(m[1])
+			offset = time.Duration(ms) * time.Millisecond
+			continue
+		}
+		var stamps []time.Duration
+		for {
+			m := lrcTimestamp.FindStringSubmatch(line)
+			if m == nil {
+				break
+			}
+			stamps = append(stamps, lrcTime(m[1], m[2], m[3]))
+			line = strings.TrimSpace(line[len(m[0]):])
+		}
+		if len(stamps) == 0 {
+			if !lrcTag.MatchString(line) {
+				plain = append(plain, LyricLine{Text: strings.TrimRightFunc(raw, isSpaceOrCR)})
+			}
+			continue
+		}
+		for _, at := range stamps {
+			synced = append(synced, LyricLine{At: at, Text: line})
+		}
+	}
+	if len(synced) > 0 {
+		for i := range synced {
+			synced[i].At = max(synced[i].At-offset, 0)
+		}
+		slices.SortStableFunc(synced, func(a, b LyricLine) int { return cmp.Compare(a.At, b.At) })
+		return Lyrics{Lines: synced, Synced: true}
+	}
+	return Lyrics{Lines: trimBlankLines(plain)}
+}
+
+func lrcTime(mm, ss, frac string) time.Duration {
+	m, _ := strconv.Atoi(mm)
+	s, _ := strconv.Atoi(ss)
+	d := time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	if frac != "" {
+		f, _ := strconv.Atoi(frac)
+		for n := len(frac); n < 3; n++ {
+			f *= 10 // .5 and .50 are both half a second
+		}
+		d += time.Duration(f) * time.Millisecond
+	}
+	return d
+}
+
+func isSpaceOrCR(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r'
+}
+
+// trimBlankLines drops blank lines at the start and end.
+func trimBlankLines(lines []LyricLine) []LyricLine {
+	for len(lines) > 0 && strings.TrimSpace(lines[0].Text) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1].Text) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// readLRCFile parses the <name>.lrc file next to path, if there is one.
+func readLRCFile(path string) Lyrics {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, ext := range []string{".lrc", ".LRC"} {
+		data, err := os.ReadFile(base + ext)
+		if err == nil {
+			return ParseLRC(string(data))
+		}
+	}
+	return Lyrics{}
+}
+
+// parseSYLT decodes the body of an ID3v2 SYLT (synchronised lyrics) frame:
+// text encoding, language, timestamp format, content type, a descriptor,
+// then text and 32-bit timestamp pairs. Only millisecond timestamps are
+// supported; MPEG frame counts depend on the stream.
+func parseSYLT(body []byte) ([]LyricLine, bool) {
+	if len(body) < 6 || body[4] != 2 {
+		return nil, false
+	}
+	enc := body[0]
+	b := body[6:]
+	if _, rest, ok := cutID3Text(b, enc); ok {
+		b = rest // content descriptor
+	} else {
+		return nil, false
+	}
+	var lines []LyricLine
+	for len(b) > 0 {
+		text, rest, ok := cutID3Text(b, enc)
+		if !ok || len(rest) < 4 {
+			break
+		}
+		ms := binary.BigEndian.Uint32(rest)
+		b = rest[4:]
+		// Entries often start with a newline marking a new line of text.
+		text = strings.TrimLeft(text, "\r\n")
+		lines = append(lines, LyricLine{At: time.Duration(ms) * time.Millisecond, Text: strings.TrimSpace(text)})
+	}
+	if len(lines) == 0 {
+		return nil, false
+	}
+	slices.SortStableFunc(lines, func(a, b LyricLine) int { return cmp.Compare(a.At, b.At) })
+	return lines, true
+}
+
+// cutID3Text splits a terminated string in ID3 text encoding enc off the
+// front of b: 0 is ISO-8859-1, 1 UTF-16 with BOM, 2 UTF-16BE, 3 UTF-8.
+func cutID3Text(b []byte, enc byte) (string, []byte, bool) {
+	switch enc {
+	case 0, 3:
+		i := bytes.IndexByte(b, 0)
+		if i < 0 {
+			return "", nil, false
+		}
+		if enc == 3 {
+			return string(b[:i]), b[i+1:], true
+		}
+		runes := make([]rune, i)
+		for j, c := range b[:i] {
+			runes[j] = rune(c)
+		}
+		return string(runes), b[i+1:], true
+	case 1, 2:
+		end := -1
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return "", nil, false
+		}
+		u := b[:end]
+		order := binary.ByteOrder(binary.BigEndian)
+		if enc == 1 && len(u) >= 2 {
+			if u[0] == 0xFF && u[1] == 0xFE {
+				order = binary.LittleEndian
+			}
+			if (u[0] == 0xFF && u[1] == 0xFE) || (u[0] == 0xFE && u[1] == 0xFF) {
+				u = u[2:]
+			}
+		}
+		units := make([]uint16, len(u)/2)
+		for i := range units {
+			units[i] = order.Uint16(u[2*i:])
+		}
+		return string(utf16.Decode(units)), b[end+2:], true
+	}
+	return "", nil, false
+}
@@ -0,0 +1,112 @@
+package player
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseLRCSynced(t *testing.T) {
+	l := ParseLRC("\ufeff[ar:Someone]\r\n[offset:500]\r\n[00:12.50]First\r\n[00:05][01:00.1]Chorus\r\n[00:20.123]\r\n")
+	if !l.Synced {
+		t.Fatal("timestamped lyrics should be synced")
+	}
+	want := []LyricLine{
+		{At: 4500 * time.Millisecond, Text: "Chorus"},
+		{At: 12 * time.Second, Text: "First"},
+		{At: 19623 * time.Millisecond, Text: ""},
+		{At: 59600 * time.Millisecond, Text: "Chorus"},
+	}
+	if len(l.Lines) != len(want) {
+		t.Fatalf("got %d lines %v, want %v", len(l.Lines), l.Lines, want)
+	}
+	for i := range want {
+		if l.Lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, l.Lines[i], want[i])
+		}
+	}
+
+	for _, tc := range []struct {
+		pos  time.Duration
+		want int
+	}{
+		{0, -1},
+		{4500 * time.Millisecond, 0},
+		{11 * time.Second, 0},
+		{12 * time.Second, 1},
+		{2 * time.Minute, 3},
+	} {
+		if got := l.LineAt(tc.pos); got != tc.want {
+			t.Errorf("LineAt(%v) = %d, want %d", tc.pos, got, tc.want)
+		}
+	}
+}
+
+func TestParseLRCUnsynced(t *testing.T) {
+	l := ParseLRC("\n\nFirst line\n\nSecond line  \n\n")
+	if l.Synced {
+		t.Fatal("plain text should not be synced")
+	}
+	want := []string{"First line", "", "Second line"}
+	if len(l.Lines) != len(want) {
+		t.Fatalf("got %v, want %v", l.Lines, want)
+	}
+	for i, text := range want {
+		if l.Lines[i].Text != text {
+			t.Errorf("line %d = %q, want %q", i, l.Lines[i].Text, text)
+		}
+	}
+	if l.LineAt(time.Minute) != -1 {
+		t.Error("LineAt on unsynced lyrics should be -1")
+	}
+	if !ParseLRC("[ti:Only tags]\n").Empty() {
+		t.Error("header tags alone should give no lyrics")
+	}
+}
+
+func TestParseSYLT(t *testing.T) {
+	body := []byte{3, 'e', 'n', 'g', 2, 1}
+	body = append(body, "desc\x00"...)
+	body = append(body, "\nSecond\x00"...)
+	body = append(body, 0, 0, 0x07, 0xD0) // 2000 ms
+	body = append(body, "First\x00"...)
+	body = append(body, 0, 0, 0x03, 0xE8) // 1000 ms
+
+	lines, ok := parseSYLT(body)
+	if !ok {
+		t.Fatal("parseSYLT() failed")
+	}
+	want := []LyricLine{{At: time.Second, Text: "First"}, {At: 2 * time.Second, Text: "Second"}}
+	if len(lines) != 2 || lines[0] != want[0] || lines[1] != want[1] {
+		t.Fatalf("parseSYLT() = %v, want %v", lines, want)
+	}
+
+	body[4] = 1 // MPEG frame timestamps
+	if _, ok := parseSYLT(body); ok {
+		t.Error("frame-count timestamps should be rejected")
+	}
+}
+
+func TestCutID3TextUTF16(t *testing.T) {
+	b := []byte{0xFF, 0xFE, 'h', 0, 0xE9, 0, 0, 0, 'x'}
+	s, rest, ok := cutID3Text(b, 1)
+	if !ok || s != "hé" || string(rest) != "x" {
+		t.Fatalf("cutID3Text() = %q, %q, %v", s, rest, ok)
+	}
+}
+
+func TestReadLRCFile(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "song.mp3")
+	if !readLRCFile(audio).Empty() {
+		t.Fatal("no .lrc file should give no lyrics")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "song.lrc"), []byte("[00:01.00]Hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l := readLRCFile(audio)
+	if !l.Synced || len(l.Lines) != 1 || l.Lines[0].Text != "Hello" {
+		t.Fatalf("readLRCFile() = %+v", l)
+	}
+}
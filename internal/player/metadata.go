@@ -1,35 +1,71 @@
 package player
 
 import (
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/bogem/id3v2/v2"
+	"github.com/jfreymuth/oggvorbis"
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/meta"
 )
 
 // Metadata holds song information.
 type Metadata struct {
-	Title  string
-	Artist string
-	Album  string
+	Title      string
+	Artist     string
+	Album      string
+	ReplayGain ReplayGain
+	Chapters   []Chapter
+	Artwork    []byte // embedded cover image, else cover.jpg or the like beside the file; nil if none
+	Lyrics     Lyrics
 }
 
 // ReadMetadata reads tags from an audio file, falling back to filename.
-// ID3v2 tags are only read for MP3 files.
+// ID3v2 tags are only read for MP3 files; ReplayGain tags are also read
+// from FLAC, Ogg Vorbis, and MP4 files, chapters from MP4 files, and cover
+// art and lyrics from MP3, FLAC, and Ogg Vorbis files. A sibling .lrc file
+// takes precedence over embedded lyrics.
 func ReadMetadata(path string) Metadata {
 	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".oga" {
+		ext = ".ogg"
+	}
+	var rg ReplayGain
+	var art []byte
+	var lyrics Lyrics
 	if ext == ".mp3" {
 		tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
 		if err == nil {
 			defer tag.Close()
 			m := Metadata{
-				Title:  strings.TrimSpace(tag.Title()),
-				Artist: strings.TrimSpace(tag.Artist()),
-				Album:  strings.TrimSpace(tag.Album()),
+				Title:      strings.TrimSpace(tag.Title()),
+				Artist:     strings.TrimSpace(tag.Artist()),
+				Album:      strings.TrimSpace(tag.Album()),
+				ReplayGain: replayGainFromID3(tag),
+				Artwork:    artworkFromID3(tag),
+				Lyrics:     readLRCFile(path),
+			}
+			if m.Lyrics.Empty() {
+				m.Lyrics = lyricsFromID3(tag)
+			}
+			if m.Artwork == nil {
+				m.Artwork = readSidecarArtwork(path)
 			}
 			if m.Title != "" {
 				return m
 			}
+			rg, art, lyrics = m.ReplayGain, m.Artwork, m.Lyrics
+		}
+	} else {
+		rg = readReplayGain(path, ext)
+		art = readArtwork(path, ext)
+		lyrics = readLyrics(path, ext)
+		if art == nil {
+			art = readSidecarArtwork(path)
 		}
 	}
 
@@ -38,6 +74,137 @@ func ReadMetadata(path string) Metadata {
 	name := strings.TrimSuffix(base, filepath.Ext(base))
 
 	return Metadata{
-		Title: name,
+		Title:      name,
+		ReplayGain: rg,
+		Chapters:   readChapters(path, ext),
+		Artwork:    art,
+		Lyrics:     lyrics,
+	}
+}
+
+func artworkFromID3(tag *id3v2.Tag) []byte {
+	var pics []picture
+	for _, f := range tag.GetFrames(tag.CommonID("Attached picture")) {
+		if pf, ok := f.(id3v2.PictureFrame); ok {
+			pics = append(pics, picture{kind: uint32(pf.PictureType), data: pf.Picture})
+		}
+	}
+	return pickArtwork(pics)
+}
+
+// lyricsFromID3 returns synced lyrics from a SYLT frame, falling back to
+// USLT text (which is itself parsed as LRC, since some taggers store it so).
+func lyricsFromID3(tag *id3v2.Tag) Lyrics {
+	for _, f := range tag.GetFrames("SYLT") {
+		if uf, ok := f.(id3v2.UnknownFrame); ok {
+			if lines, ok := parseSYLT(uf.Body); ok {
+				return Lyrics{Lines: lines, Synced: true}
+			}
+		}
+	}
+	for _, f := range tag.GetFrames(tag.CommonID("Unsynchronised lyrics/text transcription")) {
+		if lf, ok := f.(id3v2.UnsynchronisedLyricsFrame); ok && strings.TrimSpace(lf.Lyrics) != "" {
+			return ParseLRC(lf.Lyrics)
+		}
+	}
+	return Lyrics{}
+}
+
+// readLyrics reads a sibling .lrc file, then the LYRICS or UNSYNCEDLYRICS
+// comment of FLAC and Ogg Vorbis files.
+func readLyrics(path, ext string) Lyrics {
+	if l := readLRCFile(path); !l.Empty() {
+		return l
+	}
+	if ext != ".flac" && ext != ".ogg" {
+		return Lyrics{}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Lyrics{}
+	}
+	defer f.Close()
+
+	var comments []string
+	if ext == ".flac" {
+		stream, _ := flac.Parse(f)
+		if stream == nil {
+			return Lyrics{}
+		}
+		for _, block := range stream.Blocks {
+			if vc, ok := block.Body.(*meta.VorbisComment); ok {
+				for _, t := range vc.Tags {
+					comments = append(comments, t[0]+"="+t[1])
+				}
+			}
+		}
+	} else {
+		r, err := oggvorbis.NewReader(f)
+		if err != nil {
+			return Lyrics{}
+		}
+		comments = r.CommentHeader().Comments
+	}
+	for _, c := range comments {
+		key, value, ok := strings.Cut(c, "=")
+		if ok && (strings.EqualFold(key, "LYRICS") || strings.EqualFold(key, "UNSYNCEDLYRICS")) && strings.TrimSpace(value) != "" {
+			return ParseLRC(value)
+		}
+	}
+	return Lyrics{}
+}
+
+// readArtwork reads embedded pictures from FLAC picture blocks and Ogg Vorbis
+// METADATA_BLOCK_PICTURE comments.
+func readArtwork(path, ext string) []byte {
+	if ext != ".flac" && ext != ".ogg" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var pics []picture
+	if ext == ".flac" {
+		stream, _ := flac.Parse(f)
+		if stream == nil {
+			return nil
+		}
+		for _, block := range stream.Blocks {
+			if p, ok := block.Body.(*meta.Picture); ok && p.MIME != "-->" {
+				pics = append(pics, picture{kind: p.Type, data: p.Data})
+			}
+		}
+		return pickArtwork(pics)
+	}
+	r, err := oggvorbis.NewReader(f)
+	if err != nil {
+		return nil
+	}
+	for _, c := range r.CommentHeader().Comments {
+		key, value, ok := strings.Cut(c, "=")
+		if !ok || !strings.EqualFold(key, "METADATA_BLOCK_PICTURE") {
+			continue
+		}
+		if p, ok := vorbisPicture(value); ok {
+			pics = append(pics, p)
+		}
+	}
+	return pickArtwork(pics)
+}
+
+// ProbeDuration decodes the header of a local file to report its length
+// without opening an audio device.
+func ProbeDuration(path string) (time.Duration, error) {
+	f, dec, err := openDecoder(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if c, ok := dec.(io.Closer); ok {
+		defer c.Close()
 	}
+	return durationFor(dec.Length(), dec.SampleRate()*dec.ChannelCount()*2), nil
 }
@@ -0,0 +1,84 @@
+package player
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	// meterWindowSamples covers about 85ms of stereo audio at 48 kHz, the
+	// most recent audio kept in the sample ring buffer.
+	meterWindowSamples = 8192
+	// minPeakDBFS is reported for silence, the floor of 16-bit PCM.
+	minPeakDBFS = -96.0
+)
+
+// countClips counts 16-bit samples at full scale in interleaved PCM.
+func countClips(pcm []byte) int {
+	n := 0
+	for i := 0; i+1 < len(pcm); i += 2 {
+		s := int16(binary.LittleEndian.Uint16(pcm[i:]))
+		if s == math.MaxInt16 || s == math.MinInt16 {
+			n++
+		}
+	}
+	return n
+}
+
+// peakDBFS returns the peak level of samples in dBFS, floored at minPeakDBFS.
+func peakDBFS(samples []int16) float64 {
+	peak := 0
+	for _, s := range samples {
+		v := int(s)
+		if v < 0 {
+			v = -v
+		}
+		peak = max(peak, v)
+	}
+	if peak == 0 {
+		return minPeakDBFS
+	}
+	return max(20*math.Log10(float64(peak)/32768), minPeakDBFS)
+}
+
+// PeakDBFS returns the peak level of the audio most recently read, taken from
+// the same sample ring buffer the visualizers use.
+func (p *Player) PeakDBFS() float64 {
+	return peakDBFS(p.Samples(meterWindowSamples))
+}
+
+// ClipCount returns how many full-scale samples the current track has
+// produced since it started or was last seeked.
+func (p *Player) ClipCount() int {
+	if p == nil || p.counter == nil {
+		return 0
+	}
+	p.counter.mu.Lock()
+	defer p.counter.mu.Unlock()
+	return p.counter.clips
+}
+
+// channelLevels returns the RMS level of each channel of interleaved stereo
+// samples as a fraction of full scale.
+func channelLevels(samples []int16) (left, right float64) {
+	var l, r float64
+	n := 0
+	for i := 0; i+1 < len(samples); i += 2 {
+		lv := float64(samples[i]) / 32768
+		rv := float64(samples[i+1]) / 32768
+		l += lv * lv
+		r += rv * rv
+		n++
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return math.Sqrt(l / float64(n)), math.Sqrt(r / float64(n))
+}
+
+// ChannelLevels returns the RMS level of the left and right channels of the
+// audio most recently read, from 0 to 1. Mono sources, which are decoded to
+// identical channels, report equal levels.
+func (p *Player) ChannelLevels() (left, right float64) {
+	return channelLevels(p.Samples(meterWindowSamples))
+}
@@ -0,0 +1,212 @@
+package player
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// go-mp3 sizes a file by counting every frame sync it finds, so junk and
+// trailing tags that happen to look like frames make tracks end early on
+// the progress bar. mp3Length works the length out from the Xing, Info, or
+// VBRI header in the first frame instead, or, without one, from a scan that
+// only trusts frames that follow on from each other.
+
+// mp3BytesPerSample is go-mp3's output frame size: 16-bit stereo.
+const mp3BytesPerSample = 4
+
+// mp3LengthCacheSize bounds the cache; it is cleared when full.
+const mp3LengthCacheSize = 256
+
+type mp3LengthKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+var (
+	mp3LengthMu    sync.Mutex
+	mp3LengthCache = map[mp3LengthKey]int64{}
+)
+
+// mp3Length returns the decoded length of f in go-mp3 output bytes. Results
+// are cached by path, size, and modification time, since the same file is
+// opened again for seeks, previews, and the waveform scan.
+func mp3Length(f *os.File) (int64, bool) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, false
+	}
+	key := mp3LengthKey{path: f.Name(), size: info.Size(), modTime: info.ModTime()}
+	mp3LengthMu.Lock()
+	n, ok := mp3LengthCache[key]
+	mp3LengthMu.Unlock()
+	if ok {
+		return n, true
+	}
+
+	n, ok = measureMP3(io.NewSectionReader(f, 0, info.Size()), info.Size())
+	if !ok {
+		return 0, false
+	}
+	mp3LengthMu.Lock()
+	if len(mp3LengthCache) >= mp3LengthCacheSize {
+		clear(mp3LengthCache)
+	}
+	mp3LengthCache[key] = n
+	mp3LengthMu.Unlock()
+	return n, true
+}
+
+// measureMP3 reads the frame count from a VBR header when there is one and
+// the file is not cut short of it, and scans the frames otherwise.
+func measureMP3(r io.ReaderAt, size int64) (int64, bool) {
+	start := id3v2Size(r)
+	if frames, samples, ok := readMP3FrameCount(r, start, size); ok {
+		// go-mp3 decodes the header frame itself as a frame of silence.
+		return (frames + 1) * int64(samples) * mp3BytesPerSample, true
+	}
+	samples := scanMP3Frames(io.NewSectionReader(r, start, size-start))
+	if samples == 0 {
+		return 0, false
+	}
+	return samples * mp3BytesPerSample, true
+}
+
+// id3v2Size returns the size of the ID3v2 tag at the start of r, or 0.
+func id3v2Size(r io.ReaderAt) int64 {
+	var h [10]byte
+	if _, err := r.ReadAt(h[:], 0); err != nil || string(h[:3]) != "ID3" {
+		return 0
+	}
+	size := int64(h[6]&0x7F)<<21 | int64(h[7]&0x7F)<<14 | int64(h[8]&0x7F)<<7 | int64(h[9]&0x7F)
+	size += 10
+	if h[5]&0x10 != 0 {
+		size += 10 // footer
+	}
+	return size
+}
+
+// mp3Frame describes one MPEG audio layer III frame header.
+type mp3Frame struct {
+	size     int // bytes, header included
+	samples  int // per channel
+	sideInfo int // bytes of side information after the header
+}
+
+var (
+	mp3Bitrates1 = [15]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3Bitrates2 = [15]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mp3Rates     = [3]int{44100, 48000, 32000}
+)
+
+// parseMP3Header decodes a layer III frame header. Free-format frames have
+// no size in the header and are rejected.
+func parseMP3Header(b []byte) (mp3Frame, bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	version := b[1] >> 3 & 3 // 0 is MPEG 2.5, 2 MPEG 2, 3 MPEG 1
+	layer := b[1] >> 1 & 3   // 1 is layer III
+	bitrate := b[2] >> 4
+	rate := b[2] >> 2 & 3
+	if version == 1 || layer != 1 || bitrate == 0 || bitrate == 15 || rate == 3 || b[3]&3 == 2 {
+		return mp3Frame{}, false
+	}
+	padding := int(b[2] >> 1 & 1)
+	mono := b[3]>>6 == 3
+	hz := mp3Rates[rate]
+	if version == 3 {
+		f := mp3Frame{size: 144*mp3Bitrates1[bitrate]*1000/hz + padding, samples: 1152, sideInfo: 32}
+		if mono {
+			f.sideInfo = 17
+		}
+		return f, true
+	}
+	if version == 2 {
+		hz /= 2
+	} else {
+		hz /= 4
+	}
+	f := mp3Frame{size: 72*mp3Bitrates2[bitrate]*1000/hz + padding, samples: 576, sideInfo: 17}
+	if mono {
+		f.sideInfo = 9
+	}
+	return f, true
+}
+
+// readMP3FrameCount reads the frame count from a Xing/Info or VBRI header in
+// the first frame at start. The count is not trusted when the header's byte
+// count says the file has been cut short.
+func readMP3FrameCount(r io.ReaderAt, start, size int64) (frames int64, samples int, ok bool) {
+	buf := make([]byte, 4+32+26)
+	if n, _ := r.ReadAt(buf, start); n < len(buf) {
+		return 0, 0, false
+	}
+	h, ok := parseMP3Header(buf)
+	if !ok {
+		return 0, 0, false
+	}
+
+	var claimed int64
+	switch x := buf[4+h.sideInfo:]; {
+	case string(x[:4]) == "Xing" || string(x[:4]) == "Info":
+		flags := binary.BigEndian.Uint32(x[4:])
+		if flags&1 == 0 {
+			return 0, 0, false
+		}
+		frames = int64(binary.BigEndian.Uint32(x[8:]))
+		if flags&2 != 0 {
+			claimed = int64(binary.BigEndian.Uint32(x[12:]))
+		}
+	case string(buf[36:40]) == "VBRI":
+		claimed = int64(binary.BigEndian.Uint32(buf[46:]))
+		frames = int64(binary.BigEndian.Uint32(buf[50:]))
+	default:
+		return 0, 0, false
+	}
+	if frames == 0 || claimed > size-start {
+		return 0, 0, false
+	}
+	return frames, h.samples, true
+}
+
+// scanMP3Frames counts the samples per channel in the frames of r. After
+// losing sync it only accepts a header that is followed by another, and it
+// stops at a trailing ID3v1, APE, or Lyrics3 tag.
+func scanMP3Frames(r io.Reader) int64 {
+	br := bufio.NewReaderSize(r, 8192)
+	var samples int64
+	synced := false
+	for {
+		head, _ := br.Peek(8)
+		if len(head) < 4 {
+			return samples
+		}
+		h, ok := parseMP3Header(head)
+		if ok && !synced {
+			next, _ := br.Peek(h.size + 4)
+			if len(next) == h.size+4 {
+				_, ok = parseMP3Header(next[h.size:])
+			}
+		}
+		if !ok {
+			if string(head[:3]) == "TAG" || string(head) == "APETAGEX" || string(head) == "LYRICSBE" {
+				return samples
+			}
+			synced = false
+			if _, err := br.Discard(1); err != nil {
+				return samples
+			}
+			continue
+		}
+		if n, _ := br.Discard(h.size); n < h.size {
+			return samples // a cut-off last frame does not decode
+		}
+		synced = true
+		samples += int64(h.samples)
+	}
+}
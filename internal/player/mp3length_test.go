@@ -0,0 +1,142 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mp3FrameSize is the size of a 128 kbps, 44.1 kHz MPEG-1 layer III frame.
+const mp3FrameSize = 417
+
+// fakeMP3Frame returns a stereo 128 kbps frame with an empty body.
+func fakeMP3Frame() []byte {
+	f := make([]byte, mp3FrameSize)
+	copy(f, []byte{0xFF, 0xFB, 0x90, 0x00})
+	return f
+}
+
+// xingFrame returns a frame carrying a Xing header with the given flags.
+func xingFrame(tag string, frames, bytes uint32, flags uint32) []byte {
+	f := fakeMP3Frame()
+	x := f[4+32:]
+	copy(x, tag)
+	binary.BigEndian.PutUint32(x[4:], flags)
+	binary.BigEndian.PutUint32(x[8:], frames)
+	binary.BigEndian.PutUint32(x[12:], bytes)
+	return f
+}
+
+func TestParseMP3Header(t *testing.T) {
+	h, ok := parseMP3Header([]byte{0xFF, 0xFB, 0x90, 0x00})
+	if !ok || h.size != mp3FrameSize || h.samples != 1152 || h.sideInfo != 32 {
+		t.Fatalf("MPEG-1 header = %+v, %v", h, ok)
+	}
+	// MPEG-2, 64 kbps, 22.05 kHz, padded, mono.
+	h, ok = parseMP3Header([]byte{0xFF, 0xF3, 0x82, 0xC0})
+	if !ok || h.size != 72*64000/22050+1 || h.samples != 576 || h.sideInfo != 9 {
+		t.Fatalf("MPEG-2 header = %+v, %v", h, ok)
+	}
+	for _, b := range [][]byte{
+		{0xFF, 0xFD, 0x90, 0x00}, // layer I
+		{0xFF, 0xFB, 0x00, 0x00}, // free format
+		{0xFF, 0xFB, 0x9C, 0x00}, // reserved sample rate
+		{0x54, 0x41, 0x47, 0x00}, // "TAG"
+	} {
+		if _, ok := parseMP3Header(b); ok {
+			t.Fatalf("parseMP3Header(% X) accepted an invalid header", b)
+		}
+	}
+}
+
+func TestMeasureMP3ScanSkipsJunkAndTags(t *testing.T) {
+	var file bytes.Buffer
+	file.Write([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 20})
+	file.Write(make([]byte, 20))
+	for range 4 {
+		file.Write(fakeMP3Frame())
+	}
+	// A stray sync in junk is not followed by another frame.
+	file.Write([]byte{0x00, 0xFF, 0xFB, 0x90, 0x00, 0x12, 0x34})
+	for range 6 {
+		file.Write(fakeMP3Frame())
+	}
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[40:], []byte{0xFF, 0xFB, 0x90, 0x00})
+	file.Write(tag)
+
+	r := bytes.NewReader(file.Bytes())
+	got, ok := measureMP3(r, r.Size())
+	if want := int64(10 * 1152 * mp3BytesPerSample); !ok || got != want {
+		t.Fatalf("measureMP3() = %d, %v, want %d", got, ok, want)
+	}
+}
+
+func TestMeasureMP3UsesVBRHeaders(t *testing.T) {
+	perFrame := int64(1152 * mp3BytesPerSample)
+
+	xing := append(xingFrame("Xing", 100, 0, 1), fakeMP3Frame()...)
+	r := bytes.NewReader(xing)
+	if got, ok := measureMP3(r, r.Size()); !ok || got != 101*perFrame {
+		t.Fatalf("Xing: measureMP3() = %d, %v, want %d", got, ok, 101*perFrame)
+	}
+
+	info := append(xingFrame("Info", 7, 2*mp3FrameSize, 3), fakeMP3Frame()...)
+	r = bytes.NewReader(info)
+	if got, ok := measureMP3(r, r.Size()); !ok || got != 8*perFrame {
+		t.Fatalf("Info: measureMP3() = %d, %v, want %d", got, ok, 8*perFrame)
+	}
+
+	vbri := fakeMP3Frame()
+	copy(vbri[36:], "VBRI")
+	binary.BigEndian.PutUint32(vbri[46:], 2*mp3FrameSize)
+	binary.BigEndian.PutUint32(vbri[50:], 50)
+	vbri = append(vbri, fakeMP3Frame()...)
+	r = bytes.NewReader(vbri)
+	if got, ok := measureMP3(r, r.Size()); !ok || got != 51*perFrame {
+		t.Fatalf("VBRI: measureMP3() = %d, %v, want %d", got, ok, 51*perFrame)
+	}
+}
+
+func TestMeasureMP3ScansTruncatedVBRFile(t *testing.T) {
+	// The header claims far more bytes than the file holds, so the frames
+	// that are there are counted instead.
+	file := xingFrame("Xing", 1000, 1000*mp3FrameSize, 3)
+	for range 2 {
+		file = append(file, fakeMP3Frame()...)
+	}
+	r := bytes.NewReader(file)
+	if got, ok := measureMP3(r, r.Size()); !ok || got != 3*1152*mp3BytesPerSample {
+		t.Fatalf("measureMP3() = %d, %v, want %d", got, ok, 3*1152*mp3BytesPerSample)
+	}
+}
+
+func TestMP3LengthCachesByFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.mp3")
+	if err := os.WriteFile(path, append(fakeMP3Frame(), fakeMP3Frame()...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := int64(2 * 1152 * mp3BytesPerSample)
+	if got, ok := mp3Length(f); !ok || got != want {
+		t.Fatalf("mp3Length() = %d, %v, want %d", got, ok, want)
+	}
+	info, _ := f.Stat()
+	mp3LengthMu.Lock()
+	cached, ok := mp3LengthCache[mp3LengthKey{path: path, size: info.Size(), modTime: info.ModTime()}]
+	mp3LengthMu.Unlock()
+	if !ok || cached != want {
+		t.Fatalf("cache = %d, %v, want %d", cached, ok, want)
+	}
+	if pos, _ := f.Seek(0, 1); pos != 0 {
+		t.Fatalf("mp3Length moved the file offset to %d", pos)
+	}
+}
@@ -14,13 +14,15 @@ const (
 )
 
 // normalizedDecoder wraps a seekable PCM decoder and presents a fixed
-// 48 kHz stereo s16le stream to the player.
+// 48 kHz stereo s16le stream to the player. Headless decoding can pick
+// another output rate with newNormalizedDecoderAt.
 type normalizedDecoder struct {
 	src          audioDecoder
 	passthrough  bool
 	length       int64
 	pos          int64
 	srcRate      int
+	outRate      int
 	srcChannels  int
 	srcFrameSize int
 
@@ -37,9 +39,17 @@ type normalizedDecoder struct {
 	srcBaseFrame int64
 	lastFrame    [playbackChannels]int16
 	haveLast     bool
+
+	sinc *sincKernel // nil when resampling linearly
 }
 
 func newNormalizedDecoder(src audioDecoder) (audioDecoder, error) {
+	return newNormalizedDecoderAt(src, playbackSampleRate)
+}
+
+// newNormalizedDecoderAt is newNormalizedDecoder with a stereo output at
+// outRate instead of 48 kHz.
+func newNormalizedDecoderAt(src audioDecoder, outRate int) (audioDecoder, error) {
 	sampleRate := src.SampleRate()
 	if sampleRate <= 0 {
 		return nil, fmt.Errorf("unsupported sample rate: %d", sampleRate)
@@ -52,16 +62,17 @@ func newNormalizedDecoder(src audioDecoder) (audioDecoder, error) {
 
 	srcFrameSize := channels * playbackBytesPerSample
 	totalSrcFrames := src.Length() / int64(srcFrameSize)
-	totalOutFrames := totalSrcFrames * playbackSampleRate / int64(sampleRate)
+	totalOutFrames := totalSrcFrames * int64(outRate) / int64(sampleRate)
 	if totalSrcFrames > 0 && totalOutFrames == 0 {
 		totalOutFrames = 1
 	}
 
 	d := &normalizedDecoder{
 		src:            src,
-		passthrough:    sampleRate == playbackSampleRate && channels == playbackChannels,
+		passthrough:    sampleRate == outRate && channels == playbackChannels,
 		length:         totalOutFrames * playbackFrameSize,
 		srcRate:        sampleRate,
+		outRate:        outRate,
 		srcChannels:    channels,
 		srcFrameSize:   srcFrameSize,
 		totalSrcFrames: totalSrcFrames,
@@ -71,13 +82,24 @@ func newNormalizedDecoder(src audioDecoder) (audioDecoder, error) {
 		d.length = src.Length()
 		d.totalOutFrames = d.length / playbackFrameSize
 	}
+	if sampleRate != outRate && ResampleQuality(resampleQuality.Load()) == ResampleSinc {
+		d.sinc = newSincKernel(sampleRate, outRate)
+	}
 	return d, nil
 }
 
 func (d *normalizedDecoder) Length() int64     { return d.length }
-func (d *normalizedDecoder) SampleRate() int   { return playbackSampleRate }
+func (d *normalizedDecoder) SampleRate() int   { return d.outRate }
 func (d *normalizedDecoder) ChannelCount() int { return playbackChannels }
 
+// Close releases the wrapped decoder's resources, if it holds any.
+func (d *normalizedDecoder) Close() error {
+	if c, ok := d.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 func (d *normalizedDecoder) Read(p []byte) (int, error) {
 	if d.passthrough {
 		n, err := d.src.Read(p)
@@ -157,8 +179,13 @@ func (d *normalizedDecoder) Seek(offset int64, whence int) (int64, error) {
 	}
 
 	outFrame := newPos / playbackFrameSize
-	srcFrame := outFrame * int64(d.srcRate) / playbackSampleRate
-	srcBytePos := srcFrame * int64(d.srcFrameSize)
+	srcFrame := outFrame * int64(d.srcRate) / int64(d.outRate)
+	baseFrame := srcFrame
+	if d.sinc != nil {
+		// Reload the frames behind the seek point that the filter reads.
+		baseFrame = max(srcFrame-int64(d.sinc.half)+1, 0)
+	}
+	srcBytePos := baseFrame * int64(d.srcFrameSize)
 	if _, err := d.src.Seek(srcBytePos, io.SeekStart); err != nil {
 		return d.pos, err
 	}
@@ -168,7 +195,7 @@ func (d *normalizedDecoder) Seek(offset int64, whence int) (int64, error) {
 	d.outFramePos = outFrame
 	d.srcPosNum = outFrame * int64(d.srcRate)
 	d.srcFrames = d.srcFrames[:0]
-	d.srcBaseFrame = srcFrame
+	d.srcBaseFrame = baseFrame
 	d.haveLast = false
 	return newPos, nil
 }
@@ -182,34 +209,26 @@ func (d *normalizedDecoder) generateFrames(frameCount int) ([]byte, error) {
 
 	writtenFrames := 0
 	for writtenFrames < frameCount && d.outFramePos < d.totalOutFrames {
-		srcFrame := d.srcPosNum / playbackSampleRate
+		srcFrame := d.srcPosNum / int64(d.outRate)
 		if srcFrame >= d.totalSrcFrames {
 			break
 		}
 
-		if err := d.ensureFrameAvailable(srcFrame); err != nil {
-			return raw[:writtenFrames*playbackFrameSize], err
+		fracNum := d.srcPosNum % int64(d.outRate)
+		var left, right int16
+		var err error
+		if d.sinc != nil {
+			left, right, err = d.sincFrame(srcFrame, fracNum)
+		} else {
+			left, right, err = d.linearFrame(srcFrame, fracNum)
 		}
-
-		left0, right0, err := d.frameAt(srcFrame)
 		if err != nil {
 			return raw[:writtenFrames*playbackFrameSize], err
 		}
-		left1, right1 := left0, right0
-		if srcFrame+1 < d.totalSrcFrames {
-			if err := d.ensureFrameAvailable(srcFrame + 1); err != nil {
-				return raw[:writtenFrames*playbackFrameSize], err
-			}
-			left1, right1, err = d.frameAt(srcFrame + 1)
-			if err != nil {
-				return raw[:writtenFrames*playbackFrameSize], err
-			}
-		}
 
-		fracNum := d.srcPosNum % playbackSampleRate
 		outOffset := writtenFrames * playbackFrameSize
-		binary.LittleEndian.PutUint16(raw[outOffset:], uint16(interpolateSample(left0, left1, fracNum)))
-		binary.LittleEndian.PutUint16(raw[outOffset+2:], uint16(interpolateSample(right0, right1, fracNum)))
+		binary.LittleEndian.PutUint16(raw[outOffset:], uint16(left))
+		binary.LittleEndian.PutUint16(raw[outOffset+2:], uint16(right))
 
 		writtenFrames++
 		d.outFramePos++
@@ -222,12 +241,40 @@ func (d *normalizedDecoder) generateFrames(frameCount int) ([]byte, error) {
 	return raw[:writtenFrames*playbackFrameSize], nil
 }
 
+// linearFrame interpolates between srcFrame and the frame after it.
+func (d *normalizedDecoder) linearFrame(srcFrame, fracNum int64) (int16, int16, error) {
+	if err := d.ensureFrameAvailable(srcFrame); err != nil {
+		return 0, 0, err
+	}
+
+	left0, right0, err := d.frameAt(srcFrame)
+	if err != nil {
+		return 0, 0, err
+	}
+	left1, right1 := left0, right0
+	if srcFrame+1 < d.totalSrcFrames {
+		if err := d.ensureFrameAvailable(srcFrame + 1); err != nil {
+			return 0, 0, err
+		}
+		left1, right1, err = d.frameAt(srcFrame + 1)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	den := int64(d.outRate)
+	return interpolateSample(left0, left1, fracNum, den), interpolateSample(right0, right1, fracNum, den), nil
+}
+
 func (d *normalizedDecoder) ensureFrameAvailable(absFrame int64) error {
 	if absFrame >= d.totalSrcFrames {
 		return io.EOF
 	}
 	d.compactFrames(absFrame - 1)
+	return d.bufferThrough(absFrame)
+}
 
+// bufferThrough reads source frames until absFrame is buffered.
+func (d *normalizedDecoder) bufferThrough(absFrame int64) error {
 	for absFrame >= d.srcBaseFrame+int64(len(d.srcFrames))/playbackChannels {
 		if err := d.readMoreFrames(); err != nil {
 			return err
@@ -342,12 +389,13 @@ func (d *normalizedDecoder) frameAt(absFrame int64) (int16, int16, error) {
 	return d.srcFrames[offset], d.srcFrames[offset+1], nil
 }
 
-func interpolateSample(a, b int16, fracNum int64) int16 {
+// interpolateSample returns the sample fracNum/den of the way from a to b.
+func interpolateSample(a, b int16, fracNum, den int64) int16 {
 	if fracNum == 0 || a == b {
 		return a
 	}
 	diff := int64(int32(b) - int32(a))
-	return int16(int64(int32(a)) + (diff*fracNum+playbackSampleRate/2)/playbackSampleRate)
+	return int16(int64(int32(a)) + (diff*fracNum+den/2)/den)
 }
 
 func maxInt(a, b int) int {
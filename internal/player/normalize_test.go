@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+	"math"
 	"testing"
 )
 
@@ -127,6 +128,52 @@ func TestNormalizedDecoderResamplesAndSeeks(t *testing.T) {
 	}
 }
 
+func TestNormalizedDecoderResampleModesMatchFrameCount(t *testing.T) {
+	samples := make([]int16, 44100)
+	for i := range samples {
+		samples[i] = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/44100))
+	}
+	data := pcm16(samples...)
+
+	decode := func(q ResampleQuality) (audioDecoder, []byte) {
+		t.Helper()
+		SetResampleQuality(q)
+		defer SetResampleQuality(ResampleLinear)
+		dec, err := newNormalizedDecoder(&stubPCMDecoder{data: data, sampleRate: 44100, channels: 1})
+		if err != nil {
+			t.Fatalf("newNormalizedDecoder() error = %v", err)
+		}
+		out, err := io.ReadAll(dec)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		return dec, out
+	}
+
+	_, linear := decode(ResampleLinear)
+	dec, sinc := decode(ResampleSinc)
+	if len(linear) != len(sinc) {
+		t.Fatalf("sinc output = %d bytes, linear = %d", len(sinc), len(linear))
+	}
+	if want := 48000 * playbackFrameSize; len(sinc) != want {
+		t.Fatalf("output = %d bytes, want %d", len(sinc), want)
+	}
+
+	// Seeking reloads the filter history, so reading from the middle
+	// matches the continuous decode.
+	offset := int64(12345 * playbackFrameSize)
+	if _, err := dec.Seek(offset, io.SeekStart); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+	buf := make([]byte, 64*playbackFrameSize)
+	if _, err := io.ReadFull(dec, buf); err != nil {
+		t.Fatalf("ReadFull() after seek error = %v", err)
+	}
+	if want := sinc[offset : offset+int64(len(buf))]; !bytes.Equal(buf, want) {
+		t.Fatalf("seeked sinc PCM mismatch:\n got %v\nwant %v", buf[:16], want[:16])
+	}
+}
+
 func pcm16(samples ...int16) []byte {
 	out := make([]byte, len(samples)*2)
 	for i, sample := range samples {
@@ -0,0 +1,209 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// oggCodec is the codec an Ogg file carries, as named by the identification
+// packet that starts its first logical stream.
+type oggCodec int
+
+const (
+	oggUnknown oggCodec = iota
+	oggVorbis
+	oggFLAC
+	oggOpus
+)
+
+const (
+	oggPageHeaderSize = 27
+	// oggFLACPrefix is the Ogg FLAC mapping header ahead of the native
+	// "fLaC" signature in the first packet: 0x7F "FLAC", a two-byte
+	// mapping version, and a two-byte header packet count.
+	oggFLACPrefix = 9
+)
+
+// sniffOggCodec reads the first page of r and reports which codec its
+// identification packet belongs to. It reads with ReadAt, so the file
+// position is left alone.
+func sniffOggCodec(r io.ReaderAt) oggCodec {
+	buf := make([]byte, oggPageHeaderSize+255+8)
+	n, _ := r.ReadAt(buf, 0)
+	buf = buf[:n]
+	if n < oggPageHeaderSize || !bytes.HasPrefix(buf, []byte("OggS")) {
+		return oggUnknown
+	}
+	body := oggPageHeaderSize + int(buf[26])
+	if n < body {
+		return oggUnknown
+	}
+	packet := buf[body:]
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		return oggVorbis
+	case bytes.HasPrefix(packet, []byte("\x7fFLAC")):
+		return oggFLAC
+	case bytes.HasPrefix(packet, []byte("OpusHead")):
+		return oggOpus
+	}
+	return oggUnknown
+}
+
+// newOggDecoder picks the decoder for an Ogg file by its codec rather than
+// its extension: Vorbis natively, FLAC through the native FLAC decoder, and
+// Opus through ffmpeg like .opus files. Anything else is left to the Vorbis
+// reader, whose error names the problem.
+func newOggDecoder(f *os.File) (audioDecoder, error) {
+	switch sniffOggCodec(f) {
+	case oggFLAC:
+		return newOggFLACDecoder(f)
+	case oggOpus:
+		return newFFmpegDecoder(f)
+	}
+	return newOGGDecoder(f)
+}
+
+// oggFLACDecoder is the FLAC decoder reading through an oggFLACStream.
+type oggFLACDecoder struct {
+	*flacDecoder
+}
+
+func newOggFLACDecoder(f *os.File) (*oggFLACDecoder, error) {
+	s, err := newOggFLACStream(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding Ogg FLAC: %w", err)
+	}
+	d, err := newFLACDecoder(s)
+	if err != nil {
+		return nil, err
+	}
+	// Streamed encodes may leave the STREAMINFO sample count at 0; the last
+	// page's granule position is the same count.
+	if d.totalBytes == 0 && s.samples > 0 {
+		d.totalBytes = s.samples * int64(d.channels) * 2
+	}
+	return &oggFLACDecoder{d}, nil
+}
+
+func (d *oggFLACDecoder) sourceInfo() SourceInfo {
+	info := d.flacDecoder.sourceInfo()
+	info.Decoder = "ogg flac"
+	return info
+}
+
+// oggFLACStream presents the FLAC stream inside an Ogg FLAC file as a native
+// one, so the FLAC decoder and its seeking work unchanged. The mapping keeps
+// FLAC's own metadata blocks and frames as Ogg packets, so the native stream
+// is the page bodies of the first logical stream, back to back, without the
+// mapping prefix of the first packet. Pages are indexed up front from their
+// headers alone; reads then go straight to the file.
+type oggFLACStream struct {
+	r       io.ReaderAt
+	pages   []oggSpan
+	size    int64 // native stream length
+	pos     int64
+	samples int64 // highest granule position: samples per channel
+}
+
+// oggSpan is one page body: native stream offset off maps to file offset
+// file, for n bytes.
+type oggSpan struct {
+	off, file, n int64
+}
+
+func newOggFLACStream(r io.ReaderAt) (*oggFLACStream, error) {
+	s := &oggFLACStream{r: r}
+	var hdr [oggPageHeaderSize + 255]byte
+	var serial uint32
+	skip := int64(oggFLACPrefix)
+	for file := int64(0); ; {
+		n, err := r.ReadAt(hdr[:oggPageHeaderSize], file)
+		if n < oggPageHeaderSize {
+			// A truncated last page ends the stream, like a cut-off file.
+			if len(s.pages) > 0 && (err == io.EOF || n == 0) {
+				break
+			}
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if !bytes.HasPrefix(hdr[:], []byte("OggS")) {
+			return nil, fmt.Errorf("no Ogg page at offset %d", file)
+		}
+		segs := int(hdr[26])
+		if n, err := r.ReadAt(hdr[oggPageHeaderSize:oggPageHeaderSize+segs], file+oggPageHeaderSize); n < segs {
+			if len(s.pages) > 0 {
+				break
+			}
+			return nil, err
+		}
+		var bodyLen int64
+		for _, l := range hdr[oggPageHeaderSize : oggPageHeaderSize+segs] {
+			bodyLen += int64(l)
+		}
+		body := file + oggPageHeaderSize + int64(segs)
+		file = body + bodyLen
+
+		pageSerial := binary.LittleEndian.Uint32(hdr[14:18])
+		if skip > 0 {
+			serial = pageSerial
+		} else if pageSerial != serial {
+			continue // another multiplexed stream
+		}
+		if granule := int64(binary.LittleEndian.Uint64(hdr[6:14])); granule > s.samples {
+			s.samples = granule
+		}
+		if skip > 0 {
+			if bodyLen < skip {
+				return nil, errors.New("Ogg FLAC header packet too short")
+			}
+			body += skip
+			bodyLen -= skip
+			skip = 0
+		}
+		if bodyLen > 0 {
+			s.pages = append(s.pages, oggSpan{off: s.size, file: body, n: bodyLen})
+			s.size += bodyLen
+		}
+	}
+	return s, nil
+}
+
+func (s *oggFLACStream) Read(p []byte) (int, error) {
+	if s.pos >= s.size {
+		return 0, io.EOF
+	}
+	i := sort.Search(len(s.pages), func(i int) bool {
+		return s.pages[i].off+s.pages[i].n > s.pos
+	})
+	pg := s.pages[i]
+	rel := s.pos - pg.off
+	want := min(int64(len(p)), pg.n-rel)
+	n, err := s.r.ReadAt(p[:want], pg.file+rel)
+	s.pos += int64(n)
+	if err == io.EOF && int64(n) == want {
+		err = nil
+	}
+	return n, err
+}
+
+func (s *oggFLACStream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.size
+	}
+	if offset < 0 {
+		return s.pos, errors.New("oggFLACStream.Seek: negative position")
+	}
+	s.pos = offset
+	return offset, nil
+}
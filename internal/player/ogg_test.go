@@ -0,0 +1,195 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// oggCRC is the Ogg page checksum: CRC-32 with polynomial 0x04C11DB7, MSB
+// first, no reflection or final XOR.
+func oggCRC(page []byte) uint32 {
+	var crc uint32
+	for _, b := range page {
+		crc ^= uint32(b) << 24
+		for range 8 {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04C11DB7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// oggPage builds one Ogg page holding body, which may continue a packet
+// from the previous page or run on into the next one.
+func oggPage(serial, seq uint32, granule int64, flags byte, body []byte) []byte {
+	var lacing []byte
+	for rest := len(body); ; rest -= 255 {
+		if rest < 255 {
+			lacing = append(lacing, byte(rest))
+			break
+		}
+		lacing = append(lacing, 255)
+		if rest == 255 {
+			break // the packet continues on the next page
+		}
+	}
+	var b bytes.Buffer
+	b.WriteString("OggS")
+	b.WriteByte(0)
+	b.WriteByte(flags)
+	_ = binary.Write(&b, binary.LittleEndian, granule)
+	_ = binary.Write(&b, binary.LittleEndian, serial)
+	_ = binary.Write(&b, binary.LittleEndian, seq)
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0)) // checksum, filled below
+	b.WriteByte(byte(len(lacing)))
+	b.Write(lacing)
+	b.Write(body)
+	page := b.Bytes()
+	binary.LittleEndian.PutUint32(page[22:], oggCRC(page))
+	return page
+}
+
+// writeOggFile writes data to a temp file named name and opens it.
+func writeOggFile(t *testing.T, name string, data []byte) *os.File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+// oggFLACFile encodes pcm (44.1 kHz stereo) as FLAC and wraps it in Ogg FLAC
+// pages: the mapping header with STREAMINFO first, then the rest of the
+// stream split into small pages so frames straddle page boundaries. A page
+// of another logical stream is interleaved to be skipped.
+func oggFLACFile(t *testing.T, pcm []byte) []byte {
+	t.Helper()
+	tmp := filepath.Join(t.TempDir(), "native.flac")
+	f, err := os.Create(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeFLAC(f, bytes.NewReader(pcm), 44100, 2); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	native, err := os.ReadFile(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const streamInfoEnd = 4 + 4 + 34 // "fLaC", block header, STREAMINFO
+	first := append([]byte("\x7fFLAC\x01\x00\x00\x00"), native[:streamInfoEnd]...)
+	var out []byte
+	out = append(out, oggPage(7, 0, 0, 0x02, first)...)
+	rest := native[streamInfoEnd:]
+	frames := int64(len(pcm) / 4)
+	for seq := uint32(1); len(rest) > 0; seq++ {
+		n := min(len(rest), 700)
+		granule := int64(-1)
+		if n == len(rest) {
+			granule = frames
+		}
+		out = append(out, oggPage(7, seq, granule, 0, rest[:n])...)
+		if seq == 2 {
+			out = append(out, oggPage(9, 0, 0, 0x02, []byte("not part of the FLAC stream"))...)
+		}
+		rest = rest[n:]
+	}
+	return out
+}
+
+func TestSniffOggCodecReadsIdentificationPacket(t *testing.T) {
+	tests := []struct {
+		name   string
+		packet string
+		want   oggCodec
+	}{
+		{"vorbis", "\x01vorbis\x00\x00\x00\x00\x02\x44\xac\x00\x00", oggVorbis},
+		{"flac", "\x7fFLAC\x01\x00\x00\x00fLaC", oggFLAC},
+		{"opus", "OpusHead\x01\x02\x38\x01\x80\xbb\x00\x00\x00\x00\x00", oggOpus},
+		{"speex", "Speex   1.2", oggUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := oggPage(1, 0, 0, 0x02, []byte(tt.packet))
+			if got := sniffOggCodec(bytes.NewReader(page)); got != tt.want {
+				t.Fatalf("sniffOggCodec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := sniffOggCodec(bytes.NewReader([]byte("RIFF"))); got != oggUnknown {
+		t.Fatalf("sniffOggCodec(non-Ogg) = %v, want oggUnknown", got)
+	}
+}
+
+func TestOggDecoderSelectsByCodec(t *testing.T) {
+	pcm := make([]byte, (flacBlockSize+300)*4)
+	for i := range len(pcm) / 2 {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(i*53%30000-15000)))
+	}
+	dec, err := newNativeDecoder(writeOggFile(t, "flac.oga", oggFLACFile(t, pcm)))
+	if err != nil {
+		t.Fatalf("newNativeDecoder(Ogg FLAC) error = %v", err)
+	}
+	if _, ok := dec.(*oggFLACDecoder); !ok {
+		t.Fatalf("Ogg FLAC decoded by %T, want *oggFLACDecoder", dec)
+	}
+	if dec.Length() != int64(len(pcm)) || dec.SampleRate() != 44100 || dec.ChannelCount() != 2 {
+		t.Fatalf("Length, SampleRate, ChannelCount = %d, %d, %d", dec.Length(), dec.SampleRate(), dec.ChannelCount())
+	}
+	got, err := io.ReadAll(dec)
+	if err != nil || !bytes.Equal(got, pcm) {
+		t.Fatalf("ReadAll() = %d bytes, %v; want the encoded PCM back", len(got), err)
+	}
+
+	// Vorbis goes to the Vorbis reader, which rejects an identification
+	// packet without the rest of the headers.
+	vorbis := oggPage(1, 0, 0, 0x02, []byte("\x01vorbis\x00\x00\x00\x00\x02\x44\xac\x00\x00"))
+	if _, err := newNativeDecoder(writeOggFile(t, "vorbis.ogg", vorbis)); err == nil || !strings.Contains(err.Error(), "decoding OGG") {
+		t.Fatalf("newNativeDecoder(Vorbis) error = %v, want the Vorbis reader's", err)
+	}
+
+	// Opus goes to ffmpeg, which either is missing or rejects the stub.
+	opus := oggPage(1, 0, 0, 0x02, []byte("OpusHead\x01\x02\x38\x01\x80\xbb\x00\x00\x00\x00\x00"))
+	if _, err := newNativeDecoder(writeOggFile(t, "opus.ogg", opus)); err == nil || !strings.Contains(err.Error(), "ffmpeg") {
+		t.Fatalf("newNativeDecoder(Opus) error = %v, want an ffmpeg error", err)
+	}
+}
+
+func TestOggFLACDecoderSeeks(t *testing.T) {
+	pcm := make([]byte, (flacBlockSize*3)*4)
+	for i := range len(pcm) / 4 {
+		binary.LittleEndian.PutUint16(pcm[i*4:], uint16(int16(i)))
+		binary.LittleEndian.PutUint16(pcm[i*4+2:], uint16(int16(-i)))
+	}
+	dec, err := newOggFLACDecoder(writeOggFile(t, "seek.ogg", oggFLACFile(t, pcm)))
+	if err != nil {
+		t.Fatalf("newOggFLACDecoder() error = %v", err)
+	}
+	const frame = flacBlockSize * 2 // the FLAC decoder seeks to frame starts
+	if pos, err := dec.Seek(frame*4, io.SeekStart); err != nil || pos != frame*4 {
+		t.Fatalf("Seek() = %d, %v", pos, err)
+	}
+	got := make([]byte, 8)
+	if _, err := io.ReadFull(dec, got); err != nil {
+		t.Fatalf("ReadFull() error = %v", err)
+	}
+	if want := pcm[frame*4 : frame*4+8]; !bytes.Equal(got, want) {
+		t.Fatalf("after seek got %v, want %v", got, want)
+	}
+}
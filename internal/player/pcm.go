@@ -0,0 +1,134 @@
+package player
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// PCMStream is a track decoded to raw s16le PCM by climp's own decoders,
+// for headless use such as `climp --decode`. It never opens an audio device.
+type PCMStream struct {
+	file     *os.File
+	dec      audioDecoder
+	r        io.Reader
+	rate     int
+	channels int
+	length   int64
+}
+
+// OpenPCM opens path for decoding. A rate or channels of 0 keeps the source's
+// own; otherwise the audio goes through the playback resampler (see
+// SetResampleQuality) and is downmixed for channels 1.
+func OpenPCM(path string, rate, channels int) (*PCMStream, error) {
+	if rate < 0 {
+		return nil, fmt.Errorf("unsupported sample rate: %d", rate)
+	}
+	if channels < 0 || channels > playbackChannels {
+		return nil, fmt.Errorf("unsupported channel count: %d", channels)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := newNativeDecoder(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	s := &PCMStream{file: f, dec: dec, r: dec, rate: dec.SampleRate(), channels: dec.ChannelCount(), length: dec.Length()}
+	if (rate == 0 || rate == s.rate) && (channels == 0 || channels == s.channels) {
+		return s, nil
+	}
+
+	if rate == 0 {
+		rate = s.rate
+	}
+	if channels == 0 {
+		channels = min(s.channels, playbackChannels)
+	}
+	norm, err := newNormalizedDecoderAt(dec, rate)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	s.dec, s.r = norm, norm
+	s.rate, s.channels, s.length = rate, playbackChannels, norm.Length()
+	if channels == 1 {
+		s.r = &monoDownmix{src: norm}
+		s.channels, s.length = 1, s.length/2
+	}
+	return s, nil
+}
+
+// SampleRate returns the output sample rate in Hz.
+func (s *PCMStream) SampleRate() int { return s.rate }
+
+// ChannelCount returns the number of interleaved output channels.
+func (s *PCMStream) ChannelCount() int { return s.channels }
+
+// Length returns the decoded size in bytes, as reported by the decoder.
+func (s *PCMStream) Length() int64 { return s.length }
+
+func (s *PCMStream) Read(p []byte) (int, error) { return s.r.Read(p) }
+
+// WriteWAV writes the whole stream to w as a 16-bit PCM WAV file. The header
+// uses Length, since w may be a pipe; when w can seek, the sizes are
+// corrected afterwards if the decoder ended early.
+func (s *PCMStream) WriteWAV(w io.Writer) (int64, error) {
+	if _, err := w.Write(wavHeader(s.rate, s.channels, uint32(s.length))); err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(w, s)
+	if err != nil || n == s.length {
+		return n, err
+	}
+	if ws, ok := w.(io.WriteSeeker); ok {
+		if _, serr := ws.Seek(0, io.SeekStart); serr == nil {
+			if _, err := ws.Write(wavHeader(s.rate, s.channels, uint32(n))); err != nil {
+				return n, err
+			}
+			_, err = ws.Seek(0, io.SeekEnd)
+		}
+	}
+	return n, err
+}
+
+// Close releases the decoder and the file.
+func (s *PCMStream) Close() error {
+	if c, ok := s.dec.(io.Closer); ok {
+		c.Close()
+	}
+	return s.file.Close()
+}
+
+// monoDownmix averages s16le stereo frames into mono samples.
+type monoDownmix struct {
+	src io.Reader
+	buf []byte
+}
+
+func (m *monoDownmix) Read(p []byte) (int, error) {
+	frames := len(p) / 2
+	if frames == 0 {
+		return 0, io.ErrShortBuffer
+	}
+	if cap(m.buf) < frames*4 {
+		m.buf = make([]byte, frames*4)
+	}
+	n, err := io.ReadFull(m.src, m.buf[:frames*4])
+	if err == io.ErrUnexpectedEOF {
+		err = nil // the next read reports io.EOF
+	}
+	frames = n / 4
+	for i := range frames {
+		l := int32(int16(binary.LittleEndian.Uint16(m.buf[i*4:])))
+		r := int32(int16(binary.LittleEndian.Uint16(m.buf[i*4+2:])))
+		binary.LittleEndian.PutUint16(p[i*2:], uint16(int16((l+r)/2)))
+	}
+	if frames == 0 && err == nil {
+		err = io.EOF
+	}
+	return frames * 2, err
+}
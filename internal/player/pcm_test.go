@@ -0,0 +1,89 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestNormalizedDecoderAtOtherRate(t *testing.T) {
+	src := &stubPCMDecoder{data: pcm16(0, 0, 1000, 1000, 2000, 2000, 3000, 3000), sampleRate: 8000, channels: 2}
+	dec, err := newNormalizedDecoderAt(src, 16000)
+	if err != nil {
+		t.Fatalf("newNormalizedDecoderAt() error = %v", err)
+	}
+	if dec.SampleRate() != 16000 || dec.Length() != 8*playbackFrameSize {
+		t.Fatalf("SampleRate() = %d, Length() = %d, want 16000 and 8 frames", dec.SampleRate(), dec.Length())
+	}
+	out, err := io.ReadAll(dec)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	want := pcm16(0, 0, 500, 500, 1000, 1000, 1500, 1500, 2000, 2000, 2500, 2500, 3000, 3000, 3000, 3000)
+	if !bytes.Equal(out, want) {
+		t.Fatalf("resampled PCM mismatch:\n got %v\nwant %v", out, want)
+	}
+}
+
+func TestMonoDownmixAveragesChannels(t *testing.T) {
+	m := &monoDownmix{src: bytes.NewReader(pcm16(100, 300, -32768, -32768, 7, 8))}
+	out, err := io.ReadAll(m)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := pcm16(200, -32768, 7); !bytes.Equal(out, want) {
+		t.Fatalf("downmix = %v, want %v", out, want)
+	}
+}
+
+// seekBuffer is an in-memory io.WriteSeeker.
+type seekBuffer struct {
+	data []byte
+	pos  int
+}
+
+func (b *seekBuffer) Write(p []byte) (int, error) {
+	if need := b.pos + len(p); need > len(b.data) {
+		b.data = append(b.data, make([]byte, need-len(b.data))...)
+	}
+	n := copy(b.data[b.pos:], p)
+	b.pos += n
+	return n, nil
+}
+
+func (b *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		b.pos = int(offset)
+	case io.SeekEnd:
+		b.pos = len(b.data) + int(offset)
+	}
+	return int64(b.pos), nil
+}
+
+func TestPCMStreamWriteWAVFixesShortLength(t *testing.T) {
+	pcm := pcm16(1, 2, 3, 4)
+	s := &PCMStream{r: bytes.NewReader(pcm), rate: 44100, channels: 2, length: 16}
+
+	var pipe bytes.Buffer
+	if _, err := s.WriteWAV(&pipe); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+	if got := binary.LittleEndian.Uint32(pipe.Bytes()[40:]); got != 16 {
+		t.Fatalf("piped data size = %d, want the reported length 16", got)
+	}
+
+	s.r = bytes.NewReader(pcm)
+	var file seekBuffer
+	n, err := s.WriteWAV(&file)
+	if err != nil || n != 8 {
+		t.Fatalf("WriteWAV() = %d, %v", n, err)
+	}
+	if got := binary.LittleEndian.Uint32(file.data[40:]); got != 8 {
+		t.Fatalf("seekable data size = %d, want the 8 bytes written", got)
+	}
+	if !bytes.Equal(file.data[wavHeaderSize:], pcm) || binary.LittleEndian.Uint32(file.data[24:]) != 44100 {
+		t.Fatal("WAV body or sample rate mismatch")
+	}
+}
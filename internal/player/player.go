@@ -5,10 +5,12 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ebitengine/oto/v3"
@@ -19,21 +21,64 @@ import (
 // It also copies PCM data into a ring buffer for visualization.
 // It has its own mutex (separate from Player's) because Oto's audio goroutine
 // calls Read() concurrently with UI goroutine calls to Pos().
+//
+// When a next reader is queued, hitting EOF switches to it in place so the
+// Oto player never drains between tracks (gapless playback).
+//
+// With a crossfade length set, the last fadeBytes of the current reader are
+// mixed with the head of the next one (see crossfade.go).
 type countingReader struct {
 	reader    io.ReadSeeker
+	next      io.ReadSeeker // preloaded reader to continue with at EOF
 	pos       int64
+	nextPos   int64 // bytes already mixed in from next during a crossfade
+	nextBusy  bool  // Read is mixing from next without mu held
+	rewindDue bool  // a seek asked to rewind next while it was busy
+	fadeBytes int64 // crossfade length; 0 for a gapless cut
+	fadeBuf   []byte
+	switched  bool // reader was replaced by next since the last takeSwitch
+	eof       bool // reader hit EOF with nothing queued
+	clips     int  // full-scale samples since the last seek or track change
 	mu        sync.Mutex
 	sampleBuf *visualizer.RingBuffer
+	rec       atomic.Pointer[recorder] // set while recording (see record.go)
 }
 
 func (cr *countingReader) Read(p []byte) (int, error) {
-	n, err := cr.reader.Read(p)
+	n, err, fading := cr.readCrossfade(p)
+	if !fading {
+		n, err = cr.reader.Read(p)
+	}
 	cr.mu.Lock()
 	cr.pos += int64(n)
+	if cr.sampleBuf != nil {
+		cr.clips += countClips(p[:n])
+	}
+	switched := false
+	if err == io.EOF {
+		if cr.next != nil {
+			cr.reader = cr.next
+			cr.next = nil
+			cr.pos = cr.nextPos
+			cr.nextPos = 0
+			cr.clips = 0
+			cr.switched = true
+			switched = true
+			err = nil
+		} else {
+			cr.eof = true
+		}
+	}
 	cr.mu.Unlock()
 	if n > 0 && cr.sampleBuf != nil {
 		cr.sampleBuf.Write(p[:n])
 	}
+	if rec := cr.rec.Load(); rec != nil && n > 0 {
+		rec.write(p[:n])
+	}
+	if switched && n == 0 {
+		return cr.Read(p)
+	}
 	return n, err
 }
 
@@ -46,19 +91,64 @@ func (cr *countingReader) Pos() int64 {
 func (cr *countingReader) SetPos(pos int64) {
 	cr.mu.Lock()
 	cr.pos = pos
+	cr.eof = false
+	cr.clips = 0
+	cr.rewindNextLocked()
 	cr.mu.Unlock()
 }
 
+// setNext queues r to be read once the current reader reaches EOF.
+// Returns false if the current reader has already ended.
+func (cr *countingReader) setNext(r io.ReadSeeker) bool {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.eof {
+		return false
+	}
+	cr.next = r
+	return true
+}
+
+// clearNext unqueues the next reader. Returns false if Read already switched
+// to it or has started crossfading into it, so the caller never closes a
+// decoder that Read may still use.
+func (cr *countingReader) clearNext() bool {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if cr.next == nil || cr.nextPos > 0 || cr.nextBusy {
+		return false
+	}
+	cr.next = nil
+	cr.nextPos = 0
+	return true
+}
+
+// takeSwitch reports whether Read switched to the queued reader, clearing the flag.
+func (cr *countingReader) takeSwitch() bool {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	switched := cr.switched
+	cr.switched = false
+	return switched
+}
+
 // Player manages audio playback.
 type Player struct {
 	file         *os.File
 	decoder      audioDecoder
 	counter      *countingReader
 	sr           *speedReader
+	eq           *Equalizer
+	cm           *channelMixer
+	skip         *silenceSkipper
+	lim          *softLimiter
 	otoCtx       *oto.Context
 	otoPlayer    *oto.Player
 	duration     time.Duration
 	volume       float64
+	gainDB       float64 // ReplayGain adjustment, combined with volume
+	muted        bool
+	unmuteVolume float64 // volume to restore on Unmute
 	paused       bool
 	done         chan struct{}
 	stopMon      chan struct{} // signals current monitor goroutine to exit
@@ -66,15 +156,40 @@ type Player struct {
 	closed       bool
 	bytesPerSec  int // immutable after init — safe to read without mutex
 	speed        SpeedMode
+	keepPitch    bool // time-stretch instead of resampling at 2x/0.5x
+	channelMode  ChannelMode
+	stereoWidth  float64 // side-signal scale applied by cm; 1 is normal
+	skipSilence  bool
+	boost        float64 // post-volume gain above 100%, applied by lim
 	sampleBuf    *visualizer.RingBuffer
 	canSeek      bool
+	buffering    bool // live source is refilling the output buffer
+	reconnected  bool // live source already reconnected after an underrun
+	outputLost   bool // the audio device failed; paused until RetryOutput
 	titleUpdates <-chan string
+	preloadPath  string          // next track path requested via PreloadNext
+	preload      *preloadedTrack // opened next track queued in counter
+	advanced     chan string     // receives the path of each gapless track change
+	crossfade    time.Duration   // overlap with the preloaded next track; 0 = gapless
+	loopStart    time.Duration   // A-B loop start; loop is active when loopEnd > loopStart
+	loopEnd      time.Duration
+	boundStart   time.Duration // section of the file to play (SetBounds); times are relative to it
+	boundEnd     time.Duration // 0 plays to the end of the file
 }
 
 type liveTitleProvider interface {
 	TitleUpdates() <-chan string
 }
 
+// reconnector is implemented by live decoders that can reopen their source.
+type reconnector interface {
+	Reconnect() error
+}
+
+// bufferLowWater is the share of a second of audio below which a playing
+// live source counts as buffering.
+const bufferLowWater = 0.1
+
 var (
 	globalOtoCtx *oto.Context
 	otoOnce      sync.Once
@@ -87,6 +202,7 @@ func initOto(sampleRate, channelCount int) (*oto.Context, error) {
 			SampleRate:   sampleRate,
 			ChannelCount: channelCount,
 			Format:       oto.FormatSignedInt16LE,
+			BufferSize:   time.Duration(outputLatency.Load()),
 		}
 		var ready chan struct{}
 		globalOtoCtx, ready, otoInitErr = oto.NewContext(op)
@@ -147,7 +263,21 @@ func friendlyAudioInitError(err error) error {
 	return fmt.Errorf("no Linux audio output device found (ALSA default device unavailable). This is common on headless VMs/containers; configure ALSA/PipeWire/PulseAudio or use a machine with audio")
 }
 
-func clampSeekByteOffset(target time.Duration, bytesPerSec int, totalBytes, frameSize int64) int64 {
+func durationFor(totalBytes int64, bytesPerSec int) time.Duration {
+	if totalBytes <= 0 || bytesPerSec <= 0 {
+		return 0
+	}
+	return time.Duration(float64(totalBytes) / float64(bytesPerSec) * float64(time.Second))
+}
+
+// SeekEndMargin is how far before the end of a track (or section) a seek
+// past it lands. Landing on the end itself would finish the track as soon as
+// playback resumed, turning a forward seek into a skip to the next one.
+const SeekEndMargin = time.Second
+
+// clampSeekByteOffset converts target to a frame-aligned byte offset no
+// later than endGuard bytes before totalBytes (when known).
+func clampSeekByteOffset(target time.Duration, bytesPerSec int, totalBytes, frameSize, endGuard int64) int64 {
 	if bytesPerSec <= 0 {
 		return 0
 	}
@@ -156,32 +286,75 @@ func clampSeekByteOffset(target time.Duration, bytesPerSec int, totalBytes, fram
 	if newPos < 0 {
 		newPos = 0
 	}
-	if totalBytes >= 0 && newPos > totalBytes {
-		newPos = totalBytes
+	if totalBytes >= 0 && newPos > totalBytes-endGuard {
+		newPos = max(totalBytes-endGuard, 0)
 	}
 	if frameSize > 0 {
 		newPos -= newPos % frameSize
 	}
 	return newPos
 }
+
+// defaultVolume is the volume a player starts at without saved settings.
+const defaultVolume = 0.8
+
+// startMu guards the settings new players start with (see SetStartSettings).
+var (
+	startMu     sync.Mutex
+	startVolume = defaultVolume
+	startSpeed  = Speed1x
+)
+
+// SetStartSettings sets the volume and speed of players created after the
+// call, so saved settings are in place before the first sample plays instead
+// of being applied once output has started. Volume is clamped to 0.0 - 1.0
+// and an unknown speed falls back to 1x.
+func SetStartSettings(volume float64, speed SpeedMode) {
+	switch speed {
+	case Speed2x, SpeedHalf:
+	default:
+		speed = Speed1x
+	}
+	startMu.Lock()
+	startVolume = min(max(volume, 0), 1)
+	startSpeed = speed
+	startMu.Unlock()
+}
+
+func startSettings() (float64, SpeedMode) {
+	startMu.Lock()
+	defer startMu.Unlock()
+	return startVolume, startSpeed
+}
+
 // New creates a new Player for the given audio file path.
 func New(path string) (*Player, error) {
-	f, err := os.Open(path)
+	f, dec, err := openDecoder(path)
 	if err != nil {
 		return nil, err
 	}
 
-	dec, err := newDecoder(f)
+	p, err := newFromDecoder(f, dec, true)
 	if err != nil {
-		f.Close()
 		return nil, err
 	}
+	return p, nil
+}
 
-	p, err := newFromDecoder(f, dec, true)
+// openDecoder opens path and returns the file with its playback decoder,
+// which falls back to ffmpeg if native decoding fails mid-stream.
+func openDecoder(path string) (*os.File, audioDecoder, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return p, nil
+
+	dec, err := newDecoder(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, newFallbackDecoder(path, dec), nil
 }
 
 // NewStream creates a new Player for a live URL stream decoded by ffmpeg.
@@ -206,37 +379,47 @@ func newFromDecoder(file *os.File, dec audioDecoder, canSeek bool) (*Player, err
 	}
 
 	bytesPerSec := dec.SampleRate() * dec.ChannelCount() * 2 // 16-bit = 2 bytes
-	totalBytes := dec.Length()
-	dur := time.Duration(0)
-	if totalBytes > 0 {
-		dur = time.Duration(float64(totalBytes) / float64(bytesPerSec) * float64(time.Second))
-	}
+	dur := durationFor(dec.Length(), bytesPerSec)
 
 	// ~90ms at 48kHz stereo 16-bit = 48000 * 2 * 2 * 0.09 ~= 17KB
 	sampleBuf := visualizer.NewRingBuffer(16384)
 	cr := &countingReader{reader: dec, sampleBuf: sampleBuf}
 	frameSize := dec.ChannelCount() * 2
-	sr := newSpeedReader(cr, frameSize)
+	skip := newSilenceSkipper(cr, frameSize, dec.SampleRate())
+	sr := newSpeedReader(skip, frameSize)
+	cm := newChannelMixer(sr, dec.ChannelCount())
+	eq := newEqualizer(cm, dec.SampleRate(), dec.ChannelCount())
+	lim := newSoftLimiter(eq, frameSize)
+	volume, speed := startSettings()
+	sr.setSpeed(speed)
 
 	p := &Player{
 		file:        file,
 		decoder:     dec,
 		counter:     cr,
 		sr:          sr,
+		eq:          eq,
+		cm:          cm,
+		skip:        skip,
+		lim:         lim,
 		otoCtx:      ctx,
 		duration:    dur,
-		volume:      0.8,
+		volume:      volume,
+		speed:       speed,
+		boost:       1,
+		stereoWidth: 1,
 		done:        make(chan struct{}),
 		stopMon:     make(chan struct{}),
 		bytesPerSec: bytesPerSec,
 		sampleBuf:   sampleBuf,
 		canSeek:     canSeek,
+		advanced:    make(chan string, 1),
 	}
 	if provider, ok := dec.(liveTitleProvider); ok {
 		p.titleUpdates = provider.TitleUpdates()
 	}
 
-	p.otoPlayer = ctx.NewPlayer(sr)
+	p.otoPlayer = ctx.NewPlayer(lim)
 	if p.otoPlayer == nil {
 		if file != nil {
 			file.Close()
@@ -246,22 +429,22 @@ func newFromDecoder(file *os.File, dec audioDecoder, canSeek bool) (*Player, err
 		}
 		return nil, fmt.Errorf("creating audio output player")
 	}
-	p.otoPlayer.SetVolume(p.volume)
+	p.otoPlayer.SetVolume(p.outputVolumeLocked())
 	p.otoPlayer.Play()
 
 	// Monitor for playback end
-	go p.monitor()
+	go p.monitor(p.done, p.stopMon)
 
 	return p, nil
 }
 
-func (p *Player) monitor() {
+func (p *Player) monitor(done, stopMon chan struct{}) {
 	// Poll until playback finishes, player is closed, or stopMon is signalled.
 	ticker := time.NewTicker(200 * time.Millisecond)
 	defer ticker.Stop()
 	for {
 		select {
-		case <-p.stopMon:
+		case <-stopMon:
 			return
 		case <-ticker.C:
 		}
@@ -271,9 +454,33 @@ func (p *Player) monitor() {
 			p.mu.Unlock()
 			return
 		}
+		if !p.paused && p.outputErrLocked() != nil {
+			p.pauseLocked()
+			p.outputLost = true
+			p.mu.Unlock()
+			continue
+		}
+		p.finishAdvanceLocked()
 		pos := p.counter.Pos()
 		paused := p.paused
 		canSeek := p.canSeek
+		total := p.decoder.Length()
+		end := total
+		if p.boundEnd > 0 {
+			end = min(total, int64(p.boundEnd.Seconds()*float64(p.bytesPerSec)))
+		}
+		looping := canSeek && p.loopEnd > p.loopStart
+		if looping && !paused && pos >= int64((p.boundStart+p.loopEnd).Seconds()*float64(p.bytesPerSec)) {
+			_ = p.seekToLocked(p.loopStart, true)
+			p.mu.Unlock()
+			continue
+		}
+		preloadPath := ""
+		preloadBytes := int64(p.bytesPerSec)*preloadWindowSecs + int64(p.crossfade.Seconds()*float64(p.bytesPerSec))
+		if !looping && p.boundEnd == 0 && p.preload == nil && p.preloadPath != "" && total-pos <= preloadBytes {
+			preloadPath = p.preloadPath
+		}
+		hasNext := p.preload != nil
 		p.mu.Unlock()
 
 		if paused {
@@ -281,22 +488,134 @@ func (p *Player) monitor() {
 		}
 
 		if canSeek {
-			total := p.decoder.Length()
-			if total >= 0 && pos >= total {
-				close(p.done)
+			if preloadPath != "" {
+				p.openPreload(preloadPath)
+				continue
+			}
+			if end >= 0 && pos >= end && !hasNext {
+				p.finishPlayback(done)
 				return
 			}
 			continue
 		}
 
-		// Non-seekable/live sources finish when Oto drains and pauses naturally.
-		if !p.otoPlayer.IsPlaying() && p.otoPlayer.BufferedSize() == 0 {
-			close(p.done)
+		// Non-seekable/live sources finish when Oto drains and pauses naturally,
+		// after one attempt to reconnect.
+		p.mu.Lock()
+		playing := p.otoPlayer != nil && p.otoPlayer.IsPlaying()
+		buffered := 0
+		if p.otoPlayer != nil {
+			buffered = p.otoPlayer.BufferedSize()
+		}
+		if !playing && buffered == 0 {
+			p.buffering = false
+			if p.reconnectLocked() {
+				p.mu.Unlock()
+				continue
+			}
+			p.mu.Unlock()
+			p.finishPlayback(done)
 			return
 		}
+		p.buffering = playing && buffered < int(bufferLowWater*float64(p.bytesPerSec))
+		p.mu.Unlock()
 	}
 }
 
+// reconnectLocked reopens a live source that ran dry and restarts output.
+// It is tried once per player; false means playback should end.
+func (p *Player) reconnectLocked() bool {
+	rc, ok := p.decoder.(reconnector)
+	if !ok || p.reconnected || p.closed || p.paused {
+		return false
+	}
+	p.reconnected = true
+	if err := rc.Reconnect(); err != nil {
+		return false
+	}
+	p.counter.SetPos(p.counter.Pos())
+	p.disposeOtoPlayerLocked()
+	p.recreateOtoPlayerLocked(true)
+	return p.otoPlayer != nil
+}
+
+// outputErrLocked returns the error that stopped audio output, if any, such
+// as the output device going away when headphones are unplugged.
+func (p *Player) outputErrLocked() error {
+	if p.otoCtx != nil {
+		if err := p.otoCtx.Err(); err != nil {
+			return err
+		}
+	}
+	if p.otoPlayer != nil {
+		return p.otoPlayer.Err()
+	}
+	return nil
+}
+
+// OutputLost reports whether playback was paused because audio output failed.
+func (p *Player) OutputLost() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.outputLost
+}
+
+// OutputDeviceLost reports whether the shared audio context has failed. Oto
+// allows one context per process, so only a restart brings output back;
+// RetryOutput cannot.
+func (p *Player) OutputDeviceLost() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.otoCtx != nil && p.otoCtx.Err() != nil
+}
+
+// RetryOutput recreates the audio output after OutputLost and resumes from
+// the current position. It reports whether output works again; if not,
+// playback stays paused. It does nothing once OutputDeviceLost is true.
+func (p *Player) RetryOutput() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || (p.otoCtx != nil && p.otoCtx.Err() != nil) {
+		return false
+	}
+	if p.canSeek && p.decoder != nil {
+		_ = p.seekToLocked(p.filePosition()-p.boundStart, true)
+	} else {
+		p.disposeOtoPlayerLocked()
+		p.recreateOtoPlayerLocked(true)
+	}
+	if p.otoPlayer == nil || p.outputErrLocked() != nil {
+		p.pauseLocked()
+		return false
+	}
+	p.outputLost = false
+	return true
+}
+
+// finishPlayback closes done unless Restart or SetBounds has replaced it.
+func (p *Player) finishPlayback(done chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done == done {
+		close(done)
+	}
+}
+
+// rearmLocked replaces the done channel and restarts the monitor. A done
+// channel that has not fired yet is closed so its waiters return; the UI
+// ignores it as stale.
+func (p *Player) rearmLocked() {
+	close(p.stopMon)
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
+	p.done = make(chan struct{})
+	p.stopMon = make(chan struct{})
+	go p.monitor(p.done, p.stopMon)
+}
+
 // Done returns a channel that closes when playback finishes.
 func (p *Player) Done() <-chan struct{} {
 	p.mu.Lock()
@@ -304,8 +623,8 @@ func (p *Player) Done() <-chan struct{} {
 	return p.done
 }
 
-// Restart seeks to the beginning and resumes playback.
-// This resets the done channel so Done() can be used again.
+// Restart seeks to the beginning (of the section set by SetBounds) and
+// resumes playback. This resets the done channel so Done() can be used again.
 func (p *Player) Restart() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -313,11 +632,12 @@ func (p *Player) Restart() {
 		return
 	}
 
-	// Stop the old monitor goroutine before replacing the done channel.
-	close(p.stopMon)
+	p.finishAdvanceLocked()
 
-	p.decoder.Seek(0, io.SeekStart)
-	p.counter.SetPos(0)
+	frameSize := int64(p.decoder.ChannelCount()) * 2
+	start := clampSeekByteOffset(p.boundStart, p.bytesPerSec, p.decoder.Length(), frameSize, 0)
+	p.decoder.Seek(start, io.SeekStart)
+	p.counter.SetPos(start)
 	if p.sampleBuf != nil {
 		p.sampleBuf.Clear()
 	}
@@ -326,13 +646,22 @@ func (p *Player) Restart() {
 	if p.sr != nil {
 		p.sr.clearBuf()
 	}
+	if p.eq != nil {
+		p.eq.reset()
+	}
+	if p.cm != nil {
+		p.cm.reset()
+	}
+	if p.skip != nil {
+		p.skip.reset()
+	}
+	if p.lim != nil {
+		p.lim.reset()
+	}
 	p.recreateOtoPlayerLocked(false)
 
-	p.done = make(chan struct{})
-	p.stopMon = make(chan struct{})
 	p.resumeLocked()
-
-	go p.monitor()
+	p.rearmLocked()
 }
 
 // TogglePause toggles between play and pause.
@@ -361,35 +690,102 @@ func (p *Player) Paused() bool {
 	return p.paused
 }
 
-// Position returns the current playback position.
+// Buffering reports whether a live source is playing with its output buffer
+// nearly empty, waiting on the network.
+func (p *Player) Buffering() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.buffering
+}
+
+// Position returns the current playback position, relative to the start of
+// the section set by SetBounds. Source audio still held by the time-stretcher
+// has not been played yet and is not counted.
 func (p *Player) Position() time.Duration {
 	if p == nil || p.counter == nil || p.bytesPerSec <= 0 {
 		return 0
 	}
+	p.mu.Lock()
+	start := p.boundStart
+	p.mu.Unlock()
+	return max(p.filePosition()-start, 0)
+}
+
+// filePosition returns the playback position from the start of the file.
+func (p *Player) filePosition() time.Duration {
 	pos := p.counter.Pos()
+	if p.sr != nil {
+		pos = max(pos-p.sr.bufferedSourceBytes(), 0)
+	}
 	secs := float64(pos) / float64(p.bytesPerSec)
 	return time.Duration(secs * float64(time.Second))
 }
 
-// Duration returns the total duration of the track.
+// Duration returns the total duration of the track, or of the section set by
+// SetBounds.
 func (p *Player) Duration() time.Duration {
-	return p.duration
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d := p.duration
+	if p.boundEnd > 0 && p.boundEnd < d {
+		d = p.boundEnd
+	}
+	return max(d-p.boundStart, 0)
 }
 
-// SeekTo moves playback to the given absolute target position.
+// SetBounds limits playback to the section of the file from start to end, as
+// for one track of a cue sheet; end 0 plays to the end of the file. Position,
+// Duration, SeekTo, and A-B loops become relative to start. Playback moves to
+// start unless it is already inside the new section, so contiguous sections
+// continue without a seek. Done is re-armed for the new section. Ignored for
+// non-seekable players.
+func (p *Player) SetBounds(start, end time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || !p.canSeek || p.bytesPerSec <= 0 {
+		return
+	}
+	p.finishAdvanceLocked()
+	if start < 0 {
+		start = 0
+	}
+	if end <= start {
+		end = 0
+	}
+	pos := p.filePosition()
+	p.boundStart, p.boundEnd = start, end
+	p.loopStart, p.loopEnd = 0, 0
+	if pos < start || (end > 0 && pos >= end) {
+		_ = p.seekToLocked(0, !p.paused)
+	}
+	p.rearmLocked()
+}
+
+// SeekTo moves playback to target, measured from the start of the track (or of
+// the section set by SetBounds).
 func (p *Player) SeekTo(target time.Duration, resume bool) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	if p == nil || p.closed || !p.canSeek {
+	return p.seekToLocked(target, resume)
+}
+
+func (p *Player) seekToLocked(target time.Duration, resume bool) error {
+	if p.closed || !p.canSeek {
 		return nil
 	}
 	if p.decoder == nil {
 		p.paused = !resume
 		return nil
 	}
+	p.finishAdvanceLocked()
 
+	target += p.boundStart
+	if p.boundEnd > 0 && target > p.boundEnd-SeekEndMargin {
+		target = max(p.boundEnd-SeekEndMargin, p.boundStart)
+	}
 	frameSize := int64(p.decoder.ChannelCount()) * 2
-	newPos := clampSeekByteOffset(target, p.bytesPerSec, p.decoder.Length(), frameSize)
+	endGuard := int64(SeekEndMargin.Seconds() * float64(p.bytesPerSec))
+	newPos := clampSeekByteOffset(target, p.bytesPerSec, p.decoder.Length(), frameSize, endGuard)
 	wasPaused := p.paused
 	p.pauseLocked()
 
@@ -410,11 +806,43 @@ func (p *Player) SeekTo(target time.Duration, resume bool) error {
 	if p.sr != nil {
 		p.sr.clearBuf()
 	}
+	if p.eq != nil {
+		p.eq.reset()
+	}
+	if p.cm != nil {
+		p.cm.reset()
+	}
+	if p.skip != nil {
+		p.skip.reset()
+	}
+	if p.lim != nil {
+		p.lim.reset()
+	}
 	p.disposeOtoPlayerLocked()
 	p.recreateOtoPlayerLocked(resume)
 	return nil
 }
 
+// SetLoop repeats the section from a to b: whenever playback reaches b it
+// seeks back to a. Ignored for non-seekable players or when b is not after a.
+func (p *Player) SetLoop(a, b time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.canSeek || b <= a {
+		return
+	}
+	p.loopStart = a
+	p.loopEnd = b
+}
+
+// ClearLoop disables A-B looping.
+func (p *Player) ClearLoop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loopStart = 0
+	p.loopEnd = 0
+}
+
 // Seek moves playback by the given delta from current position.
 func (p *Player) Seek(delta time.Duration) {
 	target := p.Position() + delta
@@ -442,26 +870,135 @@ func (p *Player) SetVolume(v float64) {
 		v = 1
 	}
 	p.volume = v
+	p.muted = false
 	if p.otoPlayer != nil {
-		p.otoPlayer.SetVolume(v)
+		p.otoPlayer.SetVolume(p.outputVolumeLocked())
 	}
 }
 
-// AdjustVolume adjusts volume by delta.
+// AdjustVolume adjusts the combined volume and boost level by delta. Above
+// 100% the delta goes to the boost, up to MaxBoost. While muted, the delta is
+// applied to the volume from before muting and playback is unmuted.
 func (p *Player) AdjustVolume(delta float64) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	v := p.volume + delta
-	if v < 0 {
-		v = 0
+	if p.muted {
+		p.volume = p.unmuteVolume
+		p.muted = false
 	}
-	if v > 1 {
-		v = 1
+	level := p.volume*max(p.boost, 1) + delta
+	if level < 0 {
+		level = 0
 	}
-	p.volume = v
+	if level > MaxBoost {
+		level = MaxBoost
+	}
+	p.volume = min(level, 1)
+	p.setBoostLocked(max(level, 1))
 	if p.otoPlayer != nil {
-		p.otoPlayer.SetVolume(v)
+		p.otoPlayer.SetVolume(p.outputVolumeLocked())
+	}
+}
+
+// SetBoost sets a gain applied after the volume (clamped to 1.0 - MaxBoost).
+// Above 1.0 samples pass through a soft limiter instead of clipping.
+func (p *Player) SetBoost(factor float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setBoostLocked(factor)
+}
+
+// Boost returns the current post-volume gain.
+func (p *Player) Boost() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return max(p.boost, 1)
+}
+
+func (p *Player) setBoostLocked(factor float64) {
+	factor = min(max(factor, 1), MaxBoost)
+	p.boost = factor
+	if p.lim != nil {
+		p.lim.setFactor(factor)
+	}
+}
+
+// Mute silences output, remembering the current volume for Unmute.
+func (p *Player) Mute() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.muted {
+		return
+	}
+	p.unmuteVolume = p.volume
+	p.volume = 0
+	p.muted = true
+	if p.otoPlayer != nil {
+		p.otoPlayer.SetVolume(p.outputVolumeLocked())
+	}
+}
+
+// Unmute restores the volume saved by Mute.
+func (p *Player) Unmute() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.muted {
+		return
+	}
+	p.volume = p.unmuteVolume
+	p.muted = false
+	if p.otoPlayer != nil {
+		p.otoPlayer.SetVolume(p.outputVolumeLocked())
+	}
+}
+
+// Muted reports whether output is muted.
+func (p *Player) Muted() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.muted
+}
+
+// SetGainDB sets a ReplayGain adjustment in dB. It scales the user volume
+// multiplicatively; the combined output volume is clamped to 1.0.
+func (p *Player) SetGainDB(db float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.gainDB = db
+	if p.otoPlayer != nil {
+		p.otoPlayer.SetVolume(p.outputVolumeLocked())
+	}
+}
+
+// ScanGainDB estimates a normalization gain for untagged files by decoding
+// the first few seconds of the current track in a separate decoder.
+// Returns false for live streams or when nothing could be decoded.
+func (p *Player) ScanGainDB() (float64, bool) {
+	p.mu.Lock()
+	file := p.file
+	canSeek := p.canSeek
+	p.mu.Unlock()
+	if file == nil || !canSeek {
+		return 0, false
+	}
+
+	f, dec, err := openDecoder(file.Name())
+	if err != nil {
+		return 0, false
 	}
+	defer f.Close()
+	if c, ok := dec.(io.Closer); ok {
+		defer c.Close()
+	}
+	return scanGainDB(dec)
+}
+
+func (p *Player) outputVolumeLocked() float64 {
+	v := p.volume * math.Pow(10, p.gainDB/20)
+	if v > 1 {
+		v = 1
+	}
+	return v
 }
 
 // Speed returns the current playback speed.
@@ -476,7 +1013,26 @@ func (p *Player) SetSpeed(s SpeedMode) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.speed = s
-	p.sr.setSpeed(s)
+	if p.sr != nil {
+		p.sr.setSpeed(s)
+	}
+}
+
+// SetPitchPreserve selects how 2x and 0.5x playback is produced: false uses
+// the cheap frame drop/duplicate resampling (pitch shifts with speed), true
+// time-stretches with WSOLA so pitch is unchanged at a higher CPU cost.
+func (p *Player) SetPitchPreserve(on bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keepPitch = on
+	p.sr.setKeepPitch(on)
+}
+
+// PitchPreserve reports whether speed changes keep the original pitch.
+func (p *Player) PitchPreserve() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.keepPitch
 }
 
 // CycleSpeed advances to the next speed mode and returns it.
@@ -484,10 +1040,96 @@ func (p *Player) CycleSpeed() SpeedMode {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.speed = p.speed.Next()
-	p.sr.setSpeed(p.speed)
+	if p.sr != nil {
+		p.sr.setSpeed(p.speed)
+	}
 	return p.speed
 }
 
+// SetChannelMode selects how the stereo channels are routed to the output.
+func (p *Player) SetChannelMode(mode ChannelMode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.channelMode = mode
+	if p.cm != nil {
+		p.cm.setMode(mode)
+	}
+}
+
+// ChannelMode returns the current channel routing.
+func (p *Player) ChannelMode() ChannelMode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.channelMode
+}
+
+// SetStereoWidth scales the stereo image: 0 is mono, 1 leaves it as is, and
+// up to MaxStereoWidth widens it.
+func (p *Player) SetStereoWidth(w float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stereoWidth = min(max(w, 0), MaxStereoWidth)
+	if p.cm != nil {
+		p.cm.setWidth(p.stereoWidth)
+	}
+}
+
+// StereoWidth returns the current stereo width.
+func (p *Player) StereoWidth() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stereoWidth
+}
+
+// SetSkipSilence turns skipping of long silent stretches on or off. Live
+// streams cannot skip ahead, so it stays off for them.
+func (p *Player) SetSkipSilence(on bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.skipSilence = on && p.canSeek
+	if p.skip != nil {
+		p.skip.setEnabled(p.skipSilence)
+	}
+}
+
+// SkipSilence reports whether silence skipping is on.
+func (p *Player) SkipSilence() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.skipSilence
+}
+
+// EQBands returns the current equalizer gains in dB, one per band.
+func (p *Player) EQBands() []float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.eq == nil {
+		return make([]float64, EQBandCount)
+	}
+	return p.eq.Bands()
+}
+
+// SetEQBand sets the gain of one equalizer band in dB (clamped to ±12 dB).
+func (p *Player) SetEQBand(index int, gainDB float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.eq != nil {
+		p.eq.SetBand(index, gainDB)
+	}
+}
+
+// SetEQPreset applies all band gains from the given preset.
+func (p *Player) SetEQPreset(preset EQPreset) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.eq == nil {
+		return
+	}
+	for i, g := range preset.Gains() {
+		p.eq.SetBand(i, g)
+	}
+}
+
 // CanSeek reports whether this player supports seeking/restart semantics.
 func (p *Player) CanSeek() bool {
 	p.mu.Lock()
@@ -495,6 +1137,16 @@ func (p *Player) CanSeek() bool {
 	return p.canSeek
 }
 
+// Path returns the local file being played, or "" for streams.
+func (p *Player) Path() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.file == nil {
+		return ""
+	}
+	return p.file.Name()
+}
+
 // TitleUpdates returns a stream of live title updates for stream-backed players.
 func (p *Player) TitleUpdates() <-chan string {
 	if p == nil {
@@ -535,6 +1187,7 @@ func (p *Player) Close() {
 		return
 	}
 	p.closed = true
+	p.stopRecordingLocked()
 	if p.stopMon != nil {
 		close(p.stopMon)
 	}
@@ -545,6 +1198,13 @@ func (p *Player) Close() {
 	if c, ok := p.decoder.(io.Closer); ok {
 		c.Close()
 	}
+	if p.preload != nil {
+		p.preload.close()
+		p.preload = nil
+	}
+	if p.advanced != nil {
+		close(p.advanced)
+	}
 }
 
 func (p *Player) pauseLocked() {
@@ -571,16 +1231,16 @@ func (p *Player) recreateOtoPlayerLocked(resume bool) {
 	if p == nil || p.closed {
 		return
 	}
-	if p.otoCtx == nil || p.sr == nil {
+	if p.otoCtx == nil || p.lim == nil {
 		p.paused = true
 		return
 	}
-	p.otoPlayer = p.otoCtx.NewPlayer(p.sr)
+	p.otoPlayer = p.otoCtx.NewPlayer(p.lim)
 	if p.otoPlayer == nil {
 		p.paused = true
 		return
 	}
-	p.otoPlayer.SetVolume(p.volume)
+	p.otoPlayer.SetVolume(p.outputVolumeLocked())
 	if resume {
 		p.resumeLocked()
 		return
@@ -2,8 +2,11 @@ package player
 
 import (
 	"io"
+	"math"
 	"testing"
 	"time"
+
+	"github.com/olivier-w/climp/internal/visualizer"
 )
 
 type stubSeekDecoder struct {
@@ -36,17 +39,38 @@ func (d *stubSeekDecoder) SampleRate() int   { return d.sampleRate }
 func (d *stubSeekDecoder) ChannelCount() int { return d.channels }
 
 func TestClampSeekByteOffsetClampsAndAligns(t *testing.T) {
-	got := clampSeekByteOffset(3900*time.Millisecond, 10, 10, 4)
+	got := clampSeekByteOffset(3900*time.Millisecond, 10, 10, 4, 0)
 	if got != 8 {
 		t.Fatalf("expected clamped aligned seek offset 8, got %d", got)
 	}
 
-	got = clampSeekByteOffset(-1*time.Second, 10, 100, 4)
+	got = clampSeekByteOffset(-1*time.Second, 10, 100, 4, 0)
 	if got != 0 {
 		t.Fatalf("expected negative seek to clamp to 0, got %d", got)
 	}
 }
 
+func TestClampSeekByteOffsetStopsShortOfTheEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		target time.Duration
+		total  int64
+		want   int64
+	}{
+		{"past the end", time.Hour, 100, 88},
+		{"exactly the end", 10 * time.Second, 100, 88},
+		{"before the guard", 5 * time.Second, 100, 48},
+		{"shorter than the guard", time.Second, 8, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clampSeekByteOffset(tt.target, 10, tt.total, 4, 10); got != tt.want {
+				t.Fatalf("clampSeekByteOffset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestPauseSetsPausedWithoutToggle(t *testing.T) {
 	p := &Player{}
 	p.Pause()
@@ -55,6 +79,22 @@ func TestPauseSetsPausedWithoutToggle(t *testing.T) {
 	}
 }
 
+func TestRetryOutputKeepsPausedWithoutDevice(t *testing.T) {
+	p := &Player{outputLost: true, paused: true}
+	if p.RetryOutput() {
+		t.Fatal("RetryOutput() = true with no audio context")
+	}
+	if !p.OutputLost() || !p.paused {
+		t.Fatalf("outputLost = %v, paused = %v; want both true", p.outputLost, p.paused)
+	}
+	if err := p.outputErrLocked(); err != nil {
+		t.Fatalf("outputErrLocked() = %v with no output, want nil", err)
+	}
+	if p.OutputDeviceLost() {
+		t.Fatal("OutputDeviceLost() = true with no audio context")
+	}
+}
+
 func TestSeekToClampsAndAlignsToFrameBoundary(t *testing.T) {
 	dec := &stubSeekDecoder{
 		length:     41,
@@ -72,17 +112,98 @@ func TestSeekToClampsAndAlignsToFrameBoundary(t *testing.T) {
 	if err := p.SeekTo(3900*time.Millisecond, false); err != nil {
 		t.Fatalf("SeekTo returned error: %v", err)
 	}
-	if dec.pos != 36 {
-		t.Fatalf("expected decoder seek position 36, got %d", dec.pos)
+	// 39 bytes is within SeekEndMargin (10 bytes) of the end, so the seek
+	// stops at 31, aligned down to 28.
+	if dec.pos != 28 {
+		t.Fatalf("expected decoder seek position 28, got %d", dec.pos)
 	}
-	if got := counter.Pos(); got != 36 {
-		t.Fatalf("expected counter position 36, got %d", got)
+	if got := counter.Pos(); got != 28 {
+		t.Fatalf("expected counter position 28, got %d", got)
 	}
 	if !p.paused {
 		t.Fatal("expected paused state after non-resuming seek")
 	}
 }
 
+func TestSetBoundsMakesTimesRelativeAndRearmsDone(t *testing.T) {
+	dec := &stubSeekDecoder{length: 1000, sampleRate: 44100, channels: 2}
+	counter := &countingReader{}
+	p := &Player{
+		decoder:     dec,
+		counter:     counter,
+		bytesPerSec: 10,
+		canSeek:     true,
+		duration:    100 * time.Second,
+		done:        make(chan struct{}),
+		stopMon:     make(chan struct{}),
+	}
+	defer p.Close()
+	oldDone := p.Done()
+
+	p.SetBounds(10*time.Second, 20*time.Second)
+	if dec.pos != 100 || p.Position() != 0 {
+		t.Fatalf("expected seek to the section start, got decoder pos %d position %v", dec.pos, p.Position())
+	}
+	if got := p.Duration(); got != 10*time.Second {
+		t.Fatalf("expected section duration 10s, got %v", got)
+	}
+	select {
+	case <-oldDone:
+	default:
+		t.Fatal("expected the previous done channel to be released")
+	}
+	if p.Done() == oldDone {
+		t.Fatal("expected a fresh done channel for the section")
+	}
+
+	if err := p.SeekTo(15*time.Second, false); err != nil {
+		t.Fatalf("SeekTo returned error: %v", err)
+	}
+	// SeekEndMargin before the section end (190), aligned to a 4-byte frame.
+	if dec.pos != 188 {
+		t.Fatalf("expected seek clamped short of the section end (188), got %d", dec.pos)
+	}
+
+	// A position already inside the next section is kept.
+	_, _ = dec.Seek(200, io.SeekStart)
+	counter.SetPos(200)
+	p.SetBounds(20*time.Second, 0)
+	if dec.pos != 200 || p.Duration() != 80*time.Second {
+		t.Fatalf("expected no seek into a contiguous section, got pos %d duration %v", dec.pos, p.Duration())
+	}
+}
+
+type stubLiveDecoder struct {
+	stubSeekDecoder
+	reconnects int
+}
+
+func (d *stubLiveDecoder) Reconnect() error {
+	d.reconnects++
+	return nil
+}
+
+func TestReconnectLockedTriesOnceAndClearsEOF(t *testing.T) {
+	dec := &stubLiveDecoder{stubSeekDecoder: stubSeekDecoder{sampleRate: 48000, channels: 2, length: -1}}
+	cr := &countingReader{reader: dec, pos: 4096, eof: true}
+	p := &Player{decoder: dec, counter: cr, stopMon: make(chan struct{})}
+
+	p.reconnectLocked()
+	if dec.reconnects != 1 {
+		t.Fatalf("reconnects = %d, want 1", dec.reconnects)
+	}
+	if cr.eof || cr.Pos() != 4096 {
+		t.Fatalf("counter after reconnect: eof=%v pos=%d, want eof=false pos=4096", cr.eof, cr.Pos())
+	}
+
+	if p.reconnectLocked() {
+		t.Fatal("second underrun should end playback")
+	}
+	if dec.reconnects != 1 {
+		t.Fatalf("reconnects = %d after second underrun, want 1", dec.reconnects)
+	}
+}
+
 func TestPlayerCloseIsIdempotentTransport(t *testing.T) {
 	p := &Player{
 		stopMon: make(chan struct{}),
@@ -95,3 +216,184 @@ func TestPlayerCloseIsIdempotentTransport(t *testing.T) {
 		t.Fatal("expected player to remain closed")
 	}
 }
+
+func TestCountingReaderContinuesIntoNextReaderAtEOF(t *testing.T) {
+	counter := &countingReader{
+		reader: &stubPCMDecoder{data: pcm16(1, 2), sampleRate: playbackSampleRate, channels: 2},
+	}
+	next := &stubPCMDecoder{data: pcm16(3, 4, 5, 6), sampleRate: playbackSampleRate, channels: 2}
+	if !counter.setNext(next) {
+		t.Fatal("expected next reader to be queued")
+	}
+
+	out, err := io.ReadAll(counter)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := pcm16(1, 2, 3, 4, 5, 6); string(out) != string(want) {
+		t.Fatalf("stitched PCM mismatch:\n got %v\nwant %v", out, want)
+	}
+	if got := counter.Pos(); got != int64(len(next.data)) {
+		t.Fatalf("expected position to restart for next track, got %d", got)
+	}
+	if !counter.takeSwitch() {
+		t.Fatal("expected switch to be reported")
+	}
+	if counter.setNext(next) {
+		t.Fatal("expected setNext to fail after the stream ended")
+	}
+}
+
+func TestFinishAdvanceAdoptsPreloadedTrack(t *testing.T) {
+	current := &stubPCMDecoder{data: pcm16(1, 2), sampleRate: playbackSampleRate, channels: 2}
+	next := &stubPCMDecoder{data: make([]byte, 400), sampleRate: playbackSampleRate, channels: 2}
+	counter := &countingReader{reader: current}
+	p := &Player{
+		decoder:     current,
+		counter:     counter,
+		bytesPerSec: 100,
+		canSeek:     true,
+		preloadPath: "next.flac",
+		preload:     &preloadedTrack{path: "next.flac", dec: next},
+		advanced:    make(chan string, 1),
+	}
+	counter.setNext(next)
+
+	if _, err := io.ReadAll(counter); err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	p.mu.Lock()
+	p.finishAdvanceLocked()
+	p.mu.Unlock()
+
+	if p.decoder != next {
+		t.Fatal("expected player to adopt the preloaded decoder")
+	}
+	if got := p.Duration(); got != 4*time.Second {
+		t.Fatalf("expected duration of next track, got %v", got)
+	}
+	if p.preload != nil || p.preloadPath != "" {
+		t.Fatal("expected preload state to be cleared")
+	}
+	select {
+	case path := <-p.Advanced():
+		if path != "next.flac" {
+			t.Fatalf("expected advanced path next.flac, got %q", path)
+		}
+	default:
+		t.Fatal("expected advance notification")
+	}
+}
+
+func TestMuteRestoresVolumeAndAdjustUnmutes(t *testing.T) {
+	p := &Player{volume: 0.6}
+
+	p.Mute()
+	if !p.Muted() || p.Volume() != 0 {
+		t.Fatalf("expected muted at volume 0, got muted=%v volume=%v", p.Muted(), p.Volume())
+	}
+	p.Unmute()
+	if p.Muted() || p.Volume() != 0.6 {
+		t.Fatalf("expected volume 0.6 after unmute, got muted=%v volume=%v", p.Muted(), p.Volume())
+	}
+
+	p.Mute()
+	p.AdjustVolume(-0.1)
+	if p.Muted() {
+		t.Fatal("expected volume change to unmute")
+	}
+	if got := p.Volume(); got < 0.49 || got > 0.51 {
+		t.Fatalf("expected volume 0.5 after adjusting from muted 0.6, got %v", got)
+	}
+}
+
+func TestSetLoopRequiresSeekableOrderedRange(t *testing.T) {
+	p := &Player{canSeek: true}
+	p.SetLoop(5*time.Second, 2*time.Second)
+	if p.loopEnd != 0 {
+		t.Fatal("expected loop with B before A to be ignored")
+	}
+	p.SetLoop(2*time.Second, 5*time.Second)
+	if p.loopStart != 2*time.Second || p.loopEnd != 5*time.Second {
+		t.Fatalf("expected loop 2s-5s, got %v-%v", p.loopStart, p.loopEnd)
+	}
+	p.ClearLoop()
+	if p.loopEnd != 0 {
+		t.Fatal("expected loop to be cleared")
+	}
+
+	live := &Player{}
+	live.SetLoop(time.Second, 2*time.Second)
+	if live.loopEnd != 0 {
+		t.Fatal("expected loop to be ignored for non-seekable players")
+	}
+}
+
+func TestCountingReaderCountsClipsUntilSeek(t *testing.T) {
+	buf := visualizer.NewRingBuffer(64)
+	counter := &countingReader{
+		reader:    &stubPCMDecoder{data: pcm16(32767, -32768, 1000, -16384), sampleRate: playbackSampleRate, channels: 2},
+		sampleBuf: buf,
+	}
+	p := &Player{counter: counter, sampleBuf: buf}
+	if _, err := io.ReadAll(counter); err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	if got := p.ClipCount(); got != 2 {
+		t.Fatalf("ClipCount() = %d, want 2", got)
+	}
+	if got := p.PeakDBFS(); math.Abs(got) > 0.01 {
+		t.Fatalf("PeakDBFS() = %.2f, want 0", got)
+	}
+
+	counter.SetPos(0)
+	if got := p.ClipCount(); got != 0 {
+		t.Fatalf("ClipCount() after seek = %d, want 0", got)
+	}
+	if got := peakDBFS(nil); got != minPeakDBFS {
+		t.Fatalf("peakDBFS(silence) = %.2f, want %.2f", got, minPeakDBFS)
+	}
+}
+
+func TestChannelLevelsMeasuresEachChannel(t *testing.T) {
+	left, right := channelLevels([]int16{16384, 0, -16384, 0})
+	if math.Abs(left-0.5) > 1e-9 || right != 0 {
+		t.Fatalf("channelLevels() = %.3f, %.3f; want 0.5, 0", left, right)
+	}
+	left, right = channelLevels([]int16{1000, 1000, -2000, -2000})
+	if left != right {
+		t.Fatalf("channelLevels(mono) = %v, %v; want equal levels", left, right)
+	}
+	if left, right := (&Player{}).ChannelLevels(); left != 0 || right != 0 {
+		t.Fatalf("ChannelLevels() without audio = %v, %v", left, right)
+	}
+}
+
+func TestSetLatencyClampsToSafeRange(t *testing.T) {
+	defer SetLatency(0)
+	tests := map[time.Duration]time.Duration{
+		0:                     0,
+		time.Millisecond:      MinLatency,
+		40 * time.Millisecond: 40 * time.Millisecond,
+		5 * time.Second:       MaxLatency,
+	}
+	for in, want := range tests {
+		if got := SetLatency(in); got != want || time.Duration(outputLatency.Load()) != want {
+			t.Fatalf("SetLatency(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestSetStartSettingsNormalizesValues(t *testing.T) {
+	defer SetStartSettings(defaultVolume, Speed1x)
+
+	SetStartSettings(0.35, SpeedHalf)
+	if v, s := startSettings(); v != 0.35 || s != SpeedHalf {
+		t.Fatalf("startSettings() = %v, %v; want 0.35, half speed", v, s)
+	}
+	SetStartSettings(1.7, SpeedMode(9))
+	if v, s := startSettings(); v != 1 || s != Speed1x {
+		t.Fatalf("startSettings() = %v, %v; want clamped volume and 1x", v, s)
+	}
+}
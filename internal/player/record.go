@@ -0,0 +1,201 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recordQueueLen is how many reads the recorder can fall behind before it
+// starts dropping audio rather than stall playback.
+const recordQueueLen = 256
+
+// wavHeaderSize is the length of the canonical 44-byte PCM WAV header.
+const wavHeaderSize = 44
+
+// recorder writes the decoded PCM stream to a WAV file. countingReader hands
+// it each read from the audio goroutine; the copies go through a buffered
+// channel to a writer goroutine, so a slow disk never blocks playback. The
+// RIFF and data lengths are written as zero up front and patched on close.
+type recorder struct {
+	f          *os.File
+	ch         chan []byte
+	done       chan struct{}
+	sampleRate int
+	channels   int
+	bytes      atomic.Int64 // PCM bytes written
+	dropped    atomic.Int64 // PCM bytes dropped because the writer fell behind
+	mu         sync.Mutex   // guards closed against a send racing close
+	closed     bool
+	err        error // first write error; read after done is closed
+}
+
+func newRecorder(path string, sampleRate, channels int) (*recorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(wavHeader(sampleRate, channels, 0)); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	r := &recorder{
+		f:          f,
+		ch:         make(chan []byte, recordQueueLen),
+		done:       make(chan struct{}),
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+	go r.run()
+	return r, nil
+}
+
+func (r *recorder) run() {
+	defer close(r.done)
+	for buf := range r.ch {
+		if r.err != nil {
+			continue
+		}
+		n, err := r.f.Write(buf)
+		r.bytes.Add(int64(n))
+		if err != nil {
+			r.err = err
+		}
+	}
+}
+
+// write queues a copy of p. It never blocks: when the queue is full the audio
+// is dropped and counted instead.
+func (r *recorder) write(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	buf := append([]byte(nil), p...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.ch <- buf:
+	default:
+		r.dropped.Add(int64(len(buf)))
+	}
+}
+
+// elapsed returns how much audio has been written so far.
+func (r *recorder) elapsed() time.Duration {
+	bytesPerSec := int64(r.sampleRate * r.channels * 2)
+	if bytesPerSec <= 0 {
+		return 0
+	}
+	return time.Duration(r.bytes.Load() * int64(time.Second) / bytesPerSec)
+}
+
+// close flushes the queue, patches the header lengths, and closes the file.
+// Only the first call does anything.
+func (r *recorder) close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.ch)
+	r.mu.Unlock()
+	<-r.done
+
+	err := r.err
+	size := r.bytes.Load()
+	size -= size % int64(r.channels*2)
+	if err == nil {
+		err = r.f.Truncate(wavHeaderSize + size)
+	}
+	if err == nil {
+		_, err = r.f.WriteAt(wavHeader(r.sampleRate, r.channels, uint32(size)), 0)
+	}
+	if cerr := r.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// wavHeader builds a 16-bit PCM WAV header for dataSize bytes of samples.
+func wavHeader(sampleRate, channels int, dataSize uint32) []byte {
+	h := make([]byte, wavHeaderSize)
+	copy(h[0:], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], 36+dataSize)
+	copy(h[8:], "WAVE")
+	copy(h[12:], "fmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16)
+	binary.LittleEndian.PutUint16(h[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(h[22:], uint16(channels))
+	binary.LittleEndian.PutUint32(h[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(h[28:], uint32(sampleRate*channels*2))
+	binary.LittleEndian.PutUint16(h[32:], uint16(channels*2))
+	binary.LittleEndian.PutUint16(h[34:], 16)
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], dataSize)
+	return h
+}
+
+// errRecording is returned by StartRecording when a recording is running.
+var errRecording = errors.New("already recording")
+
+// StartRecording begins writing the decoded stream to a new WAV file at path.
+// The file must not exist yet. Audio is captured before volume and effects, at
+// the player's output format.
+func (p *Player) StartRecording(path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.counter == nil {
+		return errors.New("player closed")
+	}
+	if p.counter.rec.Load() != nil {
+		return errRecording
+	}
+	rec, err := newRecorder(path, p.decoder.SampleRate(), p.decoder.ChannelCount())
+	if err != nil {
+		return err
+	}
+	p.counter.rec.Store(rec)
+	return nil
+}
+
+// StopRecording finishes the current recording, if any, and returns the
+// error from writing it.
+func (p *Player) StopRecording() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stopRecordingLocked()
+}
+
+func (p *Player) stopRecordingLocked() error {
+	if p.counter == nil {
+		return nil
+	}
+	rec := p.counter.rec.Swap(nil)
+	if rec == nil {
+		return nil
+	}
+	return rec.close()
+}
+
+// Recording reports whether a recording is running and how much audio it
+// holds so far.
+func (p *Player) Recording() (bool, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.counter == nil {
+		return false, 0
+	}
+	rec := p.counter.rec.Load()
+	if rec == nil {
+		return false, 0
+	}
+	return true, rec.elapsed()
+}
@@ -0,0 +1,76 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordingWritesWAVWithPatchedLengths(t *testing.T) {
+	pcm := pcm16(1, -1, 2, -2, 3, -3, 4, -4)
+	dec := &stubSeekDecoder{sampleRate: 48000, channels: 2, length: -1}
+	cr := &countingReader{reader: struct {
+		io.Reader
+		io.Seeker
+	}{bytes.NewReader(pcm), dec}}
+	p := &Player{decoder: dec, counter: cr}
+	path := filepath.Join(t.TempDir(), "rec.wav")
+
+	if err := p.StartRecording(path); err != nil {
+		t.Fatalf("StartRecording() error = %v", err)
+	}
+	if err := p.StartRecording(path); err != errRecording {
+		t.Fatalf("second StartRecording() error = %v, want errRecording", err)
+	}
+	if _, err := io.ReadAll(cr); err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if err := p.StopRecording(); err != nil {
+		t.Fatalf("StopRecording() error = %v", err)
+	}
+	if on, _ := p.Recording(); on {
+		t.Fatal("Recording() = true after StopRecording")
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != wavHeaderSize+len(pcm) {
+		t.Fatalf("file = %d bytes, want %d", len(got), wavHeaderSize+len(pcm))
+	}
+	if string(got[0:4]) != "RIFF" || string(got[8:12]) != "WAVE" || string(got[36:40]) != "data" {
+		t.Fatalf("header = %q, want a RIFF/WAVE/data layout", got[:wavHeaderSize])
+	}
+	if size := binary.LittleEndian.Uint32(got[4:]); size != uint32(36+len(pcm)) {
+		t.Fatalf("RIFF size = %d, want %d", size, 36+len(pcm))
+	}
+	if size := binary.LittleEndian.Uint32(got[40:]); size != uint32(len(pcm)) {
+		t.Fatalf("data size = %d, want %d", size, len(pcm))
+	}
+	if rate := binary.LittleEndian.Uint32(got[24:]); rate != 48000 {
+		t.Fatalf("sample rate = %d, want 48000", rate)
+	}
+	if !bytes.Equal(got[wavHeaderSize:], pcm) {
+		t.Fatal("recorded samples differ from the stream")
+	}
+}
+
+func TestRecorderElapsedFollowsWrittenBytes(t *testing.T) {
+	rec, err := newRecorder(filepath.Join(t.TempDir(), "rec.wav"), 48000, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.write(make([]byte, 48000*4/2))
+	if err := rec.close(); err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+	if got := rec.elapsed(); got != 500*time.Millisecond {
+		t.Fatalf("elapsed() = %v, want 500ms", got)
+	}
+	rec.write([]byte{1, 2, 3, 4}) // after close: ignored, must not panic
+}
@@ -0,0 +1,281 @@
+package player
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/jfreymuth/oggvorbis"
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/meta"
+)
+
+// GainMode selects which ReplayGain value is applied during playback.
+type GainMode int
+
+const (
+	GainOff GainMode = iota
+	GainTrack
+	GainAlbum
+)
+
+// Next cycles to the next gain mode: off → track → album → off.
+func (g GainMode) Next() GainMode {
+	switch g {
+	case GainOff:
+		return GainTrack
+	case GainTrack:
+		return GainAlbum
+	default:
+		return GainOff
+	}
+}
+
+// Label returns a display label for the gain mode.
+func (g GainMode) Label() string {
+	switch g {
+	case GainTrack:
+		return "[rg track]"
+	case GainAlbum:
+		return "[rg album]"
+	default:
+		return ""
+	}
+}
+
+const (
+	// replayGainReferenceDB approximates the ReplayGain 2.0 reference loudness
+	// (-18 LUFS) as an RMS level, used when a file has no tags.
+	replayGainReferenceDB = -18.0
+	gainScanSeconds       = 5
+	maxScanBoostDB        = 6.0
+)
+
+// ReplayGain holds ReplayGain tag values read from a file.
+// Gains are in dB; peaks are linear sample peaks (0 when not tagged).
+type ReplayGain struct {
+	TrackGain float64
+	TrackPeak float64
+	AlbumGain float64
+	AlbumPeak float64
+	HasTrack  bool
+	HasAlbum  bool
+}
+
+// GainDB returns the gain to apply for mode, limited by the tagged peak so the
+// result never clips. Album mode falls back to the track gain when untagged.
+// Returns false when the file has no usable tag for mode.
+func (rg ReplayGain) GainDB(mode GainMode) (float64, bool) {
+	var gain, peak float64
+	switch {
+	case mode == GainAlbum && rg.HasAlbum:
+		gain, peak = rg.AlbumGain, rg.AlbumPeak
+	case mode != GainOff && rg.HasTrack:
+		gain, peak = rg.TrackGain, rg.TrackPeak
+	default:
+		return 0, false
+	}
+	if peak > 0 {
+		gain = math.Min(gain, -20*math.Log10(peak))
+	}
+	return gain, true
+}
+
+// set records one tag if key is a ReplayGain field.
+func (rg *ReplayGain) set(key, value string) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(value, "dB"), "db"))
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+	switch strings.ToUpper(strings.TrimSpace(key)) {
+	case "REPLAYGAIN_TRACK_GAIN":
+		rg.TrackGain, rg.HasTrack = v, true
+	case "REPLAYGAIN_TRACK_PEAK":
+		rg.TrackPeak = v
+	case "REPLAYGAIN_ALBUM_GAIN":
+		rg.AlbumGain, rg.HasAlbum = v, true
+	case "REPLAYGAIN_ALBUM_PEAK":
+		rg.AlbumPeak = v
+	}
+}
+
+func replayGainFromID3(tag *id3v2.Tag) ReplayGain {
+	var rg ReplayGain
+	for _, f := range tag.GetFrames("TXXX") {
+		if udtf, ok := f.(id3v2.UserDefinedTextFrame); ok {
+			rg.set(udtf.Description, udtf.Value)
+		}
+	}
+	return rg
+}
+
+// readReplayGain reads ReplayGain tags from FLAC, Ogg Vorbis, and MP4 files.
+// MP3 tags are read alongside the other ID3 fields in ReadMetadata.
+func readReplayGain(path, ext string) ReplayGain {
+	var rg ReplayGain
+	f, err := os.Open(path)
+	if err != nil {
+		return rg
+	}
+	defer f.Close()
+
+	switch ext {
+	case ".flac":
+		// Parse returns the blocks read so far even if a later one is bad.
+		stream, _ := flac.Parse(f)
+		if stream == nil {
+			return rg
+		}
+		for _, block := range stream.Blocks {
+			if vc, ok := block.Body.(*meta.VorbisComment); ok {
+				for _, tag := range vc.Tags {
+					rg.set(tag[0], tag[1])
+				}
+			}
+		}
+	case ".ogg":
+		r, err := oggvorbis.NewReader(f)
+		if err != nil {
+			return rg
+		}
+		for _, c := range r.CommentHeader().Comments {
+			if key, value, ok := strings.Cut(c, "="); ok {
+				rg.set(key, value)
+			}
+		}
+	case ".m4a", ".m4b", ".aac":
+		readMP4ReplayGain(f, &rg)
+	}
+	return rg
+}
+
+// readMP4ReplayGain walks moov/udta/meta/ilst looking for iTunes freeform
+// ("----") atoms named replaygain_*.
+func readMP4ReplayGain(r io.ReadSeeker, rg *ReplayGain) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return
+	}
+	walkMP4(r, 0, end, []string{"moov", "udta", "meta", "ilst"}, rg)
+}
+
+func walkMP4(r io.ReadSeeker, start, end int64, path []string, rg *ReplayGain) {
+	for pos := start; pos+8 <= end; {
+		typ, bodyStart, boxEnd, ok := readMP4BoxHeader(r, pos, end)
+		if !ok {
+			return
+		}
+		switch {
+		case len(path) > 0 && typ == path[0]:
+			if typ == "meta" {
+				bodyStart += 4 // full box: version + flags
+			}
+			walkMP4(r, bodyStart, boxEnd, path[1:], rg)
+		case len(path) == 0 && typ == "----":
+			readMP4Freeform(r, bodyStart, boxEnd, rg)
+		}
+		pos = boxEnd
+	}
+}
+
+func readMP4Freeform(r io.ReadSeeker, start, end int64, rg *ReplayGain) {
+	var name, value string
+	for pos := start; pos+8 <= end; {
+		typ, bodyStart, boxEnd, ok := readMP4BoxHeader(r, pos, end)
+		if !ok {
+			return
+		}
+		// mean/name skip 4 bytes of version+flags; data skips type+locale.
+		skip := int64(4)
+		if typ == "data" {
+			skip = 8
+		}
+		if (typ == "name" || typ == "data") && boxEnd-bodyStart > skip && boxEnd-bodyStart < 1024 {
+			buf := make([]byte, boxEnd-bodyStart-skip)
+			if _, err := r.Seek(bodyStart+skip, io.SeekStart); err != nil {
+				return
+			}
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			if typ == "name" {
+				name = string(buf)
+			} else {
+				value = string(buf)
+			}
+		}
+		pos = boxEnd
+	}
+	if name != "" {
+		rg.set(name, value)
+	}
+}
+
+// readMP4BoxHeader reads the box header at pos and returns its type, body
+// start, and end offset.
+func readMP4BoxHeader(r io.ReadSeeker, pos, limit int64) (string, int64, int64, bool) {
+	var hdr [16]byte
+	if _, err := r.Seek(pos, io.SeekStart); err != nil {
+		return "", 0, 0, false
+	}
+	if _, err := io.ReadFull(r, hdr[:8]); err != nil {
+		return "", 0, 0, false
+	}
+	size := int64(binary.BigEndian.Uint32(hdr[:4]))
+	typ := string(hdr[4:8])
+	bodyStart := pos + 8
+	switch size {
+	case 0:
+		size = limit - pos
+	case 1:
+		if _, err := io.ReadFull(r, hdr[8:16]); err != nil {
+			return "", 0, 0, false
+		}
+		size = int64(binary.BigEndian.Uint64(hdr[8:16]))
+		bodyStart += 8
+	}
+	if size < bodyStart-pos || pos+size > limit {
+		return "", 0, 0, false
+	}
+	return typ, bodyStart, pos + size, true
+}
+
+// scanGainDB estimates a gain for untagged audio from the RMS level of the
+// first few seconds, limited so the scanned peak does not clip.
+func scanGainDB(dec io.Reader) (float64, bool) {
+	buf := make([]byte, 64*1024)
+	remaining := playbackSampleRate * playbackFrameSize * gainScanSeconds
+	var sumSq float64
+	var peak, count int
+	for remaining > 0 {
+		n, err := dec.Read(buf[:min(len(buf), remaining)])
+		n -= n % 2
+		for i := 0; i < n; i += 2 {
+			s := int(int16(binary.LittleEndian.Uint16(buf[i:])))
+			sumSq += float64(s * s)
+			if s < 0 {
+				s = -s
+			}
+			peak = max(peak, s)
+			count++
+		}
+		remaining -= n
+		if err != nil {
+			break
+		}
+	}
+	if count == 0 || peak == 0 {
+		return 0, false
+	}
+
+	rmsDB := 20 * math.Log10(math.Sqrt(sumSq/float64(count))/32768)
+	peakDB := 20 * math.Log10(float64(peak)/32768)
+	gain := math.Min(replayGainReferenceDB-rmsDB, maxScanBoostDB)
+	return math.Min(gain, -peakDB), true
+}
@@ -0,0 +1,83 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestReplayGainParsesTagsAndLimitsByPeak(t *testing.T) {
+	var rg ReplayGain
+	rg.set("replaygain_track_gain", "-6.50 dB")
+	rg.set("REPLAYGAIN_TRACK_PEAK", "0.98")
+	rg.set("REPLAYGAIN_ALBUM_GAIN", "+3.00 dB")
+	rg.set("REPLAYGAIN_ALBUM_PEAK", "0.9")
+	rg.set("REPLAYGAIN_REFERENCE_LOUDNESS", "89.0 dB")
+
+	if got, ok := rg.GainDB(GainTrack); !ok || got != -6.5 {
+		t.Fatalf("track gain = %v, %v; want -6.5, true", got, ok)
+	}
+	got, ok := rg.GainDB(GainAlbum)
+	want := -20 * math.Log10(0.9)
+	if !ok || math.Abs(got-want) > 1e-9 {
+		t.Fatalf("album gain = %v, %v; want peak-limited %v", got, ok, want)
+	}
+	if _, ok := rg.GainDB(GainOff); ok {
+		t.Fatal("expected no gain when mode is off")
+	}
+}
+
+func TestReplayGainAlbumFallsBackToTrack(t *testing.T) {
+	rg := ReplayGain{TrackGain: -4, HasTrack: true}
+	if got, ok := rg.GainDB(GainAlbum); !ok || got != -4 {
+		t.Fatalf("album fallback = %v, %v; want -4, true", got, ok)
+	}
+}
+
+func TestReadMP4ReplayGainFreeformAtoms(t *testing.T) {
+	box := func(typ string, body ...[]byte) []byte {
+		payload := bytes.Join(body, nil)
+		out := make([]byte, 8, 8+len(payload))
+		binary.BigEndian.PutUint32(out, uint32(8+len(payload)))
+		copy(out[4:], typ)
+		return append(out, payload...)
+	}
+	freeform := box("----",
+		box("mean", make([]byte, 4), []byte("com.apple.iTunes")),
+		box("name", make([]byte, 4), []byte("replaygain_track_gain")),
+		box("data", make([]byte, 8), []byte("-7.25 dB")),
+	)
+	file := bytes.Join([][]byte{
+		box("ftyp", []byte("M4A ")),
+		box("moov", box("udta", box("meta", make([]byte, 4), box("hdlr"), box("ilst", freeform)))),
+	}, nil)
+
+	var rg ReplayGain
+	readMP4ReplayGain(bytes.NewReader(file), &rg)
+	if !rg.HasTrack || rg.TrackGain != -7.25 {
+		t.Fatalf("expected track gain -7.25, got %+v", rg)
+	}
+}
+
+func TestScanGainDBLimitsBoostByPeak(t *testing.T) {
+	// A quiet constant signal gets boosted, but never past the clip point.
+	quiet := bytes.Repeat(pcm16(1000, -1000), 4800)
+	got, ok := scanGainDB(bytes.NewReader(quiet))
+	if !ok {
+		t.Fatal("expected scan result")
+	}
+	if got <= 0 || got > maxScanBoostDB {
+		t.Fatalf("expected bounded boost, got %v", got)
+	}
+
+	loud := bytes.Repeat(pcm16(32000, -32000), 4800)
+	got, ok = scanGainDB(bytes.NewReader(loud))
+	if !ok || got >= 0 {
+		t.Fatalf("expected attenuation for loud input, got %v, %v", got, ok)
+	}
+
+	if _, ok := scanGainDB(bytes.NewReader(make([]byte, 400))); ok {
+		t.Fatal("expected no result for silence")
+	}
+}
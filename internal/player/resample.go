@@ -0,0 +1,142 @@
+package player
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+)
+
+// ResampleQuality selects how sources that are not 48 kHz are converted.
+type ResampleQuality int
+
+const (
+	// ResampleLinear interpolates between neighbouring samples. It is cheap
+	// but lets some aliasing through, most audibly when upsampling.
+	ResampleLinear ResampleQuality = iota
+	// ResampleSinc uses a windowed-sinc filter for a cleaner conversion at
+	// a higher CPU cost.
+	ResampleSinc
+)
+
+const (
+	// sincHalfTaps is the filter half-width in source frames when
+	// upsampling; downsampling widens it by the rate ratio.
+	sincHalfTaps = 16
+	// sincPhases is the number of fractional positions with a precomputed
+	// kernel. Positions in between use the nearest one.
+	sincPhases = 512
+)
+
+// resampleQuality is the converter used by decoders opened from now on.
+var resampleQuality atomic.Int32
+
+// SetResampleQuality sets the converter for tracks opened after the call.
+func SetResampleQuality(q ResampleQuality) {
+	resampleQuality.Store(int32(q))
+}
+
+// ParseResampleQuality parses a --resample value: "linear" or "hq".
+func ParseResampleQuality(s string) (ResampleQuality, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "linear", "":
+		return ResampleLinear, nil
+	case "hq", "sinc":
+		return ResampleSinc, nil
+	}
+	return ResampleLinear, fmt.Errorf("unknown resample quality %q (use linear or hq)", s)
+}
+
+// sincKernel holds a windowed-sinc low-pass filter sampled at sincPhases+1
+// fractional offsets. Row p has 2*half taps for source frames
+// srcFrame-half+1 .. srcFrame+half, where the output sits p/sincPhases of
+// the way from srcFrame to srcFrame+1. Each row sums to 1.
+type sincKernel struct {
+	half int
+	taps [][]float64
+}
+
+// newSincKernel builds the filter for converting srcRate to outRate. When
+// downsampling, the cutoff drops to the output Nyquist frequency and the
+// kernel widens to keep the same number of zero crossings.
+func newSincKernel(srcRate, outRate int) *sincKernel {
+	cutoff := 1.0
+	if srcRate > outRate {
+		cutoff = float64(outRate) / float64(srcRate)
+	}
+	half := int(math.Ceil(sincHalfTaps / cutoff))
+
+	k := &sincKernel{half: half, taps: make([][]float64, sincPhases+1)}
+	for p := range k.taps {
+		frac := float64(p) / sincPhases
+		row := make([]float64, 2*half)
+		sum := 0.0
+		for j := range row {
+			x := float64(j-half+1) - frac
+			row[j] = cutoff * sinc(cutoff*x) * blackman(x, float64(half))
+			sum += row[j]
+		}
+		for j := range row {
+			row[j] /= sum
+		}
+		k.taps[p] = row
+	}
+	return k
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is the Blackman window over -width..width.
+func blackman(x, width float64) float64 {
+	if math.Abs(x) >= width {
+		return 0
+	}
+	t := math.Pi * x / width
+	return 0.42 + 0.5*math.Cos(t) + 0.08*math.Cos(2*t)
+}
+
+// sincFrame filters the source frames around srcFrame for an output frame
+// fracNum/outRate of the way to the next source frame. Frames
+// before the start of the file count as silence.
+func (d *normalizedDecoder) sincFrame(srcFrame, fracNum int64) (int16, int16, error) {
+	k := d.sinc
+	first := srcFrame - int64(k.half) + 1
+	last := min(srcFrame+int64(k.half), d.totalSrcFrames-1)
+	d.compactFrames(first)
+	if err := d.bufferThrough(last); err != nil {
+		return 0, 0, err
+	}
+
+	den := int64(d.outRate)
+	row := k.taps[(fracNum*sincPhases+den/2)/den]
+	var left, right float64
+	for j, w := range row {
+		f := first + int64(j)
+		if f < d.srcBaseFrame {
+			continue
+		}
+		l, r, err := d.frameAt(f)
+		if err != nil {
+			return 0, 0, err
+		}
+		left += w * float64(l)
+		right += w * float64(r)
+	}
+	return clampSample(left), clampSample(right), nil
+}
+
+func clampSample(v float64) int16 {
+	v = math.Round(v)
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
@@ -0,0 +1,116 @@
+package player
+
+import (
+	"errors"
+	"io"
+)
+
+// reverseWindowFrames is how much audio reverseReader decodes forward at a
+// time before emitting it back to front.
+const reverseWindowFrames = 4096
+
+// reverseReader reads a decoder's PCM backwards: frames come out last to
+// first, each frame's samples in their usual channel order. It positions a
+// window with the decoder's own Seek, decodes it forward, and emits it
+// reversed, so codec state such as the AAC IMDCT overlap is rebuilt the same
+// way a forward seek rebuilds it (the native AAC reader seeks through
+// locateRawFrame and decodes the preceding access unit first).
+//
+// The decoder's forward position is saved and restored around every window,
+// so forward Read and Seek calls see no change. Offsets given to Seek count
+// from the end of the track, the start of the reversed stream.
+type reverseReader struct {
+	dec       audioDecoder
+	frameSize int64
+	length    int64  // whole frames, in bytes
+	start     int64  // source offset of window[0]
+	window    []byte // decoded forward, emitted from the end
+	left      int    // bytes of window not yet emitted
+}
+
+func newReverseReader(dec audioDecoder) (*reverseReader, error) {
+	frameSize := int64(dec.ChannelCount() * 2)
+	if frameSize <= 0 || dec.Length() < 0 {
+		return nil, errors.New("reverse: unknown PCM layout or length")
+	}
+	length := dec.Length() - dec.Length()%frameSize
+	return &reverseReader{
+		dec:       dec,
+		frameSize: frameSize,
+		length:    length,
+		start:     length,
+		window:    make([]byte, reverseWindowFrames*frameSize),
+	}, nil
+}
+
+func (r *reverseReader) Read(p []byte) (int, error) {
+	fs := int(r.frameSize)
+	if len(p) < fs {
+		return 0, io.ErrShortBuffer
+	}
+	if r.left == 0 {
+		if r.start == 0 {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := 0
+	for n+fs <= len(p) && r.left > 0 {
+		n += copy(p[n:n+fs], r.window[r.left-fs:r.left])
+		r.left -= fs
+	}
+	return n, nil
+}
+
+// fill decodes the window ending at r.start.
+func (r *reverseReader) fill() error {
+	saved, err := r.dec.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	from := max(r.start-int64(len(r.window)), 0)
+	if _, err := r.dec.Seek(from, io.SeekStart); err != nil {
+		return err
+	}
+	n, err := io.ReadFull(r.dec, r.window[:r.start-from])
+	if _, serr := r.dec.Seek(saved, io.SeekStart); err == nil {
+		err = serr
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	n -= n % int(r.frameSize)
+	if n == 0 {
+		// The decoder came up short of its reported length; treat the
+		// undecodable tail as the end of the reversed stream.
+		r.start = 0
+		return io.EOF
+	}
+	// A decoder that ends short of its reported length fills less of the
+	// window; the missing tail is skipped.
+	r.left = n
+	r.start = from
+	return nil
+}
+
+// Seek positions the reversed stream; offset 0 is the end of the track.
+func (r *reverseReader) Seek(offset int64, whence int) (int64, error) {
+	pos := r.length - r.start - int64(r.left) // bytes emitted so far
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos += offset
+	case io.SeekEnd:
+		pos = r.length + offset
+	default:
+		return pos, errors.New("reverse: invalid whence")
+	}
+	pos = min(max(pos, 0), r.length)
+	pos -= pos % r.frameSize
+	r.start = r.length - pos
+	r.left = 0
+	return pos, nil
+}
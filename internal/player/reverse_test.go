@@ -0,0 +1,64 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// reverseFrames returns pcm with its frames in reverse order.
+func reverseFrames(pcm []byte, frameSize int) []byte {
+	out := make([]byte, 0, len(pcm))
+	for end := len(pcm); end >= frameSize; end -= frameSize {
+		out = append(out, pcm[end-frameSize:end]...)
+	}
+	return out
+}
+
+func TestReverseReaderEmitsFramesBackwardsWithoutMovingTheDecoder(t *testing.T) {
+	samples := make([]int16, 2*(reverseWindowFrames*2+123))
+	for i := range samples {
+		samples[i] = int16(i)
+	}
+	pcm := pcm16(samples...)
+	dec := stubPCMReader{bytes.NewReader(pcm), 2}
+	if _, err := dec.Seek(400, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := newReverseReader(dec)
+	if err != nil {
+		t.Fatalf("newReverseReader() error = %v", err)
+	}
+	// Reads that are not a whole number of frames still return whole frames.
+	var got []byte
+	buf := make([]byte, 1023)
+	for {
+		n, err := r.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+	}
+	if !bytes.Equal(got, reverseFrames(pcm, 4)) {
+		t.Fatal("reversed stream differs from the forward frames in reverse order")
+	}
+	if pos, _ := dec.Seek(0, io.SeekCurrent); pos != 400 {
+		t.Fatalf("decoder position = %d after reverse reads, want 400", pos)
+	}
+
+	if pos, err := r.Seek(8, io.SeekStart); err != nil || pos != 8 {
+		t.Fatalf("Seek(8) = %d, %v", pos, err)
+	}
+	buf = make([]byte, 7)
+	n, err := r.Read(buf)
+	if err != nil || n != 4 || !bytes.Equal(buf[:4], pcm[len(pcm)-12:len(pcm)-8]) {
+		t.Fatalf("Read after Seek = %d %v %v, want the third frame from the end", n, err, buf[:n])
+	}
+	if _, err := r.Read(make([]byte, 3)); err != io.ErrShortBuffer {
+		t.Fatalf("Read into a buffer smaller than a frame: err = %v, want io.ErrShortBuffer", err)
+	}
+}
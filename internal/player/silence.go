@@ -0,0 +1,147 @@
+package player
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+const (
+	// silencePeak and silenceRMS are the levels, as fractions of full scale,
+	// that a window must stay under in both measures to count as silent:
+	// about -50 dBFS peak and -60 dBFS RMS. Quiet passages in music sit well
+	// above these.
+	silencePeak = 0.00316
+	silenceRMS  = 0.001
+	// minSilenceMillis is how much silence plays before the rest is skipped, so
+	// short pauses and fades stay intact.
+	minSilenceMillis = 1500
+	// silenceWindowMillis is the length of one analysis window.
+	silenceWindowMillis = 20
+)
+
+// silenceSkipper sits between countingReader and speedReader. With skipping
+// on, sustained silence plays for minSilenceMillis and the rest is read and
+// dropped until sound returns, so long stretches of dead air pass as a short
+// pause. countingReader has already counted the dropped bytes, so the
+// position moves past them as if they had played. Off, reads pass through.
+type silenceSkipper struct {
+	source          io.Reader
+	frameSize       int
+	windowFrames    int
+	minSilentFrames int
+	enabled         atomic.Bool
+	dropPend        atomic.Bool // discard pending before the next read (after a seek)
+	pending         []byte      // partial frame carried over from the previous read
+	silentFrames    int         // length of the current silent run
+}
+
+func newSilenceSkipper(source io.Reader, frameSize, sampleRate int) *silenceSkipper {
+	return &silenceSkipper{
+		source:          source,
+		frameSize:       frameSize,
+		windowFrames:    max(sampleRate*silenceWindowMillis/1000, 1),
+		minSilentFrames: sampleRate * minSilenceMillis / 1000,
+	}
+}
+
+func (s *silenceSkipper) setEnabled(on bool) {
+	s.enabled.Store(on)
+}
+
+// reset drops any carried partial frame and the current silent run; called
+// when the stream is repositioned.
+func (s *silenceSkipper) reset() {
+	s.dropPend.Store(true)
+}
+
+func (s *silenceSkipper) Read(p []byte) (int, error) {
+	if s.dropPend.Swap(false) {
+		s.pending = s.pending[:0]
+		s.silentFrames = 0
+	}
+	if !s.enabled.Load() && len(s.pending) == 0 {
+		s.silentFrames = 0
+		return s.source.Read(p)
+	}
+
+	fs := s.frameSize
+	if len(p) < fs {
+		if n := copy(p, s.pending); n > 0 {
+			s.pending = s.pending[n:]
+			return n, nil
+		}
+		return s.source.Read(p)
+	}
+
+	for {
+		carried := copy(p, s.pending)
+		s.pending = s.pending[:0]
+		n, err := s.source.Read(p[carried:])
+		total := carried + n
+
+		aligned := total - total%fs
+		if aligned < total {
+			s.pending = append(s.pending, p[aligned:total]...)
+		}
+		if kept := s.dropSilence(p[:aligned]); kept > 0 {
+			return kept, nil
+		}
+		if err != nil {
+			if len(s.pending) > 0 {
+				n := copy(p, s.pending)
+				s.pending = s.pending[:0]
+				return n, err
+			}
+			return 0, err
+		}
+	}
+}
+
+// dropSilence removes the frames of buf that fall past minSilenceMillis into
+// a silent run, compacting the rest to the front. It returns the bytes kept.
+func (s *silenceSkipper) dropSilence(buf []byte) int {
+	if !s.enabled.Load() {
+		s.silentFrames = 0
+		return len(buf)
+	}
+	windowBytes := s.windowFrames * s.frameSize
+	kept := 0
+	for off := 0; off < len(buf); off += windowBytes {
+		window := buf[off:min(off+windowBytes, len(buf))]
+		if isSilent(window) {
+			s.silentFrames += len(window) / s.frameSize
+		} else {
+			s.silentFrames = 0
+		}
+		if s.silentFrames > s.minSilentFrames {
+			continue
+		}
+		kept += copy(buf[kept:], window)
+	}
+	return kept
+}
+
+// isSilent reports whether s16le samples stay under both silencePeak and
+// silenceRMS.
+func isSilent(buf []byte) bool {
+	n := len(buf) / 2
+	if n == 0 {
+		return true
+	}
+	var peak int32
+	var sum float64
+	for off := 0; off+2 <= len(buf); off += 2 {
+		v := int32(int16(binary.LittleEndian.Uint16(buf[off:])))
+		if v < 0 {
+			v = -v
+		}
+		peak = max(peak, v)
+		sum += float64(v) * float64(v)
+	}
+	if float64(peak) >= silencePeak*32768 {
+		return false
+	}
+	return math.Sqrt(sum/float64(n)) < silenceRMS*32768
+}
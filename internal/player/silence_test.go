@@ -0,0 +1,65 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+	"testing/iotest"
+)
+
+// toneThenSilence returns 48 kHz stereo PCM: a tone at amp, seconds of
+// silence, and the tone again.
+func toneThenSilence(amp, silence float64) []byte {
+	tone := make([]int16, 0, 2*48000)
+	for i := 0; i < 48000; i++ {
+		v := int16(amp * 32767 * math.Sin(2*math.Pi*440*float64(i)/48000))
+		tone = append(tone, v, v)
+	}
+	var buf bytes.Buffer
+	buf.Write(pcm16(tone...))
+	buf.Write(make([]byte, int(silence*48000)*playbackFrameSize))
+	buf.Write(pcm16(tone...))
+	return buf.Bytes()
+}
+
+func TestSilenceSkipperDropsLongSilence(t *testing.T) {
+	src := toneThenSilence(0.5, 5)
+	s := newSilenceSkipper(bytes.NewReader(src), playbackFrameSize, playbackSampleRate)
+	s.setEnabled(true)
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	// Both tones and about minSilenceMillis of the gap remain.
+	want := (2*48000 + 48000*minSilenceMillis/1000) * playbackFrameSize
+	window := 48000 * silenceWindowMillis / 1000 * playbackFrameSize
+	if len(got) < want-window || len(got) > want+window {
+		t.Fatalf("output = %d bytes, want about %d", len(got), want)
+	}
+	if !bytes.Equal(got[len(got)-48000*playbackFrameSize:], src[len(src)-48000*playbackFrameSize:]) {
+		t.Fatal("expected the tone after the silence to play in full")
+	}
+}
+
+func TestSilenceSkipperKeepsQuietMusicAndShortPauses(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		src     []byte
+		enabled bool
+	}{
+		{name: "quiet passage", src: toneThenSilence(0.01, 0), enabled: true},
+		{name: "short pause", src: toneThenSilence(0.5, 1), enabled: true},
+		{name: "disabled", src: toneThenSilence(0.5, 5), enabled: false},
+	} {
+		s := newSilenceSkipper(iotest.HalfReader(bytes.NewReader(tc.src)), playbackFrameSize, playbackSampleRate)
+		s.setEnabled(tc.enabled)
+		got, err := io.ReadAll(s)
+		if err != nil {
+			t.Fatalf("%s: ReadAll() error = %v", tc.name, err)
+		}
+		if !bytes.Equal(got, tc.src) {
+			t.Fatalf("%s: output = %d bytes, want the input's %d unchanged", tc.name, len(got), len(tc.src))
+		}
+	}
+}
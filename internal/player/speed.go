@@ -38,13 +38,29 @@ func (s SpeedMode) Label() string {
 	}
 }
 
+// tempo returns the playback rate multiplier for the speed mode.
+func (s SpeedMode) tempo() float64 {
+	switch s {
+	case Speed2x:
+		return 2
+	case SpeedHalf:
+		return 0.5
+	default:
+		return 1
+	}
+}
+
 // speedReader sits between countingReader and Oto, dropping or duplicating
 // frames to achieve playback speed changes. At 2x it drops every other frame;
-// at 0.5x it duplicates each frame.
+// at 0.5x it duplicates each frame. With keepPitch set it time-stretches
+// through WSOLA instead, which costs more CPU but keeps voices natural.
 type speedReader struct {
 	source    io.Reader
 	frameSize int // channels * 2 (16-bit samples)
 	speed     SpeedMode
+	keepPitch bool
+	stretch   *wsola
+	srcErr    error  // deferred source error while stretched output drains
 	buf       []byte // leftover bytes from 0.5x duplication
 	tmpBuf    []byte // reusable read buffer (grow-only)
 	tmpExp    []byte // reusable expanded buffer for 0.5x (grow-only)
@@ -56,14 +72,19 @@ func newSpeedReader(source io.Reader, frameSize int) *speedReader {
 		source:    source,
 		frameSize: frameSize,
 		speed:     Speed1x,
+		stretch:   newWSOLA(playbackSampleRate, frameSize/2),
 	}
 }
 
 func (sr *speedReader) Read(p []byte) (int, error) {
 	sr.mu.Lock()
 	speed := sr.speed
+	preserve := sr.keepPitch
 	sr.mu.Unlock()
 
+	if preserve && speed != Speed1x {
+		return sr.readStretch(p)
+	}
 	switch speed {
 	case Speed2x:
 		return sr.read2x(p)
@@ -156,15 +177,67 @@ func (sr *speedReader) readHalf(p []byte) (int, error) {
 	return 0, err
 }
 
+// readStretch returns time-stretched PCM, pulling source data until the
+// stretcher has output. It holds sr.mu so seeks cannot reset the stretcher
+// mid-read.
+func (sr *speedReader) readStretch(p []byte) (int, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	st := sr.stretch
+	for len(st.out) == 0 {
+		if sr.srcErr != nil {
+			err := sr.srcErr
+			sr.srcErr = nil
+			return 0, err
+		}
+		if cap(sr.tmpBuf) < 16384 {
+			sr.tmpBuf = make([]byte, 16384)
+		}
+		n, err := sr.source.Read(sr.tmpBuf[:16384])
+		st.write(sr.tmpBuf[:n])
+		st.process()
+		if err != nil {
+			st.flush()
+			sr.srcErr = err
+		}
+	}
+	n := copy(p, st.out)
+	st.out = st.out[:copy(st.out, st.out[n:])]
+	return n, nil
+}
+
+// bufferedSourceBytes is the source PCM read from upstream but not yet output.
+func (sr *speedReader) bufferedSourceBytes() int64 {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if !sr.keepPitch || sr.speed == Speed1x {
+		return 0
+	}
+	return sr.stretch.bufferedBytes()
+}
+
 func (sr *speedReader) setSpeed(s SpeedMode) {
 	sr.mu.Lock()
 	sr.speed = s
 	sr.buf = nil
+	sr.stretch.reset()
+	sr.stretch.tempo = s.tempo()
+	sr.mu.Unlock()
+}
+
+func (sr *speedReader) setKeepPitch(on bool) {
+	sr.mu.Lock()
+	sr.keepPitch = on
+	sr.buf = nil
+	sr.stretch.reset()
 	sr.mu.Unlock()
 }
 
 func (sr *speedReader) clearBuf() {
 	sr.mu.Lock()
 	sr.buf = nil
+	sr.stretch.reset()
+	sr.srcErr = nil
 	sr.mu.Unlock()
 }
@@ -14,6 +14,8 @@ const (
 
 // streamDecoder adapts an ffmpeg live decode subprocess to the audioDecoder interface.
 type streamDecoder struct {
+	url       string
+	mu        sync.Mutex // guards cmd, stdout and waitDone across Reconnect
 	cmd       *exec.Cmd
 	stdout    io.ReadCloser
 	titleMeta *icyTitleWatcher
@@ -23,9 +25,27 @@ type streamDecoder struct {
 }
 
 func newStreamDecoder(url string) (*streamDecoder, error) {
+	d := &streamDecoder{url: url}
+	if err := d.start(); err != nil {
+		return nil, err
+	}
+
+	titleMeta, err := newICYTitleWatcher(url)
+	if err != nil {
+		titleMeta = nil
+	}
+	d.titleMeta = titleMeta
+	if titleMeta != nil {
+		d.titles = titleMeta.Updates()
+	}
+	return d, nil
+}
+
+// start launches ffmpeg for d.url and makes its output the decoder's source.
+func (d *streamDecoder) start() error {
 	ffmpeg, err := exec.LookPath("ffmpeg")
 	if err != nil {
-		return nil, fmt.Errorf("ffmpeg not found (required for live stream playback)")
+		return fmt.Errorf("ffmpeg not found (required for live stream playback)")
 	}
 
 	cmd := exec.Command(
@@ -36,7 +56,7 @@ func newStreamDecoder(url string) (*streamDecoder, error) {
 		"-reconnect", "1",
 		"-reconnect_streamed", "1",
 		"-reconnect_delay_max", "5",
-		"-i", url,
+		"-i", d.url,
 		"-vn",
 		"-ac", "2",
 		"-ar", "48000",
@@ -48,35 +68,55 @@ func newStreamDecoder(url string) (*streamDecoder, error) {
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, fmt.Errorf("setting up ffmpeg stream: %w", err)
+		return fmt.Errorf("setting up ffmpeg stream: %w", err)
 	}
 	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("starting ffmpeg stream: %w", err)
+		return fmt.Errorf("starting ffmpeg stream: %w", err)
 	}
 
-	titleMeta, err := newICYTitleWatcher(url)
-	if err != nil {
-		titleMeta = nil
-	}
-
-	d := &streamDecoder{
-		cmd:       cmd,
-		stdout:    stdout,
-		titleMeta: titleMeta,
-		waitDone:  make(chan struct{}),
-	}
-	if titleMeta != nil {
-		d.titles = titleMeta.Updates()
-	}
+	waitDone := make(chan struct{})
 	go func() {
 		_ = cmd.Wait()
-		close(d.waitDone)
+		close(waitDone)
 	}()
-	return d, nil
+
+	d.mu.Lock()
+	d.cmd = cmd
+	d.stdout = stdout
+	d.waitDone = waitDone
+	d.mu.Unlock()
+	return nil
+}
+
+// stop kills the current ffmpeg process and waits for it to exit.
+func (d *streamDecoder) stop() {
+	d.mu.Lock()
+	cmd, stdout, waitDone := d.cmd, d.stdout, d.waitDone
+	d.mu.Unlock()
+	if stdout != nil {
+		_ = stdout.Close()
+	}
+	if cmd != nil && cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+	if waitDone != nil {
+		<-waitDone
+	}
+}
+
+// Reconnect replaces the ffmpeg process with a fresh one for the same URL.
+// Live streams have no position to resume from, so playback rejoins the
+// broadcast where it is now.
+func (d *streamDecoder) Reconnect() error {
+	d.stop()
+	return d.start()
 }
 
 func (d *streamDecoder) Read(p []byte) (int, error) {
-	return d.stdout.Read(p)
+	d.mu.Lock()
+	stdout := d.stdout
+	d.mu.Unlock()
+	return stdout.Read(p)
 }
 
 func (d *streamDecoder) Seek(int64, int) (int64, error) {
@@ -88,18 +128,16 @@ func (d *streamDecoder) Length() int64               { return -1 }
 func (d *streamDecoder) SampleRate() int             { return streamSampleRate }
 func (d *streamDecoder) ChannelCount() int           { return streamChannels }
 
+func (d *streamDecoder) sourceInfo() SourceInfo {
+	return SourceInfo{Decoder: "ffmpeg", SampleRate: streamSampleRate, Channels: streamChannels, BitDepth: 16, URL: d.url, Live: true}
+}
+
 func (d *streamDecoder) Close() error {
 	d.closeOnce.Do(func() {
 		if d.titleMeta != nil {
 			_ = d.titleMeta.Close()
 		}
-		if d.stdout != nil {
-			_ = d.stdout.Close()
-		}
-		if d.cmd != nil && d.cmd.Process != nil {
-			_ = d.cmd.Process.Kill()
-		}
-		<-d.waitDone
+		d.stop()
 	})
 	return nil
 }
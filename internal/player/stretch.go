@@ -0,0 +1,142 @@
+package player
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	stretchSequenceMs = 40 // length of each copied sequence
+	stretchOverlapMs  = 8  // crossfade between consecutive sequences
+	stretchSeekMs     = 15 // how far ahead to search for the best splice point
+)
+
+// wsola time-stretches interleaved 16-bit PCM without changing pitch, using
+// waveform-similarity overlap-add. Each step copies one sequence of input,
+// crossfades it into the previous one at the offset where the waveforms line
+// up best, then advances the input by tempo times the output produced.
+type wsola struct {
+	channels      int
+	tempo         float64
+	seqFrames     int
+	overlapFrames int
+	seekFrames    int
+
+	in        []int16   // buffered input samples, starting at the nominal read position
+	tail      []float32 // end of the previous sequence, crossfaded into the next
+	hasTail   bool
+	out       []byte // stretched PCM not yet returned
+	skipFract float64
+}
+
+func newWSOLA(sampleRate, channels int) *wsola {
+	return &wsola{
+		channels:      channels,
+		tempo:         1,
+		seqFrames:     sampleRate * stretchSequenceMs / 1000,
+		overlapFrames: sampleRate * stretchOverlapMs / 1000,
+		seekFrames:    sampleRate * stretchSeekMs / 1000,
+	}
+}
+
+func (w *wsola) reset() {
+	w.in = w.in[:0]
+	w.tail = w.tail[:0]
+	w.hasTail = false
+	w.out = w.out[:0]
+	w.skipFract = 0
+}
+
+// bufferedBytes is the amount of source PCM taken in but not yet played out.
+func (w *wsola) bufferedBytes() int64 {
+	return int64(len(w.in)) * 2
+}
+
+func (w *wsola) write(pcm []byte) {
+	for i := 0; i+1 < len(pcm); i += 2 {
+		w.in = append(w.in, int16(binary.LittleEndian.Uint16(pcm[i:])))
+	}
+}
+
+// process emits output for every complete sequence in the input buffer.
+func (w *wsola) process() {
+	ch := w.channels
+	overlap := w.overlapFrames * ch
+	seq := w.seqFrames * ch
+	for {
+		skipF := w.tempo*float64(w.seqFrames-w.overlapFrames) + w.skipFract
+		skip := int(skipF)
+		if len(w.in)/ch < max(w.seqFrames+w.seekFrames, skip) {
+			return
+		}
+
+		start := 0
+		if w.hasTail {
+			start = w.bestOffset() * ch
+			for i := 0; i < overlap; i += ch {
+				t := float32(i/ch) / float32(w.overlapFrames)
+				for c := 0; c < ch; c++ {
+					w.emit(w.tail[i+c]*(1-t) + float32(w.in[start+i+c])*t)
+				}
+			}
+		} else {
+			for i := 0; i < overlap; i++ {
+				w.emit(float32(w.in[i]))
+			}
+		}
+		for i := start + overlap; i < start+seq-overlap; i++ {
+			w.emit(float32(w.in[i]))
+		}
+
+		w.tail = w.tail[:0]
+		for _, s := range w.in[start+seq-overlap : start+seq] {
+			w.tail = append(w.tail, float32(s))
+		}
+		w.hasTail = true
+
+		w.skipFract = skipF - float64(skip)
+		w.in = w.in[:copy(w.in, w.in[skip*ch:])]
+	}
+}
+
+// flush emits whatever input remains unstretched, used at end of stream.
+func (w *wsola) flush() {
+	if w.hasTail {
+		for _, s := range w.tail {
+			w.emit(s)
+		}
+	}
+	for _, s := range w.in {
+		w.emit(float32(s))
+	}
+	w.in = w.in[:0]
+	w.tail = w.tail[:0]
+	w.hasTail = false
+}
+
+// bestOffset returns the frame offset within the seek window whose overlap
+// region correlates best with the previous sequence's tail.
+func (w *wsola) bestOffset() int {
+	ch := w.channels
+	overlap := w.overlapFrames * ch
+	best, bestScore := 0, math.Inf(-1)
+	for off := 0; off < w.seekFrames; off++ {
+		base := off * ch
+		var corr, norm float64
+		for i := 0; i < overlap; i++ {
+			v := float64(w.in[base+i])
+			corr += float64(w.tail[i]) * v
+			norm += v * v
+		}
+		score := corr / math.Sqrt(norm+1)
+		if score > bestScore {
+			best, bestScore = off, score
+		}
+	}
+	return best
+}
+
+func (w *wsola) emit(v float32) {
+	v = min(max(v, -32768), 32767)
+	w.out = binary.LittleEndian.AppendUint16(w.out, uint16(int16(v)))
+}
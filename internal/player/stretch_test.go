@@ -0,0 +1,82 @@
+package player
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+func sinePCM(freq float64, frames int) []byte {
+	buf := make([]byte, 0, frames*playbackFrameSize)
+	for i := 0; i < frames; i++ {
+		v := int16(12000 * math.Sin(2*math.Pi*freq*float64(i)/playbackSampleRate))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(v))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(v))
+	}
+	return buf
+}
+
+// zeroCrossingFreq estimates the frequency of the left channel.
+func zeroCrossingFreq(pcm []byte) float64 {
+	frames := len(pcm) / playbackFrameSize
+	crossings := 0
+	prev := int16(binary.LittleEndian.Uint16(pcm))
+	for i := 1; i < frames; i++ {
+		s := int16(binary.LittleEndian.Uint16(pcm[i*playbackFrameSize:]))
+		if (prev < 0) != (s < 0) {
+			crossings++
+		}
+		prev = s
+	}
+	return float64(crossings) / 2 / (float64(frames) / playbackSampleRate)
+}
+
+func stretchAll(t *testing.T, mode SpeedMode, src []byte) []byte {
+	t.Helper()
+	sr := newSpeedReader(bytes.NewReader(src), playbackFrameSize)
+	sr.setKeepPitch(true)
+	sr.setSpeed(mode)
+	out, err := io.ReadAll(sr)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	return out
+}
+
+func TestStretchKeepsPitch(t *testing.T) {
+	src := sinePCM(440, playbackSampleRate*2)
+	for _, tc := range []struct {
+		mode  SpeedMode
+		ratio float64
+	}{
+		{Speed2x, 0.5},
+		{SpeedHalf, 2},
+	} {
+		out := stretchAll(t, tc.mode, src)
+		if len(out)%playbackFrameSize != 0 {
+			t.Fatalf("%s: output %d bytes is not frame aligned", tc.mode.Label(), len(out))
+		}
+		got := float64(len(out)) / float64(len(src))
+		if math.Abs(got-tc.ratio) > tc.ratio*0.05 {
+			t.Errorf("%s: length ratio = %.3f, want about %.3f", tc.mode.Label(), got, tc.ratio)
+		}
+		if f := zeroCrossingFreq(out); math.Abs(f-440) > 15 {
+			t.Errorf("%s: frequency = %.1f Hz, want about 440", tc.mode.Label(), f)
+		}
+	}
+}
+
+func TestResampleSpeedShiftsPitch(t *testing.T) {
+	src := sinePCM(440, playbackSampleRate)
+	sr := newSpeedReader(bytes.NewReader(src), playbackFrameSize)
+	sr.setSpeed(Speed2x)
+	out, err := io.ReadAll(sr)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if f := zeroCrossingFreq(out); math.Abs(f-880) > 20 {
+		t.Errorf("frequency = %.1f Hz, want about 880", f)
+	}
+}
@@ -0,0 +1,123 @@
+package player
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/jfreymuth/oggvorbis"
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/meta"
+)
+
+// ReadTrackNumber reads only the disc and track number tags of a local file,
+// for ordering an album: TRCK/TPOS in MP3 ID3v2 tags, TRACKNUMBER and
+// DISCNUMBER comments in FLAC and Ogg Vorbis, and trkn/disk atoms in MP4.
+// It is much cheaper than ReadMetadata. ok is false when there is no track
+// number; a missing disc number reads as 0.
+func ReadTrackNumber(path string) (disc, track int, ok bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".oga" {
+		ext = ".ogg"
+	}
+	if ext == ".mp3" {
+		tag, err := id3v2.Open(path, id3v2.Options{Parse: true, ParseFrames: []string{"Track number/Position in set", "Part of a set"}})
+		if err != nil {
+			return 0, 0, false
+		}
+		defer tag.Close()
+		disc = leadingNumber(tag.GetTextFrame(tag.CommonID("Part of a set")).Text)
+		track = leadingNumber(tag.GetTextFrame(tag.CommonID("Track number/Position in set")).Text)
+		return disc, track, track > 0
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+
+	var comments []string
+	switch ext {
+	case ".flac":
+		stream, _ := flac.Parse(f)
+		if stream == nil {
+			return 0, 0, false
+		}
+		for _, block := range stream.Blocks {
+			if vc, isComment := block.Body.(*meta.VorbisComment); isComment {
+				for _, t := range vc.Tags {
+					comments = append(comments, t[0]+"="+t[1])
+				}
+			}
+		}
+	case ".ogg":
+		r, err := oggvorbis.NewReader(f)
+		if err != nil {
+			return 0, 0, false
+		}
+		comments = r.CommentHeader().Comments
+	case ".m4a", ".m4b", ".aac":
+		disc, track = readMP4TrackNumber(f)
+		return disc, track, track > 0
+	}
+	for _, c := range comments {
+		key, value, found := strings.Cut(c, "=")
+		switch {
+		case !found:
+		case strings.EqualFold(key, "TRACKNUMBER"):
+			track = leadingNumber(value)
+		case strings.EqualFold(key, "DISCNUMBER"):
+			disc = leadingNumber(value)
+		}
+	}
+	return disc, track, track > 0
+}
+
+// readMP4TrackNumber reads the iTunes trkn and disk atoms under
+// moov/udta/meta/ilst. Each data payload is two bytes of padding, then the
+// number and the total as 16-bit values.
+func readMP4TrackNumber(r io.ReadSeeker) (disc, track int) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, 0
+	}
+	start := int64(0)
+	for _, typ := range []string{"moov", "udta", "meta", "ilst"} {
+		body, boxEnd, ok := findMP4Box(r, start, end, typ)
+		if !ok {
+			return 0, 0
+		}
+		if typ == "meta" {
+			body += 4 // full box: version + flags
+		}
+		start, end = body, boxEnd
+	}
+	number := func(typ string) int {
+		body, boxEnd, ok := findMP4Box(r, start, end, typ)
+		if !ok {
+			return 0
+		}
+		data, ok := readMP4Body(r, body, boxEnd, "data")
+		if !ok || len(data) < 12 {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(data[10:12])) // after type, locale, and padding
+	}
+	return number("disk"), number("trkn")
+}
+
+// leadingNumber parses the number at the start of a tag value such as "3"
+// or "3/12", or returns 0.
+func leadingNumber(s string) int {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "/")
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
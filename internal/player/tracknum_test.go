@@ -0,0 +1,31 @@
+package player
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadMP4TrackNumber(t *testing.T) {
+	number := func(typ string, n, total uint16) []byte {
+		data := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, byte(n >> 8), byte(n), byte(total >> 8), byte(total)}
+		return mp4TestBox(typ, mp4TestBox("data", data))
+	}
+	ilst := mp4TestBox("ilst", number("trkn", 7, 12), number("disk", 2, 2))
+	file := mp4TestBox("moov", mp4TestBox("udta", mp4TestBox("meta", be32(0), ilst)))
+
+	disc, track := readMP4TrackNumber(bytes.NewReader(file))
+	if disc != 2 || track != 7 {
+		t.Fatalf("readMP4TrackNumber() = disc %d, track %d; want 2, 7", disc, track)
+	}
+	if disc, track := readMP4TrackNumber(bytes.NewReader(mp4TestBox("moov"))); disc != 0 || track != 0 {
+		t.Fatalf("readMP4TrackNumber() without tags = %d, %d; want 0, 0", disc, track)
+	}
+}
+
+func TestLeadingNumber(t *testing.T) {
+	for in, want := range map[string]int{"3": 3, " 03/12 ": 3, "1/2": 1, "": 0, "A1": 0} {
+		if got := leadingNumber(in); got != want {
+			t.Errorf("leadingNumber(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"slices"
+)
+
+// waveformChunkFrames is how much audio one WaveformScan.Step decodes, about
+// 10 s, so a long file fills in over many short steps.
+const waveformChunkFrames = 10 * playbackSampleRate
+
+// WaveformPeak is the sample range of one slice of a track, from -1 to 1
+// across all channels.
+type WaveformPeak struct {
+	Min, Max float32
+}
+
+// WaveformScan builds a min/max envelope of a whole local file with its own
+// decoder, separate from playback. The caller runs Step until it reports done
+// and then calls Close; it is not safe for concurrent use.
+type WaveformScan struct {
+	file            *os.File
+	dec             audioDecoder
+	frameSize       int
+	framesPerBucket int64
+	buckets         int
+	peaks           []WaveformPeak
+	cur             WaveformPeak // bucket being filled
+	curFrames       int64
+	buf             []byte
+	done            bool
+}
+
+// NewWaveformScan opens path for an envelope of about buckets slices.
+func NewWaveformScan(path string, buckets int) (*WaveformScan, error) {
+	f, dec, err := openDecoder(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newWaveformScan(dec, buckets)
+	if err != nil {
+		f.Close()
+		if c, ok := dec.(io.Closer); ok {
+			c.Close()
+		}
+		return nil, err
+	}
+	s.file = f
+	return s, nil
+}
+
+func newWaveformScan(dec audioDecoder, buckets int) (*WaveformScan, error) {
+	frameSize := dec.ChannelCount() * 2
+	if frameSize <= 0 || dec.Length() <= 0 || buckets <= 0 {
+		return nil, errors.New("waveform: unknown track length")
+	}
+	frames := dec.Length() / int64(frameSize)
+	per := max((frames+int64(buckets)-1)/int64(buckets), 1)
+	bufSize := 64 * 1024
+	return &WaveformScan{
+		dec:             dec,
+		frameSize:       frameSize,
+		framesPerBucket: per,
+		buckets:         int((frames + per - 1) / per),
+		buf:             make([]byte, bufSize-bufSize%frameSize),
+	}, nil
+}
+
+// Buckets returns how many slices the finished envelope will have.
+func (s *WaveformScan) Buckets() int {
+	return s.buckets
+}
+
+// Step decodes the next chunk and returns a copy of the envelope so far and
+// whether the whole file has been read.
+func (s *WaveformScan) Step() ([]WaveformPeak, bool, error) {
+	for frames := 0; frames < waveformChunkFrames && !s.done; {
+		n, err := io.ReadFull(s.dec, s.buf)
+		n -= n % s.frameSize
+		s.add(s.buf[:n])
+		frames += n / s.frameSize
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			s.finish()
+		} else if err != nil {
+			return slices.Clone(s.peaks), false, err
+		}
+	}
+	return slices.Clone(s.peaks), s.done, nil
+}
+
+// add folds whole s16le frames into the envelope.
+func (s *WaveformScan) add(pcm []byte) {
+	for off := 0; off < len(pcm); off += s.frameSize {
+		if s.curFrames == 0 {
+			s.cur = WaveformPeak{Min: 1, Max: -1}
+		}
+		for ch := off; ch < off+s.frameSize; ch += 2 {
+			v := float32(int16(binary.LittleEndian.Uint16(pcm[ch:]))) / 32768
+			s.cur.Min = min(s.cur.Min, v)
+			s.cur.Max = max(s.cur.Max, v)
+		}
+		s.curFrames++
+		if s.curFrames == s.framesPerBucket {
+			s.peaks = append(s.peaks, s.cur)
+			s.curFrames = 0
+		}
+	}
+}
+
+func (s *WaveformScan) finish() {
+	if s.curFrames > 0 {
+		s.peaks = append(s.peaks, s.cur)
+		s.curFrames = 0
+	}
+	s.done = true
+}
+
+// Close releases the scan's file and decoder.
+func (s *WaveformScan) Close() {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	if c, ok := s.dec.(io.Closer); ok {
+		c.Close()
+	}
+}
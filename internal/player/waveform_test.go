@@ -0,0 +1,71 @@
+package player
+
+import (
+	"bytes"
+	"testing"
+)
+
+type stubPCMReader struct {
+	*bytes.Reader
+	channels int
+}
+
+func (d stubPCMReader) Length() int64     { return d.Size() }
+func (d stubPCMReader) SampleRate() int   { return playbackSampleRate }
+func (d stubPCMReader) ChannelCount() int { return d.channels }
+
+func TestWaveformScanBuildsMinMaxEnvelopeInChunks(t *testing.T) {
+	// 25 s of stereo: silence, then a full-scale square wave, then half scale.
+	frames := 25 * playbackSampleRate
+	samples := make([]int16, 0, 2*frames)
+	for i := 0; i < frames; i++ {
+		var v int16
+		switch {
+		case i >= 20*playbackSampleRate:
+			v = 16384
+		case i >= 10*playbackSampleRate:
+			v = 32767
+			if i%2 == 0 {
+				v = -32768
+			}
+		}
+		samples = append(samples, v, v)
+	}
+	s, err := newWaveformScan(stubPCMReader{bytes.NewReader(pcm16(samples...)), 2}, 5)
+	if err != nil {
+		t.Fatalf("newWaveformScan() error = %v", err)
+	}
+	if s.Buckets() != 5 {
+		t.Fatalf("Buckets() = %d, want 5", s.Buckets())
+	}
+
+	peaks, done, err := s.Step()
+	if err != nil || done {
+		t.Fatalf("first Step() = done %v, err %v; want a partial envelope", done, err)
+	}
+	if len(peaks) != 2 {
+		t.Fatalf("after one step: %d buckets, want the 2 covering 10 s", len(peaks))
+	}
+	for !done {
+		if peaks, done, err = s.Step(); err != nil {
+			t.Fatalf("Step() error = %v", err)
+		}
+	}
+
+	// Five 5 s buckets.
+	want := []WaveformPeak{
+		{0, 0},
+		{0, 0},
+		{-1, 32767.0 / 32768},
+		{-1, 32767.0 / 32768},
+		{0.5, 0.5},
+	}
+	if len(peaks) != len(want) {
+		t.Fatalf("envelope has %d buckets, want %d", len(peaks), len(want))
+	}
+	for i := range want {
+		if peaks[i] != want[i] {
+			t.Fatalf("bucket %d = %+v, want %+v", i, peaks[i], want[i])
+		}
+	}
+}
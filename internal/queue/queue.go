@@ -1,6 +1,10 @@
 package queue
 
-import "math/rand"
+import (
+	"math/rand"
+	"slices"
+	"time"
+)
 
 // TrackState represents the download/playback state of a track.
 type TrackState int
@@ -16,12 +20,17 @@ const (
 
 // Track represents a single item in the playlist queue.
 type Track struct {
-	ID      string
-	Title   string
-	URL     string
-	Path    string
-	State   TrackState
-	Cleanup func()
+	ID           string
+	Title        string
+	URL          string
+	Path         string
+	State        TrackState
+	Cleanup      func()
+	Start        time.Duration // section of Path to play (cue sheet tracks)
+	End          time.Duration // 0 plays to the end of the file
+	Duration     time.Duration // playing length once known (album timeline); 0 when unknown
+	Attempts     int           // failed download attempts; retried while below the UI's limit
+	VolumeOffset float64       // volume change made while this track played, on top of the session volume
 }
 
 // Queue manages an ordered list of tracks for playlist playback.
@@ -146,6 +155,20 @@ func (q *Queue) SetTrackCleanup(i int, cleanup func()) {
 	}
 }
 
+// SetTrackAttempts sets the failed download attempt count of the track at the given index.
+func (q *Queue) SetTrackAttempts(i, n int) {
+	if i >= 0 && i < len(q.tracks) {
+		q.tracks[i].Attempts = n
+	}
+}
+
+// SetTrackDuration sets the known playing length of the track at the given index.
+func (q *Queue) SetTrackDuration(i int, d time.Duration) {
+	if i >= 0 && i < len(q.tracks) {
+		q.tracks[i].Duration = d
+	}
+}
+
 // Track returns a pointer to the track at the given index, or nil if out of range.
 func (q *Queue) Track(i int) *Track {
 	if i < 0 || i >= len(q.tracks) {
@@ -174,6 +197,52 @@ func (q *Queue) Remove(i int) bool {
 	return true
 }
 
+// Compact removes every Done and Failed track except the current one in a
+// single pass, running their cleanups. The current index and the shuffle
+// mapping are remapped as in Remove. Returns how many tracks were removed.
+func (q *Queue) Compact() int {
+	newIdx := make([]int, len(q.tracks))
+	kept := q.tracks[:0]
+	for i, t := range q.tracks {
+		if i != q.current && (t.State == Done || t.State == Failed) {
+			if t.Cleanup != nil {
+				t.Cleanup()
+			}
+			newIdx[i] = -1
+			continue
+		}
+		newIdx[i] = len(kept)
+		kept = append(kept, t)
+	}
+	removed := len(q.tracks) - len(kept)
+	if removed == 0 {
+		return 0
+	}
+	clear(q.tracks[len(kept):])
+	q.tracks = kept
+	if q.current >= 0 && q.current < len(newIdx) {
+		q.current = newIdx[q.current]
+	} else if q.current >= len(newIdx) {
+		q.current = len(kept)
+	}
+	if q.shuffled {
+		newOrder := make([]int, 0, len(q.shuffleOrder))
+		newPos := 0
+		for _, idx := range q.shuffleOrder {
+			if newIdx[idx] < 0 {
+				continue
+			}
+			if newIdx[idx] == q.current {
+				newPos = len(newOrder)
+			}
+			newOrder = append(newOrder, newIdx[idx])
+		}
+		q.shuffleOrder = newOrder
+		q.shufflePos = newPos
+	}
+	return removed
+}
+
 // rebuildShuffleAfterRemove rebuilds the shuffle mapping after a track at
 // removedIdx has been spliced out. Filters the removed index, decrements
 // indices above it, and derives shufflePos from where q.current lands.
@@ -197,6 +266,66 @@ func (q *Queue) rebuildShuffleAfterRemove(removedIdx int) {
 	q.shufflePos = newPos
 }
 
+// InsertNext inserts t right after the current track and returns its index.
+// In shuffle mode it is also placed right after the current shuffle position,
+// so it plays next either way; indices above it shift up by one.
+func (q *Queue) InsertNext(t Track) int {
+	i := min(max(q.current+1, 0), len(q.tracks))
+	q.tracks = slices.Insert(q.tracks, i, t)
+	if q.current >= i {
+		q.current++
+	}
+	if q.shuffled {
+		for j, idx := range q.shuffleOrder {
+			if idx >= i {
+				q.shuffleOrder[j]++
+			}
+		}
+		pos := min(q.shufflePos+1, len(q.shuffleOrder))
+		q.shuffleOrder = slices.Insert(q.shuffleOrder, pos, i)
+	}
+	return i
+}
+
+// Move moves the track at from to position to, shifting the tracks between
+// them. The current track cannot be moved. The current index and the shuffle
+// mapping are remapped so the same track stays current and every track keeps
+// its shuffle slot. Returns false if an index is invalid or from is current.
+func (q *Queue) Move(from, to int) bool {
+	if from < 0 || from >= len(q.tracks) || to < 0 || to >= len(q.tracks) || from == q.current {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	t := q.tracks[from]
+	if from < to {
+		copy(q.tracks[from:to], q.tracks[from+1:to+1])
+	} else {
+		copy(q.tracks[to+1:from+1], q.tracks[to:from])
+	}
+	q.tracks[to] = t
+	q.current = movedIndex(q.current, from, to)
+	for i, idx := range q.shuffleOrder {
+		q.shuffleOrder[i] = movedIndex(idx, from, to)
+	}
+	return true
+}
+
+// movedIndex returns where track index i ends up after the track at from
+// moves to to.
+func movedIndex(i, from, to int) int {
+	switch {
+	case i == from:
+		return to
+	case from < to && i > from && i <= to:
+		return i - 1
+	case to < from && i >= to && i < from:
+		return i + 1
+	}
+	return i
+}
+
 // CleanupAll calls the cleanup function on every track that has one.
 func (q *Queue) CleanupAll() {
 	for i := range q.tracks {
@@ -215,13 +344,24 @@ func (q *Queue) IsShuffled() bool {
 // EnableShuffle activates shuffle mode. The current track stays at position 0
 // in the shuffle order; all other indices are randomized via Fisher-Yates.
 func (q *Queue) EnableShuffle() {
-	n := len(q.tracks)
-	if n <= 1 {
+	if len(q.tracks) <= 1 {
+		return
+	}
+	q.ShuffleWithin(0, len(q.tracks)-1)
+}
+
+// ShuffleWithin activates shuffle mode over the tracks from lo to hi
+// inclusive. The current track stays at position 0 in the shuffle order,
+// followed by the other tracks of the range in random order; tracks outside
+// the range do not play until shuffle is enabled again.
+func (q *Queue) ShuffleWithin(lo, hi int) {
+	lo, hi = max(lo, 0), min(hi, len(q.tracks)-1)
+	if lo > hi {
 		return
 	}
 	q.shuffled = true
-	q.shuffleOrder = make([]int, 0, n-1)
-	for i := 0; i < n; i++ {
+	q.shuffleOrder = make([]int, 0, hi-lo+1)
+	for i := lo; i <= hi; i++ {
 		if i != q.current {
 			q.shuffleOrder = append(q.shuffleOrder, i)
 		}
@@ -288,6 +428,36 @@ func (q *Queue) NextDownloadIndex() int {
 	return next
 }
 
+// PlaybackOrder returns all track indices in the order they play,
+// following the shuffle order when shuffle is active.
+func (q *Queue) PlaybackOrder() []int {
+	if q.shuffled {
+		order := make([]int, len(q.shuffleOrder))
+		copy(order, q.shuffleOrder)
+		return order
+	}
+	order := make([]int, len(q.tracks))
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// UpcomingIndices returns up to n track indices that play after the current
+// one, in playback order.
+func (q *Queue) UpcomingIndices(n int) []int {
+	order := q.PlaybackOrder()
+	pos := q.current
+	if q.shuffled {
+		pos = q.shufflePos
+	}
+	end := min(pos+1+n, len(order))
+	if pos+1 >= end {
+		return nil
+	}
+	return order[pos+1 : end]
+}
+
 // SetShufflePosition syncs shufflePos when the user jumps to a specific original track index.
 func (q *Queue) SetShufflePosition(originalIdx int) {
 	if !q.shuffled {
@@ -300,3 +470,59 @@ func (q *Queue) SetShufflePosition(originalIdx int) {
 		}
 	}
 }
+
+// TotalDuration returns the length of the album timeline: the summed
+// durations of the tracks in playback order, up to the first track whose
+// duration is not known yet. That track caps the seekable range until it is.
+func (q *Queue) TotalDuration() time.Duration {
+	var total time.Duration
+	for _, i := range q.PlaybackOrder() {
+		d := q.tracks[i].Duration
+		if d <= 0 {
+			break
+		}
+		total += d
+	}
+	return total
+}
+
+// TimelineStart returns where the track at index i begins on the album
+// timeline. It reports false when i or a track before it has no known
+// duration.
+func (q *Queue) TimelineStart(i int) (time.Duration, bool) {
+	var start time.Duration
+	for _, j := range q.PlaybackOrder() {
+		d := q.tracks[j].Duration
+		if d <= 0 {
+			return 0, false
+		}
+		if j == i {
+			return start, true
+		}
+		start += d
+	}
+	return 0, false
+}
+
+// Locate maps a position on the album timeline to a track index and an
+// offset into that track. Positions past TotalDuration land at the end of
+// the last track on the timeline; it returns -1 when the timeline is empty.
+func (q *Queue) Locate(pos time.Duration) (int, time.Duration) {
+	idx := -1
+	var start time.Duration
+	for _, i := range q.PlaybackOrder() {
+		d := q.tracks[i].Duration
+		if d <= 0 {
+			break
+		}
+		if pos < start+d {
+			return i, max(pos-start, 0)
+		}
+		idx = i
+		start += d
+	}
+	if idx < 0 {
+		return -1, 0
+	}
+	return idx, q.tracks[idx].Duration
+}
@@ -0,0 +1,244 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+)
+
+// newTestQueue returns a queue of tracks titled by the given strings, with
+// the track at current playing.
+func newTestQueue(current int, titles ...string) *Queue {
+	tracks := make([]Track, len(titles))
+	for i, title := range titles {
+		tracks[i] = Track{Title: title}
+	}
+	q := New(tracks)
+	q.current = current
+	return q
+}
+
+// titles returns the queue's track titles in list order.
+func titles(q *Queue) []string {
+	out := make([]string, q.Len())
+	for i := range out {
+		out[i] = q.Track(i).Title
+	}
+	return out
+}
+
+// playOrder returns the queue's track titles in playback order.
+func playOrder(q *Queue) []string {
+	var out []string
+	for _, i := range q.PlaybackOrder() {
+		out = append(out, q.Track(i).Title)
+	}
+	return out
+}
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name        string
+		from, to    int
+		ok          bool
+		want        []string
+		wantCurrent int
+	}{
+		{"down before current", 0, 1, true, []string{"b", "a", "c", "d", "e"}, 2},
+		{"up after current", 4, 3, true, []string{"a", "b", "c", "e", "d"}, 2},
+		{"down across current", 0, 3, true, []string{"b", "c", "d", "a", "e"}, 1},
+		{"up across current", 4, 0, true, []string{"e", "a", "b", "c", "d"}, 3},
+		{"onto current slot from above", 1, 2, true, []string{"a", "c", "b", "d", "e"}, 1},
+		{"onto current slot from below", 3, 2, true, []string{"a", "b", "d", "c", "e"}, 3},
+		{"same index", 1, 1, true, []string{"a", "b", "c", "d", "e"}, 2},
+		{"current track", 2, 0, false, []string{"a", "b", "c", "d", "e"}, 2},
+		{"from out of range", 5, 0, false, []string{"a", "b", "c", "d", "e"}, 2},
+		{"negative from", -1, 0, false, []string{"a", "b", "c", "d", "e"}, 2},
+		{"to out of range", 0, 5, false, []string{"a", "b", "c", "d", "e"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(2, "a", "b", "c", "d", "e")
+			if ok := q.Move(tt.from, tt.to); ok != tt.ok {
+				t.Fatalf("Move(%d, %d) = %v, want %v", tt.from, tt.to, ok, tt.ok)
+			}
+			if got := titles(q); !slices.Equal(got, tt.want) {
+				t.Errorf("tracks = %v, want %v", got, tt.want)
+			}
+			if q.CurrentIndex() != tt.wantCurrent {
+				t.Errorf("CurrentIndex() = %d, want %d", q.CurrentIndex(), tt.wantCurrent)
+			}
+
+			// Shuffled, the same move leaves the playback order and the
+			// shuffle position alone.
+			q = newTestQueue(2, "a", "b", "c", "d", "e")
+			q.shuffled = true
+			q.shuffleOrder = []int{4, 2, 0, 3, 1}
+			q.shufflePos = 1
+			q.Move(tt.from, tt.to)
+			if got, want := playOrder(q), []string{"e", "c", "a", "d", "b"}; !slices.Equal(got, want) {
+				t.Errorf("shuffled playback order = %v, want %v", got, want)
+			}
+			if q.Current().Title != "c" || q.shuffleOrder[q.shufflePos] != q.CurrentIndex() {
+				t.Errorf("shuffled current = %q at position %d, want %q", q.Current().Title, q.shufflePos, "c")
+			}
+		})
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		name        string
+		states      []TrackState
+		current     int
+		want        []string
+		wantCurrent int
+	}{
+		{
+			name:        "around current",
+			states:      []TrackState{Done, Ready, Playing, Failed, Pending},
+			current:     2,
+			want:        []string{"b", "c", "e"},
+			wantCurrent: 1,
+		},
+		{
+			name:        "keeps a finished current track",
+			states:      []TrackState{Failed, Done, Done, Ready, Done},
+			current:     2,
+			want:        []string{"c", "d"},
+			wantCurrent: 0,
+		},
+		{
+			name:        "nothing to remove",
+			states:      []TrackState{Ready, Pending, Playing, Downloading, Pending},
+			current:     2,
+			want:        []string{"a", "b", "c", "d", "e"},
+			wantCurrent: 2,
+		},
+		{
+			name:        "past the end",
+			states:      []TrackState{Done, Ready, Done, Failed, Done},
+			current:     5,
+			want:        []string{"b"},
+			wantCurrent: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup := func() (*Queue, *[]string) {
+				q := newTestQueue(tt.current, "a", "b", "c", "d", "e")
+				cleaned := new([]string)
+				for i := range q.tracks {
+					tr := &q.tracks[i]
+					tr.State = tt.states[i]
+					title := tr.Title
+					tr.Cleanup = func() { *cleaned = append(*cleaned, title) }
+				}
+				return q, cleaned
+			}
+
+			q, cleaned := setup()
+			if n := q.Compact(); n != len(tt.states)-len(tt.want) {
+				t.Errorf("Compact() = %d, want %d", n, len(tt.states)-len(tt.want))
+			}
+			if got := titles(q); !slices.Equal(got, tt.want) {
+				t.Errorf("tracks = %v, want %v", got, tt.want)
+			}
+			if q.CurrentIndex() != tt.wantCurrent {
+				t.Errorf("CurrentIndex() = %d, want %d", q.CurrentIndex(), tt.wantCurrent)
+			}
+			for _, title := range *cleaned {
+				if slices.Contains(tt.want, title) {
+					t.Errorf("cleanup ran for kept track %q", title)
+				}
+			}
+			if len(*cleaned) != len(tt.states)-len(tt.want) {
+				t.Errorf("cleanups ran for %v", *cleaned)
+			}
+
+			if tt.current >= len(tt.states) {
+				return
+			}
+			// Shuffled, the kept tracks keep their playback order and the
+			// current one its place in it.
+			q, _ = setup()
+			q.shuffled = true
+			q.shuffleOrder = []int{4, 2, 0, 3, 1}
+			q.shufflePos = slices.Index(q.shuffleOrder, tt.current)
+			var want []string
+			for _, title := range playOrder(q) {
+				if slices.Contains(tt.want, title) {
+					want = append(want, title)
+				}
+			}
+			q.Compact()
+			if got := playOrder(q); !slices.Equal(got, want) {
+				t.Errorf("shuffled playback order = %v, want %v", got, want)
+			}
+			if q.Current().Title != "c" || q.shuffleOrder[q.shufflePos] != q.CurrentIndex() {
+				t.Errorf("shuffled current = %q at position %d, want %q", q.Current().Title, q.shufflePos, "c")
+			}
+		})
+	}
+}
+
+func TestInsertNext(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     int
+		tracks      []string
+		wantIndex   int
+		want        []string
+		wantCurrent int
+	}{
+		{"after the first track", 0, []string{"a", "b", "c"}, 1, []string{"a", "x", "b", "c"}, 0},
+		{"mid queue", 1, []string{"a", "b", "c"}, 2, []string{"a", "b", "x", "c"}, 1},
+		{"after the last track", 2, []string{"a", "b", "c"}, 3, []string{"a", "b", "c", "x"}, 2},
+		{"past the end", 3, []string{"a", "b", "c"}, 3, []string{"a", "b", "c", "x"}, 4},
+		{"empty queue", 0, nil, 0, []string{"x"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(tt.current, tt.tracks...)
+			if i := q.InsertNext(Track{Title: "x"}); i != tt.wantIndex {
+				t.Errorf("InsertNext() = %d, want %d", i, tt.wantIndex)
+			}
+			if got := titles(q); !slices.Equal(got, tt.want) {
+				t.Errorf("tracks = %v, want %v", got, tt.want)
+			}
+			if q.CurrentIndex() != tt.wantCurrent {
+				t.Errorf("CurrentIndex() = %d, want %d", q.CurrentIndex(), tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestInsertNextShuffled(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		pos     int
+		want    []string
+	}{
+		{"first in shuffle order", 0, 0, []string{"a", "x", "d", "c", "b"}},
+		{"mid shuffle order", 2, 2, []string{"a", "d", "c", "x", "b"}},
+		{"last in shuffle order", 1, 3, []string{"a", "d", "c", "b", "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(tt.current, "a", "b", "c", "d")
+			q.shuffled = true
+			q.shuffleOrder = []int{0, 3, 2, 1}
+			q.shufflePos = tt.pos
+			q.InsertNext(Track{Title: "x"})
+			if got := playOrder(q); !slices.Equal(got, tt.want) {
+				t.Errorf("playback order = %v, want %v", got, tt.want)
+			}
+			if next := q.NextShuffled(); next == nil || next.Title != "x" {
+				t.Errorf("NextShuffled() = %v, want x", next)
+			}
+			if q.shuffleOrder[q.shufflePos] != q.CurrentIndex() {
+				t.Errorf("shuffle position %d no longer points at the current track", q.shufflePos)
+			}
+		})
+	}
+}
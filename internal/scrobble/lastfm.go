@@ -0,0 +1,115 @@
+package scrobble
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olivier-w/climp/internal/config"
+)
+
+const lastFMURL = "https://ws.audioscrobbler.com/2.0/"
+
+// lastFM submits listens through the Last.fm scrobbling API 2.0.
+type lastFM struct {
+	apiKey     string
+	secret     string
+	sessionKey string
+	url        string
+}
+
+func newLastFM(cfg config.LastFM) *lastFM {
+	return &lastFM{
+		apiKey:     cfg.APIKey,
+		secret:     cfg.Secret,
+		sessionKey: cfg.SessionKey,
+		url:        lastFMURL,
+	}
+}
+
+func (l *lastFM) NowPlaying(ctx context.Context, t Track) error {
+	return l.call(ctx, "track.updateNowPlaying", lastFMTrackParams(t))
+}
+
+func (l *lastFM) Scrobble(ctx context.Context, t Track) error {
+	params := lastFMTrackParams(t)
+	params.Set("timestamp", strconv.FormatInt(t.StartedAt.Unix(), 10))
+	return l.call(ctx, "track.scrobble", params)
+}
+
+func lastFMTrackParams(t Track) url.Values {
+	params := url.Values{}
+	params.Set("artist", t.Artist)
+	params.Set("track", t.Title)
+	if t.Album != "" {
+		params.Set("album", t.Album)
+	}
+	if t.Duration > 0 {
+		params.Set("duration", strconv.Itoa(int(t.Duration.Seconds())))
+	}
+	return params
+}
+
+func (l *lastFM) call(ctx context.Context, method string, params url.Values) error {
+	params.Set("method", method)
+	params.Set("api_key", l.apiKey)
+	params.Set("sk", l.sessionKey)
+	params.Set("api_sig", lastFMSignature(params, l.secret))
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "climp")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("last.fm: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var apiErr struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+	if apiErr.Error != 0 {
+		return fmt.Errorf("last.fm: %s", apiErr.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("last.fm: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// lastFMSignature signs params as the API requires: every name and value,
+// sorted by name and concatenated, followed by the shared secret, hashed
+// with MD5. The format parameter is not signed.
+func lastFMSignature(params url.Values, secret string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if name != "format" && name != "api_sig" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteString(params.Get(name))
+	}
+	sb.WriteString(secret)
+	sum := md5.Sum([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
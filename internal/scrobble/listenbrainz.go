@@ -0,0 +1,97 @@
+package scrobble
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/olivier-w/climp/internal/config"
+)
+
+const listenBrainzURL = "https://api.listenbrainz.org"
+
+// listenBrainz submits listens through the ListenBrainz submit-listens API.
+type listenBrainz struct {
+	token string
+	url   string
+}
+
+func newListenBrainz(cfg config.ListenBrainz) *listenBrainz {
+	url := strings.TrimRight(cfg.URL, "/")
+	if url == "" {
+		url = listenBrainzURL
+	}
+	return &listenBrainz{token: cfg.Token, url: url}
+}
+
+type lbSubmission struct {
+	ListenType string     `json:"listen_type"`
+	Payload    []lbListen `json:"payload"`
+}
+
+type lbListen struct {
+	ListenedAt int64      `json:"listened_at,omitempty"`
+	Metadata   lbMetadata `json:"track_metadata"`
+}
+
+type lbMetadata struct {
+	Artist string         `json:"artist_name"`
+	Title  string         `json:"track_name"`
+	Album  string         `json:"release_name,omitempty"`
+	Info   map[string]any `json:"additional_info"`
+}
+
+func (l *listenBrainz) NowPlaying(ctx context.Context, t Track) error {
+	return l.submit(ctx, "playing_now", lbListen{Metadata: lbTrackMetadata(t)})
+}
+
+func (l *listenBrainz) Scrobble(ctx context.Context, t Track) error {
+	return l.submit(ctx, "single", lbListen{ListenedAt: t.StartedAt.Unix(), Metadata: lbTrackMetadata(t)})
+}
+
+func lbTrackMetadata(t Track) lbMetadata {
+	return lbMetadata{
+		Artist: t.Artist,
+		Title:  t.Title,
+		Album:  t.Album,
+		Info: map[string]any{
+			"duration_ms":       t.Duration.Milliseconds(),
+			"submission_client": "climp",
+		},
+	}
+}
+
+func (l *listenBrainz) submit(ctx context.Context, listenType string, listen lbListen) error {
+	body, err := json.Marshal(lbSubmission{ListenType: listenType, Payload: []lbListen{listen}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url+"/1/submit-listens", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+l.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "climp")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("listenbrainz: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+		if apiErr.Error != "" {
+			return fmt.Errorf("listenbrainz: %s", apiErr.Error)
+		}
+		return fmt.Errorf("listenbrainz: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
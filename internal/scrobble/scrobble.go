@@ -0,0 +1,90 @@
+// Package scrobble submits listens to Last.fm and ListenBrainz.
+package scrobble
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/olivier-w/climp/internal/config"
+)
+
+const (
+	// minTrackLength is the shortest track either service accepts.
+	minTrackLength = 30 * time.Second
+	// scrobbleAfter is the listening time that always counts as a listen,
+	// however long the track is.
+	scrobbleAfter = 4 * time.Minute
+
+	requestTimeout = 10 * time.Second
+)
+
+var httpClient = &http.Client{
+	Timeout: requestTimeout,
+}
+
+// Track describes one listen.
+type Track struct {
+	Artist    string
+	Title     string
+	Album     string
+	Duration  time.Duration
+	StartedAt time.Time
+}
+
+// Scrobbler submits listens to one or more services.
+type Scrobbler interface {
+	// NowPlaying announces a track that just started.
+	NowPlaying(ctx context.Context, t Track) error
+	// Scrobble records a finished listen.
+	Scrobble(ctx context.Context, t Track) error
+}
+
+// New returns a Scrobbler for every service configured in cfg, or nil when
+// none is.
+func New(cfg config.Scrobble) Scrobbler {
+	var services multi
+	if cfg.ListenBrainz.Token != "" {
+		services = append(services, newListenBrainz(cfg.ListenBrainz))
+	}
+	if cfg.LastFM.APIKey != "" && cfg.LastFM.Secret != "" && cfg.LastFM.SessionKey != "" {
+		services = append(services, newLastFM(cfg.LastFM))
+	}
+	switch len(services) {
+	case 0:
+		return nil
+	case 1:
+		return services[0]
+	}
+	return services
+}
+
+// Eligible applies the standard scrobble rule: a track of at least 30
+// seconds counts once half of it or four minutes have been played,
+// whichever comes first.
+func Eligible(played, duration time.Duration) bool {
+	if duration < minTrackLength {
+		return false
+	}
+	return played >= duration/2 || played >= scrobbleAfter
+}
+
+// multi fans each call out to several services, joining their errors.
+type multi []Scrobbler
+
+func (m multi) NowPlaying(ctx context.Context, t Track) error {
+	var errs []error
+	for _, s := range m {
+		errs = append(errs, s.NowPlaying(ctx, t))
+	}
+	return errors.Join(errs...)
+}
+
+func (m multi) Scrobble(ctx context.Context, t Track) error {
+	var errs []error
+	for _, s := range m {
+		errs = append(errs, s.Scrobble(ctx, t))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package scrobble
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/olivier-w/climp/internal/config"
+)
+
+func TestEligibleFollowsHalfOrFourMinuteRule(t *testing.T) {
+	for _, tc := range []struct {
+		played, duration time.Duration
+		want             bool
+	}{
+		{played: 20 * time.Second, duration: 25 * time.Second, want: false},
+		{played: 89 * time.Second, duration: 3 * time.Minute, want: false},
+		{played: 90 * time.Second, duration: 3 * time.Minute, want: true},
+		{played: 4 * time.Minute, duration: time.Hour, want: true},
+		{played: 3 * time.Minute, duration: time.Hour, want: false},
+	} {
+		if got := Eligible(tc.played, tc.duration); got != tc.want {
+			t.Errorf("Eligible(%v, %v) = %v, want %v", tc.played, tc.duration, got, tc.want)
+		}
+	}
+}
+
+func TestNewReturnsNilWithoutCredentials(t *testing.T) {
+	if s := New(config.Scrobble{}); s != nil {
+		t.Fatalf("New() = %#v, want nil", s)
+	}
+	if s := New(config.Scrobble{LastFM: config.LastFM{APIKey: "key"}}); s != nil {
+		t.Fatalf("New() with partial Last.fm credentials = %#v, want nil", s)
+	}
+}
+
+func TestListenBrainzScrobbleSubmitsSingleListen(t *testing.T) {
+	var got lbSubmission
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/submit-listens" {
+			t.Errorf("path = %q, want /1/submit-listens", r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	lb := newListenBrainz(config.ListenBrainz{Token: "secret", URL: srv.URL + "/"})
+	started := time.Unix(1700000000, 0)
+	err := lb.Scrobble(context.Background(), Track{Artist: "Artist", Title: "Song", Album: "Album", Duration: 3 * time.Minute, StartedAt: started})
+	if err != nil {
+		t.Fatalf("Scrobble() error = %v", err)
+	}
+	if auth != "Token secret" {
+		t.Fatalf("Authorization = %q, want Token secret", auth)
+	}
+	if got.ListenType != "single" || len(got.Payload) != 1 {
+		t.Fatalf("submission = %+v, want one single listen", got)
+	}
+	listen := got.Payload[0]
+	if listen.ListenedAt != started.Unix() || listen.Metadata.Artist != "Artist" || listen.Metadata.Title != "Song" || listen.Metadata.Album != "Album" {
+		t.Fatalf("listen = %+v", listen)
+	}
+}
+
+func TestListenBrainzReportsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"code":401,"error":"Invalid authorization token."}`))
+	}))
+	defer srv.Close()
+
+	lb := newListenBrainz(config.ListenBrainz{Token: "bad", URL: srv.URL})
+	err := lb.NowPlaying(context.Background(), Track{Artist: "Artist", Title: "Song"})
+	if err == nil || err.Error() != "listenbrainz: Invalid authorization token." {
+		t.Fatalf("NowPlaying() error = %v", err)
+	}
+}
+
+func TestLastFMScrobbleSignsRequest(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		form = r.PostForm
+		w.Write([]byte(`{"scrobbles":{}}`))
+	}))
+	defer srv.Close()
+
+	lf := newLastFM(config.LastFM{APIKey: "key", Secret: "shh", SessionKey: "session"})
+	lf.url = srv.URL
+	err := lf.Scrobble(context.Background(), Track{Artist: "Artist", Title: "Song", Duration: 3 * time.Minute, StartedAt: time.Unix(1700000000, 0)})
+	if err != nil {
+		t.Fatalf("Scrobble() error = %v", err)
+	}
+	if form.Get("method") != "track.scrobble" || form.Get("timestamp") != "1700000000" || form.Get("duration") != "180" {
+		t.Fatalf("form = %v", form)
+	}
+	// MD5 of "api_keykeyartistArtistduration180methodtrack.scrobble" +
+	// "sksessiontimestamp1700000000trackSong" + "shh".
+	if got, want := form.Get("api_sig"), "876f507bad9025b61f6a0ac31f1b77bd"; got != want {
+		t.Fatalf("api_sig = %q, want %q", got, want)
+	}
+}
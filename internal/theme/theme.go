@@ -0,0 +1,244 @@
+// Package theme defines the named color palettes used by the UI styles and
+// the visualizers.
+package theme
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// RGB is a 24-bit color drawn by the visualizers.
+type RGB struct {
+	R uint8
+	G uint8
+	B uint8
+}
+
+// Adaptive is a pair of hex colors for light and dark terminal backgrounds.
+type Adaptive struct {
+	Light string
+	Dark  string
+}
+
+// Theme is a palette. UI colors adapt to the terminal background; the
+// visualizer colors are written directly and downgraded to 256 or 16 colors
+// as the terminal requires.
+type Theme struct {
+	Name string
+
+	Title          Adaptive
+	Artist         Adaptive
+	Time           Adaptive
+	Status         Adaptive
+	Help           Adaptive
+	Header         Adaptive
+	Accent         Adaptive // selection borders and the queue title
+	ActiveDot      Adaptive
+	InactiveDot    Adaptive
+	WaveformPlayed Adaptive
+	Waveform       Adaptive
+
+	// Heat is the gradient from quiet to loud used by the bars and
+	// waterfall, with its stops evenly spaced.
+	Heat [5]RGB
+	// Peak marks peaks, sparks, and the heads of falling trails.
+	Peak RGB
+	// Background is what old waterfall rows fade towards.
+	Background RGB
+	// VULow, VUMid, and VUHigh color the VU meter's three zones.
+	VULow  RGB
+	VUMid  RGB
+	VUHigh RGB
+
+	// Visualizers that sweep through hues map hue h to HueShift+HueScale*h
+	// and scale its saturation by Saturation, so 0 draws in grays.
+	HueShift   float64
+	HueScale   float64
+	Saturation float64
+}
+
+// Default is the original climp look.
+var Default = Theme{
+	Name: "default",
+
+	Title:          Adaptive{Light: "#333333", Dark: "#FFFFFF"},
+	Artist:         Adaptive{Light: "#666666", Dark: "#AAAAAA"},
+	Time:           Adaptive{Light: "#888888", Dark: "#888888"},
+	Status:         Adaptive{Light: "#555555", Dark: "#BBBBBB"},
+	Help:           Adaptive{Light: "#999999", Dark: "#666666"},
+	Header:         Adaptive{Light: "#555555", Dark: "#888888"},
+	Accent:         Adaptive{Light: "#555555", Dark: "#AAAAAA"},
+	ActiveDot:      Adaptive{Light: "#847A85", Dark: "#979797"},
+	InactiveDot:    Adaptive{Light: "#DDDADA", Dark: "#3C3C3C"},
+	WaveformPlayed: Adaptive{Light: "#555555", Dark: "#BBBBBB"},
+	Waveform:       Adaptive{Light: "#BBBBBB", Dark: "#555555"},
+
+	Heat: [5]RGB{
+		{R: 16, G: 25, B: 70},
+		{R: 0, G: 174, B: 255},
+		{R: 20, G: 255, B: 161},
+		{R: 255, G: 230, B: 92},
+		{R: 255, G: 80, B: 60},
+	},
+	Peak:       RGB{R: 250, G: 250, B: 250},
+	Background: RGB{R: 18, G: 22, B: 32},
+	VULow:      RGB{R: 60, G: 224, B: 116},
+	VUMid:      RGB{R: 240, G: 198, B: 72},
+	VUHigh:     RGB{R: 242, G: 96, B: 86},
+
+	HueShift:   0,
+	HueScale:   1,
+	Saturation: 1,
+}
+
+// Mono draws everything in grays.
+var Mono = Theme{
+	Name: "mono",
+
+	Title:          Default.Title,
+	Artist:         Default.Artist,
+	Time:           Default.Time,
+	Status:         Default.Status,
+	Help:           Default.Help,
+	Header:         Default.Header,
+	Accent:         Default.Accent,
+	ActiveDot:      Adaptive{Light: "#777777", Dark: "#999999"},
+	InactiveDot:    Default.InactiveDot,
+	WaveformPlayed: Default.WaveformPlayed,
+	Waveform:       Default.Waveform,
+
+	Heat: [5]RGB{
+		{R: 48, G: 48, B: 48},
+		{R: 100, G: 100, B: 100},
+		{R: 150, G: 150, B: 150},
+		{R: 200, G: 200, B: 200},
+		{R: 245, G: 245, B: 245},
+	},
+	Peak:       RGB{R: 255, G: 255, B: 255},
+	Background: RGB{R: 20, G: 20, B: 20},
+	VULow:      RGB{R: 150, G: 150, B: 150},
+	VUMid:      RGB{R: 200, G: 200, B: 200},
+	VUHigh:     RGB{R: 245, G: 245, B: 245},
+
+	HueShift:   0,
+	HueScale:   1,
+	Saturation: 0,
+}
+
+// Sunset runs from deep purple through magenta to orange and gold.
+var Sunset = Theme{
+	Name: "sunset",
+
+	Title:          Adaptive{Light: "#7A2E12", Dark: "#FFD9A8"},
+	Artist:         Adaptive{Light: "#9C4A3A", Dark: "#F0A58C"},
+	Time:           Adaptive{Light: "#A0707A", Dark: "#B08A94"},
+	Status:         Adaptive{Light: "#8A4A2A", Dark: "#F2C29A"},
+	Help:           Adaptive{Light: "#B0909A", Dark: "#6E5560"},
+	Header:         Adaptive{Light: "#9C4A3A", Dark: "#E08A6A"},
+	Accent:         Adaptive{Light: "#C0503A", Dark: "#FF8A5C"},
+	ActiveDot:      Adaptive{Light: "#C0503A", Dark: "#FF8A5C"},
+	InactiveDot:    Adaptive{Light: "#EAD5D0", Dark: "#4A3038"},
+	WaveformPlayed: Adaptive{Light: "#C0503A", Dark: "#FFB27A"},
+	Waveform:       Adaptive{Light: "#E0C0B8", Dark: "#5A3A44"},
+
+	Heat: [5]RGB{
+		{R: 45, G: 16, B: 70},
+		{R: 130, G: 36, B: 120},
+		{R: 230, G: 70, B: 96},
+		{R: 255, G: 140, B: 60},
+		{R: 255, G: 215, B: 120},
+	},
+	Peak:       RGB{R: 255, G: 236, B: 200},
+	Background: RGB{R: 30, G: 14, B: 32},
+	VULow:      RGB{R: 255, G: 170, B: 80},
+	VUMid:      RGB{R: 250, G: 110, B: 70},
+	VUHigh:     RGB{R: 220, G: 48, B: 96},
+
+	HueShift:   0.8,
+	HueScale:   0.32,
+	Saturation: 1,
+}
+
+// Matrix is green on black.
+var Matrix = Theme{
+	Name: "matrix",
+
+	Title:          Adaptive{Light: "#0B6B1E", Dark: "#B8FFC4"},
+	Artist:         Adaptive{Light: "#2E7A3A", Dark: "#5FD87A"},
+	Time:           Adaptive{Light: "#4E8A58", Dark: "#3FA055"},
+	Status:         Adaptive{Light: "#2E7A3A", Dark: "#7CE890"},
+	Help:           Adaptive{Light: "#7FAA86", Dark: "#2E6A3A"},
+	Header:         Adaptive{Light: "#2E7A3A", Dark: "#3FA055"},
+	Accent:         Adaptive{Light: "#138A2C", Dark: "#3DFF6A"},
+	ActiveDot:      Adaptive{Light: "#138A2C", Dark: "#3DFF6A"},
+	InactiveDot:    Adaptive{Light: "#CFE6D2", Dark: "#17361D"},
+	WaveformPlayed: Adaptive{Light: "#138A2C", Dark: "#5FD87A"},
+	Waveform:       Adaptive{Light: "#B8D8BE", Dark: "#1F4A27"},
+
+	Heat: [5]RGB{
+		{R: 0, G: 30, B: 8},
+		{R: 0, G: 90, B: 24},
+		{R: 0, G: 170, B: 48},
+		{R: 70, G: 235, B: 90},
+		{R: 200, G: 255, B: 205},
+	},
+	Peak:       RGB{R: 234, G: 255, B: 240},
+	Background: RGB{R: 0, G: 14, B: 4},
+	VULow:      RGB{R: 0, G: 170, B: 48},
+	VUMid:      RGB{R: 70, G: 235, B: 90},
+	VUHigh:     RGB{R: 200, G: 255, B: 205},
+
+	HueShift:   0.28,
+	HueScale:   0.12,
+	Saturation: 1,
+}
+
+var themes = []*Theme{&Default, &Mono, &Sunset, &Matrix}
+
+var current atomic.Pointer[Theme]
+
+func init() {
+	current.Store(&Default)
+}
+
+// Current returns the active theme.
+func Current() *Theme {
+	return current.Load()
+}
+
+// Set makes t the active theme for everything drawn after the call.
+func Set(t *Theme) {
+	if t == nil {
+		t = &Default
+	}
+	current.Store(t)
+}
+
+// Names lists the built-in themes.
+func Names() []string {
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Parse looks up a built-in theme by name. An empty name is the default.
+func Parse(name string) (*Theme, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return &Default, nil
+	}
+	for _, t := range themes {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return &Default, fmt.Errorf("unknown theme %q (use %s)", name, strings.Join(Names(), ", "))
+}
+
+// Hue maps a hue in 0..1 into the theme's range.
+func (t *Theme) Hue(h float64) float64 {
+	return t.HueShift + t.HueScale*h
+}
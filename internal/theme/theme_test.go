@@ -0,0 +1,38 @@
+package theme
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for _, name := range Names() {
+		got, err := Parse(" " + name + " ")
+		if err != nil || got.Name != name {
+			t.Fatalf("Parse(%q) = %v, %v", name, got, err)
+		}
+	}
+	if got, err := Parse(""); err != nil || got != &Default {
+		t.Fatalf("Parse(\"\") = %v, %v, want the default", got, err)
+	}
+	if got, err := Parse("neon"); err == nil || got != &Default {
+		t.Fatalf("Parse(unknown) = %v, %v, want the default and an error", got, err)
+	}
+}
+
+func TestSetNilRestoresDefault(t *testing.T) {
+	t.Cleanup(func() { Set(&Default) })
+	Set(&Matrix)
+	if Current() != &Matrix {
+		t.Fatalf("Current() = %q, want matrix", Current().Name)
+	}
+	Set(nil)
+	if Current() != &Default {
+		t.Fatalf("Current() = %q, want default", Current().Name)
+	}
+}
+
+func TestThemesHaveValidHueSettings(t *testing.T) {
+	for _, th := range themes {
+		if th.HueScale <= 0 || th.Saturation < 0 || th.Saturation > 1 {
+			t.Fatalf("%s: hue scale %v, saturation %v", th.Name, th.HueScale, th.Saturation)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg" // cover art decoders
+	_ "image/png"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// artworkRows is the height of the cover art drawn above the title. Each row
+// holds two image pixels, drawn as an upper half block with separate
+// foreground and background colors.
+const artworkRows = 8
+
+// artworkCache holds the decoded cover of the current track and its rendered
+// lines. It is shared between Model copies, like the caches it feeds.
+type artworkCache struct {
+	src   []byte
+	img   image.Image
+	cols  int
+	lines string
+}
+
+// image returns src decoded, reusing the last result while src is the same
+// slice. It returns nil for missing or undecodable images.
+func (c *artworkCache) image(src []byte) image.Image {
+	if c == nil || len(src) == 0 {
+		return nil
+	}
+	if len(c.src) != len(src) || &c.src[0] != &src[0] {
+		c.src, c.lines = src, ""
+		c.img, _, _ = image.Decode(bytes.NewReader(src))
+	}
+	return c.img
+}
+
+// toggleArtwork switches the header between cover art and the title only.
+func (m *Model) toggleArtwork() {
+	m.showArt = !m.showArt
+	if m.showArt && m.art.image(m.metadata.Artwork) == nil {
+		m.saveMsg = "No cover art for this track"
+		m.saveMsgTime = time.Now()
+		m.invalidate(dirtyMid)
+	}
+	m.invalidate(dirtyHeader)
+}
+
+// artworkShown reports whether the header draws cover art: it is toggled on,
+// the track has a decodable image, and the terminal shows colors. Otherwise
+// the header is text only.
+func (m *Model) artworkShown() bool {
+	return m.showArt && !m.compactLayout() &&
+		lipgloss.ColorProfile() != termenv.Ascii &&
+		m.art.image(m.metadata.Artwork) != nil
+}
+
+// artworkView renders the cover art in artworkRows lines and at most
+// effectiveWidth() columns, as indented lines ending in newlines. A cover
+// too wide for the terminal is shrunk to fit and centered vertically, so
+// the header keeps its height.
+func (m *Model) artworkView() string {
+	img := m.art.image(m.metadata.Artwork)
+	if img == nil {
+		return ""
+	}
+	b := img.Bounds()
+	cols, rows := fitImage(b.Dx(), b.Dy(), m.effectiveWidth(), artworkRows)
+	if m.art.lines == "" || m.art.cols != cols {
+		m.art.cols = cols
+		pad := artworkRows - rows
+		m.art.lines = strings.Repeat("\n", pad/2) + renderHalfBlocks(img, cols, rows) + strings.Repeat("\n", pad-pad/2)
+	}
+	return m.art.lines
+}
+
+// fitImage returns the largest cols x rows of half-block cells, within
+// maxCols x maxRows, that show a w x h image without distortion. A cell is
+// one pixel wide and two tall, so a square image takes twice as many
+// columns as rows. The result is never smaller than 4x2, even when that
+// overflows a very small terminal.
+func fitImage(w, h, maxCols, maxRows int) (cols, rows int) {
+	w, h = max(w, 1), max(h, 1)
+	rows = maxRows
+	cols = 2 * rows * w / h
+	if cols > maxCols {
+		cols = maxCols
+		rows = (cols*h + w) / (2 * w) // rounded
+	}
+	return max(cols, 4), max(rows, 2)
+}
+
+// renderHalfBlocks draws img in cols x rows cells of "▀".
+func renderHalfBlocks(img image.Image, cols, rows int) string {
+	var sb strings.Builder
+	for y := 0; y < rows; y++ {
+		sb.WriteString("  ")
+		for x := 0; x < cols; x++ {
+			top := samplePixel(img, x, 2*y, cols, 2*rows)
+			bottom := samplePixel(img, x, 2*y+1, cols, 2*rows)
+			sb.WriteString(lipgloss.NewStyle().
+				Foreground(lipgloss.Color(top)).
+				Background(lipgloss.Color(bottom)).
+				Render("▀"))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// samplePixel averages the block of img that maps to pixel (x, y) of a w x h
+// downscale and returns it as a #rrggbb color.
+func samplePixel(img image.Image, x, y, w, h int) string {
+	b := img.Bounds()
+	x0, x1 := b.Min.X+x*b.Dx()/w, b.Min.X+(x+1)*b.Dx()/w
+	y0, y1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+(y+1)*b.Dy()/h
+	x1, y1 = max(x1, x0+1), max(y1, y0+1)
+
+	var r, g, bl, n uint64
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			pr, pg, pb, _ := img.At(px, py).RGBA()
+			r, g, bl = r+uint64(pr>>8), g+uint64(pg>>8), bl+uint64(pb>>8)
+			n++
+		}
+	}
+	return fmt.Sprintf("#%02x%02x%02x", r/n, g/n, bl/n)
+}
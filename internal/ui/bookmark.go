@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/olivier-w/climp/internal/config"
+	"github.com/olivier-w/climp/internal/util"
+)
+
+const (
+	// autoBookmarkMinDuration limits bookmarking on quit to long tracks
+	// (audiobooks, podcasts) so ordinary songs still start from the top.
+	autoBookmarkMinDuration = 20 * time.Minute
+	// bookmarkEndMargin skips resuming a track that was listened to the end.
+	bookmarkEndMargin = 10 * time.Second
+)
+
+// bookmarkKey returns the bookmark key for the current track, or "" when it
+// is not a seekable local file. Streams, downloaded URL tracks, and cue sheet
+// tracks are skipped.
+func (m *Model) bookmarkKey() string {
+	if m.player == nil || !m.player.CanSeek() || m.sourcePath != "" {
+		return ""
+	}
+	if m.queue != nil {
+		if t := m.queue.Current(); t == nil || t.URL != "" || isSection(t) {
+			return ""
+		}
+	}
+	path := m.player.Path()
+	if path == "" {
+		return ""
+	}
+	key, err := config.BookmarkKey(path)
+	if err != nil {
+		return ""
+	}
+	return key
+}
+
+// saveBookmark stores the current position for the b key.
+func (m *Model) saveBookmark() {
+	key := m.bookmarkKey()
+	if key == "" {
+		return
+	}
+	pos := m.player.Position()
+	if err := config.SaveBookmark(key, pos); err != nil {
+		m.saveMsg = "Bookmark failed: " + err.Error()
+	} else {
+		m.saveMsg = "Bookmarked at " + util.FormatDuration(pos)
+	}
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+}
+
+// autoBookmark stores the position of long tracks on quit. Errors are
+// ignored, like settings persistence.
+func (m *Model) autoBookmark() {
+	if m.duration < autoBookmarkMinDuration {
+		return
+	}
+	if key := m.bookmarkKey(); key != "" {
+		_ = config.SaveBookmark(key, m.player.Position())
+	}
+}
+
+// ResumeBookmark seeks the opening track to its saved bookmark, if any.
+func (m Model) ResumeBookmark() Model {
+	key := m.bookmarkKey()
+	if key == "" {
+		return m
+	}
+	pos, ok := config.LoadBookmark(key)
+	if !ok || pos >= m.duration-bookmarkEndMargin {
+		return m
+	}
+	if err := m.player.SeekTo(pos, true); err != nil {
+		return m
+	}
+	m.elapsed = pos
+	m.saveMsg = "Resumed at " + util.FormatDuration(pos)
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+	m.flushCaches()
+	return m
+}
+
+// StartAt seeks the opening track to pos before the TUI starts, for
+// --start-at. Use it instead of ResumeBookmark: an explicit position wins
+// over a saved one. Live streams and positions past the end are errors.
+func (m Model) StartAt(pos time.Duration) (Model, error) {
+	if m.player == nil || !m.player.CanSeek() {
+		return m, fmt.Errorf("--start-at needs a seekable file, not a live stream")
+	}
+	if m.duration > 0 && pos >= m.duration {
+		return m, fmt.Errorf("--start-at %s is past the end of the track (%s)", util.FormatDuration(pos), util.FormatDuration(m.duration))
+	}
+	if err := m.player.SeekTo(pos, true); err != nil {
+		return m, fmt.Errorf("--start-at: %w", err)
+	}
+	m.elapsed = pos
+	m.invalidate(dirtyMid)
+	m.flushCaches()
+	return m, nil
+}
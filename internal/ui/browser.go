@@ -10,7 +10,9 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/olivier-w/climp/internal/config"
 	"github.com/olivier-w/climp/internal/media"
+	"github.com/olivier-w/climp/internal/theme"
 )
 
 // BrowserResult holds the outcome of the file browser.
@@ -34,20 +36,77 @@ func (i fileItem) Title() string       { return i.name }
 func (i fileItem) Description() string { return i.ext }
 func (i fileItem) FilterValue() string { return i.name }
 
-type urlItem struct{}
+type openItem struct{}
 
-func (i urlItem) Title() string       { return "Play from URL..." }
-func (i urlItem) Description() string { return "enter a URL to stream" }
-func (i urlItem) FilterValue() string { return "url" }
+func (i openItem) Title() string       { return "Open path or URL..." }
+func (i openItem) Description() string { return "type or paste a file, folder, or URL (o)" }
+func (i openItem) FilterValue() string { return "url path" }
+
+type recentSectionItem struct{ count int }
+
+func (i recentSectionItem) Title() string { return "Recently played..." }
+func (i recentSectionItem) Description() string {
+	return fmt.Sprintf("%d tracks and streams from the play history", i.count)
+}
+func (i recentSectionItem) FilterValue() string { return "recently played" }
+
+// recentItem is a track or stream from the play history.
+type recentItem struct {
+	config.RecentPlay
+}
+
+func (i recentItem) Title() string { return i.Play.Title }
+func (i recentItem) Description() string {
+	plays := "1 play"
+	if i.Count != 1 {
+		plays = fmt.Sprintf("%d plays", i.Count)
+	}
+	return plays + " · " + i.At.Local().Format("Jan 2 15:04") + " · " + i.Source
+}
+func (i recentItem) FilterValue() string { return i.Play.Title + " " + i.Source }
+
+type favoritesSectionItem struct{ count int }
+
+func (i favoritesSectionItem) Title() string { return "Favorites..." }
+func (i favoritesSectionItem) Description() string {
+	if i.count == 1 {
+		return "1 starred track or stream"
+	}
+	return fmt.Sprintf("%d starred tracks and streams", i.count)
+}
+func (i favoritesSectionItem) FilterValue() string { return "favorites" }
+
+// favoriteItem is a track or stream starred with the favorite key.
+type favoriteItem struct {
+	config.Favorite
+}
+
+func (i favoriteItem) Title() string       { return i.Favorite.Title }
+func (i favoriteItem) Description() string { return i.Source }
+func (i favoriteItem) FilterValue() string { return i.Favorite.Title + " " + i.Source }
+
+// browserSection is the list the browser is showing.
+type browserSection int
+
+const (
+	sectionFiles browserSection = iota
+	sectionRecent
+	sectionFavorites
+)
 
 // BrowserModel is the Bubbletea model for the file browser screen.
 type BrowserModel struct {
-	list     list.Model
-	input    textinput.Model
-	urlMode  bool
-	result   *BrowserResult
-	err      error
-	embedded bool
+	list      list.Model
+	input     textinput.Model
+	inputMode bool // typing a path or URL instead of browsing
+	result    *BrowserResult
+	err       error
+	embedded  bool
+
+	files     []list.Item // the directory listing, restored when leaving a section
+	recent    []list.Item // recentItems, opened from the "Recently played..." entry
+	favorites []list.Item // favoriteItems, opened from the "Favorites..." entry
+	section   browserSection
 }
 
 // NewBrowser creates a new file browser model scanning the current directory.
@@ -67,7 +126,7 @@ func newBrowser(embedded bool) BrowserModel {
 		return BrowserModel{err: fmt.Errorf("cannot read directory: %w", err), embedded: embedded}
 	}
 
-	items := []list.Item{urlItem{}}
+	items := []list.Item{openItem{}}
 	for _, e := range entries {
 		if e.IsDir() {
 			continue
@@ -80,13 +139,14 @@ func newBrowser(embedded bool) BrowserModel {
 		items = append(items, fileItem{name: name, ext: ext})
 	}
 
+	th := theme.Current()
 	delegate := list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
-		Foreground(lipgloss.AdaptiveColor{Light: "#333333", Dark: "#FFFFFF"}).
-		BorderLeftForeground(lipgloss.AdaptiveColor{Light: "#555555", Dark: "#AAAAAA"})
+		Foreground(adaptive(th.Title)).
+		BorderLeftForeground(adaptive(th.Accent))
 	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
 		Foreground(lipgloss.AdaptiveColor{Light: "#666666", Dark: "#888888"}).
-		BorderLeftForeground(lipgloss.AdaptiveColor{Light: "#555555", Dark: "#AAAAAA"})
+		BorderLeftForeground(adaptive(th.Accent))
 
 	l := list.New(items, delegate, 80, 20)
 	l.Title = "climp"
@@ -95,11 +155,68 @@ func newBrowser(embedded bool) BrowserModel {
 	l.Styles.Title = headerStyle
 
 	ti := textinput.New()
-	ti.Placeholder = "https://..."
+	ti.Placeholder = "song.mp3, ~/Music, or https://..."
 	ti.CharLimit = 2048
 	ti.Width = 60
 
-	return BrowserModel{list: l, input: ti, embedded: embedded}
+	return BrowserModel{list: l, input: ti, embedded: embedded, files: items}
+}
+
+// WithRecentPlays adds a "Recently played..." entry listing plays, most
+// recent first. It is left out when plays is empty.
+func (m BrowserModel) WithRecentPlays(plays []config.RecentPlay) BrowserModel {
+	if m.err != nil || len(plays) == 0 {
+		return m
+	}
+	m.recent = make([]list.Item, len(plays))
+	for i, p := range plays {
+		m.recent[i] = recentItem{p}
+	}
+	m.files = append([]list.Item{m.files[0], recentSectionItem{count: len(plays)}}, m.files[1:]...)
+	m.list.SetItems(m.files)
+	return m
+}
+
+// WithFavorites adds a "Favorites..." entry listing favs in stored order. It
+// is left out when favs is empty.
+func (m BrowserModel) WithFavorites(favs []config.Favorite) BrowserModel {
+	if m.err != nil || len(favs) == 0 {
+		return m
+	}
+	m.favorites = make([]list.Item, len(favs))
+	for i, f := range favs {
+		m.favorites[i] = favoriteItem{f}
+	}
+	m.files = append([]list.Item{m.files[0], favoritesSectionItem{count: len(favs)}}, m.files[1:]...)
+	m.list.SetItems(m.files)
+	return m
+}
+
+// setSection switches the list between the directory, the play history, and
+// the favorites.
+func (m *BrowserModel) setSection(section browserSection) tea.Cmd {
+	m.section = section
+	m.list.ResetFilter()
+	m.list.ResetSelected()
+	switch section {
+	case sectionRecent:
+		m.list.Title = "Recently played"
+		return tea.Batch(m.list.SetItems(m.recent), tea.SetWindowTitle("climp - recently played"))
+	case sectionFavorites:
+		m.list.Title = "Favorites"
+		return tea.Batch(m.list.SetItems(m.favorites), tea.SetWindowTitle("climp - favorites"))
+	}
+	m.list.Title = "climp"
+	return tea.Batch(m.list.SetItems(m.files), tea.SetWindowTitle("climp"))
+}
+
+// selectPath reports path as the browser's choice.
+func (m BrowserModel) selectPath(path string) (tea.Model, tea.Cmd) {
+	if m.embedded {
+		return m, func() tea.Msg { return BrowserSelectedMsg{Path: path} }
+	}
+	m.result = &BrowserResult{Path: path}
+	return m, tea.Sequence(tea.SetWindowTitle(""), tea.Quit)
 }
 
 // HasError returns true if the browser could not be initialized.
@@ -125,8 +242,8 @@ func (m BrowserModel) Init() tea.Cmd {
 }
 
 func (m BrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if m.urlMode {
-		return m.updateURLInput(msg)
+	if m.inputMode {
+		return m.updateInput(msg)
 	}
 
 	switch msg := msg.(type) {
@@ -135,24 +252,39 @@ func (m BrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.list.FilterState() == list.Filtering {
 			break
 		}
+		// A paste while browsing opens the entry with the pasted text.
+		if msg.Paste {
+			open := m.openInput()
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, tea.Batch(open, cmd)
+		}
 
 		switch msg.String() {
+		case "o":
+			return m, m.openInput()
 		case "enter":
 			switch m.list.SelectedItem().(type) {
-			case urlItem:
-				m.urlMode = true
-				m.input.Focus()
-				return m, tea.Batch(textinput.Blink, tea.SetWindowTitle("climp - enter URL"))
+			case openItem:
+				return m, m.openInput()
+			case recentSectionItem:
+				return m, m.setSection(sectionRecent)
+			case recentItem:
+				return m.selectPath(m.list.SelectedItem().(recentItem).Source)
+			case favoritesSectionItem:
+				return m, m.setSection(sectionFavorites)
+			case favoriteItem:
+				return m.selectPath(m.list.SelectedItem().(favoriteItem).Source)
 			case fileItem:
 				item := m.list.SelectedItem().(fileItem)
-				path := item.name + item.ext
-				if m.embedded {
-					return m, func() tea.Msg { return BrowserSelectedMsg{Path: path} }
-				}
-				m.result = &BrowserResult{Path: path}
-				return m, tea.Sequence(tea.SetWindowTitle(""), tea.Quit)
+				return m.selectPath(item.name + item.ext)
+			}
+		case "esc":
+			if m.section != sectionFiles {
+				return m, m.setSection(sectionFiles)
 			}
-		case "q", "esc", "ctrl+c":
+			fallthrough
+		case "q", "ctrl+c":
 			if m.embedded {
 				return m, func() tea.Msg { return BrowserCancelledMsg{} }
 			}
@@ -171,21 +303,39 @@ func (m BrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m BrowserModel) updateURLInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+// openInput switches from the list to the path/URL entry.
+func (m *BrowserModel) openInput() tea.Cmd {
+	m.inputMode = true
+	m.input.Focus()
+	return tea.Batch(textinput.Blink, tea.SetWindowTitle("climp - open"))
+}
+
+// inputSource cleans up a typed or pasted path or URL: surrounding spaces
+// and the quotes terminals add around dropped files are removed, and a
+// leading ~ is expanded to the home directory.
+func inputSource(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	if s == "~" || strings.HasPrefix(s, "~/") || strings.HasPrefix(s, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			s = filepath.Join(home, s[1:])
+		}
+	}
+	return s
+}
+
+func (m BrowserModel) updateInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "enter":
-			url := strings.TrimSpace(m.input.Value())
-			if url != "" {
-				if m.embedded {
-					return m, func() tea.Msg { return BrowserSelectedMsg{Path: url} }
-				}
-				m.result = &BrowserResult{Path: url}
-				return m, tea.Sequence(tea.SetWindowTitle(""), tea.Quit)
+			if src := inputSource(m.input.Value()); src != "" {
+				return m.selectPath(src)
 			}
 		case "esc":
-			m.urlMode = false
+			m.inputMode = false
 			m.input.Reset()
 			m.input.Blur()
 			return m, tea.SetWindowTitle("climp")
@@ -204,14 +354,14 @@ func (m BrowserModel) updateURLInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m BrowserModel) View() string {
-	if m.urlMode {
+	if m.inputMode {
 		s := "\n"
 		s += "  " + headerStyle.Render("climp") + "\n"
 		s += "\n"
-		s += "  " + statusStyle.Render("Enter URL:") + "\n"
+		s += "  " + statusStyle.Render("Open a file, folder, playlist, or URL:") + "\n"
 		s += "  " + m.input.View() + "\n"
 		s += "\n"
-		s += "  " + helpStyle.Render("enter confirm  esc back  ctrl+c quit") + "\n"
+		s += "  " + helpStyle.Render("enter open  esc back  ctrl+c quit") + "\n"
 		return s
 	}
 	return m.list.View()
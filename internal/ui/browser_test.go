@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/config"
 )
 
 func TestEmbeddedBrowserFileSelectionReturnsMessage(t *testing.T) {
@@ -39,7 +40,7 @@ func TestEmbeddedBrowserURLSelectionReturnsMessage(t *testing.T) {
 	defer restore()
 
 	m := NewEmbeddedBrowser()
-	m.urlMode = true
+	m.inputMode = true
 	m.input.SetValue("https://example.com")
 
 	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
@@ -57,6 +58,45 @@ func TestEmbeddedBrowserURLSelectionReturnsMessage(t *testing.T) {
 	}
 }
 
+func TestBrowserOpenKeyAcceptsPastedPath(t *testing.T) {
+	restore := chdirTemp(t, map[string]string{})
+	defer restore()
+
+	m := NewEmbeddedBrowser()
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	m = model.(BrowserModel)
+	if !m.inputMode {
+		t.Fatal("expected o to open the path entry")
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" '/music/My Album' "), Paste: true})
+	m = model.(BrowserModel)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected selection command")
+	}
+	selected, ok := cmd().(BrowserSelectedMsg)
+	if !ok || selected.Path != "/music/My Album" {
+		t.Fatalf("expected the unquoted pasted path, got %#v", cmd())
+	}
+}
+
+func TestBrowserPasteOpensEntry(t *testing.T) {
+	restore := chdirTemp(t, map[string]string{})
+	defer restore()
+
+	model, _ := NewEmbeddedBrowser().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("https://example.com/a.mp3"), Paste: true})
+	m := model.(BrowserModel)
+	if !m.inputMode || m.input.Value() != "https://example.com/a.mp3" {
+		t.Fatalf("inputMode = %v, value = %q; want the pasted URL in the entry", m.inputMode, m.input.Value())
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = model.(BrowserModel); m.inputMode || m.input.Value() != "" {
+		t.Fatal("expected esc to close and clear the entry")
+	}
+}
+
 func TestEmbeddedBrowserCancelReturnsMessage(t *testing.T) {
 	restore := chdirTemp(t, map[string]string{})
 	defer restore()
@@ -73,6 +113,96 @@ func TestEmbeddedBrowserCancelReturnsMessage(t *testing.T) {
 	}
 }
 
+func TestEmbeddedBrowserRecentlyPlayedSection(t *testing.T) {
+	restore := chdirTemp(t, map[string]string{
+		"song.mp3": "data",
+	})
+	defer restore()
+
+	m := NewEmbeddedBrowser().WithRecentPlays([]config.RecentPlay{
+		{Play: config.Play{Source: "/music/a.flac", Title: "A"}, Count: 3},
+		{Play: config.Play{Source: "https://example.com/live", Title: "Radio"}, Count: 1},
+	})
+	if _, ok := m.list.Items()[1].(recentSectionItem); !ok {
+		t.Fatalf("expected the recently played entry after the URL entry, got %T", m.list.Items()[1])
+	}
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = model.(BrowserModel)
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(BrowserModel)
+	if m.section != sectionRecent || len(m.list.Items()) != 2 {
+		t.Fatalf("expected the recent list, got section=%v with %d items", m.section, len(m.list.Items()))
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = model.(BrowserModel)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected selection command")
+	}
+	if selected, ok := cmd().(BrowserSelectedMsg); !ok || selected.Path != "https://example.com/live" {
+		t.Fatalf("expected the stream URL, got %#v", cmd())
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(BrowserModel)
+	if m.section != sectionFiles || len(m.list.Items()) != 3 {
+		t.Fatalf("expected esc to return to the directory, got section=%v with %d items", m.section, len(m.list.Items()))
+	}
+}
+
+func TestEmbeddedBrowserFavoritesSection(t *testing.T) {
+	restore := chdirTemp(t, map[string]string{
+		"song.mp3": "data",
+	})
+	defer restore()
+
+	m := NewEmbeddedBrowser().
+		WithRecentPlays([]config.RecentPlay{{Play: config.Play{Source: "/music/a.flac", Title: "A"}, Count: 1}}).
+		WithFavorites([]config.Favorite{{Source: "/music/b.flac", Title: "B"}})
+	if _, ok := m.list.Items()[1].(favoritesSectionItem); !ok {
+		t.Fatalf("expected the favorites entry after the URL entry, got %T", m.list.Items()[1])
+	}
+	if _, ok := m.list.Items()[2].(recentSectionItem); !ok {
+		t.Fatalf("expected the recently played entry after favorites, got %T", m.list.Items()[2])
+	}
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = model.(BrowserModel)
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(BrowserModel)
+	if m.section != sectionFavorites || len(m.list.Items()) != 1 {
+		t.Fatalf("expected the favorites list, got section=%v with %d items", m.section, len(m.list.Items()))
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected selection command")
+	}
+	if selected, ok := cmd().(BrowserSelectedMsg); !ok || selected.Path != "/music/b.flac" {
+		t.Fatalf("expected the favorite's path, got %#v", cmd())
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(BrowserModel)
+	if m.section != sectionFiles || len(m.list.Items()) != 4 {
+		t.Fatalf("expected esc to return to the directory, got section=%v with %d items", m.section, len(m.list.Items()))
+	}
+}
+
+func TestEmbeddedBrowserWithoutPlaysHasNoRecentEntry(t *testing.T) {
+	restore := chdirTemp(t, map[string]string{})
+	defer restore()
+
+	m := NewEmbeddedBrowser().WithRecentPlays(nil)
+	for _, item := range m.list.Items() {
+		if _, ok := item.(recentSectionItem); ok {
+			t.Fatal("expected no recently played entry without plays")
+		}
+	}
+}
+
 func TestStandaloneBrowserSelectionStoresResult(t *testing.T) {
 	restore := chdirTemp(t, map[string]string{
 		"song.mp3": "data",
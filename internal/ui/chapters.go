@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/player"
+)
+
+// chapterRestartWindow is how far into a chapter "," restarts it instead of
+// going to the previous one.
+const chapterRestartWindow = 3 * time.Second
+
+// chapterAt returns the index of the chapter playing at pos, or -1 before the
+// first chapter and for tracks without chapters.
+func chapterAt(chapters []player.Chapter, pos time.Duration) int {
+	idx := -1
+	for i, c := range chapters {
+		if c.Start > pos {
+			break
+		}
+		idx = i
+	}
+	return idx
+}
+
+// syncChapter records the chapter under the displayed position and reports
+// whether it changed, so the header only re-renders on chapter boundaries.
+func (m *Model) syncChapter() bool {
+	idx := chapterAt(m.metadata.Chapters, m.elapsed)
+	if idx == m.chapter {
+		return false
+	}
+	m.chapter = idx
+	return true
+}
+
+// jumpChapter previews a seek to the next (dir > 0) or previous chapter.
+func (m *Model) jumpChapter(dir int) tea.Cmd {
+	chapters := m.metadata.Chapters
+	if len(chapters) == 0 || m.player == nil || !m.player.CanSeek() {
+		return nil
+	}
+	i := chapterAt(chapters, m.elapsed)
+	if dir > 0 {
+		i++
+		if i >= len(chapters) {
+			return nil
+		}
+	} else if i >= 0 && m.elapsed-chapters[i].Start < chapterRestartWindow {
+		i--
+	}
+	if i < 0 {
+		return m.queueSeekTo(0)
+	}
+	return m.queueSeekTo(chapters[i].Start)
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"encoding/base64"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// copySource puts the current track's URL, or its absolute path for local
+// files, on the clipboard. The status line repeats what was copied, so it
+// can still be selected by hand in a terminal that ignores OSC 52.
+func (m *Model) copySource() {
+	if m.player == nil {
+		return
+	}
+	source := m.playSource()
+	if source == "" {
+		return
+	}
+	if err := writeOSC52(terminal, source, os.Getenv("TMUX") != ""); err != nil {
+		m.saveMsg = "Copy failed: " + err.Error()
+	} else {
+		m.saveMsg = "Copied " + notificationText(source)
+	}
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+}
+
+// writeOSC52 asks the terminal to set the system clipboard to text. It works
+// over SSH since the terminal, not the host, owns the clipboard; terminals
+// without support ignore it. Inside tmux the sequence is wrapped in a
+// passthrough so it reaches the outer terminal.
+func writeOSC52(w io.Writer, text string, tmux bool) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if tmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	_, err := io.WriteString(w, seq)
+	return err
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/olivier-w/climp/internal/util"
+)
+
+// compactHeight is the terminal height below which View falls back to the
+// one-line layout.
+const compactHeight = 8
+
+// WithCompact returns a model that always uses the one-line layout.
+func (m Model) WithCompact(on bool) Model {
+	m.compact = on
+	m.invalidate(dirtyHeader | dirtyMid | dirtyQueue | dirtyBottom)
+	m.flushCaches()
+	return m
+}
+
+// compactLayout reports whether the one-line layout is in use, either forced
+// by --compact or because the terminal is too short for the full view.
+func (m Model) compactLayout() bool {
+	return m.compact || (m.height > 0 && m.height < compactHeight)
+}
+
+// rebuildCompactCache renders the one-line layout into midCache: play state,
+// title, and elapsed/duration around a mini progress bar. An open prompt
+// replaces the line so typed input stays visible.
+func (m *Model) rebuildCompactCache() {
+	w := m.width
+	if w < 30 {
+		w = 50
+	}
+
+	m.barWidth = 0
+	if m.jumpMode {
+		m.midCache = "  " + m.jumpInput.View()
+		return
+	}
+	if m.volumeMode {
+		m.midCache = "  " + m.volumeInput.View()
+		return
+	}
+	if m.addMode {
+		m.midCache = "  " + m.addInput.View()
+		return
+	}
+	if m.savePrompt {
+		m.midCache = "  " + helpStyle.Render(savePromptText)
+		return
+	}
+
+	icon := "▶"
+	if m.paused {
+		icon = "❚❚"
+	}
+
+	var right string
+	barWidth, barOffset := 0, 0 // progress bar size and column within right
+	switch {
+	case m.transitioning:
+		right = statusStyle.Render("loading...")
+	case m.player != nil && !m.player.CanSeek() && m.buffering:
+		right = statusStyle.Render("buffering...") + " " + statusStyle.Render("LIVE")
+	case m.player != nil && !m.player.CanSeek():
+		right = timeStyle.Render(util.FormatDuration(m.elapsed)) + " " + statusStyle.Render("LIVE")
+	default:
+		barWidth = min(max(w/4, 10), 20)
+		pos, duration := m.displayPosition()
+		elapsed := timeStyle.Render(util.FormatDuration(pos))
+		barOffset = lipgloss.Width(elapsed) + 1
+		right = elapsed + " " +
+			renderProgressBar(pos.Seconds(), duration.Seconds(), barWidth) + " " +
+			timeStyle.Render(m.rightTime(pos, duration))
+	}
+
+	titleWidth := w - lipgloss.Width(icon) - lipgloss.Width(right) - 7
+	title := truncateLabel(m.metadata.Title, titleWidth)
+	gap := titleWidth - lipgloss.Width(title) + 2
+
+	var sb strings.Builder
+	sb.WriteString("  ")
+	sb.WriteString(statusStyle.Render(icon))
+	sb.WriteByte(' ')
+	sb.WriteString(titleStyle.Render(title))
+	sb.WriteString(spaces(gap))
+	sb.WriteString(right)
+	m.midCache = sb.String()
+	if barWidth > 0 {
+		m.barCol = lipgloss.Width(m.midCache) - lipgloss.Width(right) + barOffset
+		m.barWidth = barWidth
+	}
+}
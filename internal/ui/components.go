@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"math"
 	"strings"
 )
 
@@ -42,5 +43,61 @@ func renderProgressBar(elapsed, total float64, width int) string {
 }
 
 func renderVolumePercent(vol float64) string {
-	return fmt.Sprintf("vol %d%%", int(vol*100))
+	return fmt.Sprintf("vol %d%%", int(math.Round(vol*100)))
+}
+
+// renderPeakLevel formats the peak meter readout, adding a clip warning once
+// the track has produced full-scale samples.
+func renderPeakLevel(db float64, clips int) string {
+	s := fmt.Sprintf("%.1f dB", db)
+	if db <= -96 {
+		s = "-inf dB"
+	}
+	if clips > 0 {
+		s = fmt.Sprintf("CLIP %d  %s", clips, s)
+	}
+	return s
+}
+
+// meterCells is the width of one channel meter; meterFloorDB is the level
+// an empty meter stands for.
+const (
+	meterCells   = 6
+	meterFloorDB = -60.0
+)
+
+// renderMeter draws an RMS level (0 to 1) as cells filled on a dB scale.
+func renderMeter(level float64, cells int) string {
+	frac := 0.0
+	if level > 0 {
+		frac = (20*math.Log10(level) - meterFloorDB) / -meterFloorDB
+	}
+	filled := int(math.Round(max(0, min(frac, 1)) * float64(cells)))
+	return strings.Repeat("█", filled) + strings.Repeat("░", cells-filled)
+}
+
+// renderChannelMeters draws left and right level meters. Identical channels
+// (a mono source) get a single meter centered in the same width, so the
+// status line does not shift when a track changes channel layout.
+func renderChannelMeters(left, right float64) string {
+	if left == right {
+		pad := spaces(meterCells / 2)
+		return pad + "M " + renderMeter(left, meterCells+4) + pad
+	}
+	return "L " + renderMeter(left, meterCells) + "  R " + renderMeter(right, meterCells)
+}
+
+// placeBarMarker overwrites the progress bar cell at pos with marker. The
+// playhead is left visible when both fall in the same cell.
+func placeBarMarker(bar string, pos, total float64, marker rune) string {
+	cells := []rune(bar)
+	if total <= 0 || len(cells) == 0 {
+		return bar
+	}
+	i := int(pos / total * float64(len(cells)))
+	i = max(0, min(i, len(cells)-1))
+	if cells[i] != '●' {
+		cells[i] = marker
+	}
+	return string(cells)
 }
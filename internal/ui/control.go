@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/player"
+)
+
+// ControlMsg runs one command from the daemon's control socket against the
+// model, which answers with a JSON ControlReply on Reply. Reply must be
+// buffered so the model never blocks on a client that went away.
+//
+// Commands: pause (toggles, like space), next, prev, volume N (percent,
+// 0-300), seek +N / -N (seconds from the current position) or seek N (to
+// N seconds), status, and quit.
+type ControlMsg struct {
+	Command string
+	Reply   chan<- []byte
+}
+
+// ControlReply is the answer to a ControlMsg. Status is set for every
+// command that succeeded, so a client sees the state its command produced.
+type ControlReply struct {
+	OK     bool    `json:"ok"`
+	Error  string  `json:"error,omitempty"`
+	Status *Status `json:"status,omitempty"`
+}
+
+// Status describes what the model is playing.
+type Status struct {
+	Title    string  `json:"title"`
+	Artist   string  `json:"artist,omitempty"`
+	Album    string  `json:"album,omitempty"`
+	Source   string  `json:"source,omitempty"` // path or URL
+	State    string  `json:"state"`            // "playing", "paused", or "loading"
+	Position float64 `json:"position"`         // seconds
+	Duration float64 `json:"duration"`         // seconds; 0 for live streams
+	Live     bool    `json:"live"`
+	Volume   int     `json:"volume"` // percent, including boost
+	Muted    bool    `json:"muted"`
+	Track    int     `json:"track,omitempty"`  // 1-based queue position
+	Tracks   int     `json:"tracks,omitempty"` // queue length
+	Repeat   string  `json:"repeat"`
+	Shuffle  bool    `json:"shuffle"`
+}
+
+// WithHeadless returns a model for the daemon front-end, which runs it
+// with no renderer: screen caches are never rebuilt and the status tick
+// runs at the idle cadence, since nothing is drawn.
+func (m Model) WithHeadless() Model {
+	m.headless = true
+	return m
+}
+
+// handleControl runs a ControlMsg through the same paths as the matching
+// keys.
+func (m Model) handleControl(msg ControlMsg) (Model, tea.Cmd) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(msg.Command), " ")
+	arg = strings.TrimSpace(arg)
+	var cmd tea.Cmd
+	var err error
+	switch {
+	case name == "quit":
+		msg.reply(ControlReply{OK: true})
+		m.quitting = true
+		return m, m.shutdown()
+	case m.player == nil:
+		err = fmt.Errorf("nothing is playing")
+	case name == "status":
+	case name == "pause":
+		switch {
+		case m.seekApplying:
+			err = fmt.Errorf("seeking; try again")
+		case m.seekPending:
+			// The debounced seek has not started: have it land paused, or
+			// playing, instead.
+			m.seekResume = !m.seekResume
+		default:
+			m.player.TogglePause()
+			m.paused = m.player.Paused()
+			m.invalidate(dirtyMid)
+		}
+	case name == "next" || name == "prev":
+		if m.queue == nil {
+			err = fmt.Errorf("no queue to move through")
+		} else if name == "next" {
+			m, cmd = m.skipToNext()
+		} else {
+			m, cmd = m.skipToPrevious()
+		}
+	case name == "volume":
+		percent, perr := strconv.Atoi(arg)
+		if perr != nil || percent < 0 || percent > int(player.MaxBoost*100) {
+			err = fmt.Errorf("volume must be 0-%d", int(player.MaxBoost*100))
+		} else {
+			m.setVolumeLevel(float64(percent) / 100)
+		}
+	case name == "seek":
+		m, cmd, err = m.controlSeek(arg)
+	default:
+		err = fmt.Errorf("unknown command %q", name)
+	}
+	if err != nil {
+		msg.reply(ControlReply{Error: err.Error()})
+		return m, nil
+	}
+	status := m.status()
+	msg.reply(ControlReply{OK: true, Status: &status})
+	return m, cmd
+}
+
+// controlSeek seeks by a signed number of seconds, or to an unsigned one.
+func (m Model) controlSeek(arg string) (Model, tea.Cmd, error) {
+	if !m.player.CanSeek() {
+		return m, nil, fmt.Errorf("live streams cannot seek")
+	}
+	secs, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return m, nil, fmt.Errorf("seek needs seconds, like +10, -10, or 90")
+	}
+	d := time.Duration(secs * float64(time.Second))
+	var cmd tea.Cmd
+	if arg[0] == '+' || arg[0] == '-' {
+		m, cmd = m.seekBy(d)
+	} else {
+		m, cmd = m.seekToPosition(max(d, 0))
+	}
+	return m, cmd, nil
+}
+
+// status reports the current track and playback state. A pending seek
+// reports its target, as the progress bar does.
+func (m Model) status() Status {
+	s := Status{
+		Title:   m.metadata.Title,
+		Artist:  m.metadata.Artist,
+		Album:   m.metadata.Album,
+		Source:  m.playSource(),
+		State:   "playing",
+		Repeat:  m.repeatMode.String(),
+		Shuffle: m.queue != nil && m.queue.IsShuffled(),
+	}
+	pos := m.player.Position()
+	if m.seekPending || m.seekApplying {
+		pos = m.seekTarget
+	}
+	s.Position = pos.Seconds()
+	s.Live = !m.player.CanSeek()
+	if !s.Live {
+		s.Duration = m.duration.Seconds()
+	}
+	s.Volume = int(m.player.Volume()*max(m.player.Boost(), 1)*100 + 0.5)
+	s.Muted = m.player.Muted()
+	switch {
+	case m.transitioning:
+		s.State = "loading"
+	case m.player.Paused() && !m.seekPending && !m.seekApplying:
+		s.State = "paused"
+	case (m.seekPending || m.seekApplying) && !m.seekResume:
+		s.State = "paused"
+	}
+	if m.queue != nil {
+		s.Track = m.queue.CurrentIndex() + 1
+		s.Tracks = m.queue.Len()
+	}
+	return s
+}
+
+func (msg ControlMsg) reply(r ControlReply) {
+	if msg.Reply == nil {
+		return
+	}
+	data, _ := json.Marshal(r)
+	select {
+	case msg.Reply <- data:
+	default:
+	}
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/queue"
+)
+
+// isSection reports whether track plays only part of its file, as a cue sheet
+// track does.
+func isSection(t *queue.Track) bool {
+	return t != nil && (t.Start > 0 || t.End > 0)
+}
+
+// canPlaySection reports whether track is a section of the file the current
+// player already has open, so switching to it only needs new bounds.
+func (m Model) canPlaySection(track *queue.Track) bool {
+	return m.player != nil && isSection(track) && track.URL == "" &&
+		m.player.CanSeek() && m.player.Path() == track.Path
+}
+
+// playSection switches the current player to track's section of the same
+// file. Contiguous cue tracks continue without a seek or a new decoder.
+func (m Model) playSection(track *queue.Track) (Model, tea.Cmd) {
+	m.player.SetBounds(track.Start, track.End)
+	m.setTrackInfo(track)
+	m.elapsed = 0
+	m.duration = m.player.Duration()
+	m.noteTrackDuration()
+	m.paused = m.player.Paused()
+	m.applyTrackVolume()
+	m.transitioning = false
+	m.invalidate(dirtyHeader | dirtyQueue)
+	listened := m.beginListenCmd()
+	return m, tea.Batch(
+		checkDone(m.player),
+		tea.SetWindowTitle(windowTitle(m.metadata.Title, m.paused)),
+		m.startNextDownload(),
+		m.notifyTrackCmd(),
+		listened,
+	)
+}
+
+// jumpSection moves between cue tracks with the chapter keys: "." goes to the
+// next track and "," restarts the current one or, near its start, goes back.
+func (m Model) jumpSection(dir int) (Model, tea.Cmd) {
+	if dir > 0 {
+		return m.skipToNext()
+	}
+	if m.elapsed >= chapterRestartWindow {
+		return m, m.queueSeekTo(0)
+	}
+	return m.skipToPrevious()
+}
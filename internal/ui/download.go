@@ -188,6 +188,9 @@ func (m DownloadModel) View() string {
 			lines += "  " + m.spinner.View() + " " + statusStyle.Render("Starting download...") + "\n"
 		}
 
+	case "resuming":
+		lines += "  " + m.spinner.View() + " " + statusStyle.Render("Download stalled, resuming...") + "\n"
+
 	case "converting":
 		lines += "  " + m.spinner.View() + " " + statusStyle.Render("Converting...") + "\n"
 
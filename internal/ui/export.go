@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/downloader"
+	"github.com/olivier-w/climp/internal/media"
+	"github.com/olivier-w/climp/internal/queue"
+)
+
+// queueExportEntries lists the queue in playback order. URL tracks are written
+// by their URL (downloads are temp files), local tracks by their path.
+func queueExportEntries(q *queue.Queue) []media.PlaylistEntry {
+	order := q.PlaybackOrder()
+	entries := make([]media.PlaylistEntry, 0, len(order))
+	for _, idx := range order {
+		t := q.Track(idx)
+		if t == nil {
+			continue
+		}
+		e := media.PlaylistEntry{Title: t.Title, URL: t.URL}
+		if t.URL == "" {
+			e.Path = t.Path
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// queueExportDir returns the directory of the first local track in the queue,
+// or the current directory when the queue has only URL tracks.
+func queueExportDir(q *queue.Queue) string {
+	for i := 0; i < q.Len(); i++ {
+		if t := q.Track(i); t != nil && t.URL == "" && t.Path != "" {
+			return filepath.Dir(t.Path)
+		}
+	}
+	return "."
+}
+
+// exportQueueCmd writes the queue to an .m3u8 file without overwriting
+// existing playlists.
+func exportQueueCmd(dir, name string, entries []media.PlaylistEntry) tea.Cmd {
+	return func() tea.Msg {
+		base := downloader.SanitizeFilename(name)
+		for n := 1; n < 100; n++ {
+			dest := filepath.Join(dir, base+".m3u8")
+			if n > 1 {
+				dest = filepath.Join(dir, fmt.Sprintf("%s (%d).m3u8", base, n))
+			}
+			f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+			if os.IsExist(err) {
+				continue
+			}
+			if err != nil {
+				return queueExportedMsg{err: err}
+			}
+			err = media.WritePlaylist(f, entries)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			return queueExportedMsg{dest: dest, err: err}
+		}
+		return queueExportedMsg{err: fmt.Errorf("too many existing playlists named %s", base)}
+	}
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/olivier-w/climp/internal/config"
+)
+
+// toggleFavorite stars the current track in the favorites store, or unstars
+// it when it is already there. The source is the same one the play history
+// records, so a favorite reopens from the startup browser like a recent play.
+func (m *Model) toggleFavorite() {
+	if m.player == nil {
+		return
+	}
+	source := m.playSource()
+	if source == "" {
+		return
+	}
+	title := m.metadata.Title
+	if title == "" && m.queue != nil {
+		if t := m.queue.Current(); t != nil {
+			title = t.Title
+		}
+	}
+	if title == "" {
+		title = filepath.Base(source)
+	}
+
+	starred, err := config.ToggleFavorite(config.Favorite{Source: source, Title: title})
+	switch {
+	case err != nil:
+		m.saveMsg = "Favorite failed: " + err.Error()
+	case starred:
+		m.saveMsg = "Added to favorites"
+	default:
+		m.saveMsg = "Removed from favorites"
+	}
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/olivier-w/climp/internal/config"
+	"github.com/olivier-w/climp/internal/util"
+)
+
+// WithFolderSession marks the queue as the files of dir, in scan order, so
+// quitting saves where the session stopped for the next launch.
+func (m Model) WithFolderSession(dir string, files []string) Model {
+	m.folderDir = dir
+	m.folderFiles = files
+	return m
+}
+
+// ResumeFolder seeks the opening track to pos, where the last session in
+// its folder stopped, and says so in the status line.
+func (m Model) ResumeFolder(pos time.Duration) Model {
+	if pos > 0 && (m.duration <= 0 || pos < m.duration-bookmarkEndMargin) && m.player != nil && m.player.CanSeek() {
+		if err := m.player.SeekTo(pos, true); err == nil {
+			m.elapsed = pos
+		}
+	}
+	if m.queue != nil {
+		m.saveMsg = fmt.Sprintf("Resumed folder at track %d", m.queue.CurrentIndex()+1)
+		if m.elapsed > 0 {
+			m.saveMsg += ", " + util.FormatDuration(m.elapsed)
+		}
+		m.saveMsgTime = time.Now()
+	}
+	m.invalidate(dirtyMid)
+	m.flushCaches()
+	return m
+}
+
+// saveFolderPosition stores the current track and position of a folder
+// session on quit. A track played to the end saves the start of the next
+// one. Errors are ignored, like settings persistence.
+func (m *Model) saveFolderPosition() {
+	if m.folderDir == "" || m.queue == nil || m.player == nil {
+		return
+	}
+	t := m.queue.Current()
+	if t == nil {
+		return
+	}
+	idx := slices.Index(m.folderFiles, t.Path)
+	if idx < 0 {
+		return
+	}
+	pos := m.player.Position()
+	if m.duration > 0 && pos >= m.duration-bookmarkEndMargin {
+		idx, pos = (idx+1)%len(m.folderFiles), 0
+	}
+	_ = config.SaveFolderPosition(m.folderDir, m.folderFiles, idx, pos)
+}
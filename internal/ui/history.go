@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// maxTitleHistory is how many live stream titles the h panel keeps.
+const maxTitleHistory = 20
+
+// streamTitle is one live stream title and when it started playing.
+type streamTitle struct {
+	title string
+	at    time.Time
+}
+
+// newHistoryList creates the list for the h panel, styled like the queue.
+func newHistoryList(width int) list.Model {
+	l := newQueueList(width)
+	l.Title = "Stream History"
+	l.SetFilteringEnabled(false)
+	return l
+}
+
+// noteStreamTitle adds a new live stream title to the history, skipping a
+// repeat of the latest one and dropping the oldest past maxTitleHistory.
+func (m *Model) noteStreamTitle(title string, at time.Time) {
+	if n := len(m.titleHistory); n > 0 && m.titleHistory[n-1].title == title {
+		return
+	}
+	if m.titleHistory == nil {
+		m.historyList = newHistoryList(m.effectiveWidth())
+	}
+	m.titleHistory = append(m.titleHistory, streamTitle{title: title, at: at})
+	if len(m.titleHistory) > maxTitleHistory {
+		m.titleHistory = m.titleHistory[len(m.titleHistory)-maxTitleHistory:]
+	}
+
+	// Newest first.
+	items := make([]list.Item, len(m.titleHistory))
+	for i, t := range m.titleHistory {
+		items[len(items)-1-i] = trackItem{idx: i, title: t.title, desc: t.at.Format("15:04")}
+	}
+	m.historyList.SetItems(items)
+	if m.showHistory {
+		m.invalidate(dirtyBottom)
+	}
+}
+
+// toggleHistory shows or hides the stream title history in place of the
+// queue for the h key. It replaces the source info and lyrics panels.
+func (m *Model) toggleHistory() {
+	m.showHistory = !m.showHistory
+	if m.showHistory {
+		m.showInfo = false
+		m.showLyrics = false
+	}
+	m.invalidate(dirtyBottom)
+}
+
+// historyView renders the stream title history panel.
+func (m *Model) historyView() string {
+	if len(m.titleHistory) == 0 {
+		var sb strings.Builder
+		sb.WriteString("  ")
+		sb.WriteString(headerStyle.Render("Stream History"))
+		sb.WriteString("\n\n  ")
+		sb.WriteString(statusStyle.Render("No stream titles yet"))
+		sb.WriteByte('\n')
+		return sb.String()
+	}
+	m.historyList.SetSize(m.effectiveWidth(), m.listHeight())
+	return m.historyList.View() + "\n"
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	tickInterval = 200 * time.Millisecond
+	// idleTickInterval is the status tick while a seekable track is paused.
+	// Nothing on screen moves, so the tick only has to notice the output
+	// failing or a message expiring.
+	idleTickInterval = time.Second
+	vizTickInterval  = 50 * time.Millisecond
+)
+
+// tickCadence is the status tick interval for the current state. Live
+// streams keep the fast tick while paused: their buffering and reconnect
+// state can still change. A headless model draws nothing, so it always
+// ticks at the idle cadence.
+func (m *Model) tickCadence() time.Duration {
+	if m.headless {
+		return idleTickInterval
+	}
+	if m.paused && !m.seekPending && !m.seekApplying && m.player != nil && m.player.CanSeek() {
+		return idleTickInterval
+	}
+	return tickInterval
+}
+
+// restartTick starts a new status tick chain at the current cadence. The
+// previous chain stops at its next tick.
+func (m *Model) restartTick() tea.Cmd {
+	m.tickSeq++
+	m.tickRate = m.tickCadence()
+	return tickCmd(m.tickSeq, m.tickRate)
+}
+
+// syncTickRate brings the tick and visualizer back to full speed once
+// playback resumes or a seek starts. Slowing down needs nothing here: the
+// next tick picks the idle cadence itself.
+func (m *Model) syncTickRate() tea.Cmd {
+	if m.player == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	if m.tickRate == idleTickInterval && m.tickCadence() != idleTickInterval {
+		cmds = append(cmds, m.restartTick())
+	}
+	if !m.paused && m.vizEnabled {
+		cmds = append(cmds, m.startVizTick())
+	}
+	return tea.Batch(cmds...)
+}
+
+// startVizTick starts the visualizer tick unless one is already in flight.
+func (m *Model) startVizTick() tea.Cmd {
+	if m.vizRunning {
+		return nil
+	}
+	m.vizRunning = true
+	return vizTickCmd()
+}
+
+// nextVizTick schedules the next visualizer frame, or lets the chain lapse
+// while paused, since the sample buffer does not change.
+func (m *Model) nextVizTick() tea.Cmd {
+	if m.paused {
+		return nil
+	}
+	return m.startVizTick()
+}
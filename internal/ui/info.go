@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// infoView renders the source details panel shown in place of the queue:
+// decoder, source format, bitrate, and for URL tracks where they came from.
+func (m Model) infoView() string {
+	if m.player == nil {
+		return ""
+	}
+	info := m.player.SourceInfo()
+	w := m.effectiveWidth()
+
+	var rows [][2]string
+	if info.Decoder != "" {
+		rows = append(rows, [2]string{"decoder", info.Decoder})
+	}
+	var format []string
+	if info.SampleRate > 0 {
+		format = append(format, strconv.FormatFloat(float64(info.SampleRate)/1000, 'f', -1, 64)+" kHz")
+	}
+	switch {
+	case info.Channels == 1:
+		format = append(format, "mono")
+	case info.Channels == 2:
+		format = append(format, "stereo")
+	case info.Channels > 2:
+		format = append(format, fmt.Sprintf("%d ch", info.Channels))
+	}
+	if info.BitDepth > 0 {
+		format = append(format, fmt.Sprintf("%d-bit", info.BitDepth))
+	}
+	if len(format) > 0 {
+		rows = append(rows, [2]string{"format", strings.Join(format, " · ")})
+	}
+	if info.Bitrate > 0 {
+		rows = append(rows, [2]string{"bitrate", fmt.Sprintf("%d kbps", (info.Bitrate+500)/1000)})
+	}
+	url := m.originalURL
+	if m.queue != nil {
+		if t := m.queue.Current(); t != nil {
+			url = t.URL
+		}
+	}
+	if url != "" {
+		rows = append(rows, [2]string{"url", url})
+	}
+	if info.URL != "" && info.URL != url {
+		rows = append(rows, [2]string{"stream", info.URL})
+	}
+	if info.Live {
+		rows = append(rows, [2]string{"live", "yes"})
+	}
+
+	var sb strings.Builder
+	sb.WriteString("  ")
+	sb.WriteString(headerStyle.Render("Source"))
+	sb.WriteString("\n\n")
+	for _, r := range rows {
+		sb.WriteString("  ")
+		sb.WriteString(statusStyle.Render(fmt.Sprintf("%-9s", r[0])))
+		sb.WriteString(truncateLabel(r[1], w-13))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/util"
+)
+
+func newJumpInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "Jump to: "
+	ti.Placeholder = "HH:MM:SS"
+	ti.CharLimit = 12
+	ti.Width = 12
+	// A static cursor avoids blink ticks re-rendering the mid section.
+	ti.Cursor.SetMode(cursor.CursorStatic)
+	return ti
+}
+
+// seekToDecile previews a seek to n tenths of the current track, or of the
+// album timeline when it is active.
+func (m Model) seekToDecile(n int) (Model, tea.Cmd) {
+	_, duration := m.displayPosition()
+	if duration <= 0 {
+		return m, nil
+	}
+	return m.seekToPosition(duration * time.Duration(n) / 10)
+}
+
+// openJumpInput shows the timestamp prompt on seekable tracks.
+func (m *Model) openJumpInput() {
+	if m.player == nil || !m.player.CanSeek() {
+		return
+	}
+	m.jumpMode = true
+	m.jumpInput = newJumpInput()
+	m.jumpInput.Focus()
+	m.invalidate(dirtyMid)
+}
+
+func (m *Model) closeJumpInput() {
+	m.jumpMode = false
+	m.jumpInput.Blur()
+	m.invalidate(dirtyMid)
+}
+
+// updateJumpInput routes key presses to the timestamp prompt while it is open.
+func (m Model) updateJumpInput(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, m.shutdown()
+	case "esc":
+		m.closeJumpInput()
+		return m, nil
+	case "enter":
+		value := m.jumpInput.Value()
+		m.closeJumpInput()
+		target, err := util.ParseTimestamp(value)
+		if err != nil {
+			m.saveMsg = "Invalid timestamp (use HH:MM:SS)"
+			m.saveMsgTime = time.Now()
+			return m, nil
+		}
+		return m.seekToPosition(target)
+	}
+
+	var cmd tea.Cmd
+	m.jumpInput, cmd = m.jumpInput.Update(msg)
+	m.invalidate(dirtyMid)
+	return m, cmd
+}
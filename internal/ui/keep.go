@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/olivier-w/climp/internal/downloader"
+)
+
+// WithKeepDownloads returns a model that keeps downloaded tracks in
+// downloader.KeepDir() instead of deleting them.
+func (m Model) WithKeepDownloads(on bool) Model {
+	m.keep = on
+	return m
+}
+
+// toggleKeep turns keep mode on or off for the S key. It applies to every
+// download not yet cleaned up, including the one playing.
+func (m *Model) toggleKeep() {
+	m.keep = !m.keep
+	if !m.keep {
+		m.saveMsg = "Downloads will be deleted after playing"
+	} else if dir, err := downloader.KeepDir(); err != nil {
+		m.saveMsg = fmt.Sprintf("Keep failed: %v", err)
+		m.keep = false
+	} else {
+		m.saveMsg = "Keeping downloads in " + dir
+	}
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+}
+
+// releaseTrack runs a queue track's temp file cleanup, keeping a copy first
+// when keep mode is on.
+func (m *Model) releaseTrack(i int) {
+	t := m.queue.Track(i)
+	if t == nil || t.Cleanup == nil {
+		return
+	}
+	m.keepFile(t.Path, t.Title)
+	t.Cleanup()
+	m.queue.SetTrackCleanup(i, nil)
+}
+
+// keepFile copies a downloaded temp file into the keep directory when keep
+// mode is on. Files already saved with s are skipped.
+func (m *Model) keepFile(path, title string) {
+	if !m.keep || path == "" || m.savedPaths[path] {
+		return
+	}
+	dir, err := downloader.KeepDir()
+	dest := ""
+	if err == nil {
+		dest, err = downloader.KeepFile(path, title, dir)
+	}
+	if err != nil {
+		m.saveMsg = fmt.Sprintf("Keep failed: %v", err)
+	} else {
+		m.saveMsg = fmt.Sprintf("Kept %s in %s", filepath.Base(dest), dir)
+	}
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+}
@@ -1,8 +1,13 @@
 package ui
 
 import (
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/config"
 )
 
 func isQuit(msg tea.KeyMsg) bool {
@@ -13,47 +18,243 @@ func isQuit(msg tea.KeyMsg) bool {
 	return false
 }
 
-// keyMap defines all keybindings for the help component.
+// keyMap defines all keybindings. Each playback action has its own binding so
+// keys.json can remap it; the help view pairs related actions back up.
 type keyMap struct {
-	Pause      key.Binding
-	Seek       key.Binding
-	Volume     key.Binding
-	Repeat     key.Binding
-	Speed      key.Binding
-	Shuffle    key.Binding
-	Visualizer key.Binding
-	NextTrack  key.Binding
-	PrevTrack  key.Binding
-	Scroll     key.Binding
-	Play       key.Binding
-	Remove     key.Binding
-	Save       key.Binding
-	Help       key.Binding
-	Quit       key.Binding
+	Pause       key.Binding
+	SeekBack    key.Binding
+	SeekForward key.Binding
+
+	SeekBackFar    key.Binding
+	SeekForwardFar key.Binding
+	SeekStepDown   key.Binding
+	SeekStepUp     key.Binding
+
+	VolumeFineUp   key.Binding
+	VolumeFineDown key.Binding
+	VolumeSet      key.Binding
+
+	Percent     key.Binding
+	Jump        key.Binding
+	LoopStart   key.Binding
+	LoopEnd     key.Binding
+	ChapterPrev key.Binding
+	ChapterNext key.Binding
+	Bookmark    key.Binding
+	Favorite    key.Binding
+	Copy        key.Binding
+	VolumeUp    key.Binding
+	VolumeDown  key.Binding
+	Mute        key.Binding
+	Repeat      key.Binding
+	RangeStart  key.Binding
+	RangeEnd    key.Binding
+	Speed       key.Binding
+	KeepPitch   key.Binding
+	EQ          key.Binding
+	Gain        key.Binding
+	Crossfade   key.Binding
+	Channels    key.Binding
+	StereoWidth key.Binding
+	SkipSilence key.Binding
+	Record      key.Binding
+	Info        key.Binding
+	History     key.Binding
+	Lyrics      key.Binding
+	Timeline    key.Binding
+	Remaining   key.Binding
+	Shuffle     key.Binding
+	Visualizer  key.Binding
+	Artwork     key.Binding
+	Waveform    key.Binding
+	Sleep       key.Binding
+	NextTrack   key.Binding
+	PrevTrack   key.Binding
+	Scroll      key.Binding
+	Play        key.Binding
+	Remove      key.Binding
+	Prune       key.Binding
+	PlayNext    key.Binding
+	MoveUp      key.Binding
+	MoveDown    key.Binding
+	UpNext      key.Binding
+	Filter      key.Binding
+	Export      key.Binding
+	Save        key.Binding
+	Keep        key.Binding
+	Help        key.Binding
+	Quit        key.Binding
+
+	seekStep time.Duration // shown in the seek help; follows Model.seekStep
 }
 
+// newKeyMap returns the default bindings with any remaps from keys.json in
+// the config dir applied.
 func newKeyMap() keyMap {
+	k := defaultKeyMap()
+	k.remap(config.LoadKeys())
+	return k
+}
+
+func defaultKeyMap() keyMap {
 	return keyMap{
 		Pause: key.NewBinding(
 			key.WithKeys(" "),
 			key.WithHelp("space", "pause"),
 		),
-		Seek: key.NewBinding(
-			key.WithKeys("left", "right"),
-			key.WithHelp("←/→", "seek"),
+		SeekBack: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←", "seek -5s"),
+		),
+		SeekForward: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→", "seek +5s"),
+		),
+		SeekBackFar: key.NewBinding(
+			key.WithKeys("shift+left"),
+			key.WithHelp("⇧←", "seek back further"),
+		),
+		SeekForwardFar: key.NewBinding(
+			key.WithKeys("shift+right"),
+			key.WithHelp("⇧→", "seek forward further"),
+		),
+		SeekStepDown: key.NewBinding(
+			key.WithKeys("<"),
+			key.WithHelp("<", "smaller seek step"),
+		),
+		SeekStepUp: key.NewBinding(
+			key.WithKeys(">"),
+			key.WithHelp(">", "larger seek step"),
+		),
+		Percent: key.NewBinding(
+			key.WithKeys("0", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("0-9", "jump to %"),
+		),
+		Jump: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g", "jump"),
+		),
+		LoopStart: key.NewBinding(
+			key.WithKeys("["),
+			key.WithHelp("[", "loop start"),
+		),
+		LoopEnd: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "loop end"),
+		),
+		ChapterPrev: key.NewBinding(
+			key.WithKeys(","),
+			key.WithHelp(",", "prev chapter"),
+		),
+		ChapterNext: key.NewBinding(
+			key.WithKeys("."),
+			key.WithHelp(".", "next chapter"),
+		),
+		Bookmark: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "bookmark"),
+		),
+		Favorite: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "favorite"),
+		),
+		Copy: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy source"),
 		),
-		Volume: key.NewBinding(
-			key.WithKeys("+", "-"),
-			key.WithHelp("+/-", "volume"),
+		VolumeUp: key.NewBinding(
+			key.WithKeys("="),
+			key.WithHelp("=", "volume up"),
+		),
+		VolumeDown: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "volume down"),
+		),
+		VolumeFineUp: key.NewBinding(
+			key.WithKeys("+"),
+			key.WithHelp("+", "volume up 1%"),
+		),
+		VolumeFineDown: key.NewBinding(
+			key.WithKeys("_"),
+			key.WithHelp("_", "volume down 1%"),
+		),
+		VolumeSet: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "set volume"),
+		),
+		Mute: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "mute"),
 		),
 		Repeat: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("r", "repeat"),
 		),
+		RangeStart: key.NewBinding(
+			key.WithKeys("{"),
+			key.WithHelp("{", "repeat range start"),
+		),
+		RangeEnd: key.NewBinding(
+			key.WithKeys("}"),
+			key.WithHelp("}", "repeat range end"),
+		),
 		Speed: key.NewBinding(
 			key.WithKeys("x"),
 			key.WithHelp("x", "speed"),
 		),
+		KeepPitch: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "keep pitch"),
+		),
+		EQ: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "eq preset"),
+		),
+		Gain: key.NewBinding(
+			key.WithKeys("G"),
+			key.WithHelp("G", "replaygain"),
+		),
+		Crossfade: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "crossfade"),
+		),
+		Channels: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "channels"),
+		),
+		StereoWidth: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "stereo width"),
+		),
+		SkipSilence: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "skip silence"),
+		),
+		Record: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "record stream"),
+		),
+		Info: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "source info"),
+		),
+		History: key.NewBinding(
+			key.WithKeys("h"),
+			key.WithHelp("h", "title history"),
+		),
+		Lyrics: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "lyrics"),
+		),
+		Timeline: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "album timeline"),
+			key.WithDisabled(),
+		),
+		Remaining: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "time left"),
+		),
 		Shuffle: key.NewBinding(
 			key.WithKeys("z"),
 			key.WithHelp("z", "shuffle"),
@@ -63,6 +264,18 @@ func newKeyMap() keyMap {
 			key.WithKeys("v"),
 			key.WithHelp("v", "viz mode"),
 		),
+		Waveform: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "waveform"),
+		),
+		Artwork: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "cover art"),
+		),
+		Sleep: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "sleep timer"),
+		),
 		NextTrack: key.NewBinding(
 			key.WithKeys("n"),
 			key.WithHelp("n", "next track"),
@@ -88,43 +301,259 @@ func newKeyMap() keyMap {
 			key.WithHelp("del", "remove"),
 			key.WithDisabled(),
 		),
+		Prune: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "clear played"),
+			key.WithDisabled(),
+		),
+		PlayNext: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "play next"),
+		),
+		MoveUp: key.NewBinding(
+			key.WithKeys("K", "shift+up"),
+			key.WithHelp("K", "move up"),
+			key.WithDisabled(),
+		),
+		MoveDown: key.NewBinding(
+			key.WithKeys("J", "shift+down"),
+			key.WithHelp("J", "move down"),
+			key.WithDisabled(),
+		),
+		UpNext: key.NewBinding(
+			key.WithKeys("home"),
+			key.WithHelp("home", "up next"),
+			key.WithDisabled(),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+			key.WithDisabled(),
+		),
+		Export: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "save queue"),
+			key.WithDisabled(),
+		),
 		Save: key.NewBinding(
 			key.WithKeys("s"),
 			key.WithHelp("s", "save"),
 			key.WithDisabled(),
 		),
+		Keep: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "keep downloads"),
+			key.WithDisabled(),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
 		),
 		Quit: key.NewBinding(
-			key.WithKeys("q"),
+			key.WithKeys("q", "esc"),
 			key.WithHelp("q", "quit"),
 		),
 	}
 }
 
+// actions maps the action names used in keys.json to their bindings. The
+// digit jumps, queue scrolling, and the queue filter keep their fixed keys.
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"pause":        &k.Pause,
+		"seek-back":    &k.SeekBack,
+		"seek-forward": &k.SeekForward,
+
+		"seek-back-far":    &k.SeekBackFar,
+		"seek-forward-far": &k.SeekForwardFar,
+		"seek-step-down":   &k.SeekStepDown,
+		"seek-step-up":     &k.SeekStepUp,
+
+		"volume-fine-up":   &k.VolumeFineUp,
+		"volume-fine-down": &k.VolumeFineDown,
+		"volume-set":       &k.VolumeSet,
+
+		"jump":         &k.Jump,
+		"loop-start":   &k.LoopStart,
+		"loop-end":     &k.LoopEnd,
+		"chapter-prev": &k.ChapterPrev,
+		"chapter-next": &k.ChapterNext,
+		"bookmark":     &k.Bookmark,
+		"favorite":     &k.Favorite,
+		"copy":         &k.Copy,
+		"volume-up":    &k.VolumeUp,
+		"volume-down":  &k.VolumeDown,
+		"mute":         &k.Mute,
+		"repeat":       &k.Repeat,
+		"range-start":  &k.RangeStart,
+		"range-end":    &k.RangeEnd,
+		"speed":        &k.Speed,
+		"keep-pitch":   &k.KeepPitch,
+		"eq":           &k.EQ,
+		"replaygain":   &k.Gain,
+		"crossfade":    &k.Crossfade,
+		"channels":     &k.Channels,
+		"width":        &k.StereoWidth,
+		"skip-silence": &k.SkipSilence,
+		"record":       &k.Record,
+		"info":         &k.Info,
+		"history":      &k.History,
+		"lyrics":       &k.Lyrics,
+		"timeline":     &k.Timeline,
+		"remaining":    &k.Remaining,
+		"shuffle":      &k.Shuffle,
+		"visualizer":   &k.Visualizer,
+		"artwork":      &k.Artwork,
+		"waveform":     &k.Waveform,
+		"sleep":        &k.Sleep,
+		"next":         &k.NextTrack,
+		"prev":         &k.PrevTrack,
+		"play":         &k.Play,
+		"remove":       &k.Remove,
+		"prune":        &k.Prune,
+		"play-next":    &k.PlayNext,
+		"move-up":      &k.MoveUp,
+		"move-down":    &k.MoveDown,
+		"up-next":      &k.UpNext,
+		"export":       &k.Export,
+		"save":         &k.Save,
+		"keep":         &k.Keep,
+		"help":         &k.Help,
+		"quit":         &k.Quit,
+	}
+}
+
+// remap replaces the keys of each named action. Unknown action names are
+// ignored. The help label of a remapped action lists its new keys.
+func (k *keyMap) remap(remap map[string]config.KeyList) {
+	actions := k.actions()
+	for name, keys := range remap {
+		b, ok := actions[name]
+		if !ok || len(keys) == 0 {
+			continue
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(helpKeys(keys), b.Help().Desc)
+	}
+}
+
+// helpKeys formats keys for the help view, using the same short names as
+// the default labels.
+func helpKeys(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		switch k {
+		case " ":
+			k = "space"
+		case "left":
+			k = "←"
+		case "right":
+			k = "→"
+		case "up":
+			k = "↑"
+		case "down":
+			k = "↓"
+		case "delete":
+			k = "del"
+		}
+		names[i] = k
+	}
+	return strings.Join(names, "/")
+}
+
+// matches reports whether msg is one of b's keys. Unlike key.Matches it
+// ignores the enabled state, which only controls what help shows; handlers
+// check their own preconditions.
+func matches(msg tea.KeyMsg, b key.Binding) bool {
+	return slices.Contains(b.Keys(), msg.String())
+}
+
+// pairHelp joins two related actions into one help entry, such as "←/→ seek".
+func pairHelp(a, b key.Binding, desc string) key.Binding {
+	pair := key.NewBinding(
+		key.WithKeys(append(slices.Clone(a.Keys()), b.Keys()...)...),
+		key.WithHelp(a.Help().Key+"/"+b.Help().Key, desc),
+	)
+	pair.SetEnabled(a.Enabled())
+	return pair
+}
+
 // updateEnabled enables or disables conditional bindings.
 func (k *keyMap) updateEnabled(canSave bool, hasQueue bool, canSeek bool) {
-	k.Seek.SetEnabled(canSeek)
+	k.SeekBack.SetEnabled(canSeek)
+	k.SeekForward.SetEnabled(canSeek)
+	k.SeekBackFar.SetEnabled(canSeek)
+	k.SeekForwardFar.SetEnabled(canSeek)
+	k.SeekStepDown.SetEnabled(canSeek)
+	k.SeekStepUp.SetEnabled(canSeek)
+	k.Percent.SetEnabled(canSeek)
+	k.Jump.SetEnabled(canSeek)
+	k.LoopStart.SetEnabled(canSeek)
+	k.LoopEnd.SetEnabled(canSeek)
+	k.ChapterPrev.SetEnabled(canSeek)
+	k.ChapterNext.SetEnabled(canSeek)
+	k.Bookmark.SetEnabled(canSeek)
+	k.Crossfade.SetEnabled(canSeek)
+	k.SkipSilence.SetEnabled(canSeek)
+	k.Waveform.SetEnabled(canSeek)
+	k.Timeline.SetEnabled(hasQueue && canSeek)
+	k.Remaining.SetEnabled(canSeek)
+	k.Record.SetEnabled(!canSeek)
+	k.History.SetEnabled(!canSeek)
 	k.NextTrack.SetEnabled(hasQueue)
 	k.PrevTrack.SetEnabled(hasQueue)
 	k.Scroll.SetEnabled(hasQueue)
 	k.Play.SetEnabled(hasQueue)
 	k.Remove.SetEnabled(hasQueue)
+	k.Prune.SetEnabled(hasQueue)
+	k.MoveUp.SetEnabled(hasQueue)
+	k.MoveDown.SetEnabled(hasQueue)
+	k.UpNext.SetEnabled(hasQueue)
+	k.Filter.SetEnabled(hasQueue)
+	k.Export.SetEnabled(hasQueue)
 	k.Shuffle.SetEnabled(hasQueue)
+	k.RangeStart.SetEnabled(hasQueue)
+	k.RangeEnd.SetEnabled(hasQueue)
 	k.Save.SetEnabled(canSave)
+	k.Keep.SetEnabled(canSave || hasQueue)
+}
+
+// step is the current seek step.
+func (k keyMap) step() time.Duration {
+	if k.seekStep <= 0 {
+		return DefaultSeekStep
+	}
+	return k.seekStep
+}
+
+func (k keyMap) seekLabel() string {
+	return formatSeekStep(k.step())
 }
 
 // ShortHelp returns the keybindings shown in the collapsed help view.
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Pause, k.Seek, k.Volume, k.Help, k.Quit}
+	return []key.Binding{k.Pause, pairHelp(k.SeekBack, k.SeekForward, "seek "+k.seekLabel()), pairHelp(k.VolumeUp, k.VolumeDown, "volume"), k.Help, k.Quit}
 }
 
 // FullHelp returns keybindings organized into columns for the expanded help view.
 func (k keyMap) FullHelp() [][]key.Binding {
-	playback := []key.Binding{k.Pause, k.Seek, k.Volume, k.Repeat, k.Speed, k.Shuffle, k.Visualizer}
-	queue := []key.Binding{k.NextTrack, k.PrevTrack, k.Scroll, k.Play, k.Remove}
-	other := []key.Binding{k.Save, k.Help, k.Quit}
+	playback := []key.Binding{
+		k.Pause,
+		pairHelp(k.SeekBack, k.SeekForward, "seek "+k.seekLabel()),
+		pairHelp(k.SeekBackFar, k.SeekForwardFar, "seek "+formatSeekStep(k.step()*seekFarFactor)),
+		pairHelp(k.SeekStepDown, k.SeekStepUp, "seek step"),
+		pairHelp(k.Percent, k.Jump, "jump"),
+		pairHelp(k.LoopStart, k.LoopEnd, "a-b loop"),
+		pairHelp(k.ChapterPrev, k.ChapterNext, "chapter"),
+		k.Bookmark,
+		k.Favorite,
+		k.Copy,
+		pairHelp(k.VolumeUp, k.VolumeDown, "volume"),
+		pairHelp(k.VolumeFineUp, k.VolumeFineDown, "volume 1%"),
+		k.VolumeSet,
+		k.Mute, k.Repeat, k.Speed, k.KeepPitch, k.EQ, k.Gain, k.Crossfade, k.Channels, k.StereoWidth, k.SkipSilence, k.Record, k.Shuffle, k.Visualizer, k.Artwork, k.Waveform, k.Info, k.History, k.Lyrics, k.Timeline, k.Remaining, k.Sleep,
+	}
+	queue := []key.Binding{k.NextTrack, k.PrevTrack, k.Scroll, k.Play, k.PlayNext, k.Remove, k.Prune, pairHelp(k.MoveUp, k.MoveDown, "move"), pairHelp(k.RangeStart, k.RangeEnd, "repeat range"), k.UpNext, k.Filter, k.Export}
+	other := []key.Binding{k.Save, k.Keep, k.Help, k.Quit}
 	return [][]key.Binding{playback, queue, other}
 }
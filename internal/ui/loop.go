@@ -0,0 +1,51 @@
+package ui
+
+import "time"
+
+// setLoopStart marks point A at the current position and drops any active
+// loop, so a new B can be chosen.
+func (m *Model) setLoopStart() {
+	if m.player == nil || !m.player.CanSeek() {
+		return
+	}
+	m.player.ClearLoop()
+	m.loopStart = m.player.Position()
+	m.loopStartSet = true
+	m.loopActive = false
+	m.invalidate(dirtyMid)
+}
+
+// setLoopEnd marks point B and starts looping A-B. Pressing it while a loop
+// is active clears the loop instead.
+func (m *Model) setLoopEnd() {
+	if m.player == nil || !m.player.CanSeek() {
+		return
+	}
+	if m.loopActive {
+		m.player.ClearLoop()
+		m.clearLoopMarks()
+		m.saveMsg = "Loop cleared"
+		m.saveMsgTime = time.Now()
+		return
+	}
+	end := m.player.Position()
+	if !m.loopStartSet || end <= m.loopStart {
+		m.saveMsg = "Set loop start with [ first"
+		m.saveMsgTime = time.Now()
+		m.invalidate(dirtyMid)
+		return
+	}
+	m.player.SetLoop(m.loopStart, end)
+	m.loopEnd = end
+	m.loopActive = true
+	m.invalidate(dirtyMid)
+}
+
+// clearLoopMarks resets the A-B markers, e.g. when a new track starts.
+func (m *Model) clearLoopMarks() {
+	m.loopStart = 0
+	m.loopEnd = 0
+	m.loopStartSet = false
+	m.loopActive = false
+	m.invalidate(dirtyMid)
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toggleLyrics shows or hides the lyrics panel in place of the queue for the
+// L key. It replaces the source info and history panels.
+func (m *Model) toggleLyrics() {
+	m.showLyrics = !m.showLyrics
+	if m.showLyrics {
+		m.showInfo = false
+		m.showHistory = false
+		m.lyricLine = m.metadata.Lyrics.LineAt(m.elapsed)
+	}
+	m.invalidate(dirtyBottom)
+}
+
+// syncLyricLine follows playback through synced lyrics, redrawing the panel
+// only when the current line changes.
+func (m *Model) syncLyricLine() {
+	if !m.showLyrics || !m.metadata.Lyrics.Synced {
+		return
+	}
+	if i := m.metadata.Lyrics.LineAt(m.elapsed); i != m.lyricLine {
+		m.lyricLine = i
+		m.invalidate(dirtyBottom)
+	}
+}
+
+// scrollLyrics scrolls unsynced lyrics for the navigation keys. It reports
+// whether msg was a scroll key.
+func (m *Model) scrollLyrics(msg tea.KeyMsg) bool {
+	if m.metadata.Lyrics.Synced {
+		return false
+	}
+	h := m.listHeight()
+	switch msg.String() {
+	case "up", "k":
+		m.lyricsScroll--
+	case "down", "j":
+		m.lyricsScroll++
+	case "pgup":
+		m.lyricsScroll -= h
+	case "pgdown":
+		m.lyricsScroll += h
+	default:
+		return false
+	}
+	m.lyricsScroll = min(m.lyricsScroll, len(m.metadata.Lyrics.Lines)-h)
+	m.lyricsScroll = max(m.lyricsScroll, 0)
+	m.invalidate(dirtyBottom)
+	return true
+}
+
+// lyricsView renders the lyrics panel. Synced lyrics keep the current line a
+// third of the way down and highlight it; unsynced lyrics scroll by hand.
+func (m *Model) lyricsView() string {
+	var sb strings.Builder
+	sb.WriteString("  ")
+	sb.WriteString(headerStyle.Render("Lyrics"))
+	sb.WriteString("\n\n")
+
+	lyrics := m.metadata.Lyrics
+	if lyrics.Empty() {
+		sb.WriteString("  ")
+		sb.WriteString(statusStyle.Render("No lyrics for this track"))
+		sb.WriteByte('\n')
+		return sb.String()
+	}
+
+	h := m.listHeight()
+	start := m.lyricsScroll
+	if lyrics.Synced {
+		start = m.lyricLine - h/3
+	}
+	start = max(min(start, len(lyrics.Lines)-h), 0)
+	end := min(start+h, len(lyrics.Lines))
+	w := m.effectiveWidth() - 4
+	for i := start; i < end; i++ {
+		text := truncateLabel(lyrics.Lines[i].Text, w)
+		sb.WriteString("  ")
+		switch {
+		case !lyrics.Synced:
+			sb.WriteString(text)
+		case i == m.lyricLine:
+			sb.WriteString(titleStyle.Render(text))
+		default:
+			sb.WriteString(helpStyle.Render(text))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -8,19 +8,49 @@ import (
 	"github.com/olivier-w/climp/internal/player"
 )
 
-type tickMsg time.Time
+type tickMsg struct {
+	seq uint64
+}
 type playbackEndedMsg struct {
 	player *player.Player
+	done   <-chan struct{}
+}
+type trackAdvancedMsg struct {
+	player *player.Player
+	path   string
 }
 type liveTitleUpdatedMsg struct {
 	player *player.Player
 	title  string
 }
 type fileSavedMsg struct {
+	src      string
 	destName string
 	err      error
 }
+type scrobbledMsg struct {
+	err error
+}
+type queueExportedMsg struct {
+	dest string
+	err  error
+}
+type gainScannedMsg struct {
+	player *player.Player
+	db     float64
+	ok     bool
+}
 type vizTickMsg time.Time
+type volumeInputTimeoutMsg struct {
+	seq uint64
+}
+type sleepTimerMsg struct {
+	seq uint64
+}
+type sleepFadeMsg struct {
+	seq  uint64
+	step int
+}
 type seekDebounceMsg struct {
 	player *player.Player
 	seq    uint64
@@ -34,12 +64,19 @@ type seekAppliedMsg struct {
 
 type trackDownloadedMsg struct {
 	index   int
+	url     string
 	path    string
 	title   string
 	cleanup func()
 	err     error
 }
 
+// downloadRetryMsg fires when a failed track download's backoff has elapsed.
+type downloadRetryMsg struct {
+	index int
+	url   string
+}
+
 type playlistExtractedMsg struct {
 	entries []downloader.PlaylistEntry
 	err     error
@@ -51,14 +88,14 @@ type trackFailedMsg struct {
 
 const seekDebounceDelay = 200 * time.Millisecond
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(200*time.Millisecond, func(t time.Time) tea.Msg {
-		return tickMsg(t)
+func tickCmd(seq uint64, d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return tickMsg{seq: seq}
 	})
 }
 
 func vizTickCmd() tea.Cmd {
-	return tea.Tick(50*time.Millisecond, func(t time.Time) tea.Msg {
+	return tea.Tick(vizTickInterval, func(t time.Time) tea.Msg {
 		return vizTickMsg(t)
 	})
 }
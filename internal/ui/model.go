@@ -1,20 +1,26 @@
 package ui
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
 
+	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/olivier-w/climp/internal/downloader"
 	"github.com/olivier-w/climp/internal/player"
 	"github.com/olivier-w/climp/internal/queue"
+	"github.com/olivier-w/climp/internal/scrobble"
+	"github.com/olivier-w/climp/internal/theme"
 	"github.com/olivier-w/climp/internal/util"
 	"github.com/olivier-w/climp/internal/visualizer"
 )
@@ -31,6 +37,17 @@ const (
 
 const maxVizHeight = 8 // maximum lines for the visualizer
 
+// outputLostMsg is shown while playback is paused on a failed audio device.
+const outputLostMsg = "Audio output lost — press space to retry"
+
+// deviceLostMsg replaces outputLostMsg once the audio context itself has
+// failed, which retrying cannot fix.
+const deviceLostMsg = "Audio device lost — restart climp"
+
+// openPlayer opens the player for a queue track; tests swap it out to run
+// without an audio device.
+var openPlayer = player.New
+
 // Model is the Bubbletea model for the climp TUI.
 type Model struct {
 	player       *player.Player
@@ -38,40 +55,128 @@ type Model struct {
 	elapsed      time.Duration
 	duration     time.Duration
 	volume       float64
+	boost        float64 // post-volume gain above 100%
+	masterVolume float64 // session volume level (volume × boost); queue tracks add their VolumeOffset
+	muted        bool
+	peakDB       float64 // recent peak level in dBFS
+	levelL       float64 // recent RMS level of the left channel, 0 to 1
+	levelR       float64 // recent RMS level of the right channel, 0 to 1
+	clips        int     // full-scale samples in the current track
 	paused       bool
+	buffering    bool // live stream is waiting on the network
 	seekPending  bool
 	seekApplying bool
 	seekTarget   time.Duration
 	seekResume   bool
 	seekSeq      uint64
+	jumpMode     bool            // timestamp prompt is open
+	jumpInput    textinput.Model // HH:MM:SS entry for the g key
+	volumeMode   bool            // volume prompt is open
+	volumeInput  textinput.Model // percent entry for the V key
+	volumeSeq    uint64          // invalidates prompt timeouts after a key press
+	loopStart    time.Duration   // A-B loop point A, set with [
+	loopEnd      time.Duration   // A-B loop point B, set with ]
+	loopStartSet bool
+	loopActive   bool
+	chapter      int // chapter index under elapsed, -1 when none
 	width        int
 	height       int
 	quitting     bool
 	repeatMode   RepeatMode
+	repeatRange  queueRange // queue span looped by RepeatRange
 	shuffleMode  ShuffleMode
 	speed        player.SpeedMode
-
-	sourcePath  string    // temp file path (empty for local files)
-	sourceTitle string    // title for saved filename
-	saveMsg     string    // transient status message
-	saveMsgTime time.Time // when saveMsg was set
-	saving      bool      // conversion in progress
-	cleanup     func()    // optional cleanup for single-track temp files
-
+	eqPreset     player.EQPreset
+	gainMode     player.GainMode
+	crossfade    time.Duration
+	keepPitch    bool // time-stretch speed changes instead of resampling
+	channelMode  player.ChannelMode
+	widthStep    int  // index into player.StereoWidths; 0 is normal width
+	skipSilence  bool // skip long silent stretches on seekable tracks
+	showInfo     bool // source details panel replaces the queue
+	showHistory  bool // live stream title history replaces the queue
+	showLyrics   bool // lyrics panel replaces the queue
+	lyricLine    int  // synced lyrics line under elapsed, -1 before the first
+	lyricsScroll int  // first unsynced lyrics line shown
+
+	titleHistory []streamTitle // recent live stream titles, oldest first
+	historyList  list.Model    // h panel; created with the first title
+
+	albumTimeline bool // elapsed, duration, and seeking span the whole queue (t)
+	showRemaining bool // the time right of the bar counts down to the end (E)
+
+	recordPath    string        // WAV file being recorded from a live stream; "" when not recording
+	recordElapsed time.Duration // audio captured so far
+
+	persistSettings bool // write settings back to the config dir on shutdown
+	notify          bool // announce each new queue track (--notify)
+	compact         bool // always use the one-line layout (--compact)
+	headless        bool // run by the daemon with no renderer (WithHeadless)
+
+	themeName string // theme from the state file, written back unchanged
+	dither    bool   // dither setting from the state file, written back unchanged
+
+	seekStep time.Duration // how far ←/→ seek; shift seeks seekFarFactor times as far
+
+	addMode  bool            // play-next prompt is open
+	addInput textinput.Model // URL or file path entry for the a key
+
+	logPlays bool       // append played tracks to the play history (WithPlayHistory)
+	play     playRecord // the current track's play, for the history
+
+	scrobbler scrobble.Scrobbler // nil when no scrobbling service is configured
+	listen    listen             // the current track's listen, for scrobbling
+
+	sleepDeadline time.Time // zero when no sleep timer is set
+	sleepSeq      uint64    // invalidates timer messages after cancel/reset
+	sleepFading   bool      // volume fade-out before quitting is running
+	sleepFadeFrom float64   // volume when the fade-out started
+
+	sourcePath  string          // temp file path (empty for local files)
+	sourceTitle string          // title for saved filename
+	saveMsg     string          // transient status message
+	saveMsgTime time.Time       // when saveMsg was set
+	saving      bool            // conversion in progress
+	savePrompt  bool            // save format prompt is open
+	cleanup     func()          // optional cleanup for single-track temp files
+	keep        bool            // keep downloads in downloader.KeepDir() instead of deleting them
+	savedPaths  map[string]bool // temp files already saved with s; keep mode skips them
+
+	folderDir   string   // directory of a sibling-file queue (WithFolderSession)
+	folderFiles []string // its files in scan order, indexed by the saved position
+
+	analyzer    *visualizer.Analyzer // one FFT per viz tick, shared by the visualizers
 	visualizers []visualizer.Visualizer
 	vizIndex    int
 	vizEnabled  bool
+	vizRunning  bool // a vizTickMsg is in flight; paused playback lets it lapse
+
+	tickSeq  uint64        // invalidates tick chains replaced by restartTick
+	tickRate time.Duration // cadence of the current tick chain; 0 is tickInterval
+
+	showArt   bool          // draw cover art above the title (A)
+	artLayout bool          // cover art is in the header; fixedLines counts it
+	art       *artworkCache // decoded cover of the current track
+
+	showWave   bool     // draw the track overview above the progress bar (W)
+	waveLayout bool     // the overview is in the mid section; fixedLines counts it
+	wave       waveform // overview of the current track
 
 	// Queue fields
 	queue            *queue.Queue // nil for single-track playback
 	queueList        list.Model   // bubbles list for upcoming tracks display
-	downloading      int          // queue index being downloaded, -1 if none
+	maxDownloads     int          // upcoming tracks downloaded at once; 0 uses defaultConcurrentDownloads
 	transitioning    bool         // waiting for a track to finish downloading
 	transitionTarget int          // queue index we're waiting to play (-1 if not jumping)
 
 	originalURL  string // original URL for deferred playlist extraction
 	playlistName string // queue label shown in header for playlist mode
 
+	// extractCtx is cancelled on quit or once a queue exists, killing a
+	// playlist extraction still running in the background.
+	extractCtx  context.Context
+	stopExtract context.CancelFunc
+
 	keys keyMap
 	help help.Model
 
@@ -83,11 +188,17 @@ type Model struct {
 	queueViewCache string // rendered list.Model.View() (changes on queue mutations / key navigation)
 	dotsCache      string // pagination dots
 
+	// Progress bar screen position for click-to-seek: the row is set with the
+	// header, the columns with the mid section. barWidth is 0 when no seekable
+	// bar is drawn.
+	barRow, barCol, barWidth int
+
 	dirty dirtyFlags // tracks which caches need rebuilding
 }
 
 // trackItem implements list.DefaultItem for queue display.
 type trackItem struct {
+	idx   int // queue index, kept so filtered selections map back
 	title string
 	desc  string
 }
@@ -98,13 +209,14 @@ func (t trackItem) Description() string { return t.desc }
 
 // newQueueList creates a configured bubbles list for the queue display.
 func newQueueList(width int) list.Model {
+	th := theme.Current()
 	delegate := list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
-		Foreground(lipgloss.AdaptiveColor{Light: "#333333", Dark: "#FFFFFF"}).
-		BorderLeftForeground(lipgloss.AdaptiveColor{Light: "#555555", Dark: "#AAAAAA"})
+		Foreground(adaptive(th.Title)).
+		BorderLeftForeground(adaptive(th.Accent))
 	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
 		Foreground(lipgloss.AdaptiveColor{Light: "#666666", Dark: "#888888"}).
-		BorderLeftForeground(lipgloss.AdaptiveColor{Light: "#555555", Dark: "#AAAAAA"})
+		BorderLeftForeground(adaptive(th.Accent))
 	delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.
 		Foreground(lipgloss.AdaptiveColor{Light: "#666666", Dark: "#AAAAAA"})
 	delegate.Styles.NormalDesc = delegate.Styles.NormalDesc.
@@ -112,7 +224,7 @@ func newQueueList(width int) list.Model {
 	l := list.New(nil, delegate, width, 14)
 	l.Title = "Up Next"
 	l.Styles.Title = lipgloss.NewStyle().
-		Background(lipgloss.AdaptiveColor{Light: "#555555", Dark: "#AAAAAA"}).
+		Background(adaptive(th.Accent)).
 		Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#1A1A1A"}).
 		Padding(0, 1)
 	l.Styles.TitleBar = lipgloss.NewStyle().Padding(0, 0, 1, 2)
@@ -124,8 +236,9 @@ func newQueueList(width int) list.Model {
 	l.KeyMap.PrevPage.SetKeys("pgup")
 	l.KeyMap.NextPage.SetKeys("pgdown")
 	l.SetShowHelp(false)
-	l.SetShowFilter(false)
-	l.SetFilteringEnabled(false)
+	l.SetFilteringEnabled(true)
+	// A static cursor avoids blink ticks re-rendering the queue.
+	l.FilterInput.Cursor.SetMode(cursor.CursorStatic)
 	return l
 }
 
@@ -164,7 +277,7 @@ func (m *Model) syncQueueList() {
 	if !changed {
 		for i := range old {
 			oi, ni := old[i].(trackItem), items[i].(trackItem)
-			if oi.title != ni.title || oi.desc != ni.desc {
+			if oi.idx != ni.idx || oi.title != ni.title || oi.desc != ni.desc {
 				changed = true
 				break
 			}
@@ -172,8 +285,14 @@ func (m *Model) syncQueueList() {
 	}
 	if changed {
 		sel := m.queueList.Index()
-		m.queueList.SetItems(items)
-		if sel < len(items) {
+		if cmd := m.queueList.SetItems(items); cmd != nil {
+			// While filtered, SetItems re-filters asynchronously; apply the
+			// matches now so the list never renders empty in between.
+			if matches, ok := cmd().(list.FilterMatchesMsg); ok {
+				m.queueList, _ = m.queueList.Update(matches)
+			}
+		}
+		if sel < len(m.queueList.VisibleItems()) {
 			m.queueList.Select(sel)
 		}
 	}
@@ -185,6 +304,9 @@ func (m *Model) trackToItem(t *queue.Track, i, totalTracks int) trackItem {
 	switch t.State {
 	case queue.Downloading:
 		desc = "downloading..."
+		if t.Attempts > 0 {
+			desc = fmt.Sprintf("retrying (%d/%d)...", t.Attempts+1, maxDownloadAttempts)
+		}
 	case queue.Failed:
 		desc = "failed"
 	case queue.Ready:
@@ -192,17 +314,20 @@ func (m *Model) trackToItem(t *queue.Track, i, totalTracks int) trackItem {
 	case queue.Done:
 		desc = "played"
 	}
+	if m.inRepeatRange(i) {
+		desc += "  ↻ repeat range"
+	}
 	title := t.Title
 	if title == "" {
 		title = fmt.Sprintf("Track %d", i+1)
 	}
-	return trackItem{title: title, desc: desc}
+	return trackItem{idx: i, title: title, desc: desc}
 }
 
 // rebuildQueueViewCache re-renders the queue list view and pagination dots,
 // then rebuilds the bottom cache since the queue is embedded in it.
 func (m *Model) rebuildQueueViewCache() {
-	if m.queue == nil || m.queue.Len() <= 1 {
+	if m.queue == nil || m.queue.Len() <= 1 || m.compactLayout() {
 		m.queueViewCache = ""
 		m.dotsCache = ""
 		m.rebuildBottomCache()
@@ -252,8 +377,18 @@ func (m *Model) rebuildQueueViewCache() {
 
 // rebuildHeaderCache rebuilds the cached title+subtitle section.
 func (m *Model) rebuildHeaderCache() {
+	if m.compactLayout() {
+		m.headerCache = ""
+		m.barRow = 0
+		return
+	}
 	var sb strings.Builder
-	sb.WriteString("\n  ")
+	sb.WriteByte('\n')
+	if m.artLayout {
+		sb.WriteString(m.artworkView())
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("  ")
 	sb.WriteString(titleStyle.Render(m.metadata.Title))
 	sb.WriteByte('\n')
 
@@ -270,13 +405,39 @@ func (m *Model) rebuildHeaderCache() {
 		sb.WriteString(artistStyle.Render(m.metadata.Album))
 		sb.WriteByte('\n')
 	}
+	if chapters := m.metadata.Chapters; len(chapters) > 0 {
+		if i := chapterAt(chapters, m.elapsed); i >= 0 {
+			sb.WriteString("  ")
+			sb.WriteString(artistStyle.Render(fmt.Sprintf("%s (%d/%d)", chapters[i].Title, i+1, len(chapters))))
+			sb.WriteByte('\n')
+		}
+	}
 
 	sb.WriteByte('\n')
 	m.headerCache = sb.String()
+	m.barRow = strings.Count(m.headerCache, "\n")
+	if m.waveLayout {
+		m.barRow += waveformRows
+	}
+}
+
+// rightTime formats the time shown right of the progress bar: the duration,
+// or with showRemaining the time left as -MM:SS, rounded up so it adds up
+// with the elapsed time. An unknown duration is shown as is, since nothing
+// can be counted down.
+func (m Model) rightTime(elapsed, duration time.Duration) string {
+	if !m.showRemaining || duration <= 0 {
+		return util.FormatDuration(duration)
+	}
+	return "-" + util.FormatDuration(max(duration-elapsed, 0)+time.Second-1)
 }
 
 // rebuildMidCache rebuilds the cached progress bar and status line section.
 func (m *Model) rebuildMidCache() {
+	if m.compactLayout() {
+		m.rebuildCompactCache()
+		return
+	}
 	w := m.width
 	if w < 30 {
 		w = 50
@@ -288,16 +449,31 @@ func (m *Model) rebuildMidCache() {
 	sb.Grow(256)
 
 	// Progress bar or transitioning message
+	m.barWidth = 0
 	if m.transitioning {
 		sb.WriteString("  ")
 		sb.WriteString(statusStyle.Render("Loading next track..."))
 		sb.WriteByte('\n')
 	} else {
-		elapsedStr := timeStyle.Render(util.FormatDuration(m.elapsed))
+		if m.waveLayout {
+			var frac float64
+			if m.duration > 0 {
+				frac = m.elapsed.Seconds() / m.duration.Seconds()
+			}
+			sb.WriteString(renderWaveform(m.wave.peaks, m.wave.total, m.effectiveWidth(), frac))
+		}
+		// On the album timeline, track positions such as chapters shift by
+		// where the track starts.
+		elapsed, duration := m.displayPosition()
+		offset := elapsed - m.elapsed
+		elapsedStr := timeStyle.Render(util.FormatDuration(elapsed))
 		if m.player != nil && !m.player.CanSeek() {
 			liveStr := statusStyle.Render("LIVE")
+			if m.buffering {
+				elapsedStr = statusStyle.Render("buffering...")
+			}
 			// Right-align LIVE to the row edge, matching the seek row's right anchor.
-			gap := w - lipgloss.Width(util.FormatDuration(m.elapsed)) - lipgloss.Width("LIVE") - 4
+			gap := w - lipgloss.Width(elapsedStr) - lipgloss.Width("LIVE") - 4
 			if gap < 2 {
 				gap = 2
 			}
@@ -307,15 +483,29 @@ func (m *Model) rebuildMidCache() {
 			sb.WriteString(liveStr)
 			sb.WriteByte('\n')
 		} else {
-			durationStr := timeStyle.Render(util.FormatDuration(m.duration))
-			barWidth := w - len(util.FormatDuration(m.elapsed)) - len(util.FormatDuration(m.duration)) - 6
+			rightTime := m.rightTime(elapsed, duration)
+			durationStr := timeStyle.Render(rightTime)
+			barWidth := w - len(util.FormatDuration(elapsed)) - len(rightTime) - 6
 			if barWidth < 10 {
 				barWidth = 10
 			}
-			bar := renderProgressBar(m.elapsed.Seconds(), m.duration.Seconds(), barWidth)
+			bar := renderProgressBar(elapsed.Seconds(), duration.Seconds(), barWidth)
+			for _, c := range m.metadata.Chapters {
+				if c.Start > 0 {
+					bar = placeBarMarker(bar, (offset + c.Start).Seconds(), duration.Seconds(), '┆')
+				}
+			}
+			if m.loopStartSet {
+				bar = placeBarMarker(bar, (offset + m.loopStart).Seconds(), duration.Seconds(), '[')
+			}
+			if m.loopActive {
+				bar = placeBarMarker(bar, (offset + m.loopEnd).Seconds(), duration.Seconds(), ']')
+			}
 			sb.WriteString("  ")
 			sb.WriteString(fmt.Sprintf("%s %s %s", elapsedStr, bar, durationStr))
 			sb.WriteByte('\n')
+			m.barCol = 2 + lipgloss.Width(elapsedStr) + 1
+			m.barWidth = barWidth
 		}
 	}
 
@@ -328,9 +518,23 @@ func (m *Model) rebuildMidCache() {
 		statusText = "paused"
 	}
 	repeatIcon := m.repeatMode.Icon()
+	if lo, hi, ok := m.activeRange(); ok {
+		repeatIcon = fmt.Sprintf("[repeat %d-%d]", lo+1, hi+1)
+	}
 	speedLabel := m.speed.Label()
+	if m.keepPitch {
+		speedLabel = strings.TrimSpace(speedLabel + " [keep pitch]")
+	}
+	eqLabel := m.eqPreset.Label()
+	gainLabel := m.gainMode.Label()
+	crossfadeLabel := player.CrossfadeLabel(m.crossfade)
+	channelLabel := m.channelMode.Label()
+	widthLabel := player.StereoWidthLabel(player.StereoWidths[m.widthStep])
 	shuffleIcon := m.shuffleMode.Icon()
-	volStr := renderVolumePercent(m.volume)
+	volStr := renderVolumePercent(m.volume * m.boost)
+	if m.muted {
+		volStr = "🔇 muted"
+	}
 
 	leftText := fmt.Sprintf("%s  %s", statusIcon, statusText)
 	if repeatIcon != "" {
@@ -339,15 +543,50 @@ func (m *Model) rebuildMidCache() {
 	if speedLabel != "" {
 		leftText += "  " + speedLabel
 	}
+	if eqLabel != "" {
+		leftText += "  " + eqLabel
+	}
+	if gainLabel != "" {
+		leftText += "  " + gainLabel
+	}
+	if crossfadeLabel != "" {
+		leftText += "  " + crossfadeLabel
+	}
+	if channelLabel != "" {
+		leftText += "  " + channelLabel
+	}
+	if widthLabel != "" {
+		leftText += "  " + widthLabel
+	}
+	if m.skipSilence {
+		leftText += "  [skip silence]"
+	}
+	if m.albumTimeline {
+		leftText += "  [album]"
+	}
+	if m.recordPath != "" {
+		leftText += "  ● REC " + util.FormatDuration(m.recordElapsed)
+	}
 	if shuffleIcon != "" {
 		leftText += "  " + shuffleIcon
 	}
+	if !m.sleepDeadline.IsZero() {
+		leftText += "  ⏾ " + util.FormatDuration(time.Until(m.sleepDeadline))
+	}
 	if m.vizEnabled && m.vizIndex < len(m.visualizers) {
 		leftText += "  viz:" + m.visualizers[m.vizIndex].Name()
 	}
+	rightText := volStr
+	if m.player != nil {
+		rightText = renderPeakLevel(m.peakDB, m.clips) + "  " + volStr
+		// The meters are dropped before the status labels when space runs out.
+		if meters := renderChannelMeters(m.levelL, m.levelR); lipgloss.Width(leftText)+lipgloss.Width(rightText)+lipgloss.Width(meters)+8 <= w {
+			rightText = meters + "  " + rightText
+		}
+	}
 	statusLeft := statusStyle.Render(leftText)
-	statusRight := statusStyle.Render(volStr)
-	gap := w - lipgloss.Width(leftText) - lipgloss.Width(volStr) - 4
+	statusRight := statusStyle.Render(rightText)
+	gap := w - lipgloss.Width(leftText) - lipgloss.Width(rightText) - 4
 	if gap < 2 {
 		gap = 2
 	}
@@ -358,7 +597,23 @@ func (m *Model) rebuildMidCache() {
 	sb.WriteString(statusRight)
 	sb.WriteByte('\n')
 
-	if m.saveMsg != "" {
+	if m.jumpMode {
+		sb.WriteString("  ")
+		sb.WriteString(m.jumpInput.View())
+		sb.WriteByte('\n')
+	} else if m.volumeMode {
+		sb.WriteString("  ")
+		sb.WriteString(m.volumeInput.View())
+		sb.WriteByte('\n')
+	} else if m.addMode {
+		sb.WriteString("  ")
+		sb.WriteString(m.addInput.View())
+		sb.WriteByte('\n')
+	} else if m.savePrompt {
+		sb.WriteString("  ")
+		sb.WriteString(helpStyle.Render(savePromptText))
+		sb.WriteByte('\n')
+	} else if m.saveMsg != "" {
 		sb.WriteString("  ")
 		sb.WriteString(helpStyle.Render(m.saveMsg))
 		sb.WriteByte('\n')
@@ -373,11 +628,21 @@ func (m *Model) rebuildMidCache() {
 
 // rebuildBottomCache rebuilds the cached queue display and help text section.
 func (m *Model) rebuildBottomCache() {
+	if m.compactLayout() {
+		m.bottomCache = ""
+		return
+	}
 	var sb strings.Builder
 	sb.Grow(256)
 
 	// Queue display — always show full queue list
-	if m.queue != nil && m.queue.Len() > 1 {
+	if m.showInfo {
+		sb.WriteString(m.infoView())
+	} else if m.showHistory {
+		sb.WriteString(m.historyView())
+	} else if m.showLyrics {
+		sb.WriteString(m.lyricsView())
+	} else if m.queue != nil && m.queue.Len() > 1 {
 		sb.WriteString(m.queueViewCache)
 		sb.WriteByte('\n')
 		if m.dotsCache != "" {
@@ -413,11 +678,29 @@ func (m *Model) flushCaches() {
 	if m.dirty == 0 {
 		return
 	}
+	if m.dirty&dirtyHeader != 0 && m.artworkShown() != m.artLayout {
+		// Cover art changes the header height, so the queue gets resized.
+		m.artLayout = !m.artLayout
+		m.updateQueueHeight()
+		m.dirty |= dirtyQueue
+	}
+	if m.dirty&dirtyMid != 0 && m.waveformShown() != m.waveLayout {
+		// So does the track overview, and it moves the progress bar down.
+		m.waveLayout = !m.waveLayout
+		m.updateQueueHeight()
+		m.dirty |= dirtyQueue | dirtyHeader
+	}
 	if m.dirty&dirtyQueue != 0 {
 		m.syncQueueList()
 		m.rebuildQueueViewCache() // also rebuilds bottom
 		m.dirty &^= dirtyBottom   // bottom was rebuilt by rebuildQueueViewCache
 	}
+	if m.dirty&dirtyMid != 0 && m.syncChapter() {
+		m.dirty |= dirtyHeader
+	}
+	if m.dirty&dirtyHeader != 0 && m.compactLayout() {
+		m.dirty |= dirtyMid // the title lives on the compact line
+	}
 	if m.dirty&dirtyHeader != 0 {
 		m.rebuildHeaderCache()
 	}
@@ -439,27 +722,36 @@ func New(p *player.Player, meta player.Metadata, sourcePath, originalURL string,
 	keys.updateEnabled(sourcePath != "", false, canSeek)
 	h := help.New()
 	h.ShortSeparator = "  "
-	h.Styles.ShortKey = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#999999", Dark: "#666666"})
-	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#999999", Dark: "#666666"})
-	h.Styles.FullKey = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#999999", Dark: "#666666"})
-	h.Styles.FullDesc = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#999999", Dark: "#666666"})
-	h.Styles.FullSeparator = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#999999", Dark: "#666666"})
-	h.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#999999", Dark: "#666666"})
+	helpColor := adaptive(theme.Current().Help)
+	h.Styles.ShortKey = lipgloss.NewStyle().Foreground(helpColor)
+	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(helpColor)
+	h.Styles.FullKey = lipgloss.NewStyle().Foreground(helpColor)
+	h.Styles.FullDesc = lipgloss.NewStyle().Foreground(helpColor)
+	h.Styles.FullSeparator = lipgloss.NewStyle().Foreground(helpColor)
+	h.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(helpColor)
+	analyzer := visualizer.NewAnalyzer()
 	m := Model{
 		player:           p,
 		metadata:         meta,
 		duration:         p.Duration(),
 		volume:           p.Volume(),
+		boost:            p.Boost(),
+		masterVolume:     p.Volume() * p.Boost(),
 		sourcePath:       sourcePath,
 		sourceTitle:      meta.Title,
 		cleanup:          cleanup,
-		visualizers:      visualizer.Modes(),
-		downloading:      -1,
+		analyzer:         analyzer,
+		visualizers:      visualizer.Modes(analyzer),
+		art:              &artworkCache{},
 		transitionTarget: -1,
 		originalURL:      originalURL,
+		seekStep:         DefaultSeekStep,
 		keys:             keys,
 		help:             h,
 	}
+	if originalURL != "" {
+		m.extractCtx, m.stopExtract = context.WithCancel(context.Background())
+	}
 	m.rebuildHeaderCache()
 	m.rebuildMidCache()
 	m.rebuildBottomCache()
@@ -479,7 +771,8 @@ func NewWithQueue(p *player.Player, meta player.Metadata, sourcePath string, q *
 }
 
 func (m Model) Init() tea.Cmd {
-	cmds := []tea.Cmd{tickCmd(), checkDone(m.player), waitForLiveTitle(m.player), tea.SetWindowTitle(windowTitle(m.metadata.Title, false))}
+	m.preloadNext()
+	cmds := []tea.Cmd{tickCmd(m.tickSeq, tickInterval), checkDone(m.player), waitForAdvance(m.player), waitForLiveTitle(m.player), tea.SetWindowTitle(windowTitle(m.metadata.Title, m.paused)), m.nowPlayingCmd()}
 	if m.queue != nil {
 		next := m.queue.Next()
 		if next != nil && next.State == queue.Pending {
@@ -487,16 +780,38 @@ func (m Model) Init() tea.Cmd {
 			cmds = append(cmds, m.downloadTrackCmd(idx))
 		}
 	}
-	if m.originalURL != "" && m.queue == nil {
-		cmds = append(cmds, extractPlaylistCmd(m.originalURL))
+	if m.originalURL != "" && m.queue == nil && m.extractCtx != nil {
+		cmds = append(cmds, extractPlaylistCmd(m.extractCtx, m.originalURL))
+	}
+	if !m.sleepDeadline.IsZero() {
+		cmds = append(cmds, sleepTimerCmd(m.sleepSeq, time.Until(m.sleepDeadline)))
 	}
 	return tea.Batch(cmds...)
 }
 
 func checkDone(p *player.Player) tea.Cmd {
 	return func() tea.Msg {
-		<-p.Done()
-		return playbackEndedMsg{player: p}
+		done := p.Done()
+		<-done
+		return playbackEndedMsg{player: p, done: done}
+	}
+}
+
+// waitForAdvance waits for the player to continue gaplessly into a preloaded track.
+func waitForAdvance(p *player.Player) tea.Cmd {
+	if p == nil {
+		return nil
+	}
+	advanced := p.Advanced()
+	if advanced == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		path, ok := <-advanced
+		if !ok {
+			return nil
+		}
+		return trackAdvancedMsg{player: p, path: path}
 	}
 }
 
@@ -519,18 +834,45 @@ func waitForLiveTitle(p *player.Player) tea.Cmd {
 
 func (m *Model) shutdown() tea.Cmd {
 	m.clearSeekState()
+	m.sleepSeq++ // ignore a sleep timer still in flight
+	m.cancelExtraction()
+	m.finishPlay()
+	scrobbled := m.finishListenCmd()
+	m.autoBookmark()
+	m.saveFolderPosition()
+	m.saveSettings()
 	if m.player != nil {
 		m.player.Close()
 		m.player = nil
 	}
 	if m.cleanup != nil {
+		m.keepFile(m.sourcePath, m.sourceTitle)
 		m.cleanup()
 		m.cleanup = nil
 	}
+	if m.queue != nil {
+		for i := 0; i < m.queue.Len(); i++ {
+			m.releaseTrack(i)
+		}
+		m.queue.CleanupAll()
+	}
+	// The last listen is submitted before quitting so it is not lost.
+	return tea.Sequence(tea.SetWindowTitle(""), scrobbled, tea.Quit)
+}
+
+// Close releases the player and temporary downloads of a model that will
+// not be run, such as one rejected by StartAt.
+func (m Model) Close() {
+	m.cancelExtraction()
+	if m.player != nil {
+		m.player.Close()
+	}
+	if m.cleanup != nil {
+		m.cleanup()
+	}
 	if m.queue != nil {
 		m.queue.CleanupAll()
 	}
-	return tea.Sequence(tea.SetWindowTitle(""), tea.Quit)
 }
 
 func (m *Model) clearSeekState() {
@@ -545,8 +887,8 @@ func (m *Model) beginSeekPreview(base, delta time.Duration, resume bool) tea.Cmd
 	if target < 0 {
 		target = 0
 	}
-	if m.duration > 0 && target > m.duration {
-		target = m.duration
+	if m.duration > 0 && target > m.duration-player.SeekEndMargin {
+		target = max(m.duration-player.SeekEndMargin, 0)
 	}
 
 	m.seekPending = true
@@ -567,14 +909,25 @@ func (m *Model) queueSeekDelta(delta time.Duration) tea.Cmd {
 	base := m.player.Position()
 	if m.seekPending || m.seekApplying {
 		base = m.seekTarget
-	} else {
+	}
+	return m.queueSeekTo(base + delta)
+}
+
+// queueSeekTo previews an absolute seek target through the same debounce
+// path as relative seeks.
+func (m *Model) queueSeekTo(target time.Duration) tea.Cmd {
+	if m.player == nil || !m.player.CanSeek() {
+		return nil
+	}
+
+	if !m.seekPending && !m.seekApplying {
 		m.seekResume = !m.player.Paused()
 		if m.seekResume {
 			m.player.Pause()
 		}
 	}
 
-	return m.beginSeekPreview(base, delta, m.seekResume)
+	return m.beginSeekPreview(target, 0, m.seekResume)
 }
 
 func (m *Model) applyPendingSeek() tea.Cmd {
@@ -587,64 +940,226 @@ func (m *Model) applyPendingSeek() tea.Cmd {
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m, cmd := m.handleMsg(msg)
-	m.flushCaches()
+	if resume := m.syncTickRate(); resume != nil {
+		cmd = tea.Batch(cmd, resume)
+	}
+	if !m.headless {
+		m.flushCaches()
+	}
 	return m, cmd
 }
 
 func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case ControlMsg:
+		return m.handleControl(msg)
 	case tea.MouseMsg:
-		if msg.Action == tea.MouseActionRelease && m.player != nil {
-			m.player.TogglePause()
-			m.paused = m.player.Paused()
-			m.invalidate(dirtyMid)
-			return m, tea.SetWindowTitle(windowTitle(m.metadata.Title, m.paused))
-		}
-		return m, nil
+		return m.handleMouse(msg)
 	case tea.KeyMsg:
-		if isQuit(msg) {
+		if m.jumpMode {
+			return m.updateJumpInput(msg)
+		}
+		if m.volumeMode {
+			return m.updateVolumeInput(msg)
+		}
+		if m.addMode {
+			return m.updateAddInput(msg)
+		}
+		if m.savePrompt {
+			return m.updateSavePrompt(msg)
+		}
+		if m.queueList.SettingFilter() {
+			return m.updateQueueFilter(msg)
+		}
+		if msg.String() == "esc" && m.queueList.IsFiltered() {
+			m.clearQueueFilter()
+			return m, nil
+		}
+		if msg.String() == "ctrl+c" || matches(msg, m.keys.Quit) {
 			m.quitting = true
 			return m, m.shutdown()
 		}
 		if m.player == nil {
 			return m, nil
 		}
-		switch msg.String() {
-		case " ":
+		switch {
+		case matches(msg, m.keys.Pause):
 			if m.seekPending || m.seekApplying {
 				return m, nil
 			}
-			m.player.TogglePause()
+			if m.player.OutputLost() {
+				switch {
+				case m.player.OutputDeviceLost():
+					m.saveMsg = deviceLostMsg
+				case m.player.RetryOutput():
+					m.saveMsg = "Audio output restored"
+				default:
+					m.saveMsg = "Audio output still unavailable"
+				}
+				m.saveMsgTime = time.Now()
+			} else {
+				m.player.TogglePause()
+			}
 			m.paused = m.player.Paused()
 			m.invalidate(dirtyMid)
 			return m, tea.SetWindowTitle(windowTitle(m.metadata.Title, m.paused))
-		case "left", "h":
-			return m, m.queueSeekDelta(-5 * time.Second)
-		case "right", "l":
-			return m, m.queueSeekDelta(5 * time.Second)
-		case "+", "=":
-			m.player.AdjustVolume(0.05)
+		case matches(msg, m.keys.History) && !m.player.CanSeek():
+			// h seeks on seekable tracks; live streams use it for the history.
+			m.toggleHistory()
+			return m, nil
+		case matches(msg, m.keys.SeekBack):
+			return m.seekBy(-m.seekStep)
+		case matches(msg, m.keys.SeekForward):
+			return m.seekBy(m.seekStep)
+		case matches(msg, m.keys.SeekBackFar):
+			return m.seekBy(-m.seekStep * seekFarFactor)
+		case matches(msg, m.keys.SeekForwardFar):
+			return m.seekBy(m.seekStep * seekFarFactor)
+		case matches(msg, m.keys.SeekStepDown):
+			m.adjustSeekStep(-1)
+			return m, nil
+		case matches(msg, m.keys.SeekStepUp):
+			m.adjustSeekStep(1)
+			return m, nil
+		case matches(msg, m.keys.Percent):
+			return m.seekToDecile(int(msg.String()[0] - '0'))
+		case matches(msg, m.keys.Jump):
+			m.openJumpInput()
+			return m, nil
+		case matches(msg, m.keys.PlayNext):
+			m.openAddInput()
+			return m, nil
+		case matches(msg, m.keys.Sleep):
+			return m, m.cycleSleepTimer()
+		case matches(msg, m.keys.Bookmark):
+			m.saveBookmark()
+			return m, nil
+		case matches(msg, m.keys.Favorite):
+			m.toggleFavorite()
+			return m, nil
+		case matches(msg, m.keys.Copy):
+			m.copySource()
+			return m, nil
+		case matches(msg, m.keys.LoopStart):
+			m.setLoopStart()
+			return m, nil
+		case matches(msg, m.keys.LoopEnd):
+			m.setLoopEnd()
+			return m, nil
+		case matches(msg, m.keys.VolumeUp):
+			m.adjustVolume(volumeStep)
+		case matches(msg, m.keys.VolumeDown):
+			m.adjustVolume(-volumeStep)
+		case matches(msg, m.keys.VolumeFineUp):
+			m.adjustVolume(volumeFineStep)
+		case matches(msg, m.keys.VolumeFineDown):
+			m.adjustVolume(-volumeFineStep)
+		case matches(msg, m.keys.VolumeSet):
+			return m, m.openVolumeInput()
+		case matches(msg, m.keys.Mute):
+			if m.player.Muted() {
+				m.player.Unmute()
+			} else {
+				m.player.Mute()
+			}
 			m.volume = m.player.Volume()
+			m.boost = m.player.Boost()
+			m.muted = m.player.Muted()
 			m.invalidate(dirtyMid)
-		case "-":
-			m.player.AdjustVolume(-0.05)
-			m.volume = m.player.Volume()
+		case matches(msg, m.keys.Repeat):
+			m.cycleRepeat()
+			return m, nil
+		case matches(msg, m.keys.RangeStart):
+			if m.queue != nil && m.queue.Len() > 1 {
+				m.markRange(false)
+			}
+			return m, nil
+		case matches(msg, m.keys.RangeEnd):
+			if m.queue != nil && m.queue.Len() > 1 {
+				m.markRange(true)
+			}
+			return m, nil
+		case matches(msg, m.keys.Speed):
+			m.speed = m.player.CycleSpeed()
+			m.invalidate(dirtyMid)
+			return m, nil
+		case matches(msg, m.keys.EQ):
+			m.eqPreset = m.eqPreset.Next()
+			m.player.SetEQPreset(m.eqPreset)
 			m.invalidate(dirtyMid)
-		case "r":
-			m.repeatMode = m.repeatMode.Next()
+			return m, nil
+		case matches(msg, m.keys.Channels):
+			m.channelMode = m.channelMode.Next()
+			m.player.SetChannelMode(m.channelMode)
 			m.invalidate(dirtyMid)
 			return m, nil
-		case "x":
-			m.speed = m.player.CycleSpeed()
+		case matches(msg, m.keys.StereoWidth):
+			m.widthStep = (m.widthStep + 1) % len(player.StereoWidths)
+			m.player.SetStereoWidth(player.StereoWidths[m.widthStep])
+			m.invalidate(dirtyMid)
+			return m, nil
+		case matches(msg, m.keys.KeepPitch):
+			m.keepPitch = !m.keepPitch
+			m.player.SetPitchPreserve(m.keepPitch)
+			m.invalidate(dirtyMid)
+			return m, nil
+		case matches(msg, m.keys.ChapterNext):
+			if m.queue != nil && len(m.metadata.Chapters) == 0 && isSection(m.queue.Current()) {
+				return m.jumpSection(1)
+			}
+			return m, m.jumpChapter(1)
+		case matches(msg, m.keys.ChapterPrev):
+			if m.queue != nil && len(m.metadata.Chapters) == 0 && isSection(m.queue.Current()) {
+				return m.jumpSection(-1)
+			}
+			return m, m.jumpChapter(-1)
+		case matches(msg, m.keys.Crossfade):
+			m.crossfade = player.NextCrossfade(m.crossfade)
+			m.player.SetCrossfade(m.crossfade)
 			m.invalidate(dirtyMid)
 			return m, nil
-		case "v":
+		case matches(msg, m.keys.Info):
+			m.showInfo = !m.showInfo
+			if m.showInfo {
+				m.showHistory = false
+				m.showLyrics = false
+			}
+			m.invalidate(dirtyBottom)
+			return m, nil
+		case matches(msg, m.keys.Timeline):
+			return m, m.toggleTimeline()
+		case matches(msg, m.keys.Remaining):
+			m.showRemaining = !m.showRemaining
+			m.invalidate(dirtyMid)
+			return m, nil
+		case matches(msg, m.keys.Lyrics):
+			m.toggleLyrics()
+			return m, nil
+		case matches(msg, m.keys.Record):
+			if m.player.CanSeek() {
+				return m, nil
+			}
+			m.toggleRecord()
+			return m, nil
+		case matches(msg, m.keys.SkipSilence):
+			if !m.player.CanSeek() {
+				return m, nil
+			}
+			m.skipSilence = !m.skipSilence
+			m.player.SetSkipSilence(m.skipSilence)
+			m.invalidate(dirtyMid)
+			return m, nil
+		case matches(msg, m.keys.Gain):
+			m.gainMode = m.gainMode.Next()
+			m.invalidate(dirtyMid)
+			return m, m.applyGain()
+		case matches(msg, m.keys.Visualizer):
 			if !m.vizEnabled {
 				m.vizEnabled = true
 				m.vizIndex = 0
 				m.updateQueueHeight()
 				m.invalidate(dirtyQueue)
-				return m, vizTickCmd()
+				return m, m.startVizTick()
 			}
 			m.vizIndex++
 			if m.vizIndex >= len(m.visualizers) {
@@ -655,54 +1170,89 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 				m.invalidate(dirtyQueue)
 			}
 			return m, nil
-		case "s":
-			if m.sourcePath != "" && !m.saving {
-				m.saving = true
-				m.saveMsg = "Saving..."
-				m.saveMsgTime = time.Now()
-				m.invalidate(dirtyMid)
-				src, title := m.sourcePath, m.sourceTitle
-				return m, func() tea.Msg {
-					destName, err := downloader.SaveFile(src, title)
-					return fileSavedMsg{destName: destName, err: err}
+		case matches(msg, m.keys.Waveform):
+			cmd := m.toggleWaveform()
+			return m, cmd
+		case matches(msg, m.keys.Artwork):
+			m.toggleArtwork()
+			return m, nil
+		case matches(msg, m.keys.Keep):
+			if m.sourcePath != "" || m.queue != nil {
+				m.toggleKeep()
+			}
+			return m, nil
+		case matches(msg, m.keys.Save):
+			m.openSavePrompt()
+			return m, nil
+		case matches(msg, m.keys.Export):
+			if m.queue != nil && m.queue.Len() > 1 {
+				name := m.playlistName
+				if name == "" || name == "Playlist" {
+					name = "climp queue"
 				}
+				return m, exportQueueCmd(queueExportDir(m.queue), name, queueExportEntries(m.queue))
 			}
 			return m, nil
-		case "z":
+		case matches(msg, m.keys.Shuffle):
 			if m.queue != nil && m.queue.Len() > 1 {
 				m.shuffleMode = m.shuffleMode.Toggle()
 				if m.shuffleMode == ShuffleOn {
-					m.queue.EnableShuffle()
+					m.shuffleQueue()
 				} else {
 					m.queue.DisableShuffle()
 				}
+				m.preloadNext()
 				m.invalidate(dirtyMid)
 				return m, nil
 			}
 			return m, nil
-		case "n":
+		case matches(msg, m.keys.NextTrack):
 			if m.queue != nil {
 				return m.skipToNext()
 			}
-		case "N", "p":
+		case matches(msg, m.keys.PrevTrack):
 			if m.queue != nil {
 				return m.skipToPrevious()
 			}
-		case "enter":
+		case matches(msg, m.keys.Play):
 			if m.queue != nil && m.queue.Len() > 1 {
 				return m.jumpToSelected()
 			}
-		case "backspace", "delete":
+		case matches(msg, m.keys.Remove):
 			if m.queue != nil && m.queue.Len() > 1 {
 				return m.removeSelected()
 			}
-		case "?":
+		case matches(msg, m.keys.Prune):
+			if m.queue != nil && m.queue.Len() > 1 {
+				return m.pruneQueue()
+			}
+		case matches(msg, m.keys.MoveUp):
+			if m.queue != nil && m.queue.Len() > 1 {
+				return m.moveSelected(-1)
+			}
+		case matches(msg, m.keys.MoveDown):
+			if m.queue != nil && m.queue.Len() > 1 {
+				return m.moveSelected(1)
+			}
+		case matches(msg, m.keys.UpNext):
+			if m.queue != nil && m.queue.Len() > 1 {
+				return m.selectUpNext()
+			}
+		case matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
 			m.invalidate(dirtyBottom)
 			return m, nil
-
 		}
-		// Forward navigation keys to queue list
+		// Forward navigation keys to the lyrics, history or queue list
+		if m.showLyrics && m.scrollLyrics(msg) {
+			return m, nil
+		}
+		if m.showHistory && len(m.titleHistory) > 0 {
+			var cmd tea.Cmd
+			m.historyList, cmd = m.historyList.Update(msg)
+			m.invalidate(dirtyBottom)
+			return m, cmd
+		}
 		if m.queue != nil && m.queue.Len() > 1 {
 			var cmd tea.Cmd
 			m.queueList, cmd = m.queueList.Update(msg)
@@ -711,6 +1261,15 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case list.FilterMatchesMsg:
+		var cmd tea.Cmd
+		m.queueList, cmd = m.queueList.Update(msg)
+		m.invalidate(dirtyQueue)
+		return m, cmd
+
+	case scrobbledMsg:
+		return m.handleScrobbled(msg)
+
 	case fileSavedMsg:
 		m.saving = false
 		if msg.err != nil {
@@ -718,6 +1277,10 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 		} else {
 			m.saveMsg = fmt.Sprintf("Saved to %s", msg.destName)
 			m.sourcePath = ""
+			if m.savedPaths == nil {
+				m.savedPaths = make(map[string]bool)
+			}
+			m.savedPaths[msg.src] = true
 		}
 		m.saveMsgTime = time.Now()
 		m.invalidate(dirtyMid | dirtyBottom)
@@ -728,7 +1291,11 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 			return m, nil
 		}
 		next := waitForLiveTitle(m.player)
-		if msg.title == "" || msg.title == m.metadata.Title {
+		if msg.title == "" {
+			return m, next
+		}
+		m.noteStreamTitle(msg.title, time.Now())
+		if msg.title == m.metadata.Title {
 			return m, next
 		}
 		m.metadata.Title = msg.title
@@ -736,21 +1303,65 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 		return m, tea.Batch(next, tea.SetWindowTitle(windowTitle(m.metadata.Title, m.paused)))
 
 	case tickMsg:
-		if m.player == nil {
+		if m.player == nil || msg.seq != m.tickSeq {
 			return m, nil
 		}
 		m.volume = m.player.Volume()
+		m.boost = m.player.Boost()
+		m.muted = m.player.Muted()
+		m.peakDB = m.player.PeakDBFS()
+		m.levelL, m.levelR = m.player.ChannelLevels()
+		m.clips = m.player.ClipCount()
 		if m.seekPending || m.seekApplying {
 			m.paused = true
 		} else {
 			m.elapsed = m.player.Position()
 			m.paused = m.player.Paused()
 		}
+		if m.listen.active && m.elapsed > m.listen.played {
+			m.listen.played = m.elapsed
+		}
+		if m.play.active && m.elapsed > m.play.played {
+			m.play.played = m.elapsed
+		}
+		m.buffering = m.player.Buffering()
+		m.syncRecording()
+		m.syncLyricLine()
+		if at, ok := m.player.TakeDecoderFallback(); ok {
+			m.saveMsg = fmt.Sprintf("Decode error at %s; continuing with ffmpeg", util.FormatDuration(at))
+			m.saveMsgTime = time.Now()
+		}
+		if m.player.OutputLost() && (m.saveMsg == "" || m.saveMsg == outputLostMsg || m.saveMsg == deviceLostMsg) {
+			// Stays up until space brings the output back.
+			m.saveMsg = outputLostMsg
+			if m.player.OutputDeviceLost() {
+				m.saveMsg = deviceLostMsg
+			}
+			m.saveMsgTime = time.Now()
+		}
 		if m.saveMsg != "" && time.Since(m.saveMsgTime) > 5*time.Second {
 			m.saveMsg = ""
 		}
 		m.invalidate(dirtyMid)
-		return m, tickCmd()
+		m.tickRate = m.tickCadence()
+		return m, tea.Batch(tickCmd(m.tickSeq, m.tickRate), m.syncWaveform())
+
+	case waveformMsg:
+		return m.handleWaveform(msg)
+
+	case timelineDurationsMsg:
+		return m.handleTimelineDurations(msg)
+
+	case volumeInputTimeoutMsg:
+		if m.volumeMode && msg.seq == m.volumeSeq {
+			m.closeVolumeInput()
+		}
+		return m, nil
+	case sleepTimerMsg:
+		return m.handleSleepTimer(msg)
+
+	case sleepFadeMsg:
+		return m.handleSleepFade(msg)
 
 	case seekDebounceMsg:
 		if msg.player != m.player || !m.seekPending || msg.seq != m.seekSeq {
@@ -787,12 +1398,37 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 		m.invalidate(dirtyMid)
 		return m, nil
 
+	case gainScannedMsg:
+		if msg.player != m.player || !msg.ok || m.gainMode == player.GainOff {
+			return m, nil
+		}
+		if _, tagged := m.metadata.ReplayGain.GainDB(m.gainMode); !tagged {
+			m.player.SetGainDB(msg.db)
+		}
+		return m, nil
+
+	case queueExportedMsg:
+		if msg.err != nil {
+			m.saveMsg = fmt.Sprintf("Queue export failed: %v", msg.err)
+		} else {
+			m.saveMsg = fmt.Sprintf("Queue saved to %s", msg.dest)
+		}
+		m.saveMsgTime = time.Now()
+		m.invalidate(dirtyMid)
+		return m, nil
+
 	case vizTickMsg:
+		m.vizRunning = false
 		if m.player == nil {
 			return m, nil
 		}
 		if m.vizEnabled && m.vizIndex < len(m.visualizers) {
+			if m.compactLayout() {
+				m.vizCache = ""
+				return m, m.nextVizTick()
+			}
 			samples := m.player.Samples(2048)
+			m.analyzer.Update(samples)
 			vizHeight := m.vizHeight()
 			m.visualizers[m.vizIndex].Update(samples, m.effectiveWidth(), vizHeight)
 			vizView := m.visualizers[m.vizIndex].View()
@@ -808,7 +1444,7 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 			} else {
 				m.vizCache = ""
 			}
-			return m, vizTickCmd()
+			return m, m.nextVizTick()
 		}
 		return m, nil
 
@@ -820,10 +1456,15 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 		if m.player == nil {
 			return m, nil
 		}
+		// Restart and cue track switches re-arm the same player's done channel.
+		if msg.done != m.player.Done() {
+			return m, nil
+		}
 		if m.repeatMode == RepeatOne && m.player.CanSeek() {
 			m.player.Restart()
 			m.elapsed = 0
-			return m, checkDone(m.player)
+			listened := m.beginListenCmd()
+			return m, tea.Batch(checkDone(m.player), listened)
 		}
 		if m.queue != nil {
 			return m.handleQueuePlaybackEnd()
@@ -832,10 +1473,19 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 		m.quitting = true
 		return m, m.shutdown()
 
+	case trackAdvancedMsg:
+		if msg.player != m.player || m.player == nil {
+			return m, nil
+		}
+		return m.handleTrackAdvanced(msg)
+
 	case trackFailedMsg:
-		if msg.err != nil {
+		switch {
+		case errors.Is(msg.err, player.ErrDRMProtected):
+			m.saveMsg = "This file is DRM-protected and can't be played"
+		case msg.err != nil:
 			m.saveMsg = fmt.Sprintf("Track failed: %v", msg.err)
-		} else {
+		default:
 			m.saveMsg = "Track failed"
 		}
 		m.saveMsgTime = time.Now()
@@ -851,6 +1501,9 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 	case trackDownloadedMsg:
 		return m.handleTrackDownloaded(msg)
 
+	case downloadRetryMsg:
+		return m.handleDownloadRetry(msg)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -871,6 +1524,9 @@ func (m Model) handleMsg(msg tea.Msg) (Model, tea.Cmd) {
 // it wraps around and re-shuffles if needed. Returns the track, its original index,
 // and whether one was found.
 func (m *Model) findNextPlayable(wrap bool) (*queue.Track, int, bool) {
+	if lo, hi, ok := m.activeRange(); ok {
+		return m.findNextInRange(lo, hi)
+	}
 	for range m.queue.Len() {
 		var next *queue.Track
 		var nextIdx int
@@ -888,14 +1544,7 @@ func (m *Model) findNextPlayable(wrap bool) (*queue.Track, int, bool) {
 			}
 			// Wrap around — re-shuffle or scan from index 0
 			wrap = false // only wrap once
-			// Reset cleaned-up URL tracks to Pending so they can be re-downloaded
-			for i := 0; i < m.queue.Len(); i++ {
-				t := m.queue.Track(i)
-				if t != nil && t.State == queue.Done && t.URL != "" && t.Cleanup == nil && !downloader.IsLiveURL(t.URL) {
-					m.queue.SetTrackState(i, queue.Pending)
-					m.queue.SetTrackPath(i, "")
-				}
-			}
+			m.resetPlayedDownloads(0, m.queue.Len()-1)
 			if m.queue.IsShuffled() {
 				m.queue.EnableShuffle()
 			} else {
@@ -934,6 +1583,8 @@ func (m Model) advanceAndPlay() (Model, tea.Cmd) {
 	}
 	if m.queue.IsShuffled() {
 		m.queue.AdvanceShuffle()
+	} else if _, _, ok := m.activeRange(); ok {
+		m.queue.SetCurrentIndex(m.nextQueueIndex())
 	} else {
 		m.queue.Advance()
 	}
@@ -975,8 +1626,8 @@ func (m Model) skipToNext() (Model, tea.Cmd) {
 
 // jumpToSelected jumps to the track currently highlighted in the queue list.
 func (m Model) jumpToSelected() (Model, tea.Cmd) {
-	sel := m.queueList.Index()
-	targetIdx := m.listIndexToQueueIndex(sel)
+	targetIdx := m.selectedQueueIndex()
+	m.clearQueueFilter()
 	if targetIdx < 0 || targetIdx >= m.queue.Len() || targetIdx == m.queue.CurrentIndex() {
 		return m, nil
 	}
@@ -1014,21 +1665,10 @@ func (m Model) jumpToSelected() (Model, tea.Cmd) {
 	return m, nil
 }
 
-// listIndexToQueueIndex maps a queue list selection index back to the real queue index.
-// The list is ordered: tracks after current, then tracks before current.
-func (m Model) listIndexToQueueIndex(sel int) int {
-	currentIdx := m.queue.CurrentIndex()
-	afterCount := m.queue.Len() - currentIdx - 1
-	if sel < afterCount {
-		return currentIdx + 1 + sel
-	}
-	return sel - afterCount
-}
-
 // removeSelected removes the track currently highlighted in the queue list.
 func (m Model) removeSelected() (Model, tea.Cmd) {
 	sel := m.queueList.Index()
-	targetIdx := m.listIndexToQueueIndex(sel)
+	targetIdx := m.selectedQueueIndex()
 	if targetIdx < 0 || targetIdx >= m.queue.Len() {
 		return m, nil
 	}
@@ -1041,11 +1681,13 @@ func (m Model) removeSelected() (Model, tea.Cmd) {
 	if !m.queue.Remove(targetIdx) {
 		return m, nil
 	}
+	m.shiftRangeForRemoval([]int{targetIdx})
+	m.preloadNext()
 	// Sync immediately so cursor adjustment below sees updated items.
 	m.syncQueueList()
 	if m.queue.Len() > 1 {
 		// Adjust cursor if it's now past the end of the list
-		if sel >= len(m.queueList.Items()) && sel > 0 {
+		if sel >= len(m.queueList.VisibleItems()) && sel > 0 {
 			m.queueList.Select(sel - 1)
 		}
 	}
@@ -1053,6 +1695,89 @@ func (m Model) removeSelected() (Model, tea.Cmd) {
 	return m, nil
 }
 
+// pruneQueue removes every played and failed track except the current one.
+func (m Model) pruneQueue() (Model, tea.Cmd) {
+	// The track being waited for is tracked by queue index, so keep it stable.
+	if m.transitioning {
+		m.saveMsg = "Cannot clear tracks while waiting for a download"
+		m.saveMsgTime = time.Now()
+		m.invalidate(dirtyMid)
+		return m, nil
+	}
+	sel := m.queueList.Index()
+	var removed []int
+	for i := range m.queue.Len() {
+		if t := m.queue.Track(i); i != m.queue.CurrentIndex() && (t.State == queue.Done || t.State == queue.Failed) {
+			removed = append(removed, i)
+		}
+	}
+	n := m.queue.Compact()
+	m.shiftRangeForRemoval(removed)
+	switch n {
+	case 0:
+		m.saveMsg = "No played or failed tracks to clear"
+	case 1:
+		m.saveMsg = "Cleared 1 track"
+	default:
+		m.saveMsg = fmt.Sprintf("Cleared %d tracks", n)
+	}
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+	if n == 0 {
+		return m, nil
+	}
+	m.preloadNext()
+	m.syncQueueList()
+	if items := len(m.queueList.VisibleItems()); sel >= items {
+		m.queueList.Select(max(items-1, 0))
+	}
+	m.invalidate(dirtyHeader | dirtyQueue)
+	return m, nil
+}
+
+// moveSelected moves the highlighted track one place earlier (delta < 0) or
+// later in the queue and keeps it highlighted, so repeated presses keep moving
+// the same track. Tracks never move past the playing track.
+func (m Model) moveSelected(delta int) (Model, tea.Cmd) {
+	from := m.selectedQueueIndex()
+	to := from + delta
+	cur := m.queue.CurrentIndex()
+	if from < 0 || to < 0 || to >= m.queue.Len() || from == cur || to == cur {
+		return m, nil
+	}
+	// The track being waited for is tracked by queue index, so keep it stable.
+	if m.transitioning {
+		m.saveMsg = "Cannot move tracks while waiting for a download"
+		m.saveMsgTime = time.Now()
+		m.invalidate(dirtyMid)
+		return m, nil
+	}
+	if !m.queue.Move(from, to) {
+		return m, nil
+	}
+	m.syncQueueList()
+	for i, item := range m.queueList.VisibleItems() {
+		if t, ok := item.(trackItem); ok && t.idx == to {
+			m.queueList.Select(i)
+			break
+		}
+	}
+	m.invalidate(dirtyQueue)
+	return m, m.startNextDownload()
+}
+
+// selectUpNext moves the queue cursor back to the top of the list, where
+// syncQueueList keeps the up-next track. A filter is cleared first so the top
+// item really is the up-next track.
+func (m Model) selectUpNext() (Model, tea.Cmd) {
+	if m.queueList.IsFiltered() {
+		m.clearQueueFilter()
+	}
+	m.queueList.Select(0)
+	m.invalidate(dirtyQueue)
+	return m, nil
+}
+
 // skipToPrevious goes back to the previous track if it's still ready.
 func (m Model) skipToPrevious() (Model, tea.Cmd) {
 	if m.queue.IsShuffled() {
@@ -1105,11 +1830,19 @@ func (m Model) handleQueuePlaybackEnd() (Model, tea.Cmd) {
 
 // handleTrackDownloaded processes a completed background download.
 func (m Model) handleTrackDownloaded(msg trackDownloadedMsg) (Model, tea.Cmd) {
+	msg.index = m.downloadingTrackIndex(msg.index, msg.url)
+	if msg.index < 0 {
+		// The track was removed from the queue while it downloaded.
+		if msg.cleanup != nil {
+			msg.cleanup()
+		}
+		return m, m.startNextDownload()
+	}
 	if msg.err != nil {
-		m.queue.SetTrackState(msg.index, queue.Failed)
-		if msg.index == m.downloading {
-			m.downloading = -1
+		if cmd, ok := m.retryDownload(msg); ok {
+			return m, cmd
 		}
+		m.queue.SetTrackState(msg.index, queue.Failed)
 		m.saveMsg = downloadErrorSummary(msg.err)
 		m.saveMsgTime = time.Now()
 		m.invalidate(dirtyMid)
@@ -1135,11 +1868,8 @@ func (m Model) handleTrackDownloaded(msg trackDownloadedMsg) (Model, tea.Cmd) {
 		m.queue.SetTrackTitle(msg.index, msg.title)
 	}
 	m.queue.SetTrackState(msg.index, queue.Ready)
-	if msg.index == m.downloading {
-		m.downloading = -1
-	}
 
-	var cmds []tea.Cmd
+	cmds := []tea.Cmd{m.probeTrackDuration(msg.index)}
 
 	if m.transitioning && msg.index == m.transitionTarget {
 		m.transitioning = false
@@ -1149,13 +1879,14 @@ func (m Model) handleTrackDownloaded(msg trackDownloadedMsg) (Model, tea.Cmd) {
 		m.queue.SetTrackState(m.transitionTarget, queue.Playing)
 		m.transitionTarget = -1
 		m.clearSeekState()
+		m.clearLoopMarks()
 		track := m.queue.Current()
 		m.metadata = player.Metadata{Title: track.Title}
 		m.sourceTitle = track.Title
 		m.sourcePath = track.Path
 
 		var err error
-		m.player, err = player.New(track.Path)
+		m.player, err = openPlayer(track.Path)
 		if err != nil {
 			m.queue.SetTrackState(m.queue.CurrentIndex(), queue.Failed)
 			m.invalidate(dirtyQueue)
@@ -1163,14 +1894,13 @@ func (m Model) handleTrackDownloaded(msg trackDownloadedMsg) (Model, tea.Cmd) {
 		}
 		m.elapsed = 0
 		m.duration = m.player.Duration()
-		m.volume = m.player.Volume()
+		m.noteTrackDuration()
+		m.muted = false
 		m.paused = false
-		if m.speed != player.Speed1x {
-			m.player.SetSpeed(m.speed)
-		}
+		m.applyPlayerSettings()
 		m.invalidate(dirtyHeader)
 
-		cmds = append(cmds, checkDone(m.player), tickCmd(), waitForLiveTitle(m.player), tea.SetWindowTitle(windowTitle(m.metadata.Title, false)))
+		cmds = append(cmds, checkDone(m.player), m.restartTick(), waitForAdvance(m.player), waitForLiveTitle(m.player), tea.SetWindowTitle(windowTitle(m.metadata.Title, false)), m.applyGain(), m.notifyTrackCmd(), m.beginListenCmd())
 	}
 
 	// Start downloading next undownloaded track
@@ -1183,8 +1913,9 @@ func (m Model) handleTrackDownloaded(msg trackDownloadedMsg) (Model, tea.Cmd) {
 
 // handlePlaylistExtracted builds the queue from background extraction results.
 func (m Model) handlePlaylistExtracted(msg playlistExtractedMsg) (Model, tea.Cmd) {
-	if msg.err != nil || len(msg.entries) <= 1 {
-		// Single video or extraction failed — stay in single-track mode.
+	if msg.err != nil || len(msg.entries) <= 1 || m.queue != nil {
+		// Single video, extraction failed, or a queue was started with
+		// play-next: stay as is.
 		return m, nil
 	}
 
@@ -1207,51 +1938,52 @@ func (m Model) handlePlaylistExtracted(msg playlistExtractedMsg) (Model, tea.Cmd
 	tracks[0].State = queue.Playing
 	tracks[0].Path = m.sourcePath
 	tracks[0].Title = m.sourceTitle
+	tracks[0].Cleanup = m.cleanup
+	m.cleanup = nil
+
+	m.useQueue(queue.New(tracks))
+	m.playlistName = playlistLabelFromURL(m.originalURL)
+	m.originalURL = "" // extraction done
 
-	m.queue = queue.New(tracks)
+	// Start downloading the next track.
+	return m, m.startNextDownload()
+}
+
+// useQueue turns a single-track model into one playing q, with the queue
+// list sized for the window.
+func (m *Model) useQueue(q *queue.Queue) {
+	m.cancelExtraction()
+	m.queue = q
+	if m.shuffleMode == ShuffleOn {
+		m.queue.EnableShuffle()
+	}
 	w := m.width
 	if w < 30 {
 		w = 50
 	}
 	m.queueList = newQueueList(w - 4)
 	m.updateQueueHeight()
-	m.playlistName = playlistLabelFromURL(m.originalURL)
 	m.invalidate(dirtyHeader | dirtyQueue)
-	m.originalURL = "" // extraction done
-
-	// Start downloading the next track.
-	return m, m.startNextDownload()
 }
 
 // advanceToTrack switches playback to the given track.
 func (m Model) advanceToTrack(track *queue.Track) (Model, tea.Cmd) {
 	m.clearSeekState()
+	m.clearLoopMarks()
+	if m.canPlaySection(track) {
+		return m.playSection(track)
+	}
 	if m.player != nil {
 		m.player.Close()
 	}
 	isLiveURL := track.URL != "" && downloader.IsLiveURL(track.URL)
-
-	// Local files (no URL) have full metadata on disk; URL downloads only have a title.
-	if track.URL == "" && track.Path != "" {
-		m.metadata = player.ReadMetadata(track.Path)
-	} else {
-		m.metadata = player.Metadata{Title: track.Title}
-		if m.metadata.Title == "" {
-			m.metadata.Title = track.URL
-		}
-	}
-	m.sourceTitle = track.Title
-	if track.URL != "" && !isLiveURL {
-		m.sourcePath = track.Path
-	} else {
-		m.sourcePath = ""
-	}
+	m.setTrackInfo(track)
 
 	var err error
 	if isLiveURL {
 		m.player, err = player.NewStream(track.URL)
 	} else {
-		m.player, err = player.New(track.Path)
+		m.player, err = openPlayer(track.Path)
 	}
 	if err != nil {
 		// For queue playback, mark the track as failed and try the next one.
@@ -1263,36 +1995,169 @@ func (m Model) advanceToTrack(track *queue.Track) (Model, tea.Cmd) {
 		m.quitting = true
 		return m, m.shutdown()
 	}
+	if isSection(track) {
+		m.player.SetBounds(track.Start, track.End)
+	}
 
 	m.elapsed = 0
 	m.duration = m.player.Duration()
-	m.volume = m.player.Volume()
+	m.noteTrackDuration()
+	m.muted = false
 	m.paused = false
 	m.transitioning = false
-	if m.speed != player.Speed1x {
-		m.player.SetSpeed(m.speed)
-	}
+	m.applyPlayerSettings()
 	m.invalidate(dirtyHeader | dirtyQueue)
 
 	cmds := []tea.Cmd{
 		checkDone(m.player),
-		tickCmd(),
+		m.restartTick(),
+		waitForAdvance(m.player),
 		waitForLiveTitle(m.player),
 		tea.SetWindowTitle(windowTitle(m.metadata.Title, false)),
 		m.startNextDownload(),
+		m.applyGain(),
+		m.notifyTrackCmd(),
+		m.beginListenCmd(),
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
-// extractPlaylistCmd runs playlist extraction in the background.
-func extractPlaylistCmd(url string) tea.Cmd {
+// setTrackInfo updates the metadata and save source shown for track.
+func (m *Model) setTrackInfo(track *queue.Track) {
+	isLiveURL := track.URL != "" && downloader.IsLiveURL(track.URL)
+
+	// Local files (no URL) have full metadata on disk; URL downloads only have a title.
+	if track.URL == "" && track.Path != "" {
+		m.metadata = player.ReadMetadata(track.Path)
+	} else {
+		m.metadata = player.Metadata{Title: track.Title}
+		if m.metadata.Title == "" {
+			m.metadata.Title = track.URL
+		}
+	}
+	if isSection(track) {
+		// A cue track is one part of a larger file; its own title replaces
+		// the file's, and the file's chapters do not apply.
+		m.metadata.Title = track.Title
+		m.metadata.Chapters = nil
+		m.metadata.Lyrics = player.Lyrics{}
+	}
+	m.lyricsScroll = 0
+	m.lyricLine = m.metadata.Lyrics.LineAt(0)
+	if m.showLyrics {
+		m.invalidate(dirtyBottom)
+	}
+	m.sourceTitle = track.Title
+	if track.URL != "" && !isLiveURL {
+		m.sourcePath = track.Path
+	} else {
+		m.sourcePath = ""
+	}
+}
+
+// handleTrackAdvanced updates the queue after the player continued gaplessly
+// into the preloaded next track. The player itself is kept.
+func (m Model) handleTrackAdvanced(msg trackAdvancedMsg) (Model, tea.Cmd) {
+	next := waitForAdvance(m.player)
+	m.elapsed = 0
+	m.duration = m.player.Duration()
+	m.invalidate(dirtyMid)
+	if m.queue == nil {
+		return m, next
+	}
+
+	idx := m.nextQueueIndex()
+	if t := m.queue.Track(idx); t == nil || t.Path != msg.path {
+		// The queue changed after the preload; find the track that is now playing.
+		idx = -1
+		for i := 0; i < m.queue.Len(); i++ {
+			if m.queue.Track(i).Path == msg.path {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return m, next
+		}
+	}
+
+	m.cleanupOldTracks()
+	m.queue.SetTrackState(m.queue.CurrentIndex(), queue.Done)
+	m.queue.SetCurrentIndex(idx)
+	m.queue.SetTrackState(idx, queue.Playing)
+	m.noteTrackDuration()
+	m.clearSeekState()
+	m.clearLoopMarks()
+	m.setTrackInfo(m.queue.Current())
+	m.applyTrackVolume()
+	m.invalidate(dirtyHeader | dirtyQueue)
+	listened := m.beginListenCmd()
+
+	return m, tea.Batch(
+		next,
+		tea.SetWindowTitle(windowTitle(m.metadata.Title, m.paused)),
+		m.startNextDownload(),
+		m.applyGain(),
+		m.notifyTrackCmd(),
+		listened,
+	)
+}
+
+// applyGain sets the player's ReplayGain adjustment for the current track.
+// Untagged tracks play at unity gain until a background level scan finishes.
+func (m Model) applyGain() tea.Cmd {
+	if m.player == nil {
+		return nil
+	}
+	if db, ok := m.metadata.ReplayGain.GainDB(m.gainMode); ok {
+		m.player.SetGainDB(db)
+		return nil
+	}
+	m.player.SetGainDB(0)
+	if m.gainMode == player.GainOff || !m.player.CanSeek() {
+		return nil
+	}
+	p := m.player
 	return func() tea.Msg {
-		entries, err := downloader.ExtractPlaylist(url)
+		db, ok := p.ScanGainDB()
+		return gainScannedMsg{player: p, db: db, ok: ok}
+	}
+}
+
+// preloadNext hands the next ready local track to the player for gapless
+// playback, or cancels the preload when there is none.
+func (m Model) preloadNext() {
+	if m.player == nil || m.queue == nil {
+		return
+	}
+	path := ""
+	if m.repeatMode != RepeatOne {
+		t := m.queue.Track(m.nextQueueIndex())
+		if t != nil && t.Path != "" && !isSection(t) && (t.State == queue.Ready || t.State == queue.Done) && !downloader.IsLiveURL(t.URL) {
+			path = t.Path
+		}
+	}
+	m.player.PreloadNext(path)
+}
+
+// extractPlaylistCmd runs playlist extraction in the background. Playback of
+// the single video continues meanwhile; cancelling ctx kills yt-dlp.
+func extractPlaylistCmd(ctx context.Context, url string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := downloader.ExtractPlaylistContext(ctx, url)
 		return playlistExtractedMsg{entries: entries, err: err}
 	}
 }
 
+// cancelExtraction stops a background playlist extraction, if any. Its
+// result, should one still arrive, is ignored.
+func (m *Model) cancelExtraction() {
+	if m.stopExtract != nil {
+		m.stopExtract()
+	}
+}
+
 // downloadTrackCmd creates a command to download a track by queue index.
 func (m Model) downloadTrackCmd(index int) tea.Cmd {
 	track := m.queue.Track(index)
@@ -1300,7 +2165,6 @@ func (m Model) downloadTrackCmd(index int) tea.Cmd {
 		return nil
 	}
 	m.queue.SetTrackState(index, queue.Downloading)
-	m.downloading = index
 
 	trackURL := track.URL
 	return func() tea.Msg {
@@ -1310,6 +2174,7 @@ func (m Model) downloadTrackCmd(index int) tea.Cmd {
 		}
 		return trackDownloadedMsg{
 			index:   index,
+			url:     trackURL,
 			path:    path,
 			title:   title,
 			cleanup: cleanup,
@@ -1318,33 +2183,41 @@ func (m Model) downloadTrackCmd(index int) tea.Cmd {
 	}
 }
 
-// startNextDownload downloads only the immediate next track in playback order.
+// startNextDownload fills free download slots with the nearest pending tracks
+// among the next concurrentDownloads() in playback order.
 func (m Model) startNextDownload() tea.Cmd {
-	if m.queue == nil || m.downloading >= 0 {
-		return nil
-	}
-	next := m.queue.NextDownloadIndex()
-	if next < 0 || next >= m.queue.Len() {
+	m.preloadNext()
+	if m.queue == nil {
 		return nil
 	}
-	t := m.queue.Track(next)
-	if t != nil && t.State == queue.Pending {
-		return m.downloadTrackCmd(next)
+	limit := m.concurrentDownloads()
+	free := limit - m.downloadsInFlight()
+	var cmds []tea.Cmd
+	for _, i := range m.queue.UpcomingIndices(limit) {
+		if free <= 0 {
+			break
+		}
+		if t := m.queue.Track(i); t != nil && t.State == queue.Pending {
+			cmds = append(cmds, m.downloadTrackCmd(i))
+			free--
+		}
 	}
-	return nil
+	return tea.Batch(cmds...)
 }
 
-// cleanupOldTracks frees disk space for tracks 2+ positions behind current.
-func (m Model) cleanupOldTracks() {
+// cleanupOldTracks frees disk space for played tracks 2+ positions behind
+// current in playback order. Tracks that have not played yet, such as
+// prefetched ones behind current after a jump or in shuffle order, are kept.
+// In keep mode the files are copied to the keep directory first.
+func (m *Model) cleanupOldTracks() {
 	if m.queue == nil {
 		return
 	}
-	cur := m.queue.CurrentIndex()
-	for i := 0; i < cur-1; i++ {
-		t := m.queue.Track(i)
-		if t != nil && t.Cleanup != nil {
-			t.Cleanup()
-			m.queue.SetTrackCleanup(i, nil)
+	order := m.queue.PlaybackOrder()
+	pos := slices.Index(order, m.queue.CurrentIndex())
+	for _, i := range order[:max(pos-1, 0)] {
+		if t := m.queue.Track(i); t != nil && t.State == queue.Done {
+			m.releaseTrack(i)
 		}
 	}
 }
@@ -1361,7 +2234,14 @@ func (m Model) effectiveWidth() int {
 // Top padding (2) + title (1) + artist (1) + gaps (3) + progress (1) + status (1)
 // + queue gap (1) + help (~3) = ~13. Long titles may wrap for 1-2 extra lines.
 func (m Model) fixedLines() int {
-	return 13
+	n := 13
+	if m.artLayout {
+		n += artworkRows + 1
+	}
+	if m.waveLayout {
+		n += waveformRows
+	}
+	return n
 }
 
 func downloadErrorSummary(err error) string {
@@ -1372,6 +2252,10 @@ func downloadErrorSummary(err error) string {
 		return "Live stream download fallback timed out"
 	case errors.Is(err, downloader.ErrUnsupportedScheme):
 		return "Unsupported URL scheme (http/https only)"
+	case errors.Is(err, downloader.ErrNetwork):
+		return "Download failed (network error)"
+	case errors.Is(err, downloader.ErrUnavailable):
+		return "Download failed (media unavailable)"
 	default:
 		return "Download failed"
 	}
@@ -1397,6 +2281,11 @@ func (m *Model) updateQueueHeight() {
 	if m.queue == nil {
 		return
 	}
+	m.queueList.SetHeight(m.listHeight())
+}
+
+// listHeight returns the rows left for the queue or history list.
+func (m Model) listHeight() int {
 	avail := m.height - m.fixedLines()
 	if m.vizEnabled {
 		// Subtract the visualizer height + 1 blank line
@@ -1406,7 +2295,7 @@ func (m *Model) updateQueueHeight() {
 	if avail < 6 {
 		avail = 6
 	}
-	m.queueList.SetHeight(avail)
+	return avail
 }
 
 func (m Model) View() string {
@@ -1414,6 +2303,9 @@ func (m Model) View() string {
 		return ""
 	}
 	view := m.headerCache + m.midCache + m.vizCache + m.bottomCache
+	if m.compactLayout() {
+		view = m.midCache
+	}
 	if m.height <= 0 {
 		return view
 	}
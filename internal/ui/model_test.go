@@ -1,13 +1,29 @@
 package ui
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/olivier-w/climp/internal/config"
+	"github.com/olivier-w/climp/internal/downloader"
 	"github.com/olivier-w/climp/internal/player"
 	"github.com/olivier-w/climp/internal/queue"
+	"github.com/olivier-w/climp/internal/scrobble"
+	"github.com/olivier-w/climp/internal/visualizer"
 )
 
 func TestHandleLiveTitleUpdatedMsgUpdatesCurrentMetadata(t *testing.T) {
@@ -69,6 +85,67 @@ func TestHandleLiveTitleUpdatedMsgLeavesQueueUntouched(t *testing.T) {
 	}
 }
 
+func TestLiveTitleHistoryDedupesAndKeepsRecentTitles(t *testing.T) {
+	p := new(player.Player)
+	m := Model{player: p, width: 80, height: 24, keys: defaultKeyMap()}
+
+	for _, title := range []string{"A - One", "A - One", "B - Two"} {
+		m, _ = m.handleMsg(liveTitleUpdatedMsg{player: p, title: title})
+	}
+	if len(m.titleHistory) != 2 {
+		t.Fatalf("history has %d titles, want 2 after a repeat", len(m.titleHistory))
+	}
+	if got := m.historyList.Items()[0].(trackItem).title; got != "B - Two" {
+		t.Fatalf("first history row = %q, want the newest title", got)
+	}
+
+	for i := range maxTitleHistory + 5 {
+		m, _ = m.handleMsg(liveTitleUpdatedMsg{player: p, title: fmt.Sprintf("Song %d", i)})
+	}
+	if len(m.titleHistory) != maxTitleHistory || m.titleHistory[0].title != "Song 5" {
+		t.Fatalf("history = %d titles starting %q, want the last %d", len(m.titleHistory), m.titleHistory[0].title, maxTitleHistory)
+	}
+
+	// h toggles the panel on a live stream instead of seeking.
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if !m.showHistory {
+		t.Fatal("expected h to open the history on a live stream")
+	}
+	m.rebuildBottomCache()
+	if !strings.Contains(m.bottomCache, "Stream History") || !strings.Contains(m.bottomCache, "Song 24") {
+		t.Fatal("expected the history panel with the latest title in place of the queue")
+	}
+}
+
+func TestLyricsPanelFollowsSyncedLyrics(t *testing.T) {
+	m := Model{player: new(player.Player), width: 80, height: 24, keys: defaultKeyMap(), showInfo: true}
+	m.metadata.Lyrics = player.ParseLRC("[00:01.00]First line\n[00:05.00]Second line\n")
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	if !m.showLyrics || m.showInfo {
+		t.Fatal("expected L to replace the info panel with the lyrics")
+	}
+	if m.lyricLine != -1 {
+		t.Fatalf("lyricLine = %d before the first timestamp, want -1", m.lyricLine)
+	}
+
+	m.elapsed = 6 * time.Second
+	m.syncLyricLine()
+	if m.lyricLine != 1 || m.dirty&dirtyBottom == 0 {
+		t.Fatalf("lyricLine = %d, want 1 and a redraw after the second timestamp", m.lyricLine)
+	}
+	m.rebuildBottomCache()
+	if !strings.Contains(m.bottomCache, "Lyrics") || !strings.Contains(m.bottomCache, "Second line") {
+		t.Fatal("expected the lyrics panel in place of the queue")
+	}
+
+	m.metadata.Lyrics = player.Lyrics{}
+	m.rebuildBottomCache()
+	if !strings.Contains(m.bottomCache, "No lyrics for this track") {
+		t.Fatal("expected a no-lyrics note")
+	}
+}
+
 func TestViewPadsToWindowHeight(t *testing.T) {
 	m := Model{
 		height:      8,
@@ -117,6 +194,15 @@ func TestBeginSeekPreviewUpdatesElapsedImmediately(t *testing.T) {
 	}
 }
 
+func TestBeginSeekPreviewStopsShortOfTheEnd(t *testing.T) {
+	m := Model{player: new(player.Player), duration: 30 * time.Second}
+
+	m.beginSeekPreview(28*time.Second, 5*time.Second, true)
+	if want := 30*time.Second - player.SeekEndMargin; m.seekTarget != want || m.elapsed != want {
+		t.Fatalf("seekTarget = %v, elapsed = %v; want %v", m.seekTarget, m.elapsed, want)
+	}
+}
+
 func TestSeekDebounceIgnoresStaleSeq(t *testing.T) {
 	p := new(player.Player)
 	m := Model{
@@ -178,7 +264,7 @@ func TestTickMsgDoesNotOverwriteElapsedDuringSeekPreview(t *testing.T) {
 		seekPending: true,
 	}
 
-	next, _ := m.handleMsg(tickMsg(time.Now()))
+	next, _ := m.handleMsg(tickMsg{})
 	if got := next.elapsed; got != 18*time.Second {
 		t.Fatalf("expected elapsed to stay at preview target, got %v", got)
 	}
@@ -187,6 +273,46 @@ func TestTickMsgDoesNotOverwriteElapsedDuringSeekPreview(t *testing.T) {
 	}
 }
 
+func TestTickChainRestartsAtFullSpeedOnResume(t *testing.T) {
+	m := Model{player: new(player.Player), paused: true}
+	if got := m.tickCadence(); got != tickInterval {
+		t.Fatalf("expected a paused live stream to keep the fast tick, got %v", got)
+	}
+
+	// A chain that slowed down while paused is replaced once playback resumes.
+	m.tickRate = idleTickInterval
+	m.paused = false
+	if cmd := m.syncTickRate(); cmd == nil {
+		t.Fatal("expected resuming to restart the tick")
+	}
+	if m.tickSeq != 1 || m.tickRate != tickInterval {
+		t.Fatalf("expected a new fast chain, got seq=%d rate=%v", m.tickSeq, m.tickRate)
+	}
+	if _, cmd := m.handleMsg(tickMsg{seq: 0}); cmd != nil {
+		t.Fatal("expected the replaced chain's tick to be dropped")
+	}
+	if cmd := m.syncTickRate(); cmd != nil {
+		t.Fatal("expected no second restart while the fast chain runs")
+	}
+}
+
+func TestVizTickLapsesWhilePausedAndResumes(t *testing.T) {
+	m := Model{player: new(player.Player), paused: true, vizEnabled: true, vizRunning: true, compact: true}
+	m.visualizers = visualizer.Modes(visualizer.NewAnalyzer())
+
+	next, cmd := m.handleMsg(vizTickMsg(time.Now()))
+	if cmd != nil || next.vizRunning {
+		t.Fatal("expected the visualizer tick to stop while paused")
+	}
+	next.paused = false
+	if cmd := next.syncTickRate(); cmd == nil || !next.vizRunning {
+		t.Fatal("expected resuming to restart the visualizer tick")
+	}
+	if cmd := next.startVizTick(); cmd != nil {
+		t.Fatal("expected only one visualizer tick in flight")
+	}
+}
+
 func TestSeekAppliedMsgClearsStateOnLatestSuccess(t *testing.T) {
 	p := new(player.Player)
 	m := Model{
@@ -216,3 +342,1240 @@ func TestSeekAppliedMsgClearsStateOnLatestSuccess(t *testing.T) {
 		t.Fatal("expected resumed state after successful seek")
 	}
 }
+
+func TestStartupFlagsOverridePersistedModes(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "One", State: queue.Playing},
+		{Title: "Two", State: queue.Ready},
+		{Title: "Three", State: queue.Ready},
+	})
+	m := Model{queue: q}.ApplySettings(config.State{Repeat: int(RepeatOne)})
+
+	mode, err := ParseRepeatMode("all")
+	if err != nil {
+		t.Fatalf("ParseRepeatMode() error = %v", err)
+	}
+	m = m.WithRepeat(mode).WithShuffle()
+	if m.repeatMode != RepeatAll {
+		t.Fatalf("expected repeat all from the flag, got %v", m.repeatMode)
+	}
+	if m.shuffleMode != ShuffleOn || !q.IsShuffled() || q.CurrentIndex() != 0 {
+		t.Fatal("expected the queue shuffled with the current track kept")
+	}
+	if _, err := ParseRepeatMode("twice"); err == nil {
+		t.Fatal("expected unknown repeat mode to fail")
+	}
+}
+
+func TestDRMTrackFailureShowsFriendlyMessageAndSkips(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "Protected", State: queue.Failed},
+		{Title: "Next", URL: "https://example.com/next", State: queue.Downloading},
+	})
+	m := Model{queue: q, keys: defaultKeyMap()}
+
+	err := fmt.Errorf("%w: unsupported AAC feature: encrypted MP4", player.ErrDRMProtected)
+	next, _ := m.handleMsg(trackFailedMsg{err: err})
+	if next.saveMsg != "This file is DRM-protected and can't be played" {
+		t.Fatalf("saveMsg = %q, want the DRM message", next.saveMsg)
+	}
+	if !next.transitioning || next.transitionTarget != 1 {
+		t.Fatalf("expected to move on to the next track, transitioning=%v target=%d", next.transitioning, next.transitionTarget)
+	}
+}
+
+func TestApplySettingsRestoresModesAndIgnoresUnknownValues(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "One", State: queue.Playing},
+		{Title: "Two", State: queue.Ready},
+		{Title: "Three", State: queue.Ready},
+	})
+
+	m := Model{queue: q}.ApplySettings(config.State{
+		Volume:  0.5,
+		Speed:   int(player.SpeedHalf),
+		Repeat:  int(RepeatAll),
+		Shuffle: int(ShuffleOn),
+	})
+	if m.speed != player.SpeedHalf {
+		t.Fatalf("expected speed 0.5x, got %v", m.speed)
+	}
+	if m.repeatMode != RepeatAll {
+		t.Fatalf("expected repeat all, got %v", m.repeatMode)
+	}
+	if m.shuffleMode != ShuffleOn || !q.IsShuffled() {
+		t.Fatal("expected shuffle to be enabled on the queue")
+	}
+	if !m.persistSettings {
+		t.Fatal("expected settings to be persisted on shutdown")
+	}
+
+	m = Model{}.ApplySettings(config.State{Speed: 42, Repeat: -1, Shuffle: 7})
+	if m.speed != player.Speed1x || m.repeatMode != RepeatOff || m.shuffleMode != ShuffleOff {
+		t.Fatalf("expected defaults for unknown modes, got speed=%v repeat=%v shuffle=%v", m.speed, m.repeatMode, m.shuffleMode)
+	}
+}
+
+func TestJumpInputRejectsInvalidTimestamp(t *testing.T) {
+	m := Model{jumpMode: true, jumpInput: newJumpInput()}
+	m.jumpInput.SetValue("1:75")
+
+	next, cmd := m.handleMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Fatal("expected no seek command for invalid timestamp")
+	}
+	if next.jumpMode {
+		t.Fatal("expected jump prompt to close")
+	}
+	if next.seekPending {
+		t.Fatal("expected no pending seek")
+	}
+	if next.saveMsg == "" {
+		t.Fatal("expected status message for invalid timestamp")
+	}
+}
+
+func TestJumpInputEscCancels(t *testing.T) {
+	m := Model{jumpMode: true, jumpInput: newJumpInput()}
+	m.jumpInput.SetValue("1:00")
+
+	next, cmd := m.handleMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil {
+		t.Fatal("expected no command when cancelling jump prompt")
+	}
+	if next.jumpMode || next.quitting {
+		t.Fatal("expected esc to close the prompt without quitting")
+	}
+}
+
+func TestVolumeInputSetsExactVolumeAndIgnoresOtherKeys(t *testing.T) {
+	m := Model{player: new(player.Player), keys: defaultKeyMap()}
+	m, cmd := m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("V")})
+	if !m.volumeMode || cmd == nil {
+		t.Fatal("expected V to open the volume prompt with a timeout")
+	}
+	for _, r := range "1q2m5" {
+		m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if got := m.volumeInput.Value(); got != "125" || m.quitting || m.muted {
+		t.Fatalf("value = %q, quitting = %v, muted = %v; want only digits typed", got, m.quitting, m.muted)
+	}
+	stale := m.volumeSeq - 1
+	m, _ = m.handleMsg(volumeInputTimeoutMsg{seq: stale})
+	if !m.volumeMode {
+		t.Fatal("expected a timeout from before the last key press to be ignored")
+	}
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.volumeMode {
+		t.Fatal("expected enter to close the prompt")
+	}
+	if got := m.volume * m.boost; got < 1.249 || got > 1.251 {
+		t.Fatalf("level = %v, want 1.25", got)
+	}
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("_")})
+	if got := m.volume * m.boost; got < 1.239 || got > 1.241 {
+		t.Fatalf("level after fine step = %v, want 1.24", got)
+	}
+}
+
+func TestTrackAdvancedMsgMovesQueueWithoutNewPlayer(t *testing.T) {
+	p := new(player.Player)
+	q := queue.New([]queue.Track{
+		{Title: "One", Path: "one.flac", State: queue.Playing},
+		{Title: "Two", Path: "two.flac", State: queue.Ready},
+		{Title: "Three", Path: "three.flac", State: queue.Ready},
+	})
+	q.SetCurrentIndex(0)
+
+	m := Model{player: p, queue: q, elapsed: 42 * time.Second}
+	next, _ := m.handleMsg(trackAdvancedMsg{player: p, path: "two.flac"})
+	if next.player != p {
+		t.Fatal("expected gapless advance to keep the same player")
+	}
+	if got := next.queue.CurrentIndex(); got != 1 {
+		t.Fatalf("expected current index 1, got %d", got)
+	}
+	if q.Track(0).State != queue.Done || q.Track(1).State != queue.Playing {
+		t.Fatalf("expected states Done/Playing, got %v/%v", q.Track(0).State, q.Track(1).State)
+	}
+	if next.elapsed != 0 {
+		t.Fatalf("expected elapsed reset, got %v", next.elapsed)
+	}
+}
+
+func TestQueueExportEntriesPreferURLForRemoteTracks(t *testing.T) {
+	local := filepath.Join("music", "one.flac")
+	q := queue.New([]queue.Track{
+		{Title: "Local", Path: local, State: queue.Playing},
+		{Title: "Downloaded", URL: "https://example.com/a", Path: "/tmp/climp-1.wav", State: queue.Ready},
+		{Title: "Pending", URL: "https://example.com/b", State: queue.Pending},
+	})
+
+	entries := queueExportEntries(q)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Path != local || entries[0].URL != "" {
+		t.Fatalf("expected local path entry, got %#v", entries[0])
+	}
+	if entries[1].URL != "https://example.com/a" || entries[1].Path != "" {
+		t.Fatalf("expected downloaded track written by URL, got %#v", entries[1])
+	}
+	if entries[2].URL != "https://example.com/b" {
+		t.Fatalf("expected pending track written by URL, got %#v", entries[2])
+	}
+	if got := queueExportDir(q); got != "music" {
+		t.Fatalf("expected export dir music, got %q", got)
+	}
+}
+
+func TestVolumeKeysAdjustOnlyTheCurrentQueueTrack(t *testing.T) {
+	p := new(player.Player)
+	p.SetVolume(0.6)
+	q := queue.New([]queue.Track{
+		{Title: "One", Path: "one.flac", State: queue.Playing},
+		{Title: "Two", Path: "two.flac", State: queue.Ready},
+	})
+	m := Model{player: p, queue: q, queueList: newQueueList(50), volume: 0.6, boost: 1, masterVolume: 0.6, keys: defaultKeyMap()}
+	m.syncQueueList()
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("-")})
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("-")})
+	if got := p.Volume(); got < 0.49 || got > 0.51 {
+		t.Fatalf("expected the volume keys to lower the player to 0.5, got %v", got)
+	}
+	if got := q.Track(0).VolumeOffset; got > -0.09 || got < -0.11 {
+		t.Fatalf("expected track offset -0.1, got %v", got)
+	}
+	if m.masterVolume != 0.6 {
+		t.Fatalf("expected session volume to stay 0.6, got %v", m.masterVolume)
+	}
+
+	m, _ = m.handleMsg(trackAdvancedMsg{player: p, path: "two.flac"})
+	if got := p.Volume(); got != 0.6 {
+		t.Fatalf("expected next track at the session volume, got %v", got)
+	}
+
+	q.SetCurrentIndex(0)
+	m.applyTrackVolume()
+	if got := m.volume; got < 0.49 || got > 0.51 {
+		t.Fatalf("expected track one's offset restored to 0.5, got %v", got)
+	}
+}
+
+func TestQueueAdvanceKeepsSpeedSwitchedBackTo1x(t *testing.T) {
+	// Players open at the speed saved at launch, 2x here.
+	var opened *player.Player
+	restore := openPlayer
+	t.Cleanup(func() { openPlayer = restore })
+	openPlayer = func(string) (*player.Player, error) {
+		opened = new(player.Player)
+		opened.SetSpeed(player.Speed2x)
+		return opened, nil
+	}
+
+	p := new(player.Player)
+	p.SetSpeed(player.Speed2x)
+	q := queue.New([]queue.Track{
+		{Title: "One", Path: "one.flac", State: queue.Playing},
+		{Title: "Two", Path: "two.flac", State: queue.Ready},
+	})
+	m := Model{player: p, queue: q, queueList: newQueueList(50), speed: player.Speed2x, keys: defaultKeyMap()}
+	m.syncQueueList()
+
+	// 2x -> 0.5x -> 1x.
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if m.speed != player.Speed1x {
+		t.Fatalf("speed = %v after two presses, want 1x", m.speed)
+	}
+
+	m, _ = m.skipToNext()
+	if m.player != opened || q.CurrentIndex() != 1 {
+		t.Fatalf("expected the queue to advance to a new player, at track %d", q.CurrentIndex()+1)
+	}
+	if got := opened.Speed(); got != player.Speed1x {
+		t.Fatalf("next track plays at %v, want the session's 1x", got)
+	}
+}
+
+func TestSkipSilenceKeyIgnoredForLiveStreams(t *testing.T) {
+	m := Model{player: new(player.Player), keys: defaultKeyMap()}
+	next, _ := m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	if next.skipSilence || next.player.SkipSilence() {
+		t.Fatal("expected skip silence to stay off for a player that cannot seek")
+	}
+}
+
+func TestRightTimeCountsDownWhenShowingRemaining(t *testing.T) {
+	m := Model{}
+	if got := m.rightTime(90*time.Second, 4*time.Minute); got != "4:00" {
+		t.Fatalf("rightTime() = %q, want the duration", got)
+	}
+	m.showRemaining = true
+	if got := m.rightTime(90*time.Second+time.Second/2, 4*time.Minute); got != "-2:30" {
+		t.Fatalf("rightTime() = %q, want -2:30", got)
+	}
+	if got := m.rightTime(5*time.Minute, 4*time.Minute); got != "-0:00" {
+		t.Fatalf("rightTime() past the end = %q, want -0:00", got)
+	}
+	if got := m.rightTime(time.Minute, 0); got != "0:00" {
+		t.Fatalf("rightTime() without a duration = %q, want 0:00", got)
+	}
+}
+
+func TestRemainingTimeKeepsLiveIndicator(t *testing.T) {
+	m := Model{player: new(player.Player), width: 80, height: 24, keys: defaultKeyMap(), showRemaining: true}
+	m.rebuildMidCache()
+	if !strings.Contains(m.midCache, "LIVE") || strings.Contains(m.midCache, "-0:00") {
+		t.Fatalf("expected LIVE and no countdown for a live stream, got %q", m.midCache)
+	}
+	if next, _ := m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")}); next.showRemaining {
+		t.Fatal("expected E to switch back to the duration")
+	}
+}
+
+func TestControlCommandsAnswerWithJSON(t *testing.T) {
+	m := Model{player: new(player.Player), keys: defaultKeyMap()}
+	m.metadata.Title = "Radio"
+	run := func(command string) ControlReply {
+		t.Helper()
+		reply := make(chan []byte, 1)
+		m, _ = m.handleMsg(ControlMsg{Command: command, Reply: reply})
+		var r ControlReply
+		if err := json.Unmarshal(<-reply, &r); err != nil {
+			t.Fatalf("%s: reply is not JSON: %v", command, err)
+		}
+		return r
+	}
+
+	r := run("status")
+	if !r.OK || r.Status == nil || r.Status.Title != "Radio" || !r.Status.Live || r.Status.State != "playing" {
+		t.Fatalf("status = %+v", r)
+	}
+	if r := run("volume 40"); !r.OK || r.Status.Volume != 40 {
+		t.Fatalf("volume 40 = %+v, want volume 40 in the status", r)
+	}
+	if r := run("volume 400"); r.OK || r.Error == "" {
+		t.Fatalf("volume 400 = %+v, want an error", r)
+	}
+	if r := run("seek +10"); r.OK || !strings.Contains(r.Error, "live") {
+		t.Fatalf("seek on a live stream = %+v, want an error", r)
+	}
+	if r := run("next"); r.OK {
+		t.Fatalf("next without a queue = %+v, want an error", r)
+	}
+	if r := run("dance"); r.OK || !strings.Contains(r.Error, "unknown command") {
+		t.Fatalf("dance = %+v, want an unknown command error", r)
+	}
+}
+
+func TestRecordingShowsInStatusLineUntilPlayerStops(t *testing.T) {
+	m := Model{player: new(player.Player), width: 80, height: 24, keys: defaultKeyMap(), recordPath: "radio.wav", recordElapsed: 75 * time.Second}
+	m.rebuildMidCache()
+	if !strings.Contains(m.midCache, "● REC 1:15") {
+		t.Fatal("expected recording indicator with capture time in status line")
+	}
+
+	// The player is not recording, as after a track change closed it.
+	m.syncRecording()
+	if m.recordPath != "" || m.saveMsg != "Recording saved to radio.wav" {
+		t.Fatalf("after sync: recordPath=%q saveMsg=%q", m.recordPath, m.saveMsg)
+	}
+}
+
+func TestRenderWaveformScalesPeaksAndLeavesUnscannedBlank(t *testing.T) {
+	peaks := []player.WaveformPeak{{Min: -1, Max: 1}, {Min: -0.25, Max: 0.25}, {}, {Min: -0.5, Max: 0.5}}
+	out := renderWaveform(peaks, 6, 6, 0)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != waveformRows {
+		t.Fatalf("got %d rows, want %d", len(lines), waveformRows)
+	}
+	top := []rune(strings.TrimPrefix(lines[0], "  "))
+	bottom := []rune(strings.TrimPrefix(lines[1], "  "))
+	if string(top) != "█     " || string(bottom) != "█▄▁█  " {
+		t.Fatalf("waveform rows = %q / %q", string(top), string(bottom))
+	}
+}
+
+func TestWaveformIgnoresStaleScans(t *testing.T) {
+	m := Model{wave: waveform{seq: 2, path: "b.flac"}}
+	next, cmd := m.handleWaveform(waveformMsg{seq: 1, peaks: []player.WaveformPeak{{Max: 1}}, total: 4})
+	if cmd != nil || next.wave.peaks != nil {
+		t.Fatal("expected a scan for an earlier track to be dropped")
+	}
+	next, cmd = m.handleWaveform(waveformMsg{seq: 2, peaks: []player.WaveformPeak{{Max: 1}}, total: 4, done: true})
+	if cmd != nil || len(next.wave.peaks) != 1 || next.wave.total != 4 {
+		t.Fatalf("expected the finished scan stored, got %+v", next.wave)
+	}
+}
+
+func TestMuteKeyTogglesStatusLine(t *testing.T) {
+	p := new(player.Player)
+	p.SetVolume(0.4)
+	m := Model{player: p, volume: 0.4, width: 80, height: 24, keys: defaultKeyMap()}
+
+	next, _ := m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if !next.muted || next.volume != 0 {
+		t.Fatalf("expected muted at volume 0, got muted=%v volume=%v", next.muted, next.volume)
+	}
+	next.rebuildMidCache()
+	if !strings.Contains(next.midCache, "muted") {
+		t.Fatal("expected muted indicator in status line")
+	}
+
+	next, _ = next.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if next.muted || next.volume != 0.4 {
+		t.Fatalf("expected volume 0.4 restored, got muted=%v volume=%v", next.muted, next.volume)
+	}
+}
+
+func TestStereoWidthKeyCyclesPresetsInStatusLine(t *testing.T) {
+	p := new(player.Player)
+	m := Model{player: p, width: 80, height: 24, keys: defaultKeyMap()}
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("M")})
+	if p.StereoWidth() != 1.5 {
+		t.Fatalf("StereoWidth() = %v, want 1.5", p.StereoWidth())
+	}
+	m.rebuildMidCache()
+	if !strings.Contains(m.midCache, "[width 150%]") {
+		t.Fatal("expected the stereo width in the status line")
+	}
+
+	for range len(player.StereoWidths) - 1 {
+		m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("M")})
+	}
+	m.rebuildMidCache()
+	if p.StereoWidth() != 1 || strings.Contains(m.midCache, "width") {
+		t.Fatalf("expected normal width with no label after a full cycle, got %v", p.StereoWidth())
+	}
+}
+
+func TestVolumeUpContinuesPastFullAsBoost(t *testing.T) {
+	p := new(player.Player)
+	p.SetVolume(1)
+	m := Model{player: p, volume: 1, boost: 1, width: 80, height: 24, keys: defaultKeyMap()}
+
+	next, _ := m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("=")})
+	if next.volume != 1 || next.boost <= 1 {
+		t.Fatalf("expected boost above 1 at full volume, got volume=%v boost=%v", next.volume, next.boost)
+	}
+	next.rebuildMidCache()
+	if !strings.Contains(next.midCache, "vol 105%") {
+		t.Fatal("expected boosted volume in status line")
+	}
+}
+
+func TestProgressBarPositionMatchesRenderedBar(t *testing.T) {
+	for _, compact := range []bool{false, true} {
+		m := Model{metadata: player.Metadata{Title: "Song", Artist: "Artist"}, duration: time.Minute, width: 80, height: 24, compact: compact}
+		m.rebuildHeaderCache()
+		m.rebuildMidCache()
+		if m.barWidth == 0 {
+			t.Fatalf("compact=%v: expected a bar position", compact)
+		}
+		lines := strings.Split(m.View(), "\n")
+		row := []rune(lines[m.barRow])
+		if row[m.barCol] != '●' || row[m.barCol-1] != ' ' || row[m.barCol+m.barWidth-1] != '─' || row[m.barCol+m.barWidth] != ' ' {
+			t.Fatalf("compact=%v: bar at col %d width %d does not match %q", compact, m.barCol, m.barWidth, string(row))
+		}
+	}
+}
+
+func TestClickOnUnseekableTrackTogglesPause(t *testing.T) {
+	m := Model{duration: time.Minute, width: 80, height: 24}
+	m.rebuildHeaderCache()
+	m.rebuildMidCache()
+	m.player = new(player.Player)
+
+	click := tea.MouseMsg{X: m.barCol + 1, Y: m.barRow, Action: tea.MouseActionRelease, Button: tea.MouseButtonLeft}
+	next, _ := m.handleMsg(click)
+	if !next.paused {
+		t.Fatal("expected a click on a track that cannot seek to pause")
+	}
+	if _, ok := next.progressBarFraction(click); ok {
+		t.Fatal("expected no seek position on a track that cannot seek")
+	}
+}
+
+func TestPlaceBarMarkerKeepsPlayhead(t *testing.T) {
+	bar := renderProgressBar(0, 10, 10)
+	got := placeBarMarker(bar, 5, 10, '[')
+	if got != "●────[────" {
+		t.Fatalf("unexpected marked bar %q", got)
+	}
+	if got := placeBarMarker(bar, 0, 10, '['); got != bar {
+		t.Fatalf("expected playhead to win over marker, got %q", got)
+	}
+}
+
+func TestLoopKeysIgnoredForLiveStreams(t *testing.T) {
+	m := Model{player: new(player.Player)}
+	m.setLoopStart()
+	m.setLoopEnd()
+	if m.loopStartSet || m.loopActive {
+		t.Fatal("expected no loop for non-seekable player")
+	}
+}
+
+func TestStartAtRejectsLiveStreams(t *testing.T) {
+	m := Model{player: new(player.Player)}
+	if _, err := m.StartAt(time.Minute); err == nil || !strings.Contains(err.Error(), "live stream") {
+		t.Fatalf("StartAt() error = %v, want a live stream error", err)
+	}
+}
+
+func TestSleepTimerKeyStepsThroughPresetsThenCancels(t *testing.T) {
+	m := Model{}
+	for _, want := range sleepPresets {
+		if cmd := m.cycleSleepTimer(); cmd == nil {
+			t.Fatalf("expected timer command for %v preset", want)
+		}
+		if left := time.Until(m.sleepDeadline); left <= want-time.Second || left > want {
+			t.Fatalf("expected about %v left, got %v", want, left)
+		}
+	}
+	if cmd := m.cycleSleepTimer(); cmd != nil {
+		t.Fatal("expected no command after cycling past the last preset")
+	}
+	if !m.sleepDeadline.IsZero() {
+		t.Fatal("expected timer to be cancelled")
+	}
+}
+
+func TestSleepFadeIgnoresStaleTimerAndRestoresVolumeOnQuit(t *testing.T) {
+	p := new(player.Player)
+	p.SetVolume(0.6)
+	m := Model{player: p}.WithSleepTimer(time.Minute)
+
+	next, cmd := m.handleSleepTimer(sleepTimerMsg{seq: m.sleepSeq - 1})
+	if cmd != nil || next.sleepFading {
+		t.Fatal("expected stale timer message to be ignored")
+	}
+
+	next, cmd = m.handleSleepTimer(sleepTimerMsg{seq: m.sleepSeq})
+	if cmd == nil || !next.sleepFading {
+		t.Fatal("expected fade-out to start")
+	}
+	next, _ = next.handleSleepFade(sleepFadeMsg{seq: next.sleepSeq, step: sleepFadeSteps / 2})
+	if got := p.Volume(); got < 0.29 || got > 0.31 {
+		t.Fatalf("expected volume halfway through fade, got %v", got)
+	}
+
+	next, _ = next.handleSleepFade(sleepFadeMsg{seq: next.sleepSeq, step: sleepFadeSteps})
+	if !next.quitting {
+		t.Fatal("expected quit after fade-out")
+	}
+	if got := p.Volume(); got != 0.6 {
+		t.Fatalf("expected volume restored to 0.6 for saving, got %v", got)
+	}
+}
+
+func TestQueueFilterMapsSelectionToQueueIndex(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "Alpha", Path: "a.flac", State: queue.Playing},
+		{Title: "Bravo", Path: "b.flac", State: queue.Ready},
+		{Title: "Charlie", Path: "c.flac", State: queue.Ready},
+		{Title: "Delta", Path: "d.flac", State: queue.Ready},
+	})
+	q.SetCurrentIndex(1)
+
+	m := Model{queue: q, queueList: newQueueList(50)}
+	m.syncQueueList()
+	m.queueList.SetFilterText("alpha")
+	if got := m.selectedQueueIndex(); got != 0 {
+		t.Fatalf("expected filtered selection to map to queue index 0, got %d", got)
+	}
+
+	// A queue change while filtered must keep the matches visible.
+	q.SetTrackState(2, queue.Done)
+	m.syncQueueList()
+	if got := len(m.queueList.VisibleItems()); got != 1 {
+		t.Fatalf("expected 1 visible match after sync, got %d", got)
+	}
+	if got := m.selectedQueueIndex(); got != 0 {
+		t.Fatalf("expected selection to stay on queue index 0, got %d", got)
+	}
+}
+
+func TestQueueFilterKeysDoNotReachPlayback(t *testing.T) {
+	p := new(player.Player)
+	q := queue.New([]queue.Track{
+		{Title: "One", Path: "one.flac", State: queue.Playing},
+		{Title: "Two", Path: "two.flac", State: queue.Ready},
+	})
+	m := Model{player: p, queue: q, queueList: newQueueList(50), volume: p.Volume(), keys: defaultKeyMap()}
+	m.syncQueueList()
+	m.queueList.SetFilterState(list.Filtering)
+
+	for _, r := range "q m" {
+		m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if m.quitting || m.muted || m.paused {
+		t.Fatal("expected filter input to swallow playback keys")
+	}
+	if got := m.queueList.FilterValue(); got != "q m" {
+		t.Fatalf("expected filter text %q, got %q", "q m", got)
+	}
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.quitting {
+		t.Fatal("expected esc to cancel the filter without quitting")
+	}
+	if m.queueList.FilterState() != list.Unfiltered {
+		t.Fatalf("expected filter cleared, got %v", m.queueList.FilterState())
+	}
+}
+
+func TestChapterHeaderFollowsElapsed(t *testing.T) {
+	m := Model{metadata: player.Metadata{
+		Title: "Book",
+		Chapters: []player.Chapter{
+			{Title: "Opening", Start: 0},
+			{Title: "Part Two", Start: 90 * time.Second},
+		},
+	}}
+	m.elapsed = 95 * time.Second
+	m.invalidate(dirtyMid)
+	m.flushCaches()
+	if m.chapter != 1 {
+		t.Fatalf("expected chapter index 1, got %d", m.chapter)
+	}
+	if !strings.Contains(m.headerCache, "Part Two (2/2)") {
+		t.Fatalf("expected current chapter in header, got %q", m.headerCache)
+	}
+	if got := chapterAt(m.metadata.Chapters, 30*time.Second); got != 0 {
+		t.Fatalf("expected chapter 0 at 30s, got %d", got)
+	}
+	if got := chapterAt(nil, time.Minute); got != -1 {
+		t.Fatalf("expected -1 without chapters, got %d", got)
+	}
+}
+
+func TestRenderPeakLevelShowsClipWarning(t *testing.T) {
+	if got := renderPeakLevel(-6.04, 0); got != "-6.0 dB" {
+		t.Fatalf("renderPeakLevel(-6.04, 0) = %q", got)
+	}
+	if got := renderPeakLevel(-96, 0); got != "-inf dB" {
+		t.Fatalf("renderPeakLevel(silence) = %q", got)
+	}
+	if got := renderPeakLevel(0, 3); got != "CLIP 3  0.0 dB" {
+		t.Fatalf("renderPeakLevel(0, 3) = %q", got)
+	}
+}
+
+func TestRenderChannelMetersCollapsesMono(t *testing.T) {
+	stereo := renderChannelMeters(1, 0)
+	if stereo != "L ██████  R ░░░░░░" {
+		t.Fatalf("renderChannelMeters(1, 0) = %q", stereo)
+	}
+	mono := renderChannelMeters(0.1, 0.1)
+	if strings.Contains(mono, "L ") || !strings.Contains(mono, "M ") {
+		t.Fatalf("renderChannelMeters(mono) = %q, want a single meter", mono)
+	}
+	if lipgloss.Width(mono) != lipgloss.Width(stereo) {
+		t.Fatalf("mono meter width %d, stereo %d; want equal", lipgloss.Width(mono), lipgloss.Width(stereo))
+	}
+}
+
+func TestWriteOSC52EncodesAndWrapsForTmux(t *testing.T) {
+	var b strings.Builder
+	if err := writeOSC52(&b, "https://x.test/a", false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b]52;c;aHR0cHM6Ly94LnRlc3QvYQ==\x07"; b.String() != want {
+		t.Fatalf("writeOSC52() = %q, want %q", b.String(), want)
+	}
+	b.Reset()
+	_ = writeOSC52(&b, "a", true)
+	if want := "\x1bPtmux;\x1b\x1b]52;c;YQ==\x07\x1b\\"; b.String() != want {
+		t.Fatalf("writeOSC52(tmux) = %q, want %q", b.String(), want)
+	}
+}
+
+func TestSavePromptPicksFormatOrCancels(t *testing.T) {
+	p := new(player.Player)
+	m := Model{player: p, sourcePath: "/tmp/climp-1.wav", sourceTitle: "Song", keys: defaultKeyMap()}
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if !m.savePrompt {
+		t.Fatal("expected s to open the save format prompt")
+	}
+	m, cmd := m.handleMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.savePrompt || m.saving || m.quitting || cmd != nil {
+		t.Fatal("expected esc to close the prompt without saving or quitting")
+	}
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m, cmd = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if m.savePrompt || !m.saving || cmd == nil {
+		t.Fatal("expected f to start a FLAC save")
+	}
+	if m.saveMsg != "Saving as FLAC..." {
+		t.Fatalf("expected progress message, got %q", m.saveMsg)
+	}
+}
+
+func TestMoveSelectedReordersQueueAndFollowsTrack(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "One", Path: "one.flac", State: queue.Playing},
+		{Title: "Two", Path: "two.flac", State: queue.Ready},
+		{Title: "Three", Path: "three.flac", State: queue.Ready},
+		{Title: "Four", Path: "four.flac", State: queue.Ready},
+	})
+	m := Model{player: new(player.Player), queue: q, queueList: newQueueList(50), keys: defaultKeyMap()}
+	m.syncQueueList()
+	m.queueList.Select(2) // "Four"
+
+	for range 2 {
+		m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'K'}})
+	}
+	var titles []string
+	for i := 0; i < q.Len(); i++ {
+		titles = append(titles, q.Track(i).Title)
+	}
+	if got := strings.Join(titles, ","); got != "One,Four,Two,Three" {
+		t.Fatalf("queue order = %s, want One,Four,Two,Three", got)
+	}
+	if got := m.selectedQueueIndex(); got != 1 {
+		t.Fatalf("expected selection to follow the moved track to index 1, got %d", got)
+	}
+
+	// The next press would move it past the playing track.
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'K'}})
+	if q.Track(0).Title != "One" || q.CurrentIndex() != 0 {
+		t.Fatal("expected the playing track to stay first and current")
+	}
+}
+
+func TestPruneKeyClearsPlayedAndFailedButKeepsCurrent(t *testing.T) {
+	cleaned := 0
+	cleanup := func() { cleaned++ }
+	q := queue.New([]queue.Track{
+		{Title: "One", Path: "one.flac", State: queue.Done, Cleanup: cleanup},
+		{Title: "Two", Path: "two.flac", State: queue.Failed},
+		{Title: "Three", Path: "three.flac", State: queue.Done},
+		{Title: "Four", Path: "four.flac", State: queue.Done, Cleanup: cleanup},
+		{Title: "Five", Path: "five.flac", State: queue.Ready},
+	})
+	q.SetCurrentIndex(2)
+	q.EnableShuffle()
+	m := Model{player: new(player.Player), queue: q, queueList: newQueueList(50), keys: defaultKeyMap()}
+	m.syncQueueList()
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	var titles []string
+	for i := 0; i < q.Len(); i++ {
+		titles = append(titles, q.Track(i).Title)
+	}
+	if got := strings.Join(titles, ","); got != "Three,Five" {
+		t.Fatalf("queue = %s, want the finished current track and Five", got)
+	}
+	if q.Current().Title != "Three" || cleaned != 2 || m.saveMsg != "Cleared 3 tracks" {
+		t.Fatalf("current = %s, cleanups = %d, msg = %q", q.Current().Title, cleaned, m.saveMsg)
+	}
+	if got := q.PlaybackOrder(); len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Fatalf("shuffle order = %v, want the current track then Five", got)
+	}
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	if q.Len() != 2 || m.saveMsg != "No played or failed tracks to clear" {
+		t.Fatalf("second press: len = %d, msg = %q", q.Len(), m.saveMsg)
+	}
+}
+
+func TestPlayNextTurnsSingleTrackIntoQueue(t *testing.T) {
+	dir := t.TempDir()
+	next := filepath.Join(dir, "Next Song.flac")
+	if err := os.WriteFile(next, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := Model{player: new(player.Player), sourceTitle: "Now", keys: defaultKeyMap(), width: 80, height: 24}
+
+	m, _ = m.playNext(`"` + next + `"`)
+	if m.queue == nil || m.queue.Len() != 2 {
+		t.Fatalf("expected a two-track queue, got %v", m.queue)
+	}
+	if cur, nxt := m.queue.Current(), m.queue.Next(); cur.Title != "Now" || cur.State != queue.Playing || nxt.Path != next || nxt.Title != "Next Song" {
+		t.Fatalf("queue = %+v then %+v", cur, nxt)
+	}
+
+	m, _ = m.playNext("https://example.com/watch?v=1")
+	if t1 := m.queue.Next(); t1.URL != "https://example.com/watch?v=1" || m.queue.Len() != 3 {
+		t.Fatalf("expected the URL to play next, got %+v", t1)
+	}
+
+	m, _ = m.playNext(filepath.Join(dir, "missing.mp3"))
+	if m.queue.Len() != 3 || !strings.Contains(m.saveMsg, "no such file") {
+		t.Fatalf("expected a missing file to be rejected, got %q", m.saveMsg)
+	}
+}
+
+func TestStartingQueueCancelsPlaylistExtraction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := Model{player: new(player.Player), sourceTitle: "Now", originalURL: "https://example.com/list", extractCtx: ctx, stopExtract: cancel, keys: defaultKeyMap(), width: 80, height: 24}
+
+	m, _ = m.playNext("https://example.com/watch?v=1")
+	if ctx.Err() == nil {
+		t.Fatal("expected the extraction to be cancelled once play-next started a queue")
+	}
+	m, _ = m.handlePlaylistExtracted(playlistExtractedMsg{err: ctx.Err()})
+	if m.queue.Len() != 2 {
+		t.Fatalf("expected a cancelled extraction to leave the queue alone, got %d tracks", m.queue.Len())
+	}
+}
+
+func TestNotifyTrackCmdRequiresFlagAndStripsEscapes(t *testing.T) {
+	m := Model{metadata: player.Metadata{Title: "Song"}}
+	if m.notifyTrackCmd() != nil {
+		t.Fatal("expected no notification without --notify")
+	}
+	m = m.WithNotifications(true)
+	if m.notifyTrackCmd() == nil {
+		t.Fatal("expected a notification command with --notify")
+	}
+	if got := notificationText("Bad\x07\x1b]9;x Title\n"); got != "Bad]9;x Title" {
+		t.Fatalf("unexpected sanitized text %q", got)
+	}
+}
+
+func TestTerminalOutputSerializesStringWrites(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	out := &lockedFile{File: f}
+	if _, ok := Output().(interface{ Fd() uintptr }); !ok {
+		t.Fatal("expected Output to keep the terminal's Fd for Bubble Tea")
+	}
+
+	// A renderer frame is being written: the escape must wait for it.
+	out.mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.WriteString(out, "\x1b]9;Song\x07")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("io.WriteString bypassed the output lock")
+	case <-time.After(20 * time.Millisecond):
+	}
+	out.mu.Unlock()
+	<-done
+	if data, _ := os.ReadFile(f.Name()); string(data) != "\x1b]9;Song\x07" {
+		t.Fatalf("wrote %q", data)
+	}
+}
+
+func TestCompactLayoutRendersOneLine(t *testing.T) {
+	m := Model{
+		metadata: player.Metadata{Title: "Song", Artist: "Artist"},
+		elapsed:  30 * time.Second,
+		duration: 2 * time.Minute,
+		width:    80,
+		height:   5,
+	}
+	m.invalidate(dirtyHeader | dirtyMid)
+	m.flushCaches()
+	view := m.View()
+	if first := strings.Split(view, "\n")[0]; !strings.Contains(first, "Song") || !strings.Contains(first, "0:30") || !strings.Contains(first, "2:00") {
+		t.Fatalf("expected title and times on one line, got %q", first)
+	}
+	if strings.Contains(view, "Artist") {
+		t.Fatal("expected header section to be skipped in compact layout")
+	}
+
+	m.height = 24
+	m.invalidate(dirtyHeader | dirtyMid)
+	m.flushCaches()
+	if !strings.Contains(m.View(), "Artist") {
+		t.Fatal("expected full layout on a tall terminal")
+	}
+	if !m.WithCompact(true).compactLayout() {
+		t.Fatal("expected --compact to force the one-line layout")
+	}
+}
+
+func TestSetTrackInfoUsesCueTrackTitle(t *testing.T) {
+	track := &queue.Track{Title: "Artist - Second", Path: "album.flac", Start: 3 * time.Minute, End: 7 * time.Minute}
+	var m Model
+	m.setTrackInfo(track)
+	if m.metadata.Title != "Artist - Second" || m.metadata.Chapters != nil {
+		t.Fatalf("expected cue track title without chapters, got %+v", m.metadata)
+	}
+	if m.canPlaySection(track) {
+		t.Fatal("expected no section switch without a player")
+	}
+	if isSection(&queue.Track{Path: "album.flac"}) {
+		t.Fatal("expected a whole-file track not to be a section")
+	}
+}
+
+func TestTransientDownloadFailureRetriesThenFails(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "One", State: queue.Playing},
+		{Title: "Two", URL: "https://example.com/two", State: queue.Downloading},
+	})
+	m := Model{queue: q}
+	netErr := fmt.Errorf("yt-dlp failed: %w", downloader.ErrNetwork)
+
+	for attempt := 1; attempt < maxDownloadAttempts; attempt++ {
+		var cmd tea.Cmd
+		m, cmd = m.handleTrackDownloaded(trackDownloadedMsg{index: 1, url: "https://example.com/two", err: netErr})
+		if cmd == nil || q.Track(1).State != queue.Downloading || q.Track(1).Attempts != attempt {
+			t.Fatalf("attempt %d: expected a scheduled retry, got %+v", attempt, *q.Track(1))
+		}
+	}
+	if desc := m.trackToItem(q.Track(1), 1, 2).desc; desc != "retrying (3/3)..." {
+		t.Fatalf("expected retry description, got %q", desc)
+	}
+	m, _ = m.handleTrackDownloaded(trackDownloadedMsg{index: 1, url: "https://example.com/two", err: netErr})
+	if q.Track(1).State != queue.Failed {
+		t.Fatal("expected the track to fail once out of attempts")
+	}
+
+	q.SetTrackState(1, queue.Downloading)
+	q.SetTrackAttempts(1, 0)
+	m, _ = m.handleTrackDownloaded(trackDownloadedMsg{index: 1, url: "https://example.com/two", err: downloader.ErrUnsupportedScheme})
+	if q.Track(1).State != queue.Failed || q.Track(1).Attempts != 0 {
+		t.Fatal("expected a non-retryable error to fail without retrying")
+	}
+}
+
+func TestConcurrentDownloadsFillSlotsAndMatchResultsByURL(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "One", State: queue.Playing},
+		{Title: "Two", URL: "https://example.com/two"},
+		{Title: "Three", URL: "https://example.com/three"},
+		{Title: "Four", URL: "https://example.com/four"},
+		{Title: "Five", URL: "https://example.com/five"},
+	})
+	m := Model{queue: q}.WithConcurrentDownloads(2)
+
+	if cmd := m.startNextDownload(); cmd == nil {
+		t.Fatal("expected downloads to start")
+	}
+	var states []queue.TrackState
+	for i := 1; i < q.Len(); i++ {
+		states = append(states, q.Track(i).State)
+	}
+	if states[0] != queue.Downloading || states[1] != queue.Downloading || states[2] != queue.Pending {
+		t.Fatalf("expected the next two tracks downloading, got %v", states)
+	}
+
+	// "Two" is removed mid-download; "Three" reports back by its old index.
+	q.Remove(1)
+	m, _ = m.handleTrackDownloaded(trackDownloadedMsg{index: 2, url: "https://example.com/three", path: "three.wav"})
+	if got := q.Track(1); got.Title != "Three" || got.State != queue.Ready || got.Path != "three.wav" {
+		t.Fatalf("expected the result applied to Three, got %+v", *got)
+	}
+	if q.Track(2).State != queue.Downloading || q.Track(3).State != queue.Pending {
+		t.Fatal("expected the freed slot to start the next upcoming track only")
+	}
+}
+
+func TestKeepModeCopiesOldTracksBeforeCleanup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "audio.wav")
+	if err := os.WriteFile(src, []byte("RIFF-data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cleaned := 0
+	q := queue.New([]queue.Track{
+		{Title: "First", URL: "https://example.com/1", Path: src, State: queue.Done, Cleanup: func() { cleaned++ }},
+		{Title: "Saved", URL: "https://example.com/2", Path: "saved.wav", State: queue.Done, Cleanup: func() { cleaned++ }},
+		{Title: "Second", State: queue.Done},
+		{Title: "Third", State: queue.Playing},
+	})
+	q.SetCurrentIndex(3)
+	m := Model{queue: q, savedPaths: map[string]bool{"saved.wav": true}}.WithKeepDownloads(true)
+
+	m.cleanupOldTracks()
+	if cleaned != 2 || q.Track(0).Cleanup != nil {
+		t.Fatalf("expected both old tracks cleaned up, got %d", cleaned)
+	}
+	kept := filepath.Join(home, "Music", "climp", "First.wav")
+	if got, err := os.ReadFile(kept); err != nil || string(got) != "RIFF-data" {
+		t.Fatalf("kept file = %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "Music", "climp", "Saved.wav")); err == nil {
+		t.Fatal("expected a track saved with s not to be kept again")
+	}
+	if !strings.Contains(m.saveMsg, "Kept First.wav") {
+		t.Fatalf("expected keep feedback, got %q", m.saveMsg)
+	}
+}
+
+func TestArtworkViewScalesCoverToRows(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+		img.Set(x, 1, color.RGBA{B: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Model{art: &artworkCache{}, metadata: player.Metadata{Artwork: buf.Bytes()}, width: 80}
+	if got := samplePixel(img, 0, 0, 2, 2); got != "#ff0000" {
+		t.Fatalf("samplePixel() = %s, want the red top row", got)
+	}
+	view := m.artworkView()
+	if lines := strings.Count(view, "\n"); lines != artworkRows {
+		t.Fatalf("expected %d art rows, got %d", artworkRows, lines)
+	}
+	if w := lipgloss.Width(strings.SplitN(view, "\n", 2)[0]); w != 2+4*artworkRows {
+		t.Fatalf("expected a 2:1 cover to be %d columns wide, got %d", 4*artworkRows, w-2)
+	}
+
+	m.metadata.Artwork = []byte("not an image")
+	if m.artworkView() != "" {
+		t.Fatal("expected undecodable artwork to fall back to the text header")
+	}
+}
+
+func TestFitImageKeepsAspectAndMinimumSize(t *testing.T) {
+	tests := []struct {
+		name                   string
+		w, h, maxCols, maxRows int
+		wantCols, wantRows     int
+	}{
+		{name: "square fills the rows", w: 600, h: 600, maxCols: 80, maxRows: 8, wantCols: 16, wantRows: 8},
+		{name: "wide shrinks to the width", w: 1000, h: 500, maxCols: 16, maxRows: 8, wantCols: 16, wantRows: 4},
+		{name: "tall stays narrow", w: 300, h: 600, maxCols: 80, maxRows: 8, wantCols: 8, wantRows: 8},
+		{name: "tiny terminal clamps", w: 600, h: 600, maxCols: 2, maxRows: 8, wantCols: 4, wantRows: 2},
+		{name: "empty image", w: 0, h: 0, maxCols: 80, maxRows: 8, wantCols: 16, wantRows: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, rows := fitImage(tt.w, tt.h, tt.maxCols, tt.maxRows)
+			if cols != tt.wantCols || rows != tt.wantRows {
+				t.Fatalf("fitImage() = %dx%d, want %dx%d", cols, rows, tt.wantCols, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestKeyRemapChangesBindingsAndHelp(t *testing.T) {
+	k := defaultKeyMap()
+	k.remap(map[string]config.KeyList{
+		"repeat":    {"R"},
+		"seek-back": {"a"},
+		"bogus":     {"y"},
+	})
+
+	if !matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")}, k.Repeat) {
+		t.Fatal("expected R to trigger repeat")
+	}
+	if matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}, k.Repeat) {
+		t.Fatal("expected r to no longer trigger repeat")
+	}
+	if got := k.Repeat.Help().Key; got != "R" {
+		t.Fatalf("repeat help key = %q, want R", got)
+	}
+	if got := k.ShortHelp()[1].Help().Key; got != "a/→" {
+		t.Fatalf("seek help key = %q, want a/→", got)
+	}
+	if got := defaultKeyMap().ShortHelp()[1].Help().Key; got != "←/→" {
+		t.Fatalf("default seek help key = %q, want ←/→", got)
+	}
+}
+
+func TestUpNextKeyReturnsCursorToTop(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "One", Path: "one.flac", State: queue.Playing},
+		{Title: "Two", Path: "two.flac", State: queue.Ready},
+		{Title: "Three", Path: "three.flac", State: queue.Ready},
+		{Title: "Four", Path: "four.flac", State: queue.Ready},
+	})
+	m := Model{player: new(player.Player), queue: q, queueList: newQueueList(50), keys: defaultKeyMap()}
+	m.syncQueueList()
+	m.queueList.Select(2)
+
+	m, _ = m.handleMsg(tea.KeyMsg{Type: tea.KeyHome})
+	if got := m.queueList.Index(); got != 0 {
+		t.Fatalf("cursor = %d, want 0", got)
+	}
+	if got := m.selectedQueueIndex(); got != 1 {
+		t.Fatalf("selected queue index = %d, want the up-next track 1", got)
+	}
+}
+
+type fakeScrobbler struct {
+	nowPlaying []scrobble.Track
+	scrobbled  []scrobble.Track
+}
+
+func (f *fakeScrobbler) NowPlaying(_ context.Context, t scrobble.Track) error {
+	f.nowPlaying = append(f.nowPlaying, t)
+	return nil
+}
+
+func (f *fakeScrobbler) Scrobble(_ context.Context, t scrobble.Track) error {
+	f.scrobbled = append(f.scrobbled, t)
+	return nil
+}
+
+func TestFinishListenScrobblesOnlyPastHalfway(t *testing.T) {
+	fake := &fakeScrobbler{}
+	track := scrobble.Track{Artist: "Artist", Title: "Song", Duration: 3 * time.Minute}
+
+	m := Model{scrobbler: fake, listen: listen{track: track, played: time.Minute, active: true}}
+	if cmd := m.finishListenCmd(); cmd != nil {
+		t.Fatal("expected no scrobble after a third of the track")
+	}
+
+	m.listen = listen{track: track, played: 2 * time.Minute, active: true}
+	cmd := m.finishListenCmd()
+	if cmd == nil {
+		t.Fatal("expected a scrobble after two thirds of the track")
+	}
+	if msg, ok := cmd().(scrobbledMsg); !ok || msg.err != nil {
+		t.Fatalf("cmd() = %#v, want scrobbledMsg without error", msg)
+	}
+	if len(fake.scrobbled) != 1 || fake.scrobbled[0].Title != "Song" {
+		t.Fatalf("scrobbled = %+v, want Song once", fake.scrobbled)
+	}
+	if m.listen.active {
+		t.Fatal("expected the listen to end")
+	}
+}
+
+func TestScrobbleFailureShowsTransientMessage(t *testing.T) {
+	m := Model{}
+	m, _ = m.handleScrobbled(scrobbledMsg{err: fmt.Errorf("listenbrainz: HTTP 500")})
+	if m.saveMsg != "Scrobble failed: listenbrainz: HTTP 500" {
+		t.Fatalf("saveMsg = %q", m.saveMsg)
+	}
+}
+
+func TestTimelineDurationsFollowQueueOrder(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "One", Path: "/music/album.flac", End: 90 * time.Second, State: queue.Playing},
+		{Title: "Two", Path: "/music/album.flac", Start: 90 * time.Second, State: queue.Ready},
+		{Title: "Three", Path: "/music/three.mp3", State: queue.Ready},
+		{Title: "Four", URL: "https://example.com/4", State: queue.Pending},
+	})
+	m := Model{queue: q}
+
+	cmd := m.toggleTimeline()
+	if !m.albumTimeline || cmd == nil {
+		t.Fatal("expected t to turn the album timeline on and probe the files on disk")
+	}
+	m, _ = m.handleTimelineDurations(timelineDurationsMsg{durations: map[string]time.Duration{
+		"/music/album.flac": 200 * time.Second,
+		"/music/three.mp3":  60 * time.Second,
+	}})
+	if got := []time.Duration{q.Track(0).Duration, q.Track(1).Duration, q.Track(2).Duration}; got[0] != 90*time.Second || got[1] != 110*time.Second || got[2] != time.Minute {
+		t.Fatalf("durations = %v, want cue sections of 90s and 110s and a 60s file", got)
+	}
+
+	// The undownloaded track caps the timeline.
+	if total := q.TotalDuration(); total != 260*time.Second {
+		t.Fatalf("TotalDuration() = %v, want 4m20s", total)
+	}
+	if start, ok := q.TimelineStart(2); !ok || start != 200*time.Second {
+		t.Fatalf("TimelineStart(2) = %v, %v", start, ok)
+	}
+	if idx, off := q.Locate(100 * time.Second); idx != 1 || off != 10*time.Second {
+		t.Fatalf("Locate(100s) = %d, %v, want track 1 at 10s", idx, off)
+	}
+	if idx, off := q.Locate(time.Hour); idx != 2 || off != time.Minute {
+		t.Fatalf("Locate past the end = %d, %v, want the end of track 2", idx, off)
+	}
+}
+
+func TestSeekStepClampsAndStepsThroughPresets(t *testing.T) {
+	m := Model{keys: defaultKeyMap()}.WithSeekStep(100 * time.Millisecond)
+	if m.seekStep != MinSeekStep {
+		t.Fatalf("seekStep = %v, want it clamped to %v", m.seekStep, MinSeekStep)
+	}
+	m = m.WithSeekStep(7 * time.Second)
+	m.adjustSeekStep(1)
+	if m.seekStep != 10*time.Second || m.keys.seekStep != 10*time.Second {
+		t.Fatalf("seekStep = %v, want the next preset up from 7s", m.seekStep)
+	}
+	if desc := m.keys.SeekForward.Help().Desc; desc != "seek +10s" {
+		t.Fatalf("help = %q, want it to show the step", desc)
+	}
+	m.adjustSeekStep(-1)
+	m.adjustSeekStep(-1)
+	if m.seekStep != 2*time.Second {
+		t.Fatalf("seekStep = %v, want 2s after two steps down", m.seekStep)
+	}
+	for range len(seekStepPresets) {
+		m.adjustSeekStep(1)
+	}
+	if m.seekStep != 5*time.Minute || m.saveMsg != "Seek step 5m (shift: 30m)" {
+		t.Fatalf("seekStep = %v, %q, want it to stop at the largest preset", m.seekStep, m.saveMsg)
+	}
+}
+
+func TestRepeatRangeWrapsAndShufflesWithinRange(t *testing.T) {
+	q := queue.New([]queue.Track{
+		{Title: "One", Path: "one.flac", State: queue.Ready},
+		{Title: "Two", Path: "two.flac", State: queue.Ready},
+		{Title: "Three", Path: "three.flac", State: queue.Ready},
+		{Title: "Four", Path: "four.flac", State: queue.Ready},
+	})
+	q.SetCurrentIndex(2)
+	m := Model{player: new(player.Player), queue: q, queueList: newQueueList(50), keys: defaultKeyMap()}
+	m.repeatRange = queueRange{start: 1, end: 2, hasStart: true, hasEnd: true}
+	m.repeatMode = RepeatRange
+
+	if _, idx, found := m.findNextPlayable(false); !found || idx != 1 {
+		t.Fatalf("findNextPlayable() at range end = %d, %v; want the range start", idx, found)
+	}
+	q.SetCurrentIndex(1)
+	if _, idx, found := m.findNextPlayable(false); !found || idx != 2 {
+		t.Fatalf("findNextPlayable() inside range = %d, %v; want 2", idx, found)
+	}
+	if !strings.Contains(m.trackToItem(q.Track(2), 2, 4).desc, "repeat range") || strings.Contains(m.trackToItem(q.Track(3), 3, 4).desc, "repeat range") {
+		t.Fatal("expected only tracks inside the range to be marked in the queue list")
+	}
+
+	q.EnableShuffle()
+	m.reshuffle()
+	for range 3 {
+		if got := q.PlaybackOrder(); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+			t.Fatalf("shuffle order = %v, want only the range tracks", got)
+		}
+		if _, idx, found := m.findNextPlayable(false); !found || idx != 2 {
+			t.Fatalf("findNextPlayable() shuffled = %d, %v", idx, found)
+		}
+	}
+}
+
+func TestRepeatRangeFollowsRemovals(t *testing.T) {
+	var tracks []queue.Track
+	for i := range 6 {
+		tracks = append(tracks, queue.Track{Title: fmt.Sprint(i), Path: fmt.Sprint(i, ".flac"), State: queue.Ready})
+	}
+	q := queue.New(tracks)
+	m := Model{queue: q, repeatMode: RepeatRange, repeatRange: queueRange{start: 2, end: 4, hasStart: true, hasEnd: true}}
+
+	q.Remove(1)
+	m.shiftRangeForRemoval([]int{1})
+	if r := m.repeatRange; r.start != 1 || r.end != 3 {
+		t.Fatalf("range after removing an earlier track = %d-%d, want 1-3", r.start, r.end)
+	}
+	q.Remove(3)
+	m.shiftRangeForRemoval([]int{3})
+	if r := m.repeatRange; r.start != 1 || r.end != 2 {
+		t.Fatalf("range after removing its end = %d-%d, want 1-2", r.start, r.end)
+	}
+	q.Remove(2)
+	m.shiftRangeForRemoval([]int{2})
+	if m.repeatRange.complete() || m.repeatMode != RepeatOff {
+		t.Fatalf("expected a one-track range to be cleared, got %+v mode %v", m.repeatRange, m.repeatMode)
+	}
+}
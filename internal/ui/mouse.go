@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleMouse seeks when the progress bar is clicked and toggles pause on a
+// click anywhere else.
+func (m Model) handleMouse(msg tea.MouseMsg) (Model, tea.Cmd) {
+	if m.player == nil {
+		return m, nil
+	}
+	if frac, ok := m.progressBarFraction(msg); ok {
+		if _, duration := m.displayPosition(); msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft && duration > 0 {
+			return m.seekToPosition(time.Duration(frac * float64(duration)))
+		}
+		// Swallow the release too, so a seek click does not also pause.
+		return m, nil
+	}
+	if msg.Action == tea.MouseActionRelease {
+		m.player.TogglePause()
+		m.paused = m.player.Paused()
+		m.invalidate(dirtyMid)
+		return m, tea.SetWindowTitle(windowTitle(m.metadata.Title, m.paused))
+	}
+	return m, nil
+}
+
+// progressBarFraction maps a mouse event on the progress bar to a position
+// in the track from 0 to 1. It reports false off the bar and on tracks that
+// cannot seek.
+func (m Model) progressBarFraction(msg tea.MouseMsg) (float64, bool) {
+	if m.barWidth == 0 || !m.player.CanSeek() {
+		return 0, false
+	}
+	if msg.Y != m.barRow || msg.X < m.barCol || msg.X >= m.barCol+m.barWidth {
+		return 0, false
+	}
+	return float64(msg.X-m.barCol) / float64(m.barWidth), true
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// notifyTimeout bounds how long a desktop notifier may run.
+const notifyTimeout = 5 * time.Second
+
+// WithNotifications returns a model that announces each new queue track with
+// a terminal (OSC 9) and, when available, a desktop notification.
+func (m Model) WithNotifications(on bool) Model {
+	m.notify = on
+	return m
+}
+
+// notifyTrackCmd announces the current track. It is fire-and-forget: the
+// returned command never produces a message, and failures are ignored.
+func (m Model) notifyTrackCmd() tea.Cmd {
+	if !m.notify {
+		return nil
+	}
+	title := notificationText(m.metadata.Title)
+	artist := notificationText(m.metadata.Artist)
+	if title == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		sendNotification(title, artist)
+		return nil
+	}
+}
+
+func sendNotification(title, artist string) {
+	text := title
+	if artist != "" {
+		text = artist + " - " + title
+	}
+	// OSC 9 is shown by iTerm2, WezTerm, kitty, Windows Terminal and others;
+	// terminals without support ignore it.
+	_, _ = io.WriteString(terminal, "\x1b]9;"+text+"\x07")
+
+	name, args := desktopNotifier(title, artist)
+	if name == "" {
+		return
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	_ = exec.CommandContext(ctx, path, args...).Run()
+}
+
+// desktopNotifier returns the notification command for this platform.
+func desktopNotifier(title, artist string) (string, []string) {
+	switch runtime.GOOS {
+	case "darwin":
+		args := []string{"-title", "climp", "-message", title}
+		if artist != "" {
+			args = []string{"-title", artist, "-message", title}
+		}
+		return "terminal-notifier", args
+	case "linux", "freebsd", "openbsd", "netbsd":
+		args := []string{"--app-name=climp", title}
+		if artist != "" {
+			args = append(args, artist)
+		}
+		return "notify-send", args
+	}
+	return "", nil
+}
+
+// notificationText strips control characters so a title cannot end the OSC
+// sequence early or inject escapes.
+func notificationText(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s))
+}
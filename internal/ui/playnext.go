@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/downloader"
+	"github.com/olivier-w/climp/internal/media"
+	"github.com/olivier-w/climp/internal/queue"
+)
+
+func newAddInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "Play next: "
+	ti.Placeholder = "URL or file path"
+	ti.CharLimit = 2048
+	ti.Width = 48
+	// A static cursor avoids blink ticks re-rendering the mid section.
+	ti.Cursor.SetMode(cursor.CursorStatic)
+	return ti
+}
+
+// openAddInput shows the play-next prompt.
+func (m *Model) openAddInput() {
+	m.addMode = true
+	m.addInput = newAddInput()
+	m.addInput.Focus()
+	m.invalidate(dirtyMid)
+}
+
+func (m *Model) closeAddInput() {
+	m.addMode = false
+	m.addInput.Blur()
+	m.invalidate(dirtyMid)
+}
+
+// updateAddInput routes key presses to the play-next prompt while it is open.
+func (m Model) updateAddInput(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, m.shutdown()
+	case "esc":
+		m.closeAddInput()
+		return m, nil
+	case "enter":
+		value := m.addInput.Value()
+		m.closeAddInput()
+		if strings.TrimSpace(value) == "" {
+			return m, nil
+		}
+		return m.playNext(value)
+	}
+
+	var cmd tea.Cmd
+	m.addInput, cmd = m.addInput.Update(msg)
+	m.invalidate(dirtyMid)
+	return m, cmd
+}
+
+// playNext queues input to play right after the current track. A single
+// track becomes a queue first. A pending URL starts downloading if a
+// download slot is free.
+func (m Model) playNext(input string) (Model, tea.Cmd) {
+	t, err := resolvePlayNext(input)
+	if err != nil {
+		m.saveMsg = "Cannot play next: " + err.Error()
+		m.saveMsgTime = time.Now()
+		m.invalidate(dirtyMid)
+		return m, nil
+	}
+	// The track being waited for is tracked by queue index, so keep it stable.
+	if m.transitioning {
+		m.saveMsg = "Cannot add tracks while waiting for a download"
+		m.saveMsgTime = time.Now()
+		m.invalidate(dirtyMid)
+		return m, nil
+	}
+	if m.queue == nil {
+		m.startSessionQueue()
+	}
+	m.shiftRangeForInsert(m.queue.InsertNext(t))
+	m.syncQueueList()
+	m.updateQueueHeight()
+	m.saveMsg = fmt.Sprintf("Playing next: %s", t.Title)
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyHeader | dirtyMid | dirtyQueue)
+	return m, m.startNextDownload()
+}
+
+// startSessionQueue turns the single track being played into a one-track
+// queue that play-next can grow. The track takes over the model's cleanup.
+func (m *Model) startSessionQueue() {
+	t := queue.Track{
+		Title:   m.sourceTitle,
+		URL:     m.originalURL,
+		Path:    m.sourcePath,
+		State:   queue.Playing,
+		Cleanup: m.cleanup,
+	}
+	if t.Title == "" {
+		t.Title = m.metadata.Title
+	}
+	if t.Path == "" && m.player != nil {
+		t.Path = m.player.Path()
+	}
+	m.cleanup = nil
+	m.useQueue(queue.New([]queue.Track{t}))
+	m.playlistName = "Queue"
+}
+
+// resolvePlayNext turns a pasted URL or local file path into a queue track.
+// Local files must exist and have a supported extension.
+func resolvePlayNext(input string) (queue.Track, error) {
+	s := strings.TrimSpace(input)
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		s = s[1 : len(s)-1]
+	}
+	if downloader.IsURL(s) {
+		state := queue.Pending
+		if downloader.IsLiveURL(s) {
+			state = queue.Ready
+		}
+		return queue.Track{Title: s, URL: s, State: state}, nil
+	}
+
+	if rest, ok := strings.CutPrefix(s, "~"+string(filepath.Separator)); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			s = filepath.Join(home, rest)
+		}
+	}
+	path, err := filepath.Abs(s)
+	if err != nil {
+		return queue.Track{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return queue.Track{}, fmt.Errorf("no such file")
+	}
+	if info.IsDir() || !media.IsSupportedExt(filepath.Ext(path)) {
+		return queue.Track{}, fmt.Errorf("not a supported audio file")
+	}
+	base := filepath.Base(path)
+	return queue.Track{
+		Title: strings.TrimSuffix(base, filepath.Ext(base)),
+		Path:  path,
+		State: queue.Ready,
+	}, nil
+}
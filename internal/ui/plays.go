@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/olivier-w/climp/internal/config"
+	"github.com/olivier-w/climp/internal/scrobble"
+)
+
+// minLivePlay is how long a live stream must play to be logged. Streams
+// have no duration, so the scrobble rule cannot apply.
+const minLivePlay = 30 * time.Second
+
+// playRecord follows the current track for the play history. A live stream
+// is one record for the whole session; its changing titles are not plays.
+type playRecord struct {
+	source   string
+	title    string
+	duration time.Duration
+	played   time.Duration // furthest position reached
+	live     bool
+	active   bool
+}
+
+// WithPlayHistory returns a model that logs tracks played past the scrobble
+// threshold to the play history in the user config dir.
+func (m Model) WithPlayHistory() Model {
+	m.logPlays = true
+	m.startPlay()
+	return m
+}
+
+// startPlay begins a play record for the current track.
+func (m *Model) startPlay() {
+	m.play = playRecord{}
+	if !m.logPlays || m.player == nil {
+		return
+	}
+	source := m.playSource()
+	if source == "" {
+		return
+	}
+	title := m.metadata.Title
+	if title == "" {
+		title = filepath.Base(source)
+	}
+	m.play = playRecord{
+		source:   source,
+		title:    title,
+		duration: m.duration,
+		live:     !m.player.CanSeek(),
+		active:   true,
+	}
+}
+
+// finishPlay logs the current play if it went on long enough and ends it.
+// Errors are ignored: the history should never get in the way of playback.
+func (m *Model) finishPlay() {
+	p := m.play
+	m.play = playRecord{}
+	if !p.active {
+		return
+	}
+	if p.live && p.played < minLivePlay || !p.live && !scrobble.Eligible(p.played, p.duration) {
+		return
+	}
+	_ = config.AppendPlay(config.Play{Source: p.source, Title: p.title, At: time.Now(), Played: p.played})
+}
+
+// playSource names the current track for the history: its URL, or the
+// absolute path of a local file so it can be reopened from anywhere.
+func (m *Model) playSource() string {
+	path := ""
+	if m.queue != nil {
+		if t := m.queue.Current(); t != nil {
+			if t.URL != "" {
+				return t.URL
+			}
+			path = t.Path
+		}
+	} else if m.originalURL != "" {
+		return m.originalURL
+	}
+	if path == "" {
+		path = m.player.Path()
+	}
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path
+}
@@ -0,0 +1,60 @@
+package ui
+
+import "github.com/olivier-w/climp/internal/queue"
+
+const (
+	// defaultConcurrentDownloads is how many upcoming queue tracks download
+	// at once unless --downloads says otherwise.
+	defaultConcurrentDownloads = 2
+	// MaxConcurrentDownloads caps WithConcurrentDownloads; each download is
+	// a separate yt-dlp process.
+	MaxConcurrentDownloads = 4
+)
+
+// WithConcurrentDownloads returns a model that keeps up to n upcoming queue
+// tracks downloading ahead of playback. Values are clamped to
+// 1..MaxConcurrentDownloads; 0 keeps the default.
+func (m Model) WithConcurrentDownloads(n int) Model {
+	if n > 0 {
+		m.maxDownloads = min(n, MaxConcurrentDownloads)
+	}
+	return m
+}
+
+func (m Model) concurrentDownloads() int {
+	if m.maxDownloads > 0 {
+		return m.maxDownloads
+	}
+	return defaultConcurrentDownloads
+}
+
+// downloadsInFlight counts queue tracks with a download running or waiting
+// to retry.
+func (m Model) downloadsInFlight() int {
+	n := 0
+	for i := 0; i < m.queue.Len(); i++ {
+		if m.queue.Track(i).State == queue.Downloading {
+			n++
+		}
+	}
+	return n
+}
+
+// downloadingTrackIndex finds the downloading track a download result or
+// retry belongs to. Downloads report the queue index they started at, but the
+// queue may have been edited since, so the track is matched by URL when it is
+// no longer there. It returns -1 when the track has left the queue.
+func (m Model) downloadingTrackIndex(index int, url string) int {
+	match := func(t *queue.Track) bool {
+		return t != nil && t.URL == url && t.State == queue.Downloading
+	}
+	if match(m.queue.Track(index)) {
+		return index
+	}
+	for i := 0; i < m.queue.Len(); i++ {
+		if match(m.queue.Track(i)) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// selectedQueueIndex returns the queue index of the highlighted list item, or
+// -1 when nothing is selected. Items carry their queue index, so this holds
+// whether or not the list is filtered.
+func (m Model) selectedQueueIndex() int {
+	item, ok := m.queueList.SelectedItem().(trackItem)
+	if !ok {
+		return -1
+	}
+	return item.idx
+}
+
+// clearQueueFilter drops any filter text and shows the full queue again.
+func (m *Model) clearQueueFilter() {
+	m.queueList.ResetFilter()
+	m.invalidate(dirtyQueue)
+}
+
+// updateQueueFilter routes key presses to the queue filter while it is being
+// typed, so letters and arrows never reach the playback bindings.
+func (m Model) updateQueueFilter(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, m.shutdown()
+	case "esc":
+		m.clearQueueFilter()
+		return m, nil
+	case "enter":
+		return m.jumpToSelected()
+	case "up", "ctrl+k":
+		m.queueList.CursorUp()
+		m.invalidate(dirtyQueue)
+		return m, nil
+	case "down", "ctrl+j":
+		m.queueList.CursorDown()
+		m.invalidate(dirtyQueue)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.queueList, cmd = m.queueList.Update(msg)
+	m.invalidate(dirtyQueue)
+	return m, cmd
+}
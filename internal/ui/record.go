@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/olivier-w/climp/internal/downloader"
+)
+
+// toggleRecord starts or stops recording a live stream for the R key. The WAV
+// goes in downloader.KeepDir(), named after the title and start time.
+func (m *Model) toggleRecord() {
+	if m.recordPath != "" {
+		path := m.recordPath
+		m.recordPath = ""
+		m.recordElapsed = 0
+		if err := m.player.StopRecording(); err != nil {
+			m.saveMsg = fmt.Sprintf("Recording failed: %v", err)
+		} else {
+			m.saveMsg = "Recording saved to " + path
+		}
+		m.saveMsgTime = time.Now()
+		m.invalidate(dirtyMid)
+		return
+	}
+
+	dir, err := downloader.KeepDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	path := ""
+	if err == nil {
+		name := fmt.Sprintf("%s %s.wav", downloader.SanitizeFilename(m.metadata.Title), time.Now().Format("2006-01-02 150405"))
+		path = filepath.Join(dir, name)
+		err = m.player.StartRecording(path)
+	}
+	if err != nil {
+		m.saveMsg = fmt.Sprintf("Recording failed: %v", err)
+	} else {
+		m.recordPath = path
+		m.recordElapsed = 0
+		m.saveMsg = "Recording to " + path
+	}
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+}
+
+// syncRecording refreshes the capture time on each tick. Closing the player
+// (a track change or quitting) finishes the file, so a recording that is no
+// longer running is reported as saved.
+func (m *Model) syncRecording() {
+	if m.recordPath == "" {
+		return
+	}
+	on, elapsed := m.player.Recording()
+	if on {
+		m.recordElapsed = elapsed
+		return
+	}
+	m.saveMsg = "Recording saved to " + m.recordPath
+	m.saveMsgTime = time.Now()
+	m.recordPath = ""
+	m.recordElapsed = 0
+}
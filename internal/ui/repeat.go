@@ -1,5 +1,7 @@
 package ui
 
+import "fmt"
+
 // RepeatMode represents the current repeat setting.
 type RepeatMode int
 
@@ -7,15 +9,19 @@ const (
 	RepeatOff RepeatMode = iota
 	RepeatOne
 	RepeatAll
+	// RepeatRange loops the queue tracks between the marked range ends.
+	RepeatRange
 )
 
-// Next cycles to the next repeat mode: off → song → playlist → off.
+// Next cycles to the next repeat mode: off → song → playlist → range → off.
 func (r RepeatMode) Next() RepeatMode {
 	switch r {
 	case RepeatOff:
 		return RepeatOne
 	case RepeatOne:
 		return RepeatAll
+	case RepeatAll:
+		return RepeatRange
 	default:
 		return RepeatOff
 	}
@@ -28,6 +34,8 @@ func (r RepeatMode) String() string {
 		return "one"
 	case RepeatAll:
 		return "all"
+	case RepeatRange:
+		return "range"
 	default:
 		return "off"
 	}
@@ -40,7 +48,20 @@ func (r RepeatMode) Icon() string {
 		return "[repeat song]"
 	case RepeatAll:
 		return "[repeat playlist]"
+	case RepeatRange:
+		return "[repeat range]"
 	default:
 		return ""
 	}
 }
+
+// ParseRepeatMode parses a repeat mode name as returned by String. Range
+// mode needs marked queue tracks, so it cannot be chosen by name.
+func ParseRepeatMode(s string) (RepeatMode, error) {
+	for _, r := range []RepeatMode{RepeatOff, RepeatOne, RepeatAll} {
+		if s == r.String() {
+			return r, nil
+		}
+	}
+	return RepeatOff, fmt.Errorf("unknown repeat mode %q (want one, all, or off)", s)
+}
@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/olivier-w/climp/internal/downloader"
+	"github.com/olivier-w/climp/internal/queue"
+)
+
+// queueRange is a span of queue positions, both ends inclusive, marked with
+// the range keys. The ends are positions rather than tracks, so moving a
+// track into the span loops it too; removals and insertions shift them.
+type queueRange struct {
+	start, end       int
+	hasStart, hasEnd bool
+}
+
+// complete reports whether both ends are marked and span two or more tracks.
+func (r queueRange) complete() bool {
+	return r.hasStart && r.hasEnd && r.start < r.end
+}
+
+// activeRange returns the looped range while RepeatRange is on.
+func (m Model) activeRange() (lo, hi int, ok bool) {
+	if m.repeatMode != RepeatRange || m.queue == nil || !m.repeatRange.complete() {
+		return 0, 0, false
+	}
+	return m.repeatRange.start, m.repeatRange.end, true
+}
+
+// markRange sets the start (or end) of the repeat range to the highlighted
+// queue track. Once both ends span two tracks, repeat switches to the range.
+func (m *Model) markRange(end bool) {
+	idx := m.selectedQueueIndex()
+	if idx < 0 {
+		return
+	}
+	r := &m.repeatRange
+	if end {
+		r.end, r.hasEnd = idx, true
+	} else {
+		r.start, r.hasStart = idx, true
+	}
+	if r.hasStart && r.hasEnd && r.start > r.end {
+		r.start, r.end = r.end, r.start
+	}
+
+	switch {
+	case r.complete():
+		m.repeatMode = RepeatRange
+		m.reshuffle()
+		m.preloadNext()
+		m.saveMsg = fmt.Sprintf("Repeating tracks %d-%d", r.start+1, r.end+1)
+	case r.hasStart && r.hasEnd:
+		m.saveMsg = "Repeat range needs at least two tracks"
+	case end:
+		m.saveMsg = fmt.Sprintf("Repeat range ends at track %d", idx+1)
+	default:
+		m.saveMsg = fmt.Sprintf("Repeat range starts at track %d", idx+1)
+	}
+	m.saveMsgTime = time.Now()
+	m.syncQueueList()
+	m.invalidate(dirtyMid | dirtyQueue)
+}
+
+// cycleRepeat moves to the next repeat mode, skipping range mode until a
+// range has been marked.
+func (m *Model) cycleRepeat() {
+	wasRange := m.repeatMode == RepeatRange
+	m.repeatMode = m.repeatMode.Next()
+	if m.repeatMode == RepeatRange && (m.queue == nil || !m.repeatRange.complete()) {
+		m.repeatMode = m.repeatMode.Next()
+	}
+	if wasRange != (m.repeatMode == RepeatRange) {
+		m.reshuffle()
+		m.syncQueueList()
+		m.invalidate(dirtyQueue)
+	}
+	m.preloadNext()
+	m.invalidate(dirtyMid)
+}
+
+// reshuffle rebuilds the shuffle order after the repeat range changes. It
+// does nothing while shuffle is off.
+func (m *Model) reshuffle() {
+	if m.queue != nil && m.queue.IsShuffled() {
+		m.shuffleQueue()
+	}
+}
+
+// shuffleQueue shuffles the repeat range in range mode, else the whole queue.
+func (m *Model) shuffleQueue() {
+	if lo, hi, ok := m.activeRange(); ok {
+		m.queue.ShuffleWithin(lo, hi)
+		return
+	}
+	m.queue.EnableShuffle()
+}
+
+// nextQueueIndex returns the index of the track that plays after the
+// current one, or -1. In range mode the track after the range's end, or any
+// track outside the range, is followed by the range's start.
+func (m Model) nextQueueIndex() int {
+	if lo, hi, ok := m.activeRange(); ok && !m.queue.IsShuffled() {
+		if cur := m.queue.CurrentIndex(); cur < lo || cur >= hi {
+			return lo
+		}
+	}
+	return m.queue.NextDownloadIndex()
+}
+
+// findNextInRange is findNextPlayable for range mode: it wraps from the end
+// of the range to its start (or reshuffles the range) once, skipping Failed
+// tracks, and moves the queue position past them.
+func (m *Model) findNextInRange(lo, hi int) (*queue.Track, int, bool) {
+	wrapped := false
+	for range m.queue.Len() {
+		nextIdx := m.nextQueueIndex()
+		if nextIdx < 0 {
+			// The shuffled range has played through.
+			if wrapped {
+				return nil, -1, false
+			}
+			wrapped = true
+			m.resetPlayedDownloads(lo, hi)
+			m.queue.ShuffleWithin(lo, hi)
+			continue
+		}
+		if cur := m.queue.CurrentIndex(); !m.queue.IsShuffled() && (cur < lo || cur >= hi) {
+			if wrapped {
+				return nil, -1, false
+			}
+			wrapped = true
+			m.resetPlayedDownloads(lo, hi)
+		}
+
+		next := m.queue.Track(nextIdx)
+		if next.State == queue.Failed {
+			if m.queue.IsShuffled() {
+				m.queue.AdvanceShuffle()
+			} else {
+				m.queue.SetCurrentIndex(nextIdx)
+			}
+			continue
+		}
+		return next, nextIdx, true
+	}
+	return nil, -1, false
+}
+
+// resetPlayedDownloads sets played URL tracks from lo to hi whose files were
+// cleaned up back to Pending, so repeating them downloads them again.
+func (m *Model) resetPlayedDownloads(lo, hi int) {
+	for i := lo; i <= hi; i++ {
+		t := m.queue.Track(i)
+		if t != nil && t.State == queue.Done && t.URL != "" && t.Cleanup == nil && !downloader.IsLiveURL(t.URL) {
+			m.queue.SetTrackState(i, queue.Pending)
+			m.queue.SetTrackPath(i, "")
+		}
+	}
+}
+
+// inRepeatRange reports whether queue index i is looped by range mode.
+func (m Model) inRepeatRange(i int) bool {
+	lo, hi, ok := m.activeRange()
+	return ok && i >= lo && i <= hi
+}
+
+// shiftRangeForRemoval moves the range ends after the tracks at the sorted
+// indices in removed have been taken out of the queue. A removed end moves
+// to the nearest remaining track inside the range; a range left with fewer
+// than two tracks is cleared.
+func (m *Model) shiftRangeForRemoval(removed []int) {
+	r := &m.repeatRange
+	if !r.hasStart && !r.hasEnd {
+		return
+	}
+	below := func(i int, inclusive bool) int {
+		n := 0
+		for _, j := range removed {
+			if j < i || (inclusive && j == i) {
+				n++
+			}
+		}
+		return n
+	}
+	if r.hasStart {
+		r.start -= below(r.start, false)
+	}
+	if r.hasEnd {
+		r.end -= below(r.end, true)
+	}
+	if r.hasStart && r.hasEnd && r.start >= r.end {
+		m.clearRepeatRange()
+	}
+}
+
+// shiftRangeForInsert moves the range ends after a track is inserted at
+// index i. A track inserted inside the range joins the loop.
+func (m *Model) shiftRangeForInsert(i int) {
+	r := &m.repeatRange
+	if r.hasStart && i <= r.start {
+		r.start++
+	}
+	if r.hasEnd && i <= r.end {
+		r.end++
+	}
+}
+
+// clearRepeatRange forgets the range marks, leaving range mode for no repeat.
+func (m *Model) clearRepeatRange() {
+	m.repeatRange = queueRange{}
+	if m.repeatMode == RepeatRange {
+		m.repeatMode = RepeatOff
+		m.reshuffle()
+	}
+	m.invalidate(dirtyMid | dirtyQueue)
+}
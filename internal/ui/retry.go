@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/downloader"
+)
+
+const (
+	// maxDownloadAttempts caps downloads of one queue track, first try included.
+	maxDownloadAttempts = 3
+	// downloadRetryDelay is the wait before the first retry; it doubles for
+	// each later attempt.
+	downloadRetryDelay = 2 * time.Second
+)
+
+// retryDownload schedules another download of a track whose download failed
+// with a transient error. It reports false when the error is final: not
+// retryable, or the track is out of attempts. The track stays Downloading
+// while it waits, so startNextDownload does not pick it up again.
+func (m *Model) retryDownload(msg trackDownloadedMsg) (tea.Cmd, bool) {
+	t := m.queue.Track(msg.index)
+	if t == nil || !downloader.IsRetryable(msg.err) || t.Attempts+1 >= maxDownloadAttempts {
+		return nil, false
+	}
+	attempts := t.Attempts + 1
+	m.queue.SetTrackAttempts(msg.index, attempts)
+	m.invalidate(dirtyQueue)
+
+	index, url := msg.index, t.URL
+	delay := downloadRetryDelay << (attempts - 1)
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return downloadRetryMsg{index: index, url: url}
+	}), true
+}
+
+// handleDownloadRetry starts the scheduled retry, if the track is still
+// waiting for it.
+func (m Model) handleDownloadRetry(msg downloadRetryMsg) (Model, tea.Cmd) {
+	if m.queue == nil {
+		return m, nil
+	}
+	index := m.downloadingTrackIndex(msg.index, msg.url)
+	if t := m.queue.Track(index); t == nil || t.Attempts == 0 {
+		return m, nil
+	}
+	return m, m.downloadTrackCmd(index)
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/downloader"
+)
+
+const savePromptText = "Save as: (m)p3  (f)lac  (w)av  esc cancel"
+
+// saveFormatKeys maps prompt keys to downloader.SaveFormats entries.
+var saveFormatKeys = map[string]string{
+	"m": "mp3",
+	"f": "flac",
+	"w": "wav",
+}
+
+// openSavePrompt asks which format to save the downloaded track in.
+func (m *Model) openSavePrompt() {
+	if m.sourcePath == "" || m.saving {
+		return
+	}
+	m.savePrompt = true
+	m.invalidate(dirtyMid)
+}
+
+// updateSavePrompt routes key presses to the format prompt while it is open.
+func (m Model) updateSavePrompt(msg tea.KeyMsg) (Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		m.quitting = true
+		return m, m.shutdown()
+	}
+	m.savePrompt = false
+	m.invalidate(dirtyMid)
+
+	format, ok := saveFormatKeys[key]
+	if !ok {
+		return m, nil
+	}
+	m.saving = true
+	m.saveMsg = fmt.Sprintf("Saving as %s...", strings.ToUpper(format))
+	m.saveMsgTime = time.Now()
+	src, title := m.sourcePath, m.sourceTitle
+	return m, func() tea.Msg {
+		destName, err := downloader.SaveFileAs(src, title, format)
+		return fileSavedMsg{src: src, destName: destName, err: err}
+	}
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/scrobble"
+)
+
+// scrobbleTimeout bounds one submission, including the one sent on quit.
+const scrobbleTimeout = 10 * time.Second
+
+// listen tracks the current track for scrobbling. Only local, seekable tracks
+// with an artist tag are scrobbled; active is false for everything else.
+type listen struct {
+	track  scrobble.Track
+	played time.Duration // furthest position reached
+	active bool
+}
+
+// WithScrobbler returns a model that reports listens to s. A nil s leaves
+// scrobbling off. The current track is announced from Init.
+func (m Model) WithScrobbler(s scrobble.Scrobbler) Model {
+	m.scrobbler = s
+	m.startListen()
+	return m
+}
+
+// startListen begins a listen for the current track.
+func (m *Model) startListen() {
+	m.listen = listen{}
+	if m.scrobbler == nil || m.player == nil || !m.player.CanSeek() || m.metadata.Artist == "" || m.metadata.Title == "" {
+		return
+	}
+	m.listen = listen{
+		track: scrobble.Track{
+			Artist:    m.metadata.Artist,
+			Title:     m.metadata.Title,
+			Album:     m.metadata.Album,
+			Duration:  m.duration,
+			StartedAt: time.Now(),
+		},
+		active: true,
+	}
+}
+
+// beginListenCmd closes the previous track's listen, logging it to the play
+// history and scrobbling it if it was played long enough, and announces the
+// current track as now playing.
+func (m *Model) beginListenCmd() tea.Cmd {
+	m.finishPlay()
+	m.startPlay()
+	if m.scrobbler == nil {
+		return nil
+	}
+	done := m.finishListenCmd()
+	m.startListen()
+	return tea.Batch(done, m.nowPlayingCmd())
+}
+
+// finishListenCmd scrobbles the current listen if it qualifies and ends it.
+func (m *Model) finishListenCmd() tea.Cmd {
+	l := m.listen
+	m.listen = listen{}
+	if m.scrobbler == nil || !l.active || !scrobble.Eligible(l.played, l.track.Duration) {
+		return nil
+	}
+	s := m.scrobbler
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), scrobbleTimeout)
+		defer cancel()
+		return scrobbledMsg{err: s.Scrobble(ctx, l.track)}
+	}
+}
+
+// nowPlayingCmd announces the current listen.
+func (m Model) nowPlayingCmd() tea.Cmd {
+	if m.scrobbler == nil || !m.listen.active {
+		return nil
+	}
+	s, t := m.scrobbler, m.listen.track
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), scrobbleTimeout)
+		defer cancel()
+		return scrobbledMsg{err: s.NowPlaying(ctx, t)}
+	}
+}
+
+// handleScrobbled shows a failed submission in the status line. Successful
+// submissions are silent.
+func (m Model) handleScrobbled(msg scrobbledMsg) (Model, tea.Cmd) {
+	if msg.err == nil || m.quitting {
+		return m, nil
+	}
+	m.saveMsg = fmt.Sprintf("Scrobble failed: %v", msg.err)
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+	return m, nil
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSeekStep is how far ←/→ seek unless --seek-step says otherwise.
+	DefaultSeekStep = 5 * time.Second
+	// MinSeekStep and MaxSeekStep bound the step, so it can never be zero.
+	MinSeekStep = time.Second
+	MaxSeekStep = 10 * time.Minute
+
+	// seekFarFactor scales the step for shift+←/→.
+	seekFarFactor = 6
+)
+
+// seekStepPresets are the steps < and > move between.
+var seekStepPresets = []time.Duration{
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second,
+	15 * time.Second, 30 * time.Second, time.Minute, 5 * time.Minute,
+}
+
+// WithSeekStep returns a model whose ←/→ seek by d, clamped to
+// MinSeekStep-MaxSeekStep. A zero d keeps the default.
+func (m Model) WithSeekStep(d time.Duration) Model {
+	if d == 0 {
+		return m
+	}
+	m.setSeekStep(d)
+	return m
+}
+
+func (m *Model) setSeekStep(d time.Duration) {
+	m.seekStep = min(max(d, MinSeekStep), MaxSeekStep)
+	m.keys.seekStep = m.seekStep
+	m.keys.SeekBack.SetHelp(m.keys.SeekBack.Help().Key, "seek -"+formatSeekStep(m.seekStep))
+	m.keys.SeekForward.SetHelp(m.keys.SeekForward.Help().Key, "seek +"+formatSeekStep(m.seekStep))
+	m.invalidate(dirtyBottom)
+}
+
+// adjustSeekStep moves to the next smaller (dir < 0) or larger preset step
+// and reports the new step in the status line.
+func (m *Model) adjustSeekStep(dir int) {
+	next := m.seekStep
+	if dir < 0 {
+		for i := len(seekStepPresets) - 1; i >= 0; i-- {
+			if seekStepPresets[i] < m.seekStep {
+				next = seekStepPresets[i]
+				break
+			}
+		}
+	} else {
+		for _, d := range seekStepPresets {
+			if d > m.seekStep {
+				next = d
+				break
+			}
+		}
+	}
+	m.setSeekStep(next)
+	m.saveMsg = fmt.Sprintf("Seek step %s (shift: %s)", formatSeekStep(m.seekStep), formatSeekStep(m.seekStep*seekFarFactor))
+	m.saveMsgTime = time.Now()
+	m.invalidate(dirtyMid)
+}
+
+// formatSeekStep writes a step compactly: "5s", "1m", "1m30s".
+func formatSeekStep(d time.Duration) string {
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"github.com/olivier-w/climp/internal/config"
+	"github.com/olivier-w/climp/internal/player"
+)
+
+// ApplySettings restores persisted volume, speed, repeat, and shuffle state.
+// The returned model writes its settings back to disk on shutdown.
+func (m Model) ApplySettings(s config.State) Model {
+	m.persistSettings = true
+	m.themeName = s.Theme
+	m.dither = s.Dither
+	m.showRemaining = s.Remaining
+
+	switch speed := player.SpeedMode(s.Speed); speed {
+	case player.Speed2x, player.SpeedHalf:
+		m.speed = speed
+	default:
+		m.speed = player.Speed1x
+	}
+	switch repeat := RepeatMode(s.Repeat); repeat {
+	case RepeatOne, RepeatAll:
+		m.repeatMode = repeat
+	default:
+		m.repeatMode = RepeatOff
+	}
+	if ShuffleMode(s.Shuffle) == ShuffleOn {
+		m.shuffleMode = ShuffleOn
+	} else {
+		m.shuffleMode = ShuffleOff
+	}
+
+	if m.player != nil {
+		m.player.SetVolume(s.Volume)
+		m.volume = m.player.Volume()
+		m.masterVolume = m.volume * m.player.Boost()
+		m.player.SetSpeed(m.speed)
+	}
+	if m.shuffleMode == ShuffleOn && m.queue != nil && m.queue.Len() > 1 {
+		m.queue.EnableShuffle()
+	}
+	m.invalidate(dirtyMid)
+	m.flushCaches()
+	return m
+}
+
+// applyPlayerSettings carries the session's playback settings over to the
+// player just opened for the next track: the track's volume, speed, pitch,
+// channels, width, skip silence, EQ, and crossfade.
+func (m *Model) applyPlayerSettings() {
+	m.applyTrackVolume()
+	// New players start at the saved speed, so 1x is set too.
+	m.player.SetSpeed(m.speed)
+	if m.keepPitch {
+		m.player.SetPitchPreserve(true)
+	}
+	if m.channelMode != player.ChannelStereo {
+		m.player.SetChannelMode(m.channelMode)
+	}
+	if m.widthStep != 0 {
+		m.player.SetStereoWidth(player.StereoWidths[m.widthStep])
+	}
+	if m.skipSilence {
+		m.player.SetSkipSilence(true)
+	}
+	if m.eqPreset != player.EQFlat {
+		m.player.SetEQPreset(m.eqPreset)
+	}
+	if m.crossfade > 0 {
+		m.player.SetCrossfade(m.crossfade)
+	}
+}
+
+// WithRepeat returns a model that starts in repeat mode r, overriding any
+// persisted setting.
+func (m Model) WithRepeat(r RepeatMode) Model {
+	m.repeatMode = r
+	m.invalidate(dirtyMid)
+	m.flushCaches()
+	return m
+}
+
+// WithShuffle returns a model that starts with the queue shuffled, overriding
+// any persisted setting. The current track stays first.
+func (m Model) WithShuffle() Model {
+	if m.shuffleMode != ShuffleOn {
+		m.shuffleMode = ShuffleOn
+		if m.queue != nil && m.queue.Len() > 1 {
+			m.queue.EnableShuffle()
+		}
+	}
+	m.invalidate(dirtyMid)
+	m.flushCaches()
+	return m
+}
+
+// WithPaused returns a model whose first track starts paused, for
+// --paused. Space starts playback as usual.
+func (m Model) WithPaused(on bool) Model {
+	if !on || m.player == nil {
+		return m
+	}
+	m.player.Pause()
+	m.paused = true
+	m.invalidate(dirtyMid)
+	m.flushCaches()
+	return m
+}
+
+// settings captures the current persistable playback state.
+func (m Model) settings() config.State {
+	return config.State{
+		Volume:    m.volume,
+		Speed:     int(m.speed),
+		Repeat:    int(m.repeatMode),
+		Shuffle:   int(m.shuffleMode),
+		Theme:     m.themeName,
+		Dither:    m.dither,
+		Remaining: m.showRemaining,
+	}
+}
+
+// saveSettings persists the current settings if they were loaded from disk.
+// Errors are ignored: failing to save preferences should never block quitting.
+func (m *Model) saveSettings() {
+	if !m.persistSettings {
+		return
+	}
+	if m.player != nil {
+		// Persist the pre-mute level rather than 0.
+		m.player.Unmute()
+		m.volume = m.player.Volume()
+		if m.queue != nil {
+			// Per-track offsets only last for the session.
+			m.volume = min(m.masterVolume, 1)
+		}
+	}
+	_ = config.Save(m.settings())
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sleepPresets are the timer lengths the T key steps through.
+var sleepPresets = []time.Duration{15 * time.Minute, 30 * time.Minute, 45 * time.Minute, 60 * time.Minute}
+
+const (
+	sleepFadeSteps    = 20
+	sleepFadeInterval = 250 * time.Millisecond // 20 steps ≈ 5s fade
+)
+
+// WithSleepTimer returns a model that fades out and quits after d.
+// The timer starts when the model's Init runs.
+func (m Model) WithSleepTimer(d time.Duration) Model {
+	if d > 0 {
+		m.sleepSeq++
+		m.sleepDeadline = time.Now().Add(d)
+	}
+	return m
+}
+
+func sleepTimerCmd(seq uint64, d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return sleepTimerMsg{seq: seq}
+	})
+}
+
+func sleepFadeCmd(seq uint64, step int) tea.Cmd {
+	return tea.Tick(sleepFadeInterval, func(time.Time) tea.Msg {
+		return sleepFadeMsg{seq: seq, step: step}
+	})
+}
+
+// cycleSleepTimer moves to the next preset longer than the time left
+// (rounded to the minute), or cancels the timer once past the last preset.
+func (m *Model) cycleSleepTimer() tea.Cmd {
+	remaining := time.Duration(0)
+	if !m.sleepDeadline.IsZero() {
+		remaining = time.Until(m.sleepDeadline).Round(time.Minute)
+	}
+	m.cancelSleepTimer()
+	for _, d := range sleepPresets {
+		if d > remaining {
+			m.sleepDeadline = time.Now().Add(d)
+			return sleepTimerCmd(m.sleepSeq, d)
+		}
+	}
+	return nil
+}
+
+// cancelSleepTimer stops the timer and undoes any fade in progress. Pending
+// timer messages are ignored because their seq no longer matches.
+func (m *Model) cancelSleepTimer() {
+	m.sleepSeq++
+	m.sleepDeadline = time.Time{}
+	if m.sleepFading {
+		m.sleepFading = false
+		if m.player != nil {
+			m.player.SetVolume(m.sleepFadeFrom)
+			m.volume = m.player.Volume()
+		}
+	}
+	m.invalidate(dirtyMid)
+}
+
+func (m Model) handleSleepTimer(msg sleepTimerMsg) (Model, tea.Cmd) {
+	if msg.seq != m.sleepSeq || m.sleepFading {
+		return m, nil
+	}
+	if m.player == nil || m.player.Muted() {
+		m.quitting = true
+		return m, m.shutdown()
+	}
+	m.sleepFading = true
+	m.sleepFadeFrom = m.player.Volume()
+	return m, sleepFadeCmd(m.sleepSeq, 1)
+}
+
+func (m Model) handleSleepFade(msg sleepFadeMsg) (Model, tea.Cmd) {
+	if msg.seq != m.sleepSeq || !m.sleepFading {
+		return m, nil
+	}
+	if m.player == nil || msg.step >= sleepFadeSteps {
+		// Restore the level while paused so the saved volume is not zero.
+		if m.player != nil {
+			m.player.Pause()
+			m.player.SetVolume(m.sleepFadeFrom)
+		}
+		m.quitting = true
+		return m, m.shutdown()
+	}
+	m.player.SetVolume(m.sleepFadeFrom * float64(sleepFadeSteps-msg.step) / sleepFadeSteps)
+	m.volume = m.player.Volume()
+	m.invalidate(dirtyMid)
+	return m, sleepFadeCmd(m.sleepSeq, msg.step+1)
+}
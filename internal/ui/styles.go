@@ -1,31 +1,50 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/olivier-w/climp/internal/theme"
+)
 
 var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.AdaptiveColor{Light: "#333333", Dark: "#FFFFFF"})
-
-	artistStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#666666", Dark: "#AAAAAA"})
-
-	timeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#888888", Dark: "#888888"})
-
-	statusStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#555555", Dark: "#BBBBBB"})
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#999999", Dark: "#666666"})
-
-	headerStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.AdaptiveColor{Light: "#555555", Dark: "#888888"})
-
-	activeDotStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#847A85", Dark: "#979797"})
-
-	inactiveDotStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.AdaptiveColor{Light: "#DDDADA", Dark: "#3C3C3C"})
+	titleStyle          lipgloss.Style
+	artistStyle         lipgloss.Style
+	timeStyle           lipgloss.Style
+	statusStyle         lipgloss.Style
+	helpStyle           lipgloss.Style
+	headerStyle         lipgloss.Style
+	activeDotStyle      lipgloss.Style
+	inactiveDotStyle    lipgloss.Style
+	waveformPlayedStyle lipgloss.Style
+	waveformStyle       lipgloss.Style
 )
+
+func init() {
+	applyTheme(theme.Current())
+}
+
+// SetTheme makes t the palette for the UI and the visualizers. Call it before
+// building models; lists created earlier keep their colors.
+func SetTheme(t *theme.Theme) {
+	theme.Set(t)
+	applyTheme(theme.Current())
+}
+
+// applyTheme rebuilds the shared styles from t.
+func applyTheme(t *theme.Theme) {
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(adaptive(t.Title))
+	artistStyle = lipgloss.NewStyle().Foreground(adaptive(t.Artist))
+	timeStyle = lipgloss.NewStyle().Foreground(adaptive(t.Time))
+	statusStyle = lipgloss.NewStyle().Foreground(adaptive(t.Status))
+	helpStyle = lipgloss.NewStyle().Foreground(adaptive(t.Help))
+	headerStyle = lipgloss.NewStyle().Bold(true).Foreground(adaptive(t.Header))
+	activeDotStyle = lipgloss.NewStyle().Foreground(adaptive(t.ActiveDot))
+	inactiveDotStyle = lipgloss.NewStyle().Foreground(adaptive(t.InactiveDot))
+	waveformPlayedStyle = lipgloss.NewStyle().Foreground(adaptive(t.WaveformPlayed))
+	waveformStyle = lipgloss.NewStyle().Foreground(adaptive(t.Waveform))
+}
+
+// adaptive converts a theme color to lipgloss, which drops it on terminals
+// without color support.
+func adaptive(c theme.Adaptive) lipgloss.AdaptiveColor {
+	return lipgloss.AdaptiveColor{Light: c.Light, Dark: c.Dark}
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// terminal is the writer Bubble Tea renders to (see Output). Escape
+// sequences the UI sends outside of View, such as notifications, go through
+// it too, so they land between frames instead of inside one the renderer is
+// writing from its own goroutine.
+var terminal = &lockedFile{File: os.Stdout}
+
+// Output returns the writer programs running a Model should render to, with
+// tea.WithOutput.
+func Output() io.Writer {
+	return terminal
+}
+
+// lockedFile is an *os.File whose writes are serialized. It still has the
+// file's Fd, so Bubble Tea recognizes it as a terminal.
+type lockedFile struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (f *lockedFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.File.Write(p)
+}
+
+// WriteString shadows the file's own, which io.WriteString would otherwise
+// call without the lock.
+func (f *lockedFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
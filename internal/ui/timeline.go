@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"os"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/downloader"
+	"github.com/olivier-w/climp/internal/player"
+	"github.com/olivier-w/climp/internal/queue"
+)
+
+// timelineDurationsMsg carries file durations probed for the album timeline,
+// keyed by path so queue edits while probing do not misplace them.
+type timelineDurationsMsg struct {
+	durations map[string]time.Duration
+}
+
+// probeDurationsCmd reads the durations of paths in the background.
+func probeDurationsCmd(paths []string) tea.Cmd {
+	if len(paths) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		durations := make(map[string]time.Duration, len(paths))
+		for _, path := range paths {
+			if d, err := player.ProbeDuration(path); err == nil && d > 0 {
+				durations[path] = d
+			}
+		}
+		return timelineDurationsMsg{durations: durations}
+	}
+}
+
+// toggleTimeline switches between per-track and album timeline seeking for
+// the t key. Turning it on probes the queue tracks that are on disk.
+func (m *Model) toggleTimeline() tea.Cmd {
+	if m.queue == nil || m.queue.Len() < 2 {
+		return nil
+	}
+	m.albumTimeline = !m.albumTimeline
+	m.invalidate(dirtyMid)
+	if !m.albumTimeline {
+		return nil
+	}
+	m.noteTrackDuration()
+	var paths []string
+	for i := range m.queue.Len() {
+		if t := m.queue.Track(i); needsDurationProbe(t) && !slices.Contains(paths, t.Path) {
+			paths = append(paths, t.Path)
+		}
+	}
+	return probeDurationsCmd(paths)
+}
+
+// needsDurationProbe reports whether t is on disk without a known duration.
+func needsDurationProbe(t *queue.Track) bool {
+	return t != nil && t.Path != "" && t.Duration == 0 && t.State != queue.Failed && !downloader.IsLiveURL(t.URL)
+}
+
+// probeTrackDuration probes a track that just finished downloading.
+func (m Model) probeTrackDuration(i int) tea.Cmd {
+	if !m.albumTimeline || !needsDurationProbe(m.queue.Track(i)) {
+		return nil
+	}
+	return probeDurationsCmd([]string{m.queue.Track(i).Path})
+}
+
+// handleTimelineDurations stores probed durations on every track of each
+// file; cue sheet tracks get the length of their section.
+func (m Model) handleTimelineDurations(msg timelineDurationsMsg) (Model, tea.Cmd) {
+	if m.queue == nil {
+		return m, nil
+	}
+	for i := range m.queue.Len() {
+		t := m.queue.Track(i)
+		d, ok := msg.durations[t.Path]
+		if !ok || t.Duration > 0 {
+			continue
+		}
+		if t.End > 0 {
+			d = min(d, t.End)
+		}
+		if d -= t.Start; d > 0 {
+			m.queue.SetTrackDuration(i, d)
+		}
+	}
+	m.invalidate(dirtyMid)
+	return m, nil
+}
+
+// noteTrackDuration records the current track's length from its player, which
+// is exact where a probe may not be.
+func (m *Model) noteTrackDuration() {
+	if m.queue != nil && m.player != nil && m.player.CanSeek() && m.duration > 0 {
+		m.queue.SetTrackDuration(m.queue.CurrentIndex(), m.duration)
+	}
+}
+
+// timelineSpan returns where the current track starts on the album timeline
+// and the timeline's length. It reports false in per-track mode and while the
+// current track has no place on the timeline yet.
+func (m Model) timelineSpan() (start, total time.Duration, ok bool) {
+	if !m.albumTimeline || m.queue == nil || m.player == nil || !m.player.CanSeek() {
+		return 0, 0, false
+	}
+	start, ok = m.queue.TimelineStart(m.queue.CurrentIndex())
+	total = m.queue.TotalDuration()
+	if !ok || total <= start {
+		return 0, 0, false
+	}
+	return start, total, true
+}
+
+// displayPosition returns the elapsed time and duration the progress bar
+// shows: the track's own, or the album timeline's.
+func (m Model) displayPosition() (elapsed, duration time.Duration) {
+	if start, total, ok := m.timelineSpan(); ok {
+		return start + m.elapsed, total
+	}
+	return m.elapsed, m.duration
+}
+
+// seekBy moves playback by delta for the seek keys. On the album timeline a
+// seek past either end of the track continues into its neighbour.
+func (m Model) seekBy(delta time.Duration) (Model, tea.Cmd) {
+	start, total, ok := m.timelineSpan()
+	if !ok {
+		return m, m.queueSeekDelta(delta)
+	}
+	pos := m.player.Position()
+	if m.seekPending || m.seekApplying {
+		pos = m.seekTarget
+	}
+	return m.seekTimeline(min(max(start+pos+delta, 0), total))
+}
+
+// seekToPosition seeks to target, a position on the album timeline when it
+// is active and in the current track otherwise.
+func (m Model) seekToPosition(target time.Duration) (Model, tea.Cmd) {
+	if _, total, ok := m.timelineSpan(); ok {
+		return m.seekTimeline(min(target, total))
+	}
+	return m, m.queueSeekTo(target)
+}
+
+// seekTimeline seeks to a position on the album timeline, switching to the
+// track it falls in first. Playback stays paused if it was.
+func (m Model) seekTimeline(target time.Duration) (Model, tea.Cmd) {
+	idx, offset := m.queue.Locate(target)
+	if idx < 0 || idx == m.queue.CurrentIndex() {
+		return m, m.queueSeekTo(offset)
+	}
+	track := m.queue.Track(idx)
+	if track.State == queue.Failed || track.Path == "" {
+		return m, nil
+	}
+	if _, err := os.Stat(track.Path); err != nil {
+		return m, nil // a finished download that has been cleaned up
+	}
+	paused := m.paused
+	if m.seekPending || m.seekApplying {
+		paused = !m.seekResume
+	}
+
+	m.cleanupOldTracks()
+	m.queue.SetTrackState(m.queue.CurrentIndex(), queue.Done)
+	m.queue.SetCurrentIndex(idx)
+	m.queue.SetTrackState(idx, queue.Playing)
+	m, cmd := m.advanceToTrack(m.queue.Current())
+	if m.player == nil || !m.player.CanSeek() {
+		return m, cmd
+	}
+	if paused {
+		m.player.Pause()
+		m.paused = true
+	}
+	if offset > 0 {
+		cmd = tea.Batch(cmd, m.queueSeekTo(offset))
+	}
+	return m, cmd
+}
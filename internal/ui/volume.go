@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/player"
+)
+
+const (
+	volumeStep     = 0.05
+	volumeFineStep = 0.01
+
+	// volumeInputTimeout closes an idle volume prompt so a stray V does not
+	// leave the other keys captured.
+	volumeInputTimeout = 5 * time.Second
+)
+
+// adjustVolume moves the volume by delta, continuing into the boost above
+// 100%, and records the change for the current track.
+func (m *Model) adjustVolume(delta float64) {
+	m.player.AdjustVolume(delta)
+	m.volume = m.player.Volume()
+	m.boost = m.player.Boost()
+	m.muted = false
+	m.noteVolumeChange()
+	m.invalidate(dirtyMid)
+}
+
+// noteVolumeChange records a volume key press. In a queue it becomes the
+// current track's offset from the session volume, so the next track starts
+// at the session volume again; otherwise it moves the session volume.
+func (m *Model) noteVolumeChange() {
+	level := m.volume * m.boost
+	if m.queue != nil {
+		if t := m.queue.Current(); t != nil {
+			t.VolumeOffset = level - m.masterVolume
+			return
+		}
+	}
+	m.masterVolume = level
+}
+
+// applyTrackVolume sets the player to the session volume plus the current
+// queue track's offset. A muted player stays muted.
+func (m *Model) applyTrackVolume() {
+	if m.player == nil || m.player.Muted() {
+		return
+	}
+	level := m.masterVolume
+	if m.queue != nil {
+		if t := m.queue.Current(); t != nil {
+			level += t.VolumeOffset
+		}
+	}
+	level = min(max(level, 0), player.MaxBoost)
+	m.player.SetVolume(min(level, 1))
+	m.player.SetBoost(level)
+	m.volume = m.player.Volume()
+	m.boost = m.player.Boost()
+}
+
+func newVolumeInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "Volume %: "
+	ti.Placeholder = "0-300"
+	ti.CharLimit = 3
+	ti.Width = 5
+	// A static cursor avoids blink ticks re-rendering the mid section.
+	ti.Cursor.SetMode(cursor.CursorStatic)
+	return ti
+}
+
+func volumeInputTimeoutCmd(seq uint64) tea.Cmd {
+	return tea.Tick(volumeInputTimeout, func(time.Time) tea.Msg {
+		return volumeInputTimeoutMsg{seq: seq}
+	})
+}
+
+// openVolumeInput shows the exact-volume prompt. It closes by itself after
+// volumeInputTimeout without a key press.
+func (m *Model) openVolumeInput() tea.Cmd {
+	if m.player == nil {
+		return nil
+	}
+	m.volumeMode = true
+	m.volumeInput = newVolumeInput()
+	m.volumeInput.Focus()
+	m.volumeSeq++
+	m.invalidate(dirtyMid)
+	return volumeInputTimeoutCmd(m.volumeSeq)
+}
+
+func (m *Model) closeVolumeInput() {
+	m.volumeMode = false
+	m.volumeInput.Blur()
+	m.volumeSeq++
+	m.invalidate(dirtyMid)
+}
+
+// updateVolumeInput routes key presses to the volume prompt while it is open.
+// Typed characters other than digits are dropped rather than acted on.
+func (m Model) updateVolumeInput(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if msg.Type == tea.KeyRunes && !isDigits(msg.Runes) {
+		return m, nil
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, m.shutdown()
+	case "esc":
+		m.closeVolumeInput()
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.volumeInput.Value())
+		m.closeVolumeInput()
+		if value == "" {
+			return m, nil
+		}
+		percent, err := strconv.Atoi(value)
+		if err != nil || percent > int(player.MaxBoost*100) {
+			m.saveMsg = "Invalid volume (use 0-300)"
+			m.saveMsgTime = time.Now()
+			return m, nil
+		}
+		m.setVolumeLevel(float64(percent) / 100)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.volumeInput, cmd = m.volumeInput.Update(msg)
+	m.volumeSeq++
+	m.invalidate(dirtyMid)
+	return m, tea.Batch(cmd, volumeInputTimeoutCmd(m.volumeSeq))
+}
+
+// setVolumeLevel sets the combined volume and boost to level, as a typed
+// percentage does, and records it like a volume key press.
+func (m *Model) setVolumeLevel(level float64) {
+	if m.player.Muted() {
+		m.player.Unmute()
+	}
+	m.adjustVolume(level - m.player.Volume()*max(m.player.Boost(), 1))
+}
+
+func isDigits(runes []rune) bool {
+	for _, r := range runes {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
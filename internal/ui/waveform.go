@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"math"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/player"
+)
+
+const (
+	// waveformBuckets is the envelope resolution; rendering folds it down to
+	// the terminal width.
+	waveformBuckets = 1024
+	// waveformRows is the height of the overview drawn above the progress bar.
+	waveformRows = 2
+)
+
+// waveformLevels draws one cell, from empty to full.
+var waveformLevels = []rune(" ▁▂▃▄▅▆▇█")
+
+// waveform is the overview of the current track. peaks fills in as the scan
+// steps through the file; total is the bucket count once it is finished.
+type waveform struct {
+	seq   uint64 // invalidates scan messages after a track change or toggle
+	path  string // file being scanned; "" when the track has no overview
+	peaks []player.WaveformPeak
+	total int
+}
+
+// waveformMsg carries one step of a background scan.
+type waveformMsg struct {
+	seq   uint64
+	scan  *player.WaveformScan
+	peaks []player.WaveformPeak
+	total int
+	done  bool
+	err   error
+}
+
+// waveformOpenCmd opens path for scanning and runs the first step. Opening
+// can convert the whole file (ffmpeg formats), so it stays off the UI
+// goroutine too.
+func waveformOpenCmd(seq uint64, path string) tea.Cmd {
+	return func() tea.Msg {
+		scan, err := player.NewWaveformScan(path, waveformBuckets)
+		if err != nil {
+			return waveformMsg{seq: seq, err: err}
+		}
+		return waveformStep(seq, scan)
+	}
+}
+
+func waveformStepCmd(seq uint64, scan *player.WaveformScan) tea.Cmd {
+	return func() tea.Msg {
+		return waveformStep(seq, scan)
+	}
+}
+
+func waveformStep(seq uint64, scan *player.WaveformScan) waveformMsg {
+	peaks, done, err := scan.Step()
+	return waveformMsg{seq: seq, scan: scan, peaks: peaks, total: scan.Buckets(), done: done, err: err}
+}
+
+// toggleWaveform shows or hides the track overview for the W key.
+func (m *Model) toggleWaveform() tea.Cmd {
+	m.showWave = !m.showWave
+	m.invalidate(dirtyMid)
+	return m.syncWaveform()
+}
+
+// waveformPath returns the file the overview is drawn from: the current
+// local track, but not live streams or cue sheet sections, whose position
+// does not map onto the whole file.
+func (m *Model) waveformPath() string {
+	if !m.showWave || m.player == nil || !m.player.CanSeek() {
+		return ""
+	}
+	if m.queue != nil && isSection(m.queue.Current()) {
+		return ""
+	}
+	return m.player.Path()
+}
+
+// syncWaveform starts a scan when the overview is on and the current track
+// has not been scanned. It runs on every tick, so a new track is picked up
+// right after it opens. Scans in flight for another track are closed when
+// their next message arrives.
+func (m *Model) syncWaveform() tea.Cmd {
+	path := m.waveformPath()
+	if path == m.wave.path {
+		return nil
+	}
+	m.wave = waveform{seq: m.wave.seq + 1, path: path}
+	m.invalidate(dirtyMid)
+	if path == "" {
+		return nil
+	}
+	return waveformOpenCmd(m.wave.seq, path)
+}
+
+func (m Model) handleWaveform(msg waveformMsg) (Model, tea.Cmd) {
+	if msg.seq != m.wave.seq {
+		if msg.scan != nil {
+			msg.scan.Close()
+		}
+		return m, nil
+	}
+	if msg.err == nil || len(msg.peaks) > 0 {
+		m.wave.peaks = msg.peaks
+		m.wave.total = msg.total
+		m.invalidate(dirtyMid)
+	}
+	if msg.done || msg.err != nil {
+		if msg.scan != nil {
+			msg.scan.Close()
+		}
+		return m, nil
+	}
+	return m, waveformStepCmd(msg.seq, msg.scan)
+}
+
+// waveformShown reports whether the mid section draws the overview.
+func (m Model) waveformShown() bool {
+	return m.showWave && m.wave.path != "" && m.wave.total > 0 &&
+		!m.transitioning && !m.compactLayout()
+}
+
+// renderWaveform draws peaks (out of total buckets) in width columns and
+// waveformRows rows, with the columns before frac in the played style.
+// Columns the scan has not reached yet are blank.
+func renderWaveform(peaks []player.WaveformPeak, total, width int, frac float64) string {
+	levels := make([]int, width)
+	for c := range levels {
+		lo := c * total / width
+		hi := max((c+1)*total/width, lo+1)
+		if lo >= len(peaks) {
+			levels[c] = -1
+			continue
+		}
+		var amp float32
+		for _, p := range peaks[lo:min(hi, len(peaks))] {
+			amp = max(amp, -p.Min, p.Max)
+		}
+		// Keep a baseline so scanned silence is visible.
+		levels[c] = max(int(math.Round(float64(amp)*waveformRows*8)), 1)
+	}
+	played := int(min(max(frac, 0), 1) * float64(width))
+
+	var sb strings.Builder
+	for row := waveformRows - 1; row >= 0; row-- {
+		cells := make([]rune, width)
+		for c, level := range levels {
+			cells[c] = waveformLevels[min(max(level-row*8, 0), 8)]
+		}
+		sb.WriteString("  ")
+		sb.WriteString(waveformPlayedStyle.Render(string(cells[:played])))
+		sb.WriteString(waveformStyle.Render(string(cells[played:])))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package visualizer
+
+import "math"
+
+// Analyzer computes one FFT per visualizer frame and shares the magnitude
+// bins with every spectrum consumer, so adding a consumer doesn't add an FFT.
+// The owner calls Update once per viz tick; consumers only read Bins.
+type Analyzer struct {
+	fftSize int
+	window  []float64
+	real    []float64
+	imag    []float64
+	bins    []float64
+}
+
+// NewAnalyzer creates an Analyzer with the default FFT size.
+func NewAnalyzer() *Analyzer {
+	a := &Analyzer{
+		fftSize: defaultFFTSize,
+		window:  make([]float64, defaultFFTSize),
+		real:    make([]float64, defaultFFTSize),
+		imag:    make([]float64, defaultFFTSize),
+		bins:    make([]float64, defaultFFTSize/2),
+	}
+	for i := range a.window {
+		a.window[i] = 0.5 * (1.0 - math.Cos(2.0*math.Pi*float64(i)/float64(a.fftSize-1)))
+	}
+	return a
+}
+
+// Update runs the FFT on stereo int16 samples: mono mix, Hann window, FFT,
+// and magnitudes. Too few samples leave the previous bins in place.
+func (a *Analyzer) Update(samples []int16) {
+	if a == nil || len(samples) < a.fftSize {
+		return
+	}
+
+	for i := range a.fftSize {
+		idx := i * 2
+		if idx+1 < len(samples) {
+			// Convert before summing so loud frames don't overflow int16.
+			// Averaging also keeps mono sources (duplicated L/R) at unity energy.
+			a.real[i] = (float64(samples[idx]) + float64(samples[idx+1])) / 65536.0
+		} else if idx < len(samples) {
+			a.real[i] = float64(samples[idx]) / 32768.0
+		} else {
+			a.real[i] = 0
+		}
+		a.imag[i] = 0
+		a.real[i] *= a.window[i]
+	}
+
+	fft(a.real, a.imag)
+
+	for i := range a.bins {
+		a.bins[i] = math.Sqrt(a.real[i]*a.real[i] + a.imag[i]*a.imag[i])
+	}
+}
+
+// Bins returns the magnitude of each FFT bin from DC up to Nyquist, as of
+// the last Update. Callers must not modify the slice.
+func (a *Analyzer) Bins() []float64 {
+	if a == nil {
+		return nil
+	}
+	return a.bins
+}
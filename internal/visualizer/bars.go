@@ -0,0 +1,118 @@
+package visualizer
+
+import (
+	"strings"
+
+	"github.com/olivier-w/climp/internal/theme"
+)
+
+var barChars = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+const (
+	barWidth = 2    // columns per bar
+	barGap   = 1    // blank columns between bars
+	barDecay = 0.82 // per-frame fall-off multiplier
+)
+
+// Bars renders a classic bar-graph spectrum: log-spaced FFT bands drawn as
+// solid columns that jump up instantly and fall back with exponential decay.
+type Bars struct {
+	fft     *FFTBands
+	levels  []float64
+	output  string
+	profile colorProfile
+}
+
+// NewBars creates a new bar spectrum visualizer.
+func NewBars(a *Analyzer) *Bars {
+	return &Bars{
+		fft:     NewFFTBands(a, 32),
+		profile: currentColorProfile(),
+	}
+}
+
+func (b *Bars) Name() string { return "bars" }
+
+func (b *Bars) Update(samples []int16, width, height int) {
+	b.fft.Process()
+	norm := b.fft.NormalizedBands()
+
+	if height < 1 {
+		height = 1
+	}
+	cols := width - 2
+	if cols < barWidth {
+		cols = barWidth
+	}
+
+	count := (cols + barGap) / (barWidth + barGap)
+	if count < 1 {
+		count = 1
+	}
+	if len(b.levels) != count {
+		b.levels = make([]float64, count)
+	}
+
+	bands := b.fft.numBands
+	den := count - 1
+	if den < 1 {
+		den = 1
+	}
+	for i := range count {
+		frac := float64(i) / float64(den) * float64(bands-1)
+		lo := int(frac)
+		hi := lo + 1
+		if hi >= bands {
+			hi = bands - 1
+		}
+		t := frac - float64(lo)
+		target := clamp01(norm[lo]*(1-t) + norm[hi]*t)
+
+		decayed := b.levels[i] * barDecay
+		if target > decayed {
+			b.levels[i] = target
+		} else {
+			b.levels[i] = decayed
+		}
+	}
+
+	var out strings.Builder
+	color := newANSIState()
+	th := theme.Current()
+	steps := len(barChars) - 1
+
+	for row := range height {
+		if row > 0 {
+			out.WriteByte('\n')
+		}
+		rowFromBottom := height - 1 - row
+		rowFactor := float64(rowFromBottom) / float64(max(height-1, 1))
+		for i := range count {
+			if i > 0 {
+				out.WriteString(strings.Repeat(" ", barGap))
+			}
+			fill := b.levels[i]*float64(height) - float64(rowFromBottom)
+			idx := 0
+			if fill >= 1 {
+				idx = steps
+			} else if fill > 0 {
+				idx = int(fill * float64(steps))
+			}
+
+			ch := barChars[idx]
+			if ch != ' ' && b.profile != colorNone {
+				color.set(&out, heatColor(th, 0.3+0.7*rowFactor))
+			}
+			for range barWidth {
+				out.WriteRune(ch)
+			}
+		}
+		color.reset(&out)
+	}
+
+	b.output = out.String()
+}
+
+func (b *Bars) View() string {
+	return b.output
+}
@@ -12,8 +12,8 @@ type Braille struct {
 	output string
 }
 
-func NewBraille() *Braille {
-	return &Braille{fft: NewFFTBands(32)}
+func NewBraille(a *Analyzer) *Braille {
+	return &Braille{fft: NewFFTBands(a, 32)}
 }
 
 func (b *Braille) Name() string { return "braille" }
@@ -30,7 +30,7 @@ var brailleBits = [2][4]uint{
 }
 
 func (b *Braille) Update(samples []int16, width, height int) {
-	b.fft.Process(samples)
+	b.fft.Process()
 	norm := b.fft.NormalizedBands()
 
 	if height < 1 {
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 	"sync"
+
+	"github.com/olivier-w/climp/internal/theme"
 )
 
 type colorProfile uint8
@@ -17,11 +19,7 @@ const (
 	colorTrueColor
 )
 
-type colorRGB struct {
-	R uint8
-	G uint8
-	B uint8
-}
+type colorRGB = theme.RGB
 
 var (
 	profileOnce sync.Once
@@ -103,18 +101,16 @@ func rgbFromHSV(h, s, v float64) colorRGB {
 	return colorRGB{R: uint8(r * 255), G: uint8(g * 255), B: uint8(b * 255)}
 }
 
-func heatColor(t float64) colorRGB {
+// heatColor maps t in 0..1 onto th's heat gradient.
+func heatColor(th *theme.Theme, t float64) colorRGB {
 	t = clamp01(t)
-	switch {
-	case t < 0.25:
-		return lerpColor(colorRGB{R: 16, G: 25, B: 70}, colorRGB{R: 0, G: 174, B: 255}, t/0.25)
-	case t < 0.5:
-		return lerpColor(colorRGB{R: 0, G: 174, B: 255}, colorRGB{R: 20, G: 255, B: 161}, (t-0.25)/0.25)
-	case t < 0.75:
-		return lerpColor(colorRGB{R: 20, G: 255, B: 161}, colorRGB{R: 255, G: 230, B: 92}, (t-0.5)/0.25)
-	default:
-		return lerpColor(colorRGB{R: 255, G: 230, B: 92}, colorRGB{R: 255, G: 80, B: 60}, (t-0.75)/0.25)
-	}
+	seg := min(int(t*4), 3)
+	return lerpColor(th.Heat[seg], th.Heat[seg+1], t*4-float64(seg))
+}
+
+// hueColor is rgbFromHSV with th's hue range and saturation applied.
+func hueColor(th *theme.Theme, h, s, v float64) colorRGB {
+	return rgbFromHSV(th.Hue(h), s*th.Saturation, v)
 }
 
 type ansiState struct {
@@ -14,14 +14,14 @@ type Dense struct {
 	output string
 }
 
-func NewDense() *Dense {
-	return &Dense{fft: NewFFTBands(defaultBands)}
+func NewDense(a *Analyzer) *Dense {
+	return &Dense{fft: NewFFTBands(a, defaultBands)}
 }
 
 func (d *Dense) Name() string { return "dense" }
 
 func (d *Dense) Update(samples []int16, width, height int) {
-	d.fft.Process(samples)
+	d.fft.Process()
 	norm := d.fft.NormalizedBands()
 
 	if height < 1 {
@@ -8,56 +8,36 @@ const (
 	defaultDecay   = 0.3
 )
 
-// FFTBands provides reusable FFT processing with logarithmic frequency banding
-// and exponential smoothing. Multiple visualizers can embed this to share the
-// same audio analysis pipeline.
+// FFTBands groups the shared Analyzer's bins into logarithmic frequency
+// bands with exponential smoothing. Each visualizer keeps its own band count
+// and smoothing while the FFT itself runs once per frame.
 type FFTBands struct {
+	analyzer *Analyzer
 	numBands int
-	fftSize  int
 	decay    float64
 	bands    []float64
-	real     []float64
-	imag     []float64
 	norm     []float64
 }
 
-// NewFFTBands creates an FFTBands processor with the given band count.
-func NewFFTBands(numBands int) *FFTBands {
+// NewFFTBands creates an FFTBands reading from a with the given band count.
+func NewFFTBands(a *Analyzer, numBands int) *FFTBands {
 	return &FFTBands{
+		analyzer: a,
 		numBands: numBands,
-		fftSize:  defaultFFTSize,
 		decay:    defaultDecay,
 		bands:    make([]float64, numBands),
-		real:     make([]float64, defaultFFTSize),
-		imag:     make([]float64, defaultFFTSize),
 		norm:     make([]float64, numBands),
 	}
 }
 
-// Process runs the FFT pipeline on stereo int16 samples: mono mix, Hann window,
-// FFT, logarithmic banding, and exponential smoothing.
-func (f *FFTBands) Process(samples []int16) {
-	if len(samples) < f.fftSize {
+// Process bands the analyzer's current bins logarithmically and smooths
+// them into the previous frame's values.
+func (f *FFTBands) Process() {
+	bins := f.analyzer.Bins()
+	maxBin := len(bins)
+	if maxBin < 2 {
 		return
 	}
-
-	for i := range f.fftSize {
-		idx := i * 2
-		if idx+1 < len(samples) {
-			f.real[i] = float64(samples[idx]+samples[idx+1]) / 65536.0
-		} else if idx < len(samples) {
-			f.real[i] = float64(samples[idx]) / 32768.0
-		} else {
-			f.real[i] = 0
-		}
-		f.imag[i] = 0
-		w := 0.5 * (1.0 - math.Cos(2.0*math.Pi*float64(i)/float64(f.fftSize-1)))
-		f.real[i] *= w
-	}
-
-	fft(f.real, f.imag)
-
-	maxBin := f.fftSize / 2
 	for b := range f.numBands {
 		lo := int(math.Pow(float64(maxBin), float64(b)/float64(f.numBands)))
 		hi := int(math.Pow(float64(maxBin), float64(b+1)/float64(f.numBands)))
@@ -74,8 +54,7 @@ func (f *FFTBands) Process(samples []int16) {
 		sum := 0.0
 		count := 0
 		for i := lo; i < hi; i++ {
-			mag := math.Sqrt(f.real[i]*f.real[i] + f.imag[i]*f.imag[i])
-			sum += mag
+			sum += bins[i]
 			count++
 		}
 		var bandMag float64
@@ -14,8 +14,8 @@ type Hatching struct {
 	output string
 }
 
-func NewHatching() *Hatching {
-	return &Hatching{fft: NewFFTBands(defaultBands)}
+func NewHatching(a *Analyzer) *Hatching {
+	return &Hatching{fft: NewFFTBands(a, defaultBands)}
 }
 
 func (h *Hatching) Name() string { return "hatching" }
@@ -29,7 +29,7 @@ func (h *Hatching) Name() string { return "hatching" }
 //	4: # (dense cross-hatch)
 //	5: @ (fill)
 func (h *Hatching) Update(samples []int16, width, height int) {
-	h.fft.Process(samples)
+	h.fft.Process()
 	norm := h.fft.NormalizedBands()
 
 	if height < 1 {
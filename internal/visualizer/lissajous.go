@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/harmonica"
+
+	"github.com/olivier-w/climp/internal/theme"
 )
 
 var lissajousTrail = []rune{'·', '•', '✶', '✹'}
@@ -103,6 +105,7 @@ func (l *Lissajous) Update(samples []int16, width, height int) {
 
 	var out strings.Builder
 	color := newANSIState()
+	th := theme.Current()
 	for r := range rows {
 		if r > 0 {
 			out.WriteByte('\n')
@@ -115,7 +118,7 @@ func (l *Lissajous) Update(samples []int16, width, height int) {
 			}
 			age := clamp01(1 - ages[r][c])
 			hue := math.Mod(0.08+float64(c)/float64(cols)*0.75+age*0.12, 1)
-			col := rgbFromHSV(hue, 0.78, 0.3+0.7*age)
+			col := hueColor(th, hue, 0.78, 0.3+0.7*age)
 			color.set(&out, col)
 			out.WriteRune(ch)
 		}
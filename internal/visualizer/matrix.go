@@ -4,6 +4,8 @@ import (
 	"math"
 	"math/rand"
 	"strings"
+
+	"github.com/olivier-w/climp/internal/theme"
 )
 
 const matrixTrailLen = 8
@@ -28,9 +30,9 @@ type matrixCol struct {
 	chars  []rune
 }
 
-func NewMatrix() *Matrix {
+func NewMatrix(a *Analyzer) *Matrix {
 	return &Matrix{
-		fft:     NewFFTBands(defaultBands),
+		fft:     NewFFTBands(a, defaultBands),
 		energy:  newSpringField(20, 9.0, 0.78),
 		rng:     rand.New(rand.NewSource(42)),
 		profile: currentColorProfile(),
@@ -48,7 +50,7 @@ func (m *Matrix) randomChar() rune {
 }
 
 func (m *Matrix) Update(samples []int16, width, height int) {
-	m.fft.Process(samples)
+	m.fft.Process()
 	norm := m.fft.NormalizedBands()
 
 	if height < 1 {
@@ -131,6 +133,7 @@ func (m *Matrix) Update(samples []int16, width, height int) {
 
 	var out strings.Builder
 	color := newANSIState()
+	th := theme.Current()
 	den := cols - 1
 	if den < 1 {
 		den = 1
@@ -155,14 +158,14 @@ func (m *Matrix) Update(samples []int16, width, height int) {
 
 			energy := clamp01(m.energy.pos[c])
 			if age == 0 {
-				color.set(&out, colorRGB{R: 234, G: 255, B: 240})
+				color.set(&out, th.Peak)
 				out.WriteRune(ch)
 				continue
 			}
 
 			fade := 1 - float64(age)/float64(matrixTrailLen)
 			hue := 0.31 + 0.12*math.Sin(float64(c)/float64(den)*math.Pi)
-			col := rgbFromHSV(hue, 0.72, 0.2+0.65*fade+0.15*energy)
+			col := hueColor(th, hue, 0.72, 0.2+0.65*fade+0.15*energy)
 			color.set(&out, col)
 			out.WriteRune(ch)
 		}
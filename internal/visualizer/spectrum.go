@@ -1,6 +1,10 @@
 package visualizer
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/olivier-w/climp/internal/theme"
+)
 
 var spectrumChars = []rune{' ', '░', '▒', '▓', '█'}
 
@@ -14,9 +18,9 @@ type Spectrum struct {
 }
 
 // NewSpectrum creates a new spectrum visualizer.
-func NewSpectrum() *Spectrum {
+func NewSpectrum(a *Analyzer) *Spectrum {
 	return &Spectrum{
-		fft:     NewFFTBands(24),
+		fft:     NewFFTBands(a, 24),
 		smooth:  newSpringField(20, 10.0, 0.75),
 		profile: currentColorProfile(),
 	}
@@ -25,7 +29,7 @@ func NewSpectrum() *Spectrum {
 func (s *Spectrum) Name() string { return "spectrum" }
 
 func (s *Spectrum) Update(samples []int16, width, height int) {
-	s.fft.Process(samples)
+	s.fft.Process()
 	norm := s.fft.NormalizedBands()
 
 	if height < 1 {
@@ -69,6 +73,7 @@ func (s *Spectrum) Update(samples []int16, width, height int) {
 
 	var out strings.Builder
 	color := newANSIState()
+	th := theme.Current()
 	span := height - 1
 	if span < 1 {
 		span = 1
@@ -97,7 +102,7 @@ func (s *Spectrum) Update(samples []int16, width, height int) {
 			if row == peakRow && s.peaks[c] > 0.02 {
 				ch = '▀'
 				if s.profile != colorNone {
-					color.set(&out, colorRGB{R: 250, G: 250, B: 250})
+					color.set(&out, th.Peak)
 				}
 				out.WriteRune(ch)
 				continue
@@ -110,7 +115,7 @@ func (s *Spectrum) Update(samples []int16, width, height int) {
 
 			hue := 0.62 - 0.58*float64(c)/float64(den)
 			rowFactor := float64(height-1-row) / float64(span)
-			col := rgbFromHSV(hue, 0.85, 0.35+0.65*rowFactor)
+			col := hueColor(th, hue, 0.85, 0.35+0.65*rowFactor)
 			color.set(&out, col)
 			out.WriteRune(ch)
 		}
@@ -7,17 +7,19 @@ type Visualizer interface {
 	View() string
 }
 
-// Modes returns all available visualizers.
-func Modes() []Visualizer {
+// Modes returns all available visualizers. The spectrum-driven ones read
+// from a, which the caller updates once per frame.
+func Modes(a *Analyzer) []Visualizer {
 	return []Visualizer{
 		NewVUMeter(),
-		NewSpectrum(),
-		NewWaterfall(),
+		NewSpectrum(a),
+		NewBars(a),
+		NewWaterfall(a),
 		NewWaveform(),
 		NewLissajous(),
-		NewBraille(),
-		NewDense(),
-		NewMatrix(),
-		NewHatching(),
+		NewBraille(a),
+		NewDense(a),
+		NewMatrix(a),
+		NewHatching(a),
 	}
 }
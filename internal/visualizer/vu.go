@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"strings"
+
+	"github.com/olivier-w/climp/internal/theme"
 )
 
 // VUMeter renders a stereo VU meter with peak hold.
@@ -136,6 +138,7 @@ func renderVUBar(rms, peak float64, width int) string {
 	profile := currentColorProfile()
 	var sb strings.Builder
 	color := newANSIState()
+	th := theme.Current()
 	for i := range width {
 		if i < filled {
 			bar[i] = '█'
@@ -153,13 +156,13 @@ func renderVUBar(rms, peak float64, width int) string {
 	for i, ch := range bar {
 		switch {
 		case ch == '│':
-			color.set(&sb, colorRGB{R: 255, G: 252, B: 210})
+			color.set(&sb, th.Peak)
 		case i < width*6/10:
-			color.set(&sb, colorRGB{R: 60, G: 224, B: 116})
+			color.set(&sb, th.VULow)
 		case i < width*8/10:
-			color.set(&sb, colorRGB{R: 240, G: 198, B: 72})
+			color.set(&sb, th.VUMid)
 		default:
-			color.set(&sb, colorRGB{R: 242, G: 96, B: 86})
+			color.set(&sb, th.VUHigh)
 		}
 		sb.WriteRune(ch)
 	}
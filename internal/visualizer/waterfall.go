@@ -1,6 +1,10 @@
 package visualizer
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/olivier-w/climp/internal/theme"
+)
 
 var waterfallChars = []rune{' ', '.', ':', '-', '=', '+', '*', '#', '%', '@'}
 
@@ -13,9 +17,9 @@ type Waterfall struct {
 	profile colorProfile
 }
 
-func NewWaterfall() *Waterfall {
+func NewWaterfall(a *Analyzer) *Waterfall {
 	return &Waterfall{
-		fft:     NewFFTBands(36),
+		fft:     NewFFTBands(a, 36),
 		smooth:  newSpringField(20, 8.5, 0.72),
 		profile: currentColorProfile(),
 	}
@@ -24,7 +28,7 @@ func NewWaterfall() *Waterfall {
 func (w *Waterfall) Name() string { return "waterfall" }
 
 func (w *Waterfall) Update(samples []int16, width, height int) {
-	w.fft.Process(samples)
+	w.fft.Process()
 	norm := w.fft.NormalizedBands()
 
 	if height < 1 {
@@ -69,6 +73,7 @@ func (w *Waterfall) Update(samples []int16, width, height int) {
 
 	var out strings.Builder
 	color := newANSIState()
+	th := theme.Current()
 
 	for r := range height {
 		if r > 0 {
@@ -86,8 +91,8 @@ func (w *Waterfall) Update(samples []int16, width, height int) {
 				out.WriteRune(ch)
 				continue
 			}
-			col := heatColor(v)
-			col = lerpColor(col, colorRGB{R: 18, G: 22, B: 32}, age*0.65)
+			col := heatColor(th, v)
+			col = lerpColor(col, th.Background, age*0.65)
 			color.set(&out, col)
 			out.WriteRune(ch)
 		}
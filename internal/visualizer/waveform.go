@@ -3,6 +3,8 @@ package visualizer
 import (
 	"math"
 	"strings"
+
+	"github.com/olivier-w/climp/internal/theme"
 )
 
 // Waveform renders dual stereo traces with spring smoothing.
@@ -94,6 +96,7 @@ func (w *Waveform) Update(samples []int16, width, height int) {
 
 	var out strings.Builder
 	color := newANSIState()
+	th := theme.Current()
 	den := cols - 1
 	if den < 1 {
 		den = 1
@@ -108,25 +111,25 @@ func (w *Waveform) Update(samples []int16, width, height int) {
 			switch m {
 			case 1:
 				if w.profile != colorNone {
-					col := rgbFromHSV(0.53+0.04*math.Sin(float64(c)*0.22), 0.7, 0.95)
+					col := hueColor(th, 0.53+0.04*math.Sin(float64(c)*0.22), 0.7, 0.95)
 					color.set(&out, col)
 				}
 				out.WriteRune('●')
 			case 2:
 				if w.profile != colorNone {
-					col := rgbFromHSV(0.88+0.05*math.Cos(float64(c)*0.19), 0.75, 0.95)
+					col := hueColor(th, 0.88+0.05*math.Cos(float64(c)*0.19), 0.75, 0.95)
 					color.set(&out, col)
 				}
 				out.WriteRune('●')
 			case 3:
 				if w.profile != colorNone {
-					color.set(&out, colorRGB{R: 255, G: 248, B: 190})
+					color.set(&out, th.Peak)
 				}
 				out.WriteRune('✦')
 			case 4:
 				if w.profile != colorNone {
 					fade := 0.15 + 0.15*float64(c)/float64(den)
-					color.set(&out, rgbFromHSV(0.6, 0.2, fade))
+					color.set(&out, hueColor(th, 0.6, 0.2, fade))
 				}
 				out.WriteRune('·')
 			default:
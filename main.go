@@ -1,21 +1,32 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"runtime/debug"
-	"sort"
+	"slices"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/olivier-w/climp/internal/config"
 	"github.com/olivier-w/climp/internal/downloader"
 	"github.com/olivier-w/climp/internal/media"
 	"github.com/olivier-w/climp/internal/player"
+	"github.com/olivier-w/climp/internal/scrobble"
+	"github.com/olivier-w/climp/internal/theme"
 	"github.com/olivier-w/climp/internal/ui"
+	"github.com/olivier-w/climp/internal/util"
 	"golang.org/x/mod/module"
 	"golang.org/x/mod/semver"
 )
@@ -32,19 +43,77 @@ var (
 )
 
 func main() {
-	if len(os.Args) >= 2 {
-		switch os.Args[1] {
-		case "-h", "--help":
-			printHelp()
-			return
-		case "-v", "--version":
-			printVersion()
-			return
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		writeHelp(os.Stderr)
+		os.Exit(2)
+	}
+	switch {
+	case opts.help:
+		printHelp()
+		return
+	case opts.version:
+		printVersion()
+		return
+	case opts.history:
+		printHistory(os.Stdout, config.LoadHistory())
+		return
+	case opts.send != "":
+		if err := sendControl(os.Stdout, opts.socket, opts.send); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case opts.printMetadata != "":
+		if err := printMetadata(os.Stdout, opts.printMetadata); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	player.SetResampleQuality(opts.resample)
+	fileSort = opts.sort
+	freshStart = opts.fresh
+	player.SetDither(opts.dither)
+	player.SetLatency(opts.latency)
+
+	if opts.decode != "" {
+		if err := decodePCM(os.Stdout, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if opts.bench != "" {
+		if err := benchDecode(os.Stdout, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		return
+	}
+	if opts.convertTo != "" {
+		if err := convertDir(os.Stderr, opts.args[0], opts.convertTo); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	if len(os.Args) < 2 {
-		program := tea.NewProgram(newStartupModel(), tea.WithAltScreen(), tea.WithMouseCellMotion())
+	settings := config.Load()
+	ui.SetTheme(pickTheme(opts.theme, settings.Theme))
+	// Players start at the saved volume and speed rather than jumping to
+	// them once ApplySettings runs.
+	player.SetStartSettings(settings.Volume, player.SpeedMode(settings.Speed))
+	if settings.Dither {
+		player.SetDither(true)
+	}
+
+	if len(opts.args) == 0 {
+		startup := newStartupModel()
+		startup.opts = opts
+		program := tea.NewProgram(startup, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(ui.Output()))
 		if _, err := program.Run(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -52,27 +121,96 @@ func main() {
 		return
 	}
 
-	model, err := buildPlaybackModel(os.Args[1], downloadURL)
+	download := downloadURL
+	if opts.daemon {
+		download = downloadURLHeadless
+	}
+	var model ui.Model
+	switch {
+	case opts.args[0] == stdinArg:
+		if stdinIsTerminal() {
+			err = fmt.Errorf("no audio piped to stdin (try: curl -sL <url> | climp -)")
+		} else {
+			model, err = buildStdinModel(os.Stdin)
+		}
+	case opts.recursive:
+		model, err = buildRecursiveModel(opts.args, opts.shuffle, download, os.Stderr)
+	case len(opts.args) > 1:
+		model, err = buildQueueModel(opts.args, download, os.Stderr)
+	default:
+		model, err = buildPlaybackModel(opts.args[0], download)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", errorText(err))
 		os.Exit(1)
 	}
+	model = model.ApplySettings(settings)
+	if opts.hasStartAt {
+		if model, err = model.StartAt(opts.startAt); err != nil {
+			model.Close()
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		model = model.ResumeBookmark()
+	}
+	model = opts.apply(model.WithScrobbler(scrobble.New(config.LoadScrobble())).WithPlayHistory())
 
-	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if opts.daemon {
+		if err := runDaemon(model, opts.socket); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(ui.Output()))
 	if _, err := program.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// errorText words an error from opening the command-line source for the
+// user, replacing the router's terse errors with what to do about them.
+func errorText(err error) string {
+	if errors.Is(err, downloader.ErrNotPlayableMedia) {
+		return "that link isn't directly playable; it may require login or be a webpage"
+	}
+	return err.Error()
+}
+
+// pickTheme returns the --theme palette, else the one named in the state
+// file. An unknown name in the state file falls back to the default.
+func pickTheme(flagName, savedName string) *theme.Theme {
+	name := savedName
+	if flagName != "" {
+		name = flagName
+	}
+	t, _ := theme.Parse(name)
+	return t
+}
+
 // scanAudioFiles returns all supported audio files in the same directory as path,
-// sorted alphabetically (case-insensitive). Returns nil if fewer than 2 files found.
+// in --sort order (alphabetical by default). Returns nil if fewer than 2 files found.
 func scanAudioFiles(path string) []string {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return nil
 	}
-	dir := filepath.Dir(absPath)
+	files := listAudioFiles(filepath.Dir(absPath))
+	if len(files) < 2 {
+		return nil
+	}
+	return files
+}
+
+// listAudioFiles returns the supported audio files directly inside dir, in
+// --sort order (case-insensitive alphabetical by default).
+func listAudioFiles(dir string) []string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil
@@ -89,15 +227,67 @@ func scanAudioFiles(path string) []string {
 		}
 	}
 
-	if len(files) < 2 {
-		return nil
-	}
+	sortAudioFiles(files, fileSort)
 
-	sort.Slice(files, func(i, j int) bool {
-		return strings.ToLower(filepath.Base(files[i])) < strings.ToLower(filepath.Base(files[j]))
+	return files
+}
+
+// maxTreeFiles caps a --recursive scan so walking a huge tree can't stall
+// startup or build an unwieldy queue.
+const maxTreeFiles = 5000
+
+// scanAudioTree returns the supported audio files under root and its
+// subdirectories, sorted by path in --sort order (case-insensitive
+// alphabetical by default). Hidden and
+// unreadable directories are skipped. The scan stops after maxTreeFiles
+// files; truncated reports whether it did.
+func scanAudioTree(root string) (files []string, truncated bool) {
+	return walkAudioTree(root, maxTreeFiles)
+}
+
+// walkAudioTree is scanAudioTree with a file limit; 0 means no limit.
+func walkAudioTree(root string, limit int) (files []string, truncated bool) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, false
+	}
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !media.IsSupportedExt(strings.ToLower(filepath.Ext(d.Name()))) {
+			return nil
+		}
+		if limit > 0 && len(files) == limit {
+			truncated = true
+			return fs.SkipAll
+		}
+		files = append(files, path)
+		return nil
 	})
 
-	return files
+	sortAudioFiles(files, fileSort)
+	return files, truncated
+}
+
+// treeTitle is the queue title of a file found under root: its path relative
+// to root without the extension, so nested files stay distinguishable without
+// repeating the full path.
+func treeTitle(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+	return filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))
 }
 
 func playlistNameFromFile(path string) string {
@@ -114,7 +304,13 @@ func playlistNameFromDirectoryOfFile(path string) string {
 	if err != nil {
 		absPath = path
 	}
-	dir := filepath.Dir(absPath)
+	return playlistNameFromDir(filepath.Dir(absPath))
+}
+
+func playlistNameFromDir(dir string) string {
+	if absDir, err := filepath.Abs(dir); err == nil {
+		dir = absDir
+	}
 	name := strings.TrimSpace(filepath.Base(dir))
 	if name == "" || name == "." || name == string(filepath.Separator) {
 		return "Playlist"
@@ -256,20 +452,402 @@ func downloadURL(url string) (ui.DownloadResult, error) {
 	return dm.Result(), nil
 }
 
+// cliOptions holds the parsed command-line flags and remaining inputs.
+type cliOptions struct {
+	help          bool
+	version       bool
+	printMetadata string
+	history       bool
+	decode        string // --decode: write the file's PCM to stdout instead of playing
+	wav           bool   // with --decode, add a WAV header
+	rate          int    // with --decode, output sample rate; 0 keeps the source's
+	channels      int    // with --decode, output channels; 0 keeps the source's
+	bench         string // --bench: time decoding the file instead of playing
+	iterations    int    // with --bench, decode runs to average; 0 means 1
+	sleep         time.Duration
+	seekStep      time.Duration // 0 keeps the default
+	latency       time.Duration // --latency: audio output buffer; 0 keeps the driver default
+	startAt       time.Duration // with hasStartAt, where the first track starts
+	hasStartAt    bool
+	notify        bool
+	compact       bool
+	repeat        string // "" leaves the persisted repeat mode alone
+	shuffle       bool
+	downloads     int // 0 keeps the default look-ahead
+	keep          bool
+	recursive     bool
+	resample      player.ResampleQuality
+	theme         string // "" uses the state file's theme
+	dither        bool   // false leaves the state file's setting
+	convertTo     string // --convert-to: convert the directory argument to this format
+	paused        bool
+	fresh         bool   // --fresh: ignore saved folder positions
+	sort          string // --sort: directory scan order, one of sortModes
+	daemon        bool   // --daemon: play without the TUI, controlled over socket
+	socket        string // control socket for --daemon and --send; "" is defaultSocketPath
+	send          string // --send: send this command to a running daemon
+	args          []string
+}
+
+// parseFlags parses flags ahead of the file/URL inputs, so -h and --version
+// are never treated as media paths. Flags must precede inputs; "--" ends them.
+func parseFlags(args []string) (cliOptions, error) {
+	var opts cliOptions
+	fs := flag.NewFlagSet("climp", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.help, "h", false, "")
+	fs.BoolVar(&opts.help, "help", false, "")
+	fs.BoolVar(&opts.version, "v", false, "")
+	fs.BoolVar(&opts.version, "version", false, "")
+	fs.StringVar(&opts.printMetadata, "print-metadata", "", "")
+	fs.BoolVar(&opts.history, "history", false, "")
+	fs.StringVar(&opts.decode, "decode", "", "")
+	fs.BoolVar(&opts.wav, "wav", false, "")
+	fs.IntVar(&opts.rate, "rate", 0, "")
+	fs.IntVar(&opts.channels, "channels", 0, "")
+	fs.StringVar(&opts.bench, "bench", "", "")
+	fs.IntVar(&opts.iterations, "iterations", 0, "")
+	fs.DurationVar(&opts.sleep, "sleep", 0, "")
+	fs.DurationVar(&opts.seekStep, "seek-step", 0, "")
+	fs.DurationVar(&opts.latency, "latency", 0, "")
+	startAt := fs.String("start-at", "", "")
+	fs.BoolVar(&opts.notify, "notify", false, "")
+	fs.BoolVar(&opts.compact, "compact", false, "")
+	fs.BoolVar(&opts.paused, "paused", false, "")
+	fs.BoolVar(&opts.fresh, "fresh", false, "")
+	fs.StringVar(&opts.sort, "sort", sortName, "")
+	fs.StringVar(&opts.repeat, "repeat", "", "")
+	fs.BoolVar(&opts.shuffle, "shuffle", false, "")
+	fs.IntVar(&opts.downloads, "downloads", 0, "")
+	fs.BoolVar(&opts.keep, "keep", false, "")
+	fs.BoolVar(&opts.recursive, "recursive", false, "")
+	resample := fs.String("resample", "linear", "")
+	fs.StringVar(&opts.theme, "theme", "", "")
+	fs.BoolVar(&opts.dither, "dither", false, "")
+	fs.StringVar(&opts.convertTo, "convert-to", "", "")
+	fs.BoolVar(&opts.daemon, "daemon", false, "")
+	fs.StringVar(&opts.socket, "socket", "", "")
+	fs.StringVar(&opts.send, "send", "", "")
+	if err := fs.Parse(args); err != nil {
+		return cliOptions{}, err
+	}
+	if opts.sleep < 0 {
+		return cliOptions{}, fmt.Errorf("--sleep must be positive")
+	}
+	if opts.seekStep < 0 {
+		return cliOptions{}, fmt.Errorf("--seek-step must be positive")
+	}
+	if opts.latency < 0 {
+		return cliOptions{}, fmt.Errorf("--latency must be positive")
+	}
+	if *startAt != "" {
+		at, err := util.ParseTimestamp(*startAt)
+		if err != nil {
+			return cliOptions{}, fmt.Errorf("--start-at: %w (use HH:MM:SS, MM:SS, or seconds)", err)
+		}
+		opts.startAt, opts.hasStartAt = at, true
+	}
+	// 0 stands for the default look-ahead, so an explicit 0 is rejected too.
+	downloadsSet := false
+	fs.Visit(func(f *flag.Flag) { downloadsSet = downloadsSet || f.Name == "downloads" })
+	if downloadsSet && (opts.downloads < 1 || opts.downloads > ui.MaxConcurrentDownloads) {
+		return cliOptions{}, fmt.Errorf("--downloads must be between 1 and %d", ui.MaxConcurrentDownloads)
+	}
+	if opts.decode == "" && (opts.wav || opts.rate != 0 || opts.channels != 0) {
+		return cliOptions{}, fmt.Errorf("--wav, --rate, and --channels need --decode")
+	}
+	if opts.bench == "" && opts.iterations != 0 {
+		return cliOptions{}, fmt.Errorf("--iterations needs --bench")
+	}
+	if opts.iterations < 0 {
+		return cliOptions{}, fmt.Errorf("--iterations must be positive")
+	}
+	if opts.rate != 0 && (opts.rate < 8000 || opts.rate > 192000) {
+		return cliOptions{}, fmt.Errorf("--rate must be between 8000 and 192000")
+	}
+	if opts.channels < 0 || opts.channels > 2 {
+		return cliOptions{}, fmt.Errorf("--channels must be 1 or 2")
+	}
+	if opts.repeat != "" {
+		if _, err := ui.ParseRepeatMode(opts.repeat); err != nil {
+			return cliOptions{}, fmt.Errorf("--repeat: %w", err)
+		}
+	}
+	quality, err := player.ParseResampleQuality(*resample)
+	if err != nil {
+		return cliOptions{}, fmt.Errorf("--resample: %w", err)
+	}
+	opts.resample = quality
+	if opts.theme != "" {
+		t, err := theme.Parse(opts.theme)
+		if err != nil {
+			return cliOptions{}, fmt.Errorf("--theme: %w", err)
+		}
+		opts.theme = t.Name
+	}
+	if opts.printMetadata, err = fileURLToPath(opts.printMetadata, runtime.GOOS); err != nil {
+		return cliOptions{}, err
+	}
+	if opts.decode, err = fileURLToPath(opts.decode, runtime.GOOS); err != nil {
+		return cliOptions{}, err
+	}
+	if opts.bench, err = fileURLToPath(opts.bench, runtime.GOOS); err != nil {
+		return cliOptions{}, err
+	}
+	for _, arg := range fs.Args() {
+		path, err := fileURLToPath(arg, runtime.GOOS)
+		if err != nil {
+			return cliOptions{}, err
+		}
+		opts.args = append(opts.args, path)
+	}
+	if slices.Contains(opts.args, stdinArg) && (len(opts.args) > 1 || opts.recursive) {
+		return cliOptions{}, fmt.Errorf("%s (stdin) must be the only input", stdinArg)
+	}
+	opts.sort = strings.ToLower(opts.sort)
+	if !slices.Contains(sortModes, opts.sort) {
+		return cliOptions{}, fmt.Errorf("--sort must be one of: %s", strings.Join(sortModes, ", "))
+	}
+	if opts.convertTo != "" {
+		opts.convertTo = strings.ToLower(opts.convertTo)
+		if !slices.Contains(convertFormats, opts.convertTo) {
+			return cliOptions{}, fmt.Errorf("--convert-to must be one of: %s", strings.Join(convertFormats, ", "))
+		}
+		if len(opts.args) != 1 {
+			return cliOptions{}, fmt.Errorf("--convert-to needs one directory")
+		}
+	}
+	if opts.hasStartAt && len(opts.args) == 0 {
+		return cliOptions{}, fmt.Errorf("--start-at needs a file to play")
+	}
+	if opts.daemon && opts.send != "" {
+		return cliOptions{}, fmt.Errorf("--daemon and --send cannot be combined")
+	}
+	if opts.daemon && len(opts.args) == 0 {
+		return cliOptions{}, fmt.Errorf("--daemon needs something to play")
+	}
+	if opts.send != "" && len(opts.args) > 0 {
+		return cliOptions{}, fmt.Errorf("--send takes no inputs")
+	}
+	if opts.socket != "" && !opts.daemon && opts.send == "" {
+		return cliOptions{}, fmt.Errorf("--socket needs --daemon or --send")
+	}
+	return opts, nil
+}
+
+// fileURLToPath turns a file:// URL, as pasted from a browser or file
+// manager, into the local path it names; other inputs are returned as is.
+// Percent-encoding is decoded, and on Windows "file:///C:/x" becomes "C:\x"
+// and "file://server/share" a UNC path.
+func fileURLToPath(arg, goos string) (string, error) {
+	if len(arg) < 5 || !strings.EqualFold(arg[:5], "file:") {
+		return arg, nil
+	}
+	u, err := url.Parse(arg)
+	if err != nil {
+		return "", fmt.Errorf("invalid file URL %q: %w", arg, err)
+	}
+	path := u.Path
+	if u.Opaque != "" { // file:C:/x or file:x
+		if path, err = url.PathUnescape(u.Opaque); err != nil {
+			return "", fmt.Errorf("invalid file URL %q: %w", arg, err)
+		}
+	}
+	host := u.Host
+	if strings.EqualFold(host, "localhost") {
+		host = ""
+	}
+	if goos != "windows" {
+		if host != "" {
+			return "", fmt.Errorf("file URL %q names another host", arg)
+		}
+		return path, nil
+	}
+	if host != "" {
+		path = "//" + host + path
+	} else if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:] // "/C:/x"
+	}
+	return strings.ReplaceAll(path, "/", `\`), nil
+}
+
+// apply hands the playback flags to a model built from the inputs. Flags are
+// applied after persisted settings, so they take precedence.
+func (o cliOptions) apply(m ui.Model) ui.Model {
+	if mode, err := ui.ParseRepeatMode(o.repeat); err == nil && o.repeat != "" {
+		m = m.WithRepeat(mode)
+	}
+	if o.shuffle {
+		m = m.WithShuffle()
+	}
+	return m.WithSleepTimer(o.sleep).WithSeekStep(o.seekStep).WithNotifications(o.notify).WithCompact(o.compact).WithConcurrentDownloads(o.downloads).WithKeepDownloads(o.keep).WithPaused(o.paused)
+}
+
+// metadataJSON is the --print-metadata output. Duration is in seconds.
+type metadataJSON struct {
+	Title    string  `json:"title"`
+	Artist   string  `json:"artist"`
+	Album    string  `json:"album"`
+	Duration float64 `json:"duration"`
+}
+
+func printMetadata(w io.Writer, path string) error {
+	if err := checkMediaFile("--print-metadata", path); err != nil {
+		return err
+	}
+
+	duration, err := player.ProbeDuration(path)
+	if err != nil {
+		return err
+	}
+	meta := player.ReadMetadata(path)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(metadataJSON{
+		Title:    meta.Title,
+		Artist:   meta.Artist,
+		Album:    meta.Album,
+		Duration: duration.Seconds(),
+	})
+}
+
+// recentPlaysShown is how many distinct tracks --history and the browser's
+// "Recently played..." list show.
+const recentPlaysShown = 50
+
+// printHistory writes the most recently played tracks and streams to w, one
+// per line with when they were last played and how often.
+func printHistory(w io.Writer, plays []config.Play) {
+	recent := config.RecentPlays(plays, recentPlaysShown)
+	if len(recent) == 0 {
+		fmt.Fprintln(w, "No plays recorded yet.")
+		return
+	}
+	for _, p := range recent {
+		fmt.Fprintf(w, "%s  %4dx  %s  %s\n", p.At.Local().Format("2006-01-02 15:04"), p.Count, p.Title, p.Source)
+	}
+}
+
+// checkMediaFile reports why path cannot be read by flag, which only takes
+// local files in a supported format.
+func checkMediaFile(flag, path string) error {
+	if downloader.IsURL(path) {
+		return fmt.Errorf("%s requires a local file", flag)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); !media.IsSupportedExt(ext) {
+		return fmt.Errorf("unsupported format %s (supported: %s)", ext, media.SupportedExtsList())
+	}
+	return nil
+}
+
+// decodePCM writes the --decode file to w as raw s16le PCM, or as a WAV file
+// with --wav, without opening an audio device.
+func decodePCM(w io.Writer, opts cliOptions) error {
+	if err := checkMediaFile("--decode", opts.decode); err != nil {
+		return err
+	}
+	s, err := player.OpenPCM(opts.decode, opts.rate, opts.channels)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.decode, err)
+	}
+	defer s.Close()
+
+	if opts.wav {
+		_, err = s.WriteWAV(w)
+	} else {
+		_, err = io.Copy(w, s)
+	}
+	return err
+}
+
 func printHelp() {
-	fmt.Println("climp - Minimal CLI media player for local files, URLs, and playlists.")
-	fmt.Println()
-	fmt.Println("Usage:")
-	fmt.Println("  climp")
-	fmt.Println("  climp <file|playlist|url>")
-	fmt.Println()
-	fmt.Println("Flags:")
-	fmt.Println("  -h, --help")
-	fmt.Println("  -v, --version")
-	fmt.Println()
-	fmt.Println("Notes:")
-	fmt.Println("  Wrap URLs containing \"&\" in quotes so your shell passes the full URL to climp.")
-	fmt.Println("  Example: climp \"https://youtube.com/watch?v=xxx&list=RDxxx\"")
+	writeHelp(os.Stdout)
+}
+
+func writeHelp(w io.Writer) {
+	fmt.Fprintln(w, "climp - Minimal CLI media player for local files, URLs, and playlists.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  climp")
+	fmt.Fprintln(w, "  climp [flags] <file|playlist|url>")
+	fmt.Fprintln(w, "  climp [flags] <file|dir|playlist|url>...")
+	fmt.Fprintln(w, "  <command> | climp [flags] -")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	fmt.Fprintln(w, "  -h, --help                   show this help")
+	fmt.Fprintln(w, "  -v, --version                print the version")
+	fmt.Fprintln(w, "  --print-metadata <file>      print title, artist, album, and duration as JSON")
+	fmt.Fprintln(w, "  --history                    print recently played tracks and how often they were played")
+	fmt.Fprintln(w, "  --decode <file>              write the file as raw s16le PCM to stdout (no audio device)")
+	fmt.Fprintln(w, "  --wav                        with --decode, write a WAV file instead of raw PCM")
+	fmt.Fprintln(w, "  --rate <hz> --channels <n>   with --decode, convert to this sample rate or 1/2 channels")
+	fmt.Fprintln(w, "  --bench <file>               decode the file and print throughput and allocations as JSON")
+	fmt.Fprintln(w, "  --iterations <n>             with --bench, average over n decode runs (default 1)")
+	fmt.Fprintln(w, "  --convert-to <fmt> <dir>     convert every audio file under dir to wav or flac (no audio device)")
+	fmt.Fprintln(w, "  --sleep <duration>           fade out and quit after a duration (e.g. 30m)")
+	fmt.Fprintln(w, "  --start-at <HH:MM:SS>        start the first track at a position instead of its bookmark")
+	fmt.Fprintln(w, "  --paused                     open the first track paused; space starts playback")
+	fmt.Fprintln(w, "  --fresh                      start a folder at the chosen file, not where it was left off")
+	fmt.Fprintln(w, "  --seek-step <duration>       how far ←/→ seek (default 5s, at least 1s); shift seeks 6x as far")
+	fmt.Fprintln(w, "  --notify                     show a notification when a new queue track starts")
+	fmt.Fprintln(w, "  --compact                    use the one-line layout (automatic below 8 rows)")
+	fmt.Fprintln(w, "  --repeat <one|all|off>       start in a repeat mode (overrides the saved one)")
+	fmt.Fprintln(w, "  --shuffle                    start with the queue shuffled")
+	fmt.Fprintln(w, "  --downloads <n>              download up to n upcoming queue tracks at once (default 2, max 4)")
+	fmt.Fprintln(w, "  --keep                       keep downloaded tracks in ~/Music/climp instead of deleting them")
+	fmt.Fprintln(w, "  --sort name|natural|meta     order directory files by name, numbers in names, or disc/track tags")
+	fmt.Fprintln(w, "  --recursive                  queue audio files in subdirectories too (with --shuffle: random order)")
+	fmt.Fprintln(w, "  --resample <linear|hq>       resampling for non-48 kHz audio: linear (default) or windowed sinc")
+	fmt.Fprintln(w, "  --dither                     add TPDF dither when reducing 24-bit FLAC to 16-bit")
+	fmt.Fprintln(w, "  --latency <duration>         audio output buffer, 10ms-500ms (smaller reacts faster, may crackle)")
+	fmt.Fprintln(w, "  --daemon                     play without the TUI, taking commands on a control socket")
+	fmt.Fprintln(w, "  --send <command>             send pause, next, prev, volume N, seek ±N, status, or quit to the daemon")
+	fmt.Fprintln(w, "  --socket <path>              control socket for --daemon and --send (default in $XDG_RUNTIME_DIR)")
+	fmt.Fprintf(w, "  --theme <name>               color theme: %s\n", strings.Join(theme.Names(), ", "))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Keys:")
+	fmt.Fprintln(w, "  space        pause              ←/→ h/l    seek 5s (--seek-step)")
+	fmt.Fprintln(w, "  shift+←/→    seek 6x step       < / >      change seek step")
+	fmt.Fprintln(w, "  0-9          jump to 0-90%      g          jump to timestamp")
+	fmt.Fprintln(w, "  [ / ]        a-b loop           b          bookmark position")
+	fmt.Fprintln(w, "  = / -        volume             m          mute")
+	fmt.Fprintln(w, "  + / _        volume 1%          V          set volume")
+	fmt.Fprintln(w, "  v            visualizer         r          repeat")
+	fmt.Fprintln(w, "  x            speed              e          eq preset")
+	fmt.Fprintln(w, "  G            replaygain         z          shuffle")
+	fmt.Fprintln(w, "  n / N p      next / prev track  enter      play selected")
+	fmt.Fprintln(w, "  del          remove selected    w          save queue")
+	fmt.Fprintln(w, "  s            save track         T          sleep timer")
+	fmt.Fprintln(w, "  c            crossfade          ?          help")
+	fmt.Fprintln(w, "  X            keep pitch         /          filter queue")
+	fmt.Fprintln(w, "  , / .        chapter/cue        K / J      move selected")
+	fmt.Fprintln(w, "  C            channels           S          keep downloads")
+	fmt.Fprintln(w, "  A            cover art          home       select up next")
+	fmt.Fprintln(w, "  D            skip silence       i          source info")
+	fmt.Fprintln(w, "  R            record stream      W          waveform")
+	fmt.Fprintln(w, "  h (live)     title history      L          lyrics")
+	fmt.Fprintln(w, "  t            album timeline     P          clear played")
+	fmt.Fprintln(w, "  E            time left / duration")
+	fmt.Fprintln(w, "  a            play next (URL or file)")
+	fmt.Fprintln(w, "  { / }        repeat range start / end (then r cycles to range)")
+	fmt.Fprintln(w, "  f            favorite (listed in the browser)")
+	fmt.Fprintln(w, "  y            copy the track URL or path to the clipboard")
+	fmt.Fprintln(w, "  M            stereo width       q / esc    quit")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Formats: %s\n", media.SupportedExtsList())
+	fmt.Fprintln(w, "Playlists: .m3u, .m3u8, .pls")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Notes:")
+	fmt.Fprintln(w, "  Remap keys in climp/keys.json under your config dir (see README).")
+	fmt.Fprintln(w, "  Wrap URLs containing \"&\" in quotes so your shell passes the full URL to climp.")
+	fmt.Fprintln(w, "  Example: climp \"https://youtube.com/watch?v=xxx&list=RDxxx\"")
 }
 
 func printVersion() {
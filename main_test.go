@@ -1,10 +1,25 @@
 package main
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
 	"runtime/debug"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/olivier-w/climp/internal/config"
+	"github.com/olivier-w/climp/internal/downloader"
+	"github.com/olivier-w/climp/internal/media"
+	"github.com/olivier-w/climp/internal/player"
 	"golang.org/x/mod/module"
 )
 
@@ -155,3 +170,416 @@ func TestDisplayVersionPriority(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFlagsSeparatesFlagsFromInputs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want cliOptions
+	}{
+		{name: "short help", args: []string{"-h"}, want: cliOptions{help: true}},
+		{name: "long version", args: []string{"--version"}, want: cliOptions{version: true}},
+		{name: "history", args: []string{"--history"}, want: cliOptions{history: true}},
+		{name: "print metadata", args: []string{"--print-metadata", "song.mp3"}, want: cliOptions{printMetadata: "song.mp3"}},
+		{name: "decode", args: []string{"--decode", "song.flac", "--wav", "--rate", "44100", "--channels", "1"}, want: cliOptions{decode: "song.flac", wav: true, rate: 44100, channels: 1}},
+		{name: "sleep", args: []string{"--sleep", "30m", "song.mp3"}, want: cliOptions{sleep: 30 * time.Minute, args: []string{"song.mp3"}}},
+		{name: "seek step", args: []string{"--seek-step", "15s", "song.mp3"}, want: cliOptions{seekStep: 15 * time.Second, args: []string{"song.mp3"}}},
+		{name: "latency", args: []string{"--latency", "40ms", "song.mp3"}, want: cliOptions{latency: 40 * time.Millisecond, args: []string{"song.mp3"}}},
+		{name: "start at", args: []string{"--start-at", "1:02:03", "book.m4b"}, want: cliOptions{startAt: time.Hour + 2*time.Minute + 3*time.Second, hasStartAt: true, args: []string{"book.m4b"}}},
+		{name: "start at seconds", args: []string{"--start-at", "0", "song.mp3"}, want: cliOptions{hasStartAt: true, args: []string{"song.mp3"}}},
+		{name: "notify", args: []string{"--notify", "song.mp3"}, want: cliOptions{notify: true, args: []string{"song.mp3"}}},
+		{name: "paused", args: []string{"--paused", "--start-at", "90", "song.mp3"}, want: cliOptions{paused: true, startAt: 90 * time.Second, hasStartAt: true, args: []string{"song.mp3"}}},
+		{name: "sort", args: []string{"--sort", "Natural", "album/"}, want: cliOptions{sort: "natural", args: []string{"album/"}}},
+		{name: "fresh", args: []string{"--fresh", "song.mp3"}, want: cliOptions{fresh: true, args: []string{"song.mp3"}}},
+		{name: "compact", args: []string{"--compact", "song.mp3"}, want: cliOptions{compact: true, args: []string{"song.mp3"}}},
+		{name: "repeat and shuffle", args: []string{"--repeat", "all", "--shuffle", "album/"}, want: cliOptions{repeat: "all", shuffle: true, args: []string{"album/"}}},
+		{name: "downloads", args: []string{"--downloads", "3", "playlist.m3u"}, want: cliOptions{downloads: 3, args: []string{"playlist.m3u"}}},
+		{name: "keep", args: []string{"--keep", "https://example.com/list"}, want: cliOptions{keep: true, args: []string{"https://example.com/list"}}},
+		{name: "resample", args: []string{"--resample", "hq", "song.flac"}, want: cliOptions{resample: player.ResampleSinc, args: []string{"song.flac"}}},
+		{name: "dither", args: []string{"--dither", "song.flac"}, want: cliOptions{dither: true, args: []string{"song.flac"}}},
+		{name: "theme", args: []string{"--theme", "Sunset", "song.flac"}, want: cliOptions{theme: "sunset", args: []string{"song.flac"}}},
+		{name: "url input", args: []string{"https://example.com/a?b=-h"}, want: cliOptions{args: []string{"https://example.com/a?b=-h"}}},
+		{name: "dash-dash ends flags", args: []string{"--", "-v.mp3"}, want: cliOptions{args: []string{"-v.mp3"}}},
+		{name: "file url input", args: []string{"file:My%20Song.mp3"}, want: cliOptions{args: []string{"My Song.mp3"}}},
+		{name: "convert to", args: []string{"--convert-to", "FLAC", "music/"}, want: cliOptions{convertTo: "flac", args: []string{"music/"}}},
+		{name: "bench", args: []string{"--bench", "song.m4a", "--iterations", "5"}, want: cliOptions{bench: "song.m4a", iterations: 5}},
+		{name: "stdin", args: []string{"--compact", "-"}, want: cliOptions{compact: true, args: []string{"-"}}},
+		{name: "daemon", args: []string{"--daemon", "--socket", "/tmp/c.sock", "album/"}, want: cliOptions{daemon: true, socket: "/tmp/c.sock", args: []string{"album/"}}},
+		{name: "send", args: []string{"--send", "seek +10"}, want: cliOptions{send: "seek +10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFlags(tt.args)
+			if err != nil {
+				t.Fatalf("parseFlags() error = %v", err)
+			}
+			if got.help != tt.want.help || got.version != tt.want.version || got.history != tt.want.history || got.printMetadata != tt.want.printMetadata || got.decode != tt.want.decode || got.wav != tt.want.wav || got.rate != tt.want.rate || got.channels != tt.want.channels || got.bench != tt.want.bench || got.iterations != tt.want.iterations || got.sleep != tt.want.sleep || got.seekStep != tt.want.seekStep || got.startAt != tt.want.startAt || got.hasStartAt != tt.want.hasStartAt || got.notify != tt.want.notify || got.compact != tt.want.compact || got.repeat != tt.want.repeat || got.shuffle != tt.want.shuffle || got.downloads != tt.want.downloads || got.keep != tt.want.keep || got.resample != tt.want.resample || got.theme != tt.want.theme || got.dither != tt.want.dither || got.convertTo != tt.want.convertTo || got.paused != tt.want.paused || got.fresh != tt.want.fresh || got.daemon != tt.want.daemon || got.send != tt.want.send || (tt.want.socket != "" && got.socket != tt.want.socket) || (tt.want.sort != "" && got.sort != tt.want.sort) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			if strings.Join(got.args, " ") != strings.Join(tt.want.args, " ") {
+				t.Fatalf("args = %q, want %q", got.args, tt.want.args)
+			}
+		})
+	}
+
+	if _, err := parseFlags([]string{"--bogus"}); err == nil {
+		t.Fatal("expected unknown flag to fail")
+	}
+	if _, err := parseFlags([]string{"--repeat", "twice", "song.mp3"}); err == nil {
+		t.Fatal("expected invalid repeat mode to fail")
+	}
+	if _, err := parseFlags([]string{"--seek-step", "-5s", "song.mp3"}); err == nil {
+		t.Fatal("expected a negative seek step to fail")
+	}
+	if _, err := parseFlags([]string{"--start-at", "1:75", "song.mp3"}); err == nil {
+		t.Fatal("expected an invalid --start-at timestamp to fail")
+	}
+	if _, err := parseFlags([]string{"--start-at", "30"}); err == nil {
+		t.Fatal("expected --start-at without a file to fail")
+	}
+	if _, err := parseFlags([]string{"--sort", "date", "album/"}); err == nil {
+		t.Fatal("expected an unknown sort order to fail")
+	}
+	if _, err := parseFlags([]string{"--convert-to", "mp3", "music/"}); err == nil {
+		t.Fatal("expected an unsupported conversion format to fail")
+	}
+	if _, err := parseFlags([]string{"--convert-to", "wav"}); err == nil {
+		t.Fatal("expected --convert-to without a directory to fail")
+	}
+	if _, err := parseFlags([]string{"-", "song.mp3"}); err == nil {
+		t.Fatal("expected stdin mixed with other inputs to fail")
+	}
+	if _, err := parseFlags([]string{"--downloads", "9", "song.mp3"}); err == nil {
+		t.Fatal("expected out-of-range download count to fail")
+	}
+	if _, err := parseFlags([]string{"--downloads", "0", "song.mp3"}); err == nil {
+		t.Fatal("expected a download count of 0 to fail")
+	}
+	if _, err := parseFlags([]string{"--resample", "cubic", "song.mp3"}); err == nil {
+		t.Fatal("expected unknown resample quality to fail")
+	}
+	if _, err := parseFlags([]string{"--theme", "neon", "song.mp3"}); err == nil {
+		t.Fatal("expected an unknown theme to fail")
+	}
+	if _, err := parseFlags([]string{"--wav", "song.mp3"}); err == nil {
+		t.Fatal("expected --wav without --decode to fail")
+	}
+	if _, err := parseFlags([]string{"--iterations", "3", "song.mp3"}); err == nil {
+		t.Fatal("expected --iterations without --bench to fail")
+	}
+	if _, err := parseFlags([]string{"--decode", "song.mp3", "--channels", "6"}); err == nil {
+		t.Fatal("expected an unsupported channel count to fail")
+	}
+	if _, err := parseFlags([]string{"--daemon"}); err == nil {
+		t.Fatal("expected --daemon without inputs to fail")
+	}
+	if _, err := parseFlags([]string{"--send", "pause", "song.mp3"}); err == nil {
+		t.Fatal("expected --send with inputs to fail")
+	}
+	if _, err := parseFlags([]string{"--socket", "/tmp/c.sock", "song.mp3"}); err == nil {
+		t.Fatal("expected --socket without --daemon or --send to fail")
+	}
+}
+
+func TestSendControlRoundTrip(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "c.sock")
+	ln, err := listenControl(socket)
+	if err != nil {
+		t.Fatalf("listenControl() error = %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveControl(conn, func(command string) []byte {
+				if command != "status" {
+					return []byte(`{"ok":false,"error":"unknown command"}`)
+				}
+				return []byte(`{"ok":true,"status":{"title":"Song","state":"playing"}}`)
+			})
+		}
+	}()
+
+	if _, err := listenControl(socket); err == nil {
+		t.Fatal("listenControl() on a live socket succeeded, want an error")
+	}
+	var out bytes.Buffer
+	if err := sendControl(&out, socket, "status"); err != nil {
+		t.Fatalf("sendControl(status) error = %v", err)
+	}
+	if !strings.Contains(out.String(), `"title":"Song"`) || !strings.HasSuffix(out.String(), "\n") {
+		t.Fatalf("sendControl(status) wrote %q", out.String())
+	}
+	if err := sendControl(io.Discard, socket, "dance"); err == nil || err.Error() != "unknown command" {
+		t.Fatalf("sendControl(dance) error = %v, want the daemon's error", err)
+	}
+	if err := sendControl(io.Discard, filepath.Join(t.TempDir(), "none.sock"), "status"); err == nil {
+		t.Fatal("sendControl() with no daemon succeeded, want an error")
+	}
+}
+
+func TestMakePrivateDirRejectsSharedDirectories(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "climp-1000")
+	if err := makePrivateDir(dir); err != nil {
+		t.Fatalf("makePrivateDir() error = %v", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS == "windows" {
+		return // no Unix permission bits to check
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		t.Fatalf("created %s with mode %o, want 700", dir, perm)
+	}
+	if err := makePrivateDir(dir); err != nil {
+		t.Fatalf("makePrivateDir() on its own directory error = %v", err)
+	}
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := makePrivateDir(dir); err == nil {
+		t.Fatal("makePrivateDir() accepted a directory other users can enter")
+	}
+}
+
+func TestFileURLToPath(t *testing.T) {
+	tests := []struct {
+		name, in, goos, want string
+	}{
+		{name: "plain path", in: "music/song.mp3", goos: "linux", want: "music/song.mp3"},
+		{name: "http url", in: "https://example.com/a.mp3", goos: "linux", want: "https://example.com/a.mp3"},
+		{name: "encoded spaces", in: "file:///home/me/My%20Music/Song%20One.flac", goos: "linux", want: "/home/me/My Music/Song One.flac"},
+		{name: "encoded unicode and hash", in: "file:///tmp/caf%C3%A9%20%231.mp3", goos: "darwin", want: "/tmp/café #1.mp3"},
+		{name: "localhost", in: "FILE://localhost/tmp/a.mp3", goos: "linux", want: "/tmp/a.mp3"},
+		{name: "windows drive letter", in: "file:///C:/Users/Me/My%20Music/a.mp3", goos: "windows", want: `C:\Users\Me\My Music\a.mp3`},
+		{name: "windows lowercase drive", in: "file:///d:/a.mp3", goos: "windows", want: `d:\a.mp3`},
+		{name: "windows opaque drive", in: "file:C:/a%20b.mp3", goos: "windows", want: `C:\a b.mp3`},
+		{name: "windows unc", in: "file://server/share/a.mp3", goos: "windows", want: `\\server\share\a.mp3`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fileURLToPath(tt.in, tt.goos)
+			if err != nil {
+				t.Fatalf("fileURLToPath(%q) error = %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Fatalf("fileURLToPath(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := fileURLToPath("file:///tmp/bad%zz.mp3", "linux"); err == nil {
+		t.Fatal("expected malformed percent-encoding to fail")
+	}
+	if _, err := fileURLToPath("file://server/share/a.mp3", "linux"); err == nil {
+		t.Fatal("expected a remote host to fail outside Windows")
+	}
+}
+
+func TestConvertDirConvertsSkipsAndContinuesPastFailures(t *testing.T) {
+	dir := t.TempDir()
+	pcm := make([]byte, 4*4410)
+	var wav bytes.Buffer
+	wav.WriteString("RIFF")
+	wav.Write(binary.LittleEndian.AppendUint32(nil, uint32(36+len(pcm))))
+	wav.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(2), uint32(44100), uint32(44100 * 4), uint16(4), uint16(16)} {
+		_ = binary.Write(&wav, binary.LittleEndian, v)
+	}
+	wav.WriteString("data")
+	wav.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(pcm))))
+	wav.Write(pcm)
+	if err := os.WriteFile(filepath.Join(dir, "tone.wav"), wav.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.mp3"), []byte("not audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := convertDir(&out, dir, "flac"); err == nil {
+		t.Fatal("convertDir() succeeded with a broken file, want an error")
+	}
+	if !strings.Contains(out.String(), "Converted 1, skipped 0, failed 1") {
+		t.Fatalf("summary missing from:\n%s", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tone.flac")); err != nil {
+		t.Fatalf("tone.flac not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "broken.flac")); !os.IsNotExist(err) {
+		t.Fatal("a failed conversion left a partial file")
+	}
+
+	out.Reset()
+	_ = convertDir(&out, dir, "flac")
+	if !strings.Contains(out.String(), "Converted 0, skipped 2, failed 1") {
+		t.Fatalf("second run should skip tone.wav (exists) and tone.flac (already flac):\n%s", out.String())
+	}
+}
+
+func TestSortAudioFilesNatural(t *testing.T) {
+	files := []string{"/a/Track 10.mp3", "/a/track 2.mp3", "/a/Track 1.mp3", "/a/Track 02b.mp3"}
+	sortAudioFiles(files, sortNatural)
+	want := []string{"/a/Track 1.mp3", "/a/track 2.mp3", "/a/Track 02b.mp3", "/a/Track 10.mp3"}
+	if strings.Join(files, "|") != strings.Join(want, "|") {
+		t.Fatalf("natural order = %q, want %q", files, want)
+	}
+
+	sortAudioFiles(files, sortName)
+	if files[0] != "/a/Track 02b.mp3" || files[3] != "/a/track 2.mp3" {
+		t.Fatalf("name order = %q, want plain case-insensitive order", files)
+	}
+}
+
+func TestSortAudioFilesKeepsDirectoriesTogether(t *testing.T) {
+	sep := string(filepath.Separator)
+	files := []string{
+		sep + strings.Join([]string{"m", "b.mp3"}, sep),
+		sep + strings.Join([]string{"m", "artist two", "a.mp3"}, sep),
+		sep + strings.Join([]string{"m", "Artist", "y.mp3"}, sep),
+		sep + strings.Join([]string{"m", "Artist", "Album", "x.mp3"}, sep),
+	}
+	for _, mode := range []string{sortName, sortNatural} {
+		got := slices.Clone(files)
+		sortAudioFiles(got, mode)
+		want := []string{files[3], files[2], files[1], files[0]}
+		if !slices.Equal(got, want) {
+			t.Fatalf("%s order = %q, want %q", mode, got, want)
+		}
+	}
+}
+
+func TestWriteHelpListsFormats(t *testing.T) {
+	var out strings.Builder
+	writeHelp(&out)
+	if !strings.Contains(out.String(), media.SupportedExtsList()) {
+		t.Fatalf("expected supported formats in help, got %q", out.String())
+	}
+}
+
+func TestPickThemePrefersFlag(t *testing.T) {
+	if got := pickTheme("matrix", "sunset").Name; got != "matrix" {
+		t.Fatalf("pickTheme(flag) = %q, want matrix", got)
+	}
+	if got := pickTheme("", "sunset").Name; got != "sunset" {
+		t.Fatalf("pickTheme(saved) = %q, want sunset", got)
+	}
+	if got := pickTheme("", "bogus").Name; got != "default" {
+		t.Fatalf("pickTheme(unknown saved) = %q, want default", got)
+	}
+}
+
+func TestPrintHistoryListsRecentSourcesWithCounts(t *testing.T) {
+	at := time.Date(2026, time.October, 16, 14, 2, 0, 0, time.Local)
+	var out strings.Builder
+	printHistory(&out, []config.Play{
+		{Source: "/music/a.mp3", Title: "A", At: at},
+		{Source: "/music/b.mp3", Title: "B", At: at.Add(time.Minute)},
+		{Source: "/music/a.mp3", Title: "A", At: at.Add(2 * time.Minute)},
+	})
+	want := "2026-10-16 14:04     2x  A  /music/a.mp3\n" +
+		"2026-10-16 14:03     1x  B  /music/b.mp3\n"
+	if out.String() != want {
+		t.Fatalf("printHistory() = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	printHistory(&out, nil)
+	if !strings.Contains(out.String(), "No plays") {
+		t.Fatalf("printHistory(nil) = %q", out.String())
+	}
+}
+
+func TestBufferStdinSniffsFormatAndCleansUp(t *testing.T) {
+	data := append([]byte("fLaC"), bytes.Repeat([]byte{0}, 200)...)
+	path, cleanup, err := bufferStdin(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("bufferStdin() error = %v", err)
+	}
+	if filepath.Base(path) != "stdin.flac" {
+		t.Fatalf("bufferStdin() path = %q, want a stdin.flac file", path)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("buffered %d bytes (err %v), want all %d", len(got), err, len(data))
+	}
+	cleanup()
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Fatalf("cleanup left %s behind", filepath.Dir(path))
+	}
+
+	for _, in := range []string{"", "plain text, not audio"} {
+		if _, _, err := bufferStdin(strings.NewReader(in)); err == nil {
+			t.Fatalf("bufferStdin(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestDecodePCMRejectsUnsupportedInput(t *testing.T) {
+	txt := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(txt, []byte("hi"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{txt, "https://example.com/a.mp3", filepath.Dir(txt)} {
+		var out bytes.Buffer
+		if err := decodePCM(&out, cliOptions{decode: path}); err == nil {
+			t.Fatalf("decodePCM(%q) succeeded, want an error", path)
+		}
+		if out.Len() != 0 {
+			t.Fatalf("decodePCM(%q) wrote %d bytes before failing", path, out.Len())
+		}
+	}
+}
+
+func TestBenchDecodeReportsFramesAndRealtime(t *testing.T) {
+	pcm := make([]byte, 4*44100)
+	var wav bytes.Buffer
+	wav.WriteString("RIFF")
+	wav.Write(binary.LittleEndian.AppendUint32(nil, uint32(36+len(pcm))))
+	wav.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(2), uint32(44100), uint32(44100 * 4), uint16(4), uint16(16)} {
+		_ = binary.Write(&wav, binary.LittleEndian, v)
+	}
+	wav.WriteString("data")
+	wav.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(pcm))))
+	wav.Write(pcm)
+	path := filepath.Join(t.TempDir(), "tone.wav")
+	if err := os.WriteFile(path, wav.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := benchDecode(&out, cliOptions{bench: path, iterations: 3}); err != nil {
+		t.Fatalf("benchDecode() error = %v", err)
+	}
+	var got benchJSON
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("benchDecode() wrote invalid JSON %q: %v", out.String(), err)
+	}
+	if got.Iterations != 3 || got.Frames != 44100 || got.SampleRate != 44100 || got.Channels != 2 || got.AudioSeconds != 1 {
+		t.Fatalf("benchDecode() = %+v, want 3 runs of 44100 stereo frames", got)
+	}
+	if got.RealtimeFactor <= 0 || got.FramesPerSec <= 0 {
+		t.Fatalf("benchDecode() = %+v, want positive throughput", got)
+	}
+
+	if err := benchDecode(&out, cliOptions{bench: "https://example.com/a.m4a"}); err == nil {
+		t.Fatal("benchDecode() accepted a URL")
+	}
+}
+
+func TestErrorTextExplainsWebPages(t *testing.T) {
+	err := fmt.Errorf("open: %w", downloader.ErrNotPlayableMedia)
+	if got := errorText(err); !strings.Contains(got, "may require login") {
+		t.Fatalf("errorText() = %q, want the friendly web page message", got)
+	}
+	if got := errorText(errors.New("boom")); got != "boom" {
+		t.Fatalf("errorText() = %q, want the error unchanged", got)
+	}
+}
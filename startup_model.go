@@ -8,7 +8,10 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/olivier-w/climp/internal/config"
 	"github.com/olivier-w/climp/internal/downloader"
+	"github.com/olivier-w/climp/internal/player"
+	"github.com/olivier-w/climp/internal/scrobble"
 	"github.com/olivier-w/climp/internal/ui"
 )
 
@@ -37,6 +40,7 @@ type startupModel struct {
 	status    downloader.DownloadStatus
 	statusCh  chan downloader.DownloadStatus
 	hasStatus bool
+	opts      cliOptions // playback flags handed to the playback model
 }
 
 func newStartupModel() startupModel {
@@ -50,7 +54,9 @@ func newStartupModel() startupModel {
 	)
 
 	return startupModel{
-		browser:  ui.NewEmbeddedBrowser(),
+		browser: ui.NewEmbeddedBrowser().
+			WithRecentPlays(config.RecentPlays(config.LoadHistory(), recentPlaysShown)).
+			WithFavorites(config.LoadFavorites()),
 		phase:    phaseBrowse,
 		spinner:  s,
 		progress: p,
@@ -115,12 +121,13 @@ func (m startupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case startupResolvedMsg:
 		if msg.err != nil {
 			m.phase = phaseBrowse
-			m.errMsg = msg.err.Error()
+			m.errMsg = errorText(msg.err)
 			m.hasStatus = false
 			m.statusCh = nil
 			return m, nil
 		}
 
+		msg.model = m.opts.apply(msg.model)
 		cmds := []tea.Cmd{msg.model.Init()}
 		if m.width > 0 || m.height > 0 {
 			w, h := m.width, m.height
@@ -225,6 +232,12 @@ func (m startupModel) renderOpeningView() string {
 		b.WriteString(" ")
 		b.WriteString(startupStatusStyle.Render(label))
 		b.WriteString("\n")
+	case "resuming":
+		b.WriteString("  ")
+		b.WriteString(m.spinner.View())
+		b.WriteString(" ")
+		b.WriteString(startupStatusStyle.Render("Download stalled, resuming..."))
+		b.WriteString("\n")
 	case "converting":
 		b.WriteString("  ")
 		b.WriteString(m.spinner.View())
@@ -252,10 +265,15 @@ func (m startupModel) renderError() string {
 func openSelectionCmd(path string, statusCh chan downloader.DownloadStatus) tea.Cmd {
 	return func() tea.Msg {
 		defer close(statusCh)
+		settings := config.Load()
+		player.SetStartSettings(settings.Volume, player.SpeedMode(settings.Speed))
 		model, err := buildPlaybackModel(path, func(rawURL string) (ui.DownloadResult, error) {
 			return downloadURLInline(rawURL, statusCh)
 		})
-		return startupResolvedMsg{model: model, err: err}
+		if err != nil {
+			return startupResolvedMsg{err: err}
+		}
+		return startupResolvedMsg{model: model.ApplySettings(settings).ResumeBookmark().WithScrobbler(scrobble.New(config.LoadScrobble())).WithPlayHistory()}
 	}
 }
 
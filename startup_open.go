@@ -1,11 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/olivier-w/climp/internal/config"
 	"github.com/olivier-w/climp/internal/downloader"
 	"github.com/olivier-w/climp/internal/media"
 	"github.com/olivier-w/climp/internal/player"
@@ -15,9 +21,6 @@ import (
 
 func buildPlaybackModel(arg string, downloadURL urlDownloadFunc) (ui.Model, error) {
 	var playlistEntries []media.PlaylistEntry
-	playlistStartIdx := -1
-	var playlistStartCleanup func()
-	var playlistSourcePath string
 	playlistName := ""
 	metaSet := false
 
@@ -30,6 +33,9 @@ func buildPlaybackModel(arg string, downloadURL urlDownloadFunc) (ui.Model, erro
 
 	if downloader.IsURL(arg) {
 		route, err := downloader.ResolveURLRoute(arg)
+		if errors.Is(err, downloader.ErrNotPlayableMedia) {
+			return ui.Model{}, err
+		}
 		if err != nil {
 			route = downloader.URLRouteResult{
 				Kind:     downloader.RouteFiniteDownload,
@@ -52,7 +58,10 @@ func buildPlaybackModel(arg string, downloadURL urlDownloadFunc) (ui.Model, erro
 				p, err = player.NewStream(route.FinalURL)
 				if err == nil {
 					openedLive = true
-					meta = player.Metadata{Title: route.FinalURL}
+					meta = player.Metadata{
+						Title: route.Station.Title(route.FinalURL),
+						Album: route.Station.Details(),
+					}
 					metaSet = true
 				}
 			}
@@ -106,29 +115,24 @@ func buildPlaybackModel(arg string, downloadURL urlDownloadFunc) (ui.Model, erro
 			}
 		} else if !media.IsSupportedExt(ext) {
 			return ui.Model{}, fmt.Errorf("unsupported format %s (supported: %s)", ext, media.SupportedExtsList())
+		} else if sheet, ok := media.SiblingCueSheet(path); ok {
+			playlistName = playlistNameFromFile(path)
+			playlistEntries = sheet
 		}
 	}
 
 	if len(playlistEntries) > 0 {
-		var err error
-		var start playlistStart
-		playlistEntries, start, err = openFirstPlayablePlaylistEntry(playlistEntries, downloadURL)
-		if err != nil {
-			return ui.Model{}, err
-		}
-		playlistStartIdx = start.startIdx
-		playlistStartCleanup = start.cleanup
-		playlistSourcePath = start.sourcePath
-		if start.path != "" {
-			path = start.path
-		}
-		if start.player != nil {
-			p = start.player
-			path = ""
-		}
-		if start.metaSet {
-			meta = start.meta
-			metaSet = true
+		return buildPlaylistModel(playlistEntries, 0, playlistName, downloadURL)
+	}
+
+	// A sibling queue picks up where the last session in its folder stopped.
+	var siblings []string
+	var resumeAt time.Duration
+	resumed := false
+	if !downloader.IsURL(arg) {
+		siblings = scanAudioFiles(path)
+		if saved, at, ok := savedFolderTrack(siblings); ok {
+			path, resumeAt, resumed = saved, at, true
 		}
 	}
 
@@ -143,49 +147,15 @@ func buildPlaybackModel(arg string, downloadURL urlDownloadFunc) (ui.Model, erro
 			if cleanup != nil {
 				cleanup()
 			}
-			if playlistStartCleanup != nil {
-				playlistStartCleanup()
-			}
 			return ui.Model{}, fmt.Errorf("error creating player: %w", err)
 		}
 	}
 
-	if len(playlistEntries) > 0 {
-		tracks := make([]queue.Track, len(playlistEntries))
-		for i, e := range playlistEntries {
-			title := e.Title
-			if title == "" && e.Path != "" {
-				title = strings.TrimSuffix(filepath.Base(e.Path), filepath.Ext(e.Path))
-			}
-			if title == "" && e.URL != "" {
-				title = e.URL
-			}
-
-			tracks[i] = queue.Track{
-				Title: title,
-				URL:   e.URL,
-				Path:  e.Path,
-			}
-			if e.URL != "" && e.Path == "" && !downloader.IsLiveURL(e.URL) {
-				tracks[i].State = queue.Pending
-			} else {
-				tracks[i].State = queue.Ready
-			}
-		}
-		tracks[playlistStartIdx].State = queue.Playing
-		if playlistStartCleanup != nil {
-			tracks[playlistStartIdx].Cleanup = playlistStartCleanup
-		}
-		q := queue.New(tracks)
-		q.SetCurrentIndex(playlistStartIdx)
-		return ui.NewWithQueue(p, meta, playlistSourcePath, q, playlistName), nil
-	}
-
 	if downloader.IsURL(arg) {
 		return ui.New(p, meta, sourcePath, originalURL, cleanup), nil
 	}
 
-	if siblings := scanAudioFiles(path); siblings != nil {
+	if siblings != nil {
 		playlistName = playlistNameFromDirectoryOfFile(path)
 		tracks := make([]queue.Track, len(siblings))
 		var startIdx int
@@ -203,8 +173,238 @@ func buildPlaybackModel(arg string, downloadURL urlDownloadFunc) (ui.Model, erro
 		tracks[startIdx].State = queue.Playing
 		q := queue.New(tracks)
 		q.SetCurrentIndex(startIdx)
-		return ui.NewWithQueue(p, meta, "", q, playlistName), nil
+		m := ui.NewWithQueue(p, meta, "", q, playlistName).WithFolderSession(filepath.Dir(absPath), siblings)
+		if resumed {
+			m = m.ResumeFolder(resumeAt)
+		}
+		return m, nil
 	}
 
 	return ui.New(p, meta, "", "", nil), nil
 }
+
+// buildPlaylistModel opens the first playable entry at or after first and
+// queues them all.
+func buildPlaylistModel(entries []media.PlaylistEntry, first int, name string, downloadURL urlDownloadFunc) (ui.Model, error) {
+	_, start, err := openFirstPlayablePlaylistEntry(entries[first:], downloadURL)
+	if err != nil {
+		return ui.Model{}, err
+	}
+	start.startIdx += first
+
+	path := start.path
+	p := start.player
+	meta := start.meta
+	if !start.metaSet {
+		meta = player.ReadMetadata(path)
+	}
+	if p == nil {
+		p, err = player.New(path)
+		if err != nil {
+			if start.cleanup != nil {
+				start.cleanup()
+			}
+			return ui.Model{}, fmt.Errorf("error creating player: %w", err)
+		}
+	}
+	if e := entries[start.startIdx]; e.Start > 0 || e.End > 0 {
+		p.SetBounds(e.Start, e.End)
+		meta.Title = e.Title
+		meta.Chapters = nil
+	}
+
+	tracks := make([]queue.Track, len(entries))
+	for i, e := range entries {
+		title := e.Title
+		if title == "" && e.Path != "" {
+			title = strings.TrimSuffix(filepath.Base(e.Path), filepath.Ext(e.Path))
+		}
+		if title == "" && e.URL != "" {
+			title = e.URL
+		}
+
+		tracks[i] = queue.Track{
+			Title: title,
+			URL:   e.URL,
+			Path:  e.Path,
+			Start: e.Start,
+			End:   e.End,
+		}
+		if e.URL != "" && e.Path == "" && !downloader.IsLiveURL(e.URL) {
+			tracks[i].State = queue.Pending
+		} else {
+			tracks[i].State = queue.Ready
+		}
+	}
+	tracks[start.startIdx].State = queue.Playing
+	if start.cleanup != nil {
+		tracks[start.startIdx].Cleanup = start.cleanup
+	}
+	q := queue.New(tracks)
+	q.SetCurrentIndex(start.startIdx)
+	return ui.NewWithQueue(p, meta, start.sourcePath, q, name), nil
+}
+
+// buildQueueModel plays several command-line inputs as one queue, in order.
+// Each input may be a local file, directory, playlist file, or URL; inputs
+// that cannot be played are reported to warn and skipped.
+func buildQueueModel(args []string, downloadURL urlDownloadFunc, warn io.Writer) (ui.Model, error) {
+	entries := collectInputEntries(args, false, warn)
+	entries = expandRemotePlaylistEntries(entries, maxRemotePlaylistDepth)
+	if len(entries) == 0 {
+		return ui.Model{}, fmt.Errorf("no playable inputs")
+	}
+	return buildPlaylistModel(entries, 0, "Playlist", downloadURL)
+}
+
+// buildRecursiveModel is buildQueueModel for --recursive: directories are
+// walked with their subdirectories, and a lone local file queues the whole
+// tree under its directory, starting at that file. With shuffle the entries
+// are collected in random order instead of sorted.
+func buildRecursiveModel(args []string, shuffle bool, downloadURL urlDownloadFunc, warn io.Writer) (ui.Model, error) {
+	if len(args) == 1 && !downloader.IsURL(args[0]) {
+		if info, err := os.Stat(args[0]); err == nil && !info.IsDir() && media.IsSupportedExt(strings.ToLower(filepath.Ext(args[0]))) {
+			return buildFileTreeModel(args[0], shuffle, downloadURL, warn)
+		}
+	}
+
+	entries := collectInputEntries(args, true, warn)
+	entries = expandRemotePlaylistEntries(entries, maxRemotePlaylistDepth)
+	if len(entries) == 0 {
+		return ui.Model{}, fmt.Errorf("no playable inputs")
+	}
+	if shuffle {
+		rand.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+	}
+	name := "Playlist"
+	if len(args) == 1 {
+		if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+			name = playlistNameFromDir(args[0])
+		}
+	}
+	return buildPlaylistModel(entries, 0, name, downloadURL)
+}
+
+// buildFileTreeModel queues every audio file under path's directory and
+// starts at path. Like the single-directory scan, it needs at least two
+// files to build a queue.
+func buildFileTreeModel(path string, shuffle bool, downloadURL urlDownloadFunc, warn io.Writer) (ui.Model, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return ui.Model{}, err
+	}
+	root := filepath.Dir(absPath)
+	files := treeFiles(root, warn)
+	if len(files) < 2 {
+		return buildPlaybackModel(path, downloadURL)
+	}
+	if shuffle {
+		rand.Shuffle(len(files), func(i, j int) { files[i], files[j] = files[j], files[i] })
+	}
+	first := slices.Index(files, absPath)
+	if first < 0 {
+		first = 0
+	}
+	return buildPlaylistModel(treeEntries(root, files), first, playlistNameFromDirectoryOfFile(absPath), downloadURL)
+}
+
+// treeFiles scans root recursively, warning when the scan hit maxTreeFiles.
+func treeFiles(root string, warn io.Writer) []string {
+	files, truncated := scanAudioTree(root)
+	if truncated {
+		fmt.Fprintf(warn, "Stopped scanning %s after %d files\n", root, maxTreeFiles)
+	}
+	return files
+}
+
+// treeEntries turns files found under root into entries titled by their
+// path relative to root.
+func treeEntries(root string, files []string) []media.PlaylistEntry {
+	entries := make([]media.PlaylistEntry, len(files))
+	for i, f := range files {
+		entries[i] = media.PlaylistEntry{Path: f, Title: treeTitle(root, f)}
+	}
+	return entries
+}
+
+// collectInputEntries resolves command-line inputs into playlist entries.
+// URLs are left for expandRemotePlaylistEntries to route. With recursive,
+// directories include their subdirectories.
+func collectInputEntries(args []string, recursive bool, warn io.Writer) []media.PlaylistEntry {
+	var entries []media.PlaylistEntry
+	for _, arg := range args {
+		if downloader.IsURL(arg) {
+			entries = append(entries, media.PlaylistEntry{URL: arg})
+			continue
+		}
+
+		info, err := os.Stat(arg)
+		if err != nil {
+			fmt.Fprintf(warn, "Skipping %s: %v\n", arg, err)
+			continue
+		}
+		if info.IsDir() {
+			if recursive {
+				root, _ := filepath.Abs(arg)
+				files := treeFiles(root, warn)
+				if len(files) == 0 {
+					fmt.Fprintf(warn, "Skipping %s: no supported audio files\n", arg)
+					continue
+				}
+				entries = append(entries, treeEntries(root, files)...)
+				continue
+			}
+			files := listAudioFiles(arg)
+			if len(files) == 0 {
+				fmt.Fprintf(warn, "Skipping %s: no supported audio files\n", arg)
+				continue
+			}
+			for _, f := range files {
+				entries = append(entries, media.PlaylistEntry{Path: f})
+			}
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(arg))
+		switch {
+		case media.IsPlaylistExt(ext):
+			parsed, err := media.ParseLocalPlaylist(arg)
+			if err != nil {
+				fmt.Fprintf(warn, "Skipping %s: %v\n", arg, err)
+				continue
+			}
+			playable, _ := media.FilterPlayablePlaylistEntries(parsed)
+			if len(playable) == 0 {
+				fmt.Fprintf(warn, "Skipping %s: playlist contains no playable entries\n", arg)
+				continue
+			}
+			entries = append(entries, playable...)
+		case media.IsSupportedExt(ext):
+			if sheet, ok := media.SiblingCueSheet(arg); ok {
+				entries = append(entries, sheet...)
+				continue
+			}
+			entries = append(entries, media.PlaylistEntry{Path: arg})
+		default:
+			fmt.Fprintf(warn, "Skipping %s: unsupported format %s\n", arg, ext)
+		}
+	}
+	return entries
+}
+
+// freshStart ignores saved folder positions, set from --fresh.
+var freshStart bool
+
+// savedFolderTrack returns the file and position where the last session
+// over files stopped, unless --fresh was given or the folder's file list has
+// changed since.
+func savedFolderTrack(files []string) (string, time.Duration, bool) {
+	if freshStart || len(files) == 0 {
+		return "", 0, false
+	}
+	idx, pos, ok := config.LoadFolderPosition(filepath.Dir(files[0]), files)
+	if !ok {
+		return "", 0, false
+	}
+	return files[idx], pos, true
+}
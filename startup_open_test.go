@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olivier-w/climp/internal/config"
+)
+
+func TestCollectInputEntriesKeepsOrderAndSkipsBadInputs(t *testing.T) {
+	dir := t.TempDir()
+	album := filepath.Join(dir, "album")
+	if err := os.Mkdir(album, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		filepath.Join(dir, "intro.mp3"),
+		filepath.Join(dir, "notes.txt"),
+		filepath.Join(album, "02 Two.flac"),
+		filepath.Join(album, "01 One.flac"),
+		filepath.Join(album, "cover.jpg"),
+	} {
+		if err := os.WriteFile(name, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var warn strings.Builder
+	entries := collectInputEntries([]string{
+		filepath.Join(dir, "intro.mp3"),
+		"https://example.com/stream.mp3",
+		filepath.Join(dir, "missing.mp3"),
+		filepath.Join(dir, "notes.txt"),
+		album,
+	}, false, &warn)
+
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d: %+v", len(entries), entries)
+	}
+	if entries[0].Path != filepath.Join(dir, "intro.mp3") {
+		t.Fatalf("expected intro first, got %+v", entries[0])
+	}
+	if entries[1].URL != "https://example.com/stream.mp3" {
+		t.Fatalf("expected URL second, got %+v", entries[1])
+	}
+	if filepath.Base(entries[2].Path) != "01 One.flac" || filepath.Base(entries[3].Path) != "02 Two.flac" {
+		t.Fatalf("expected sorted directory tracks last, got %+v", entries[2:])
+	}
+
+	out := warn.String()
+	if !strings.Contains(out, "missing.mp3") || !strings.Contains(out, "notes.txt") {
+		t.Fatalf("expected warnings for skipped inputs, got %q", out)
+	}
+}
+
+func TestCollectInputEntriesRecursiveWalksSubdirectories(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"b.mp3",
+		filepath.Join("Artist", "Album", "01 One.flac"),
+		filepath.Join("artist two", "a.ogg"),
+		filepath.Join(".hidden", "skip.mp3"),
+		filepath.Join("Artist", "cover.jpg"),
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var warn strings.Builder
+	entries := collectInputEntries([]string{dir}, true, &warn)
+
+	var titles []string
+	for _, e := range entries {
+		titles = append(titles, e.Title)
+	}
+	want := []string{"Artist/Album/01 One", "artist two/a", "b"}
+	if strings.Join(titles, "|") != strings.Join(want, "|") {
+		t.Fatalf("titles = %q, want %q", titles, want)
+	}
+	if entries[0].Path != filepath.Join(dir, "Artist", "Album", "01 One.flac") {
+		t.Fatalf("expected absolute path for nested file, got %q", entries[0].Path)
+	}
+	if warn.Len() != 0 {
+		t.Fatalf("expected no warnings, got %q", warn.String())
+	}
+}
+
+func TestSavedFolderTrackResumesUnlessFresh(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("AppData", home)
+	t.Setenv("HOME", home)
+
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "01.mp3"), filepath.Join(dir, "02.mp3"), filepath.Join(dir, "03.mp3")}
+	if _, _, ok := savedFolderTrack(files); ok {
+		t.Fatal("expected no saved position for a new folder")
+	}
+	if err := config.SaveFolderPosition(dir, files, 1, 42*time.Second); err != nil {
+		t.Fatalf("SaveFolderPosition() error = %v", err)
+	}
+
+	path, at, ok := savedFolderTrack(files)
+	if !ok || path != files[1] || at != 42*time.Second {
+		t.Fatalf("savedFolderTrack() = %q, %v, %v; want 02.mp3 at 42s", path, at, ok)
+	}
+	if _, _, ok := savedFolderTrack(files[:2]); ok {
+		t.Fatal("expected a changed file list to start over")
+	}
+
+	freshStart = true
+	defer func() { freshStart = false }()
+	if _, _, ok := savedFolderTrack(files); ok {
+		t.Fatal("expected --fresh to ignore the saved position")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/olivier-w/climp/internal/media"
+	"github.com/olivier-w/climp/internal/player"
+	"github.com/olivier-w/climp/internal/ui"
+)
+
+// stdinArg is the input name that reads audio from standard input.
+const stdinArg = "-"
+
+// stdinSniffLen is how much of stdin is read up front to pick a decoder.
+const stdinSniffLen = 64
+
+// stdinIsTerminal reports whether stdin is an interactive terminal rather
+// than a pipe or redirected file.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// bufferStdin copies r to a temp file so the decoders can seek in it. The
+// file is named stdin with an extension sniffed from the first bytes.
+// cleanup removes it.
+func bufferStdin(r io.Reader) (path string, cleanup func(), err error) {
+	header := make([]byte, stdinSniffLen)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if errors.Is(err, io.EOF) {
+			return "", nil, fmt.Errorf("nothing to play on stdin")
+		}
+		return "", nil, err
+	}
+	header = header[:n]
+	ext := media.SniffExt(header)
+	if ext == "" {
+		return "", nil, fmt.Errorf("stdin is not a recognized audio format (supported: %s)", media.SupportedExtsList())
+	}
+
+	dir, err := os.MkdirTemp("", "climp-stdin-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	path = filepath.Join(dir, "stdin"+ext)
+	f, err := os.Create(path)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	_, err = f.Write(header)
+	if err == nil {
+		_, err = io.Copy(f, r)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("reading stdin: %w", err)
+	}
+	return path, cleanup, nil
+}
+
+// buildStdinModel plays audio piped to climp. All of stdin is buffered
+// before playback starts, so the track can seek like a local file.
+func buildStdinModel(r io.Reader) (ui.Model, error) {
+	path, cleanup, err := bufferStdin(r)
+	if err != nil {
+		return ui.Model{}, err
+	}
+	p, err := player.New(path)
+	if err != nil {
+		cleanup()
+		return ui.Model{}, fmt.Errorf("error creating player: %w", err)
+	}
+	return ui.New(p, player.ReadMetadata(path), "", "", cleanup), nil
+}